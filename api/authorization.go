@@ -0,0 +1,219 @@
+package api
+
+import (
+	"bank-api/apperr"
+	"bank-api/token"
+	"bank-api/util"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// errForbidden is returned when the caller is signed in but may not act on the requested resource
+var errForbidden = apperr.New(apperr.KindForbidden, "forbidden", "account doesn't belong to the authenticated user")
+
+// authorizedAccountKey holds the account accountOwner let the caller act on, see
+// authorizedAccount
+const authorizedAccountKey = "authorized_account"
+
+// accessPolicy tells whether the signed in caller may go on with the request. A policy returns
+// an *apperr.Error when the request itself is at fault, e.g. it names no valid account.
+type accessPolicy func(ctx *gin.Context, payload *token.Payload) (bool, error)
+
+// authorize runs the route's access policy after authMiddleware identified the caller. Admins may
+// act on any account, so they skip the policy altogether.
+func (server *Server) authorize(policy accessPolicy) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := authPayload(ctx)
+		if payload.Role == util.AdminRole {
+			ctx.Next()
+			return
+		}
+
+		allowed, err := policy(ctx, payload)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		if !allowed {
+			abortWithError(ctx, errForbidden)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// adminOnly lets no one but admins through
+func adminOnly() accessPolicy {
+	return func(ctx *gin.Context, payload *token.Payload) (bool, error) {
+		return false, nil
+	}
+}
+
+// accountOwner lets the caller through when the account the request acts on is one of its own
+func (server *Server) accountOwner(source accountIDSource) accessPolicy {
+	return func(ctx *gin.Context, payload *token.Payload) (bool, error) {
+		accountID, err := source(ctx)
+		if err != nil {
+			if apperr.KindOf(err) == apperr.KindInternal {
+				// a body that is no JSON object
+				err = apperr.Wrap(apperr.KindValidation, "invalid_request", err)
+			}
+			return false, err
+		}
+
+		allowed, err := server.holdsAccount(ctx, payload, accountID)
+		if allowed {
+			ctx.Set(authorizedAccountKey, accountID)
+		}
+		return allowed, err
+	}
+}
+
+// authorizedAccount tells whether the account a handler is about to act on is the one its
+// access policy let the caller act on. Admins skip the policies and may act on any account.
+func authorizedAccount(ctx *gin.Context, accountID int64) bool {
+	authorized, ok := ctx.Get(authorizedAccountKey)
+	return !ok || authorized.(int64) == accountID
+}
+
+// customerSelf lets the caller through when the customer whose ID is in the given URI parameter
+// is the caller itself
+func customerSelf(param string) accessPolicy {
+	return func(ctx *gin.Context, payload *token.Payload) (bool, error) {
+		customerID, err := strconv.ParseInt(ctx.Param(param), 10, 64)
+		if err != nil || customerID < 1 {
+			return false, invalidParam(param)
+		}
+
+		return customerID == payload.CustomerID, nil
+	}
+}
+
+// transferParticipant lets the caller through when it sent or received the transfer whose ID is
+// in the given URI parameter
+func (server *Server) transferParticipant(param string) accessPolicy {
+	return func(ctx *gin.Context, payload *token.Payload) (bool, error) {
+		transferID, err := strconv.ParseInt(ctx.Param(param), 10, 64)
+		if err != nil || transferID < 1 {
+			return false, invalidParam(param)
+		}
+
+		transfer, err := server.store.GetTransfer(ctx, transferID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return false, errTransferNotFound
+			}
+			return false, err
+		}
+
+		sent, err := server.holdsAccount(ctx, payload, transfer.FromAccountID)
+		if err != nil || sent {
+			return sent, err
+		}
+		return server.holdsAccount(ctx, payload, transfer.ToAccountID)
+	}
+}
+
+// webhookSubscriber lets the caller through when the webhook subscription whose ID is in the given
+// URI parameter is for one of its accounts. Subscriptions for every account are left to admins.
+func (server *Server) webhookSubscriber(param string) accessPolicy {
+	return func(ctx *gin.Context, payload *token.Payload) (bool, error) {
+		subscriptionID, err := strconv.ParseInt(ctx.Param(param), 10, 64)
+		if err != nil || subscriptionID < 1 {
+			return false, invalidParam(param)
+		}
+
+		subscription, err := server.store.GetWebhookSubscription(ctx, subscriptionID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return false, errWebhookNotFound
+			}
+			return false, err
+		}
+
+		if !subscription.AccountID.Valid {
+			return false, nil
+		}
+		return server.holdsAccount(ctx, payload, subscription.AccountID.Int64)
+	}
+}
+
+// holdsAccount tells whether the account belongs to the signed in customer
+func (server *Server) holdsAccount(ctx *gin.Context, payload *token.Payload, accountID int64) (bool, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, errAccountNotFound
+		}
+		return false, err
+	}
+
+	return account.CustomerID.Valid && account.CustomerID.Int64 == payload.CustomerID, nil
+}
+
+// accountIDSource extracts the ID of the account a request acts on
+type accountIDSource func(ctx *gin.Context) (int64, error)
+
+// uriAccountID reads the account ID from a URI parameter
+func uriAccountID(param string) accountIDSource {
+	return func(ctx *gin.Context) (int64, error) {
+		return parseAccountID(param, ctx.Param(param))
+	}
+}
+
+// queryAccountID reads the account ID from a query parameter
+func queryAccountID(param string) accountIDSource {
+	return func(ctx *gin.Context) (int64, error) {
+		return parseAccountID(param, ctx.Query(param))
+	}
+}
+
+// jsonAccountID reads the account ID from a field of the JSON body. The body is put back
+// afterwards, so the handler can still bind it as usual. It is decoded into a struct the way the
+// handler binds it, where keys match whatever their case and the last of several wins, so the
+// policy checks the account the handler acts on.
+func jsonAccountID(field string) accountIDSource {
+	return func(ctx *gin.Context) (int64, error) {
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			return 0, err
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		fields := reflect.New(reflect.StructOf([]reflect.StructField{{
+			Name: "AccountID",
+			Type: reflect.TypeFor[int64](),
+			Tag:  reflect.StructTag(fmt.Sprintf("json:%q", field)),
+		}}))
+		if err := json.Unmarshal(body, fields.Interface()); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				return 0, invalidParam(field)
+			}
+			return 0, err
+		}
+
+		accountID := fields.Elem().Field(0).Int()
+		if accountID < 1 {
+			return 0, invalidParam(field)
+		}
+		return accountID, nil
+	}
+}
+
+func parseAccountID(name string, value string) (int64, error) {
+	accountID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || accountID < 1 {
+		return 0, invalidParam(name)
+	}
+	return accountID, nil
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAuthorizeAccountOwner(t *testing.T) {
+	account := CreateUniqueRandomAccount(t)
+	otherAccountID := CreateUniqueRandomAccount(t).ID
+
+	// the customer's account in another currency is just as much theirs
+	currency := util.USD
+	if account.Currency == util.USD {
+		currency = util.EUR
+	}
+	secondAccount, err := testStore.OpenAccountTx(context.Background(), sqlc.OpenAccountTxParams{
+		CustomerID: account.CustomerID.Int64,
+		Currency:   currency,
+		CreatedAt:  time.Now(),
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		role         string
+		source       accountIDSource
+		path         string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "OwnURIAccount",
+			role:         util.DepositorRole,
+			source:       uriAccountID("id"),
+			path:         fmt.Sprintf("/accounts/%d", account.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "OwnSecondURIAccount",
+			role:         util.DepositorRole,
+			source:       uriAccountID("id"),
+			path:         fmt.Sprintf("/accounts/%d", secondAccount.Account.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "UnknownURIAccount",
+			role:         util.DepositorRole,
+			source:       uriAccountID("id"),
+			path:         fmt.Sprintf("/accounts/%d", secondAccount.Account.ID+1000),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "OtherURIAccount",
+			role:         util.DepositorRole,
+			source:       uriAccountID("id"),
+			path:         fmt.Sprintf("/accounts/%d", otherAccountID),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "OtherURIAccountAsAdmin",
+			role:         util.AdminRole,
+			source:       uriAccountID("id"),
+			path:         fmt.Sprintf("/accounts/%d", otherAccountID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "InvalidURIAccount",
+			role:         util.DepositorRole,
+			source:       uriAccountID("id"),
+			path:         "/accounts/abc",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "OwnQueryAccount",
+			role:         util.DepositorRole,
+			source:       queryAccountID("account"),
+			path:         fmt.Sprintf("/accounts/0?account=%d", account.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "OtherQueryAccount",
+			role:         util.DepositorRole,
+			source:       queryAccountID("account"),
+			path:         fmt.Sprintf("/accounts/0?account=%d", otherAccountID),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "OwnJSONAccount",
+			role:         util.DepositorRole,
+			source:       jsonAccountID("from_account_id"),
+			path:         "/accounts/0",
+			body:         fmt.Sprintf(`{"from_account_id": %d, "amount": 10}`, account.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "OtherJSONAccount",
+			role:         util.DepositorRole,
+			source:       jsonAccountID("from_account_id"),
+			path:         "/accounts/0",
+			body:         fmt.Sprintf(`{"from_account_id": %d, "amount": 10}`, otherAccountID),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			// the handler binds the last of the keys that match whatever their case
+			name:         "CaseVariantJSONAccount",
+			role:         util.DepositorRole,
+			source:       jsonAccountID("from_account_id"),
+			path:         "/accounts/0",
+			body:         fmt.Sprintf(`{"from_account_id": %d, "From_Account_ID": %d, "amount": 10}`, account.ID, otherAccountID),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "DuplicateJSONAccount",
+			role:         util.DepositorRole,
+			source:       jsonAccountID("from_account_id"),
+			path:         "/accounts/0",
+			body:         fmt.Sprintf(`{"from_account_id": %d, "from_account_id": %d, "amount": 10}`, account.ID, otherAccountID),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "StringJSONAccount",
+			role:         util.DepositorRole,
+			source:       jsonAccountID("from_account_id"),
+			path:         "/accounts/0",
+			body:         fmt.Sprintf(`{"from_account_id": "%d", "amount": 10}`, account.ID),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "MissingJSONAccount",
+			role:         util.DepositorRole,
+			source:       jsonAccountID("from_account_id"),
+			path:         "/accounts/0",
+			body:         `{"amount": 10}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, testStore)
+
+			server.router.POST("/accounts/:id",
+				authMiddleware(server.tokenMaker, server.store),
+				server.authorize(server.accountOwner(tc.source)),
+				func(ctx *gin.Context) {
+					// the body is still there for the handler to bind
+					body, err := io.ReadAll(ctx.Request.Body)
+					require.NoError(t, err)
+					require.Equal(t, tc.body, string(body))
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, tc.path, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, tc.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
+
+func TestAuthorizeAdminOnly(t *testing.T) {
+	account := sqlc.Account{ID: util.RandomInt(1, 1000), Email: util.RandomEmail()}
+
+	for role, expectedCode := range map[string]int{
+		util.DepositorRole: http.StatusForbidden,
+		util.AdminRole:     http.StatusOK,
+	} {
+		t.Run(role, func(t *testing.T) {
+			server := newTestServer(t, testStore)
+			server.router.GET("/admin", authMiddleware(server.tokenMaker, server.store), server.authorize(adminOnly()), func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, gin.H{})
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/admin", nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, expectedCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"bank-api/apperr"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	requestIDKey    = "request_id"
+	// maxRequestIDLength caps the request ID a client may bring along
+	maxRequestIDLength = 128
+)
+
+// statusOf maps the kinds of error to status codes
+var statusOf = map[apperr.Kind]int{
+	apperr.KindValidation:        http.StatusBadRequest,
+	apperr.KindUnauthorized:      http.StatusUnauthorized,
+	apperr.KindForbidden:         http.StatusForbidden,
+	apperr.KindNotFound:          http.StatusNotFound,
+	apperr.KindConflict:          http.StatusConflict,
+	apperr.KindInsufficientFunds: http.StatusUnprocessableEntity,
+	apperr.KindUnprocessable:     http.StatusUnprocessableEntity,
+	apperr.KindInternal:          http.StatusInternalServerError,
+}
+
+var (
+	errAccountNotFound  = apperr.New(apperr.KindNotFound, "account_not_found", "account not found")
+	errCustomerNotFound = apperr.New(apperr.KindNotFound, "customer_not_found", "customer not found")
+	errTransferNotFound = apperr.New(apperr.KindNotFound, "transfer_not_found", "transfer not found")
+	errWebhookNotFound  = apperr.New(apperr.KindNotFound, "webhook_not_found", "webhook subscription not found")
+	errRouteNotFound    = apperr.New(apperr.KindNotFound, "route_not_found", "no such route")
+)
+
+// errInternal is what the caller is told about an error it cannot do anything about
+var errInternal = apperr.New(apperr.KindInternal, "internal", "internal server error")
+
+// errorEnvelope is the body of every error response
+type errorEnvelope struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code      string          `json:"code"`
+	Message   string          `json:"message"`
+	Details   []apperr.Detail `json:"details,omitempty"`
+	RequestID string          `json:"request_id"`
+}
+
+// requestID tags every request with an ID, the one the client sent in X-Request-ID or a new one.
+// It is echoed in the response header and in error bodies, and logged with internal errors.
+func requestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(requestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = uuid.NewString()
+		}
+		ctx.Set(requestIDKey, id)
+		ctx.Header(requestIDHeader, id)
+		ctx.Next()
+	}
+}
+
+// handleErrors renders the error a handler or middleware recorded with ctx.Error. Handlers never
+// write error responses themselves, they record the error and return.
+func handleErrors() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+		writeError(ctx)
+	}
+}
+
+// writeError writes the response for the last recorded error, unless a response was written
+// already. Middleware that needs the response before handleErrors gets to it calls it directly.
+func writeError(ctx *gin.Context) {
+	if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+		return
+	}
+
+	err := apperr.FromDB(ctx.Errors.Last().Err)
+	id := ctx.GetString(requestIDKey)
+
+	var appErr *apperr.Error
+	if !errors.As(err, &appErr) || appErr.Kind == apperr.KindInternal {
+		log.Printf("request %s %s %s: %v", id, ctx.Request.Method, ctx.Request.URL.Path, err)
+		appErr = errInternal
+	}
+
+	// a domain error wrapped with more context keeps that context in the message, it is ours
+	message := appErr.Message
+	if appErr != errInternal && appErr.Err == nil {
+		message = err.Error()
+	}
+
+	ctx.AbortWithStatusJSON(statusOf[appErr.Kind], errorEnvelope{Error: errorBody{
+		Code:      appErr.Code,
+		Message:   message,
+		Details:   appErr.Details,
+		RequestID: id,
+	}})
+}
+
+// abortWithError records the error and stops the chain, for middleware turning a request away
+func abortWithError(ctx *gin.Context, err error) {
+	ctx.Error(err)
+	ctx.Abort()
+}
+
+// invalidRequest is the error of a request that failed to bind: a body that is no valid JSON, or
+// fields that fail their binding rules, each of which becomes a detail
+func invalidRequest(err error) error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return apperr.Wrap(apperr.KindValidation, "invalid_request", err)
+	}
+
+	details := make([]apperr.Detail, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		details = append(details, apperr.Detail{Field: fieldErr.Field(), Message: ruleMessage(fieldErr)})
+	}
+	return &apperr.Error{
+		Kind:    apperr.KindValidation,
+		Code:    "invalid_request",
+		Message: "invalid request",
+		Details: details,
+		Err:     err,
+	}
+}
+
+// invalidParam is the error of a URI or query parameter that is missing or malformed
+func invalidParam(name string) error {
+	return &apperr.Error{
+		Kind:    apperr.KindValidation,
+		Code:    "invalid_request",
+		Message: fmt.Sprintf("invalid %s", name),
+		Details: []apperr.Detail{{Field: name, Message: "is invalid"}},
+	}
+}
+
+// ruleMessage describes the binding rule a field failed
+func ruleMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", fieldErr.Param())
+	case "email":
+		return "must be an email address"
+	case "currency":
+		return "must be a supported currency code"
+	case "event_type":
+		return "must be a known event type"
+	case "bcrypt":
+		return fmt.Sprintf("must be at most %d bytes", maxPasswordBytes)
+	case "http_url":
+		return "must be an http or https URL"
+	case "uuid":
+		return "must be a UUID"
+	}
+	return fmt.Sprintf("fails the %s rule", fieldErr.Tag())
+}
+
+// fieldName names fields in validation errors the way the client sends them: by their JSON key,
+// or their query or URI parameter
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
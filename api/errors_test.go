@@ -0,0 +1,198 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// requireErrorBody checks that the response is an error envelope with the status and code
+func requireErrorBody(t *testing.T, recorder *httptest.ResponseRecorder, status int, code string) errorBody {
+	require.Equal(t, status, recorder.Code, recorder.Body.String())
+
+	var envelope errorEnvelope
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &envelope))
+	require.Equal(t, code, envelope.Error.Code)
+	require.NotEmpty(t, envelope.Error.Message)
+	require.Equal(t, recorder.Header().Get(requestIDHeader), envelope.Error.RequestID)
+	return envelope.Error
+}
+
+func signupRequest(t *testing.T, body gin.H) *http.Request {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(data))
+	require.NoError(t, err)
+	return request
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	server := newTestServer(t, sqlc.NewMemoryStore())
+	account := randomAccount(util.JPY)
+
+	t.Run("RequestIDEchoed", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d", account.ID), nil)
+		require.NoError(t, err)
+		request.Header.Set(requestIDHeader, "req-42")
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, util.DepositorRole, time.Minute)
+
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, "req-42", recorder.Header().Get(requestIDHeader))
+		body := requireErrorBody(t, recorder, http.StatusNotFound, "account_not_found")
+		require.Equal(t, "req-42", body.RequestID)
+	})
+
+	t.Run("RequestIDGenerated", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/accounts/1", nil)
+		require.NoError(t, err)
+
+		server.router.ServeHTTP(recorder, request)
+		requireErrorBody(t, recorder, http.StatusUnauthorized, "missing_authorization")
+		require.NotEmpty(t, recorder.Header().Get(requestIDHeader))
+	})
+
+	t.Run("ValidationDetails", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, signupRequest(t, gin.H{
+			"owner":    util.RandomOwner(),
+			"currency": "XYZ",
+			"email":    "not-an-email",
+			"password": "short",
+		}))
+
+		body := requireErrorBody(t, recorder, http.StatusBadRequest, "invalid_request")
+		fields := map[string]string{}
+		for _, detail := range body.Details {
+			fields[detail.Field] = detail.Message
+		}
+		require.Equal(t, map[string]string{
+			"currency": "must be a supported currency code",
+			"email":    "must be an email address",
+			"password": "must be at least 8",
+		}, fields)
+	})
+
+	t.Run("PasswordTooLong", func(t *testing.T) {
+		// bcrypt hashes 72 bytes at most, a longer password is refused rather than failing the hash
+		for password, message := range map[string]string{
+			util.RandomString(73):   "must be at most 72",
+			strings.Repeat("あ", 25): "must be at most 72 bytes",
+		} {
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, signupRequest(t, gin.H{
+				"owner":    util.RandomOwner(),
+				"currency": util.JPY,
+				"email":    util.RandomEmail(),
+				"password": password,
+			}))
+
+			body := requireErrorBody(t, recorder, http.StatusBadRequest, "invalid_request")
+			require.Len(t, body.Details, 1)
+			require.Equal(t, "password", body.Details[0].Field)
+			require.Equal(t, message, body.Details[0].Message)
+		}
+	})
+
+	t.Run("MalformedJSON", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewBufferString("{"))
+		require.NoError(t, err)
+
+		server.router.ServeHTTP(recorder, request)
+		requireErrorBody(t, recorder, http.StatusBadRequest, "invalid_request")
+	})
+
+	t.Run("UnknownEmail", func(t *testing.T) {
+		data, err := json.Marshal(gin.H{"email": util.RandomEmail(), "password": util.RandomString(8)})
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodPost, "/accounts/login", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		server.router.ServeHTTP(recorder, request)
+		body := requireErrorBody(t, recorder, http.StatusUnauthorized, "invalid_credentials")
+		require.Equal(t, errInvalidCredentials.Message, body.Message)
+	})
+
+	t.Run("NoRoute", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/nowhere", nil)
+		require.NoError(t, err)
+
+		server.router.ServeHTTP(recorder, request)
+		requireErrorBody(t, recorder, http.StatusNotFound, "route_not_found")
+	})
+}
+
+func TestErrorEnvelopeStoreErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		check  func(t *testing.T, body errorBody)
+	}{
+		{
+			name:   "UniqueViolation",
+			err:    &pq.Error{Code: "23505", Detail: "Key (email)=(a@b.c) already exists.", Constraint: "accounts_email_key"},
+			status: http.StatusConflict,
+			code:   "already_exists",
+			check: func(t *testing.T, body errorBody) {
+				require.Len(t, body.Details, 1)
+				require.Equal(t, "email", body.Details[0].Field)
+			},
+		},
+		{
+			name:   "InternalError",
+			err:    errors.New("dial tcp 10.0.0.5:5432: connection refused"),
+			status: http.StatusInternalServerError,
+			code:   "internal",
+			check: func(t *testing.T, body errorBody) {
+				require.Equal(t, errInternal.Message, body.Message)
+				require.Empty(t, body.Details)
+			},
+		},
+		{
+			name:   "WrappedDomainError",
+			err:    fmt.Errorf("%w: account [7]", sqlc.ErrInsufficientFunds),
+			status: http.StatusUnprocessableEntity,
+			code:   "insufficient_funds",
+			check: func(t *testing.T, body errorBody) {
+				require.Equal(t, "insufficient funds: account [7]", body.Message)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newMockStore(t)
+			store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(1).Return(sqlc.CreateAccountTxResult{}, tc.err)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, signupRequest(t, gin.H{
+				"owner":    util.RandomOwner(),
+				"currency": util.JPY,
+				"email":    util.RandomEmail(),
+				"password": util.RandomString(8),
+			}))
+
+			tc.check(t, requireErrorBody(t, recorder, tc.status, tc.code))
+		})
+	}
+}
@@ -1,120 +1,181 @@
 package api
 
 import (
+	"bank-api/apperr"
 	"bank-api/db/sqlc"
+	"bank-api/interest"
+	"bank-api/token"
+	"bank-api/util"
 	"database/sql"
 	"errors"
 	"github.com/Meenachinmay/microservice-shared/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"net/http"
 	"time"
 )
 
 type createAccountRequest struct {
 	Owner        string    `json:"owner" binding:"required"`
-	Currency     string    `json:"currency" binding:"required,oneof=YEN EUR USD"`
+	Currency     string    `json:"currency" binding:"required,currency"`
 	Email        string    `json:"email" binding:"required,email"`
+	Password     string    `json:"password" binding:"required,min=8,max=72,bcrypt"`
 	ReferralCode string    `json:"referral_code"`
 	CreatedAt    time.Time `json:"createdAt"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
 	var req createAccountRequest
-	var newBalance int64
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.Error(invalidRequest(err))
 		return
 	}
 
+	hashedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	arg := sqlc.CreateAccountTxParams{
+		CreateAccountParams: sqlc.CreateAccountParams{
+			Owner:     req.Owner,
+			Currency:  req.Currency,
+			Email:     req.Email,
+			CreatedAt: utils.ConvertToTokyoTime(),
+		},
+		HashedPassword: hashedPassword,
+	}
+
 	if req.ReferralCode != "" {
-		// TODO: mark code as used (this will referred_account extra interest of 1% for the following month
-		_, err := server.markCode(ctx, req.ReferralCode)
+		// the referrer is emailed from the referral.redeemed event the signup publishes
+		result, err := server.store.SignupWithReferralTx(ctx, sqlc.SignupWithReferralTxParams{
+			CreateAccountTxParams: arg,
+			ReferralCode:          req.ReferralCode,
+			BonusAmount:           server.config.Referral.WelcomeBonus,
+		})
 		if err != nil {
-			if err.Error() == "referral code is already used" {
-				ctx.JSON(http.StatusConflict, gin.H{"error": "This referral code is already used."})
-				return
-			}
-			if err.Error() == "invalid referral code" {
-				ctx.JSON(http.StatusConflict, gin.H{"error": "Referral code is invalid."})
-				return
-			}
-			if err.Error() == "error with referral code" {
-				ctx.JSON(http.StatusConflict, gin.H{"error": "Referral code is invalid."})
-				return
-			}
-			// TODO: log as CRUCIAL error as referralCode couldn't be used
+			ctx.Error(err)
+			return
 		}
-		// TODO: update balance as gift for new user joined with code
-		newBalance = 1000
-		// TODO: send email to referrer_account as notification so referrer cannot know about it.
-	}
 
-	arg := sqlc.CreateAccountParams{
-		Owner:     req.Owner,
-		Currency:  req.Currency,
-		Email:     req.Email,
-		Balance:   newBalance,
-		CreatedAt: utils.ConvertToTokyoTime(),
+		ctx.JSON(http.StatusOK, result.Account)
+		return
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	result, err := server.store.CreateAccountTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, result.Account)
+}
+
+type loginAccountRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type loginAccountResponse struct {
+	SessionID             uuid.UUID      `json:"session_id"`
+	AccessToken           string         `json:"access_token"`
+	AccessTokenExpiresAt  time.Time      `json:"access_token_expires_at"`
+	RefreshToken          string         `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time      `json:"refresh_token_expires_at"`
+	Customer              sqlc.Customer  `json:"customer"`
+	Accounts              []sqlc.Account `json:"accounts"`
 }
 
-func (server *Server) markCode(ctx *gin.Context, code string) (sqlc.ReferralCode, error) {
-	// TODO: fetch the code to check if used or not
-	referralCodeToCheck, err := server.store.GetReferralCode(ctx, code)
+// errInvalidCredentials is returned for an unknown email as well as a wrong password, so the
+// login endpoint cannot be used to find out which emails have an account.
+var errInvalidCredentials = apperr.New(apperr.KindUnauthorized, "invalid_credentials", "invalid email or password")
+
+// dummyPasswordHash is checked the password against when there is no customer or credential to
+// check it against, so a login for an unknown email takes as long as one with a wrong password.
+// It is a bcrypt hash of the same cost util.HashPassword uses, of no one's password.
+const dummyPasswordHash = "$2a$10$T3JM8UraL4P2T3hoqHiOwudJuWOFdglZNiEF3tO0xOafqbz5LPAN6"
+
+// loginAccount signs a customer in with their email and password. The tokens are good for all
+// of the customer's accounts, which are returned along with them.
+func (server *Server) loginAccount(ctx *gin.Context) {
+	var req loginAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	customer, err := server.store.GetCustomerByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return sqlc.ReferralCode{}, errors.New("invalid referral code")
+			util.CheckPassword(req.Password, dummyPasswordHash)
+			ctx.Error(errInvalidCredentials)
+			return
 		}
-		return sqlc.ReferralCode{}, errors.New("error with referral code")
+		ctx.Error(err)
+		return
 	}
 
-	if referralCodeToCheck.IsUsed {
-		return referralCodeToCheck, errors.New("referral code is already used")
+	// customers from before passwords have no credential until they choose one, see
+	// requestPasswordReset
+	credential, err := server.store.GetCustomerCredential(ctx, customer.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			util.CheckPassword(req.Password, dummyPasswordHash)
+			ctx.Error(errInvalidCredentials)
+			return
+		}
+		ctx.Error(err)
+		return
 	}
 
-	args := sqlc.MarkReferralCodeUsedParams{
-		ReferralCode: code,
-		UsedAt:       sql.NullTime{Time: utils.ConvertToTokyoTime(), Valid: true},
+	if err := util.CheckPassword(req.Password, credential.HashedPassword); err != nil {
+		ctx.Error(errInvalidCredentials)
+		return
 	}
-	referralCode, err := server.store.MarkReferralCodeUsed(ctx, args)
+
+	accounts, err := server.store.ListCustomerAccounts(ctx, sql.NullInt64{Int64: customer.ID, Valid: true})
 	if err != nil {
-		return sqlc.ReferralCode{}, err
+		ctx.Error(err)
+		return
 	}
 
-	return referralCode, nil
-}
-
-type loginAccountRequest struct {
-	Email string `json:"email" binding:"required"`
-}
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(customer.ID, customer.Email, credential.Role, token.TokenTypeAccessToken, server.config.AccessTokenDuration)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
 
-func (server *Server) loginAccount(ctx *gin.Context) {
-	var req loginAccountRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(customer.ID, customer.Email, credential.Role, token.TokenTypeRefreshToken, server.config.RefreshTokenDuration)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
 
-	// check if email exists
-	account, err := server.store.GetAccountWithEmail(ctx, req.Email)
+	session, err := server.store.CreateSession(ctx, sqlc.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		CustomerID:   customer.ID,
+		RefreshToken: refreshToken,
+		UserAgent:    ctx.Request.UserAgent(),
+		ClientIp:     ctx.ClientIP(),
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+	})
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(errors.New("email does not exist")))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	rsp := loginAccountResponse{
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
+		Customer:              customer,
+		Accounts:              accounts,
+	}
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 type getAccountRequest struct {
@@ -124,21 +185,46 @@ type getAccountRequest struct {
 func (server *Server) getAccount(ctx *gin.Context) {
 	var req getAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.Error(invalidRequest(err))
 		return
 	}
 
 	account, err := server.store.GetAccount(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Error(errAccountNotFound)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, newAccountResponse(account, utils.ConvertToTokyoTime()))
+}
+
+// accountResponse is an account along with the interest it earns today
+type accountResponse struct {
+	sqlc.Account
+	// the balance for display in the account's currency, e.g. ¥1,000 or €10.00
+	FormattedBalance string `json:"formatted_balance"`
+	// base plus extra interest in effect today, in percent
+	EffectiveInterest float64 `json:"effective_interest"`
+	// first day the extra interest no longer applies
+	ExtraInterestExpiresAt sql.NullTime `json:"extra_interest_expires_at"`
+}
+
+func newAccountResponse(account sqlc.Account, now time.Time) accountResponse {
+	response := accountResponse{
+		Account:           account,
+		EffectiveInterest: interest.AnnualRate(account, now),
+	}
+	if currency, ok := util.LookupCurrency(account.Currency); ok {
+		response.FormattedBalance = currency.Format(account.Balance)
+	}
+	if expiry, ok := sqlc.ExtraInterestExpiry(account); ok {
+		response.ExtraInterestExpiresAt = sql.NullTime{Time: expiry, Valid: true}
+	}
+	return response
 }
 
 type getAccountsRequest struct {
@@ -149,7 +235,7 @@ type getAccountsRequest struct {
 func (server *Server) getAccounts(ctx *gin.Context) {
 	var req getAccountsRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.Error(invalidRequest(err))
 		return
 	}
 
@@ -160,7 +246,7 @@ func (server *Server) getAccounts(ctx *gin.Context) {
 
 	accounts, err := server.store.ListAccounts(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.Error(err)
 		return
 	}
 
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"database/sql"
+	"errors"
+	"github.com/Meenachinmay/microservice-shared/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type customerRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// listCustomerAccounts returns every account the customer holds, one per currency
+func (server *Server) listCustomerAccounts(ctx *gin.Context) {
+	var req customerRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	customer, err := server.store.GetCustomer(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Error(errCustomerNotFound)
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+
+	accounts, err := server.store.ListCustomerAccounts(ctx, sql.NullInt64{Int64: customer.ID, Valid: true})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	now := utils.ConvertToTokyoTime()
+	rsp := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		rsp[i] = newAccountResponse(account, now)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type openCustomerAccountRequest struct {
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+// openCustomerAccount opens another account for a signed up customer, in a currency they hold
+// no account in yet
+func (server *Server) openCustomerAccount(ctx *gin.Context) {
+	var uriReq customerRequest
+	if err := ctx.ShouldBindUri(&uriReq); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	var req openCustomerAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	result, err := server.store.OpenAccountTx(ctx, sqlc.OpenAccountTxParams{
+		CustomerID: uriReq.ID,
+		Currency:   req.Currency,
+		CreatedAt:  utils.ConvertToTokyoTime(),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Error(errCustomerNotFound)
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(result.Account, utils.ConvertToTokyoTime()))
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCustomerAccounts(t *testing.T) {
+	server := newTestServer(t, testStore)
+	account := createAccountWithBalance(t, util.JPY, 500)
+	other := createAccountWithBalance(t, util.JPY, 0)
+	url := fmt.Sprintf("/customers/%d/accounts", account.CustomerID.Int64)
+
+	listAccounts := func(caller sqlc.Account, role string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, caller, role, time.Minute)
+
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// a customer opens a second account in another currency, but not in one they already hold
+	recorder := postJSON(t, server, url, account, util.DepositorRole, gin.H{"currency": util.USD})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var opened accountResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &opened))
+	require.Equal(t, util.USD, opened.Currency)
+	require.Equal(t, account.CustomerID, opened.CustomerID)
+	require.Equal(t, "$0.00", opened.FormattedBalance)
+
+	require.Equal(t, http.StatusConflict, postJSON(t, server, url, account, util.DepositorRole, gin.H{"currency": util.JPY}).Code)
+	require.Equal(t, http.StatusBadRequest, postJSON(t, server, url, account, util.DepositorRole, gin.H{"currency": "GBP"}).Code)
+	require.Equal(t, http.StatusForbidden, postJSON(t, server, url, other, util.DepositorRole, gin.H{"currency": util.EUR}).Code)
+
+	recorder = listAccounts(account, util.DepositorRole)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var accounts []accountResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &accounts))
+	require.Len(t, accounts, 2)
+	require.Equal(t, account.ID, accounts[0].ID)
+	require.Equal(t, "¥500", accounts[0].FormattedBalance)
+	require.Equal(t, opened.ID, accounts[1].ID)
+
+	// the new account is the customer's to act on like the first one
+	recorder = httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d", opened.ID), nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, util.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	require.Equal(t, http.StatusForbidden, listAccounts(other, util.DepositorRole).Code)
+	require.Equal(t, http.StatusOK, listAccounts(other, util.AdminRole).Code)
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"bank-api/apperr"
+	"bank-api/db/sqlc"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type listAccountEntriesURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listAccountEntriesRequest struct {
+	// ID of the last entry of the previous page
+	Cursor   int64  `form:"cursor" binding:"omitempty,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=50"`
+	From     string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To       string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	// credit for money in, debit for money out, both when empty
+	Direction string `form:"direction" binding:"omitempty,oneof=credit debit"`
+}
+
+// entrySource is the transfer, bonus, interest payout or adjustment that caused an entry
+type entrySource struct {
+	// transfer, bonus, interest or adjustment
+	Type      string `json:"type"`
+	JournalID int64  `json:"journal_id"`
+	// adjustments are not transfers and have neither
+	TransferID            int64 `json:"transfer_id,omitempty"`
+	CounterpartyAccountID int64 `json:"counterparty_account_id,omitempty"`
+	// the month an interest payout was for
+	InterestPeriod string `json:"interest_period,omitempty"`
+}
+
+type entryResponse struct {
+	ID        int64     `json:"id"`
+	Amount    int64     `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+	// the account balance right after the entry
+	RunningBalance int64 `json:"running_balance"`
+	// nil for entries that predate journals and could not be matched to a transfer
+	Source *entrySource `json:"source"`
+}
+
+type listAccountEntriesResponse struct {
+	Entries []entryResponse `json:"entries"`
+	// pass as cursor to get the next page, 0 on the last page
+	NextCursor int64 `json:"next_cursor"`
+}
+
+// listAccountEntries returns the movements of an account, newest first, a page at a time.
+// From and to are Tokyo calendar dates, both inclusive.
+func (server *Server) listAccountEntries(ctx *gin.Context) {
+	var uri listAccountEntriesURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	var req listAccountEntriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	arg := sqlc.ListAccountEntriesParams{
+		AccountID: uri.ID,
+		BeforeID:  sql.NullInt64{Int64: req.Cursor, Valid: req.Cursor > 0},
+		Direction: sql.NullString{String: req.Direction, Valid: req.Direction != ""},
+		// one more than asked for, to tell whether there is a next page
+		PageSize: req.PageSize + 1,
+	}
+	if req.From != "" {
+		from, _ := time.ParseInLocation(time.DateOnly, req.From, server.loc)
+		arg.FromTime = sql.NullTime{Time: from, Valid: true}
+	}
+	if req.To != "" {
+		to, _ := time.ParseInLocation(time.DateOnly, req.To, server.loc)
+		arg.ToTime = sql.NullTime{Time: to.AddDate(0, 0, 1), Valid: true}
+	}
+	if arg.FromTime.Valid && arg.ToTime.Valid && !arg.FromTime.Time.Before(arg.ToTime.Time) {
+		ctx.Error(&apperr.Error{
+			Kind:    apperr.KindValidation,
+			Code:    "invalid_request",
+			Message: "from must not be after to",
+			Details: []apperr.Detail{{Field: "from", Message: "must not be after to"}},
+		})
+		return
+	}
+
+	if _, err := server.store.GetAccount(ctx, uri.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Error(errAccountNotFound)
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+
+	rows, err := server.store.ListAccountEntries(ctx, arg)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := listAccountEntriesResponse{Entries: []entryResponse{}}
+	if len(rows) > int(req.PageSize) {
+		rows = rows[:req.PageSize]
+		response.NextCursor = rows[len(rows)-1].ID
+	}
+	for _, row := range rows {
+		response.Entries = append(response.Entries, newEntryResponse(row))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func newEntryResponse(row sqlc.ListAccountEntriesRow) entryResponse {
+	entry := entryResponse{
+		ID:             row.ID,
+		Amount:         row.Amount,
+		CreatedAt:      row.CreatedAt,
+		RunningBalance: row.RunningBalance,
+	}
+	if !row.JournalID.Valid {
+		return entry
+	}
+
+	entry.Source = &entrySource{
+		Type:                  row.ReferenceType,
+		JournalID:             row.JournalID.Int64,
+		TransferID:            row.TransferID.Int64,
+		CounterpartyAccountID: row.CounterpartyAccountID.Int64,
+		InterestPeriod:        row.InterestPeriod.String,
+	}
+	return entry
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Meenachinmay/microservice-shared/utils"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func getAccountEntries(t *testing.T, server *Server, account sqlc.Account, query string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", fmt.Sprintf("/accounts/%d/entries?%s", account.ID, query), nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, util.DepositorRole, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestListAccountEntries(t *testing.T) {
+	server := newTestServer(t, testStore)
+	account := createAccountWithBalance(t, util.JPY, 1000)
+	other := createAccountWithBalance(t, util.JPY, 0)
+
+	for i := int64(1); i <= 6; i++ {
+		_, err := testStore.TransferTx(context.Background(), sqlc.TransferTxParams{
+			FromAccountID: account.ID,
+			ToAccountID:   other.ID,
+			Amount:        i * 10,
+		})
+		require.NoError(t, err)
+	}
+
+	// and 5 of interest on top
+	today := utils.ConvertToTokyoTime()
+	_, err := testStore.CreateInterestAccrual(context.Background(), sqlc.CreateInterestAccrualParams{
+		AccountID:   account.ID,
+		AccrualDate: today,
+		AmountMicro: 5_000_000,
+	})
+	require.NoError(t, err)
+	payout, err := testStore.PayInterestTx(context.Background(), sqlc.PayInterestTxParams{
+		AccountID: account.ID,
+		Period:    today.Format("2006-01"),
+		To:        today,
+	})
+	require.NoError(t, err)
+
+	recorder := getAccountEntries(t, server, account, "page_size=5")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var page listAccountEntriesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.Len(t, page.Entries, 5)
+
+	interest := page.Entries[0]
+	require.Equal(t, int64(5), interest.Amount)
+	require.Equal(t, int64(795), interest.RunningBalance)
+	require.Equal(t, &entrySource{
+		Type:                  sqlc.ReferenceInterest,
+		JournalID:             interest.Source.JournalID,
+		TransferID:            payout.Transfer.ID,
+		CounterpartyAccountID: payout.Transfer.FromAccountID,
+		InterestPeriod:        today.Format("2006-01"),
+	}, interest.Source)
+
+	wantAmounts := []int64{-60, -50, -40, -30}
+	wantBalances := []int64{790, 850, 900, 940}
+	for i, entry := range page.Entries[1:] {
+		require.Equal(t, wantAmounts[i], entry.Amount)
+		require.Equal(t, wantBalances[i], entry.RunningBalance)
+		require.Equal(t, sqlc.ReferenceTransfer, entry.Source.Type)
+		require.Equal(t, other.ID, entry.Source.CounterpartyAccountID)
+	}
+	require.Equal(t, page.Entries[4].ID, page.NextCursor)
+
+	recorder = getAccountEntries(t, server, account, fmt.Sprintf("page_size=5&cursor=%d", page.NextCursor))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.Len(t, page.Entries, 2)
+	require.Equal(t, int64(-20), page.Entries[0].Amount)
+	require.Equal(t, int64(970), page.Entries[0].RunningBalance)
+	require.Equal(t, int64(-10), page.Entries[1].Amount)
+	require.Equal(t, int64(990), page.Entries[1].RunningBalance)
+	require.Zero(t, page.NextCursor)
+
+	recorder = getAccountEntries(t, server, account, "page_size=5&direction=credit")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.Len(t, page.Entries, 1)
+	require.Equal(t, int64(5), page.Entries[0].Amount)
+
+	tomorrow := today.AddDate(0, 0, 1).Format(time.DateOnly)
+	recorder = getAccountEntries(t, server, account, "page_size=5&from="+tomorrow)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.Empty(t, page.Entries)
+
+	recorder = getAccountEntries(t, server, account, "page_size=5&to="+today.Format(time.DateOnly))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.Len(t, page.Entries, 5)
+}
+
+func TestListAccountEntriesBadRequest(t *testing.T) {
+	server := newTestServer(t, testStore)
+	account := createAccountWithBalance(t, util.JPY, 0)
+
+	for _, query := range []string{
+		"",
+		"page_size=5&direction=sideways",
+		"page_size=5&from=2024-13-01",
+		"page_size=5&from=2024-07-02&to=2024-07-01",
+	} {
+		recorder := getAccountEntries(t, server, account, query)
+		require.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+}
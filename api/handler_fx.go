@@ -0,0 +1,88 @@
+package api
+
+import (
+	"bank-api/fx"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// listFxRates returns the current rate of every currency pair
+func (server *Server) listFxRates(ctx *gin.Context) {
+	rates, err := server.store.ListLatestFxRates(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rates)
+}
+
+type setFxRateRequest struct {
+	BaseCurrency  string `json:"base_currency" binding:"required,currency"`
+	QuoteCurrency string `json:"quote_currency" binding:"required,currency,nefield=BaseCurrency"`
+	// a decimal string, units of the quote currency one unit of the base currency buys
+	Rate string `json:"rate" binding:"required"`
+}
+
+// setFxRate records a new current rate for a currency pair, on behalf of the signed in admin
+func (server *Server) setFxRate(ctx *gin.Context) {
+	var req setFxRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	rate, err := fx.SetRate(ctx, server.store, fx.SetRateParams{
+		BaseCurrency:  req.BaseCurrency,
+		QuoteCurrency: req.QuoteCurrency,
+		Rate:          req.Rate,
+		Source:        authPayload(ctx).Email,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rate)
+}
+
+type createFxQuoteRequest struct {
+	AccountID  int64  `json:"account_id" binding:"required,min=1"`
+	ToCurrency string `json:"to_currency" binding:"required,currency"`
+	// in the minor unit of the account's currency
+	Amount int64 `json:"amount" binding:"required,gt=0"`
+}
+
+// createFxQuote locks the current rate for converting an amount out of an account. The quote
+// is good for one transfer until it expires, see fx.QuoteDuration.
+func (server *Server) createFxQuote(ctx *gin.Context) {
+	var req createFxQuoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+	if !authorizedAccount(ctx, req.AccountID) {
+		ctx.Error(errForbidden)
+		return
+	}
+
+	quote, err := fx.CreateQuote(ctx, server.store, fx.QuoteParams{
+		AccountID:  req.AccountID,
+		ToCurrency: req.ToCurrency,
+		Amount:     req.Amount,
+		Now:        time.Now(),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Error(errAccountNotFound)
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
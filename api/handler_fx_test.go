@@ -0,0 +1,117 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func postJSON(t *testing.T, server *Server, url string, account sqlc.Account, role string, body gin.H) *httptest.ResponseRecorder {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, role, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestSetAndListFxRates(t *testing.T) {
+	server := newTestServer(t, sqlc.NewMemoryStore())
+	admin := CreateUniqueRandomAccount(t)
+
+	rate := gin.H{"base_currency": util.USD, "quote_currency": util.INR, "rate": "83.25"}
+	require.Equal(t, http.StatusForbidden, postJSON(t, server, "/admin/fx/rates", admin, util.DepositorRole, rate).Code)
+
+	for _, body := range []gin.H{
+		{"base_currency": util.USD, "quote_currency": "YEN", "rate": "150"},
+		{"base_currency": util.USD, "quote_currency": util.USD, "rate": "1"},
+		{"base_currency": util.USD, "quote_currency": util.INR, "rate": "-83"},
+		{"base_currency": util.USD, "quote_currency": util.INR, "rate": "83.1234567890123"},
+	} {
+		require.Equal(t, http.StatusBadRequest, postJSON(t, server, "/admin/fx/rates", admin, util.AdminRole, body).Code, body)
+	}
+
+	recorder := postJSON(t, server, "/admin/fx/rates", admin, util.AdminRole, rate)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var created sqlc.FxRate
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &created))
+	require.Equal(t, "83.250000000000", created.Rate)
+	require.Equal(t, admin.Email, created.Source)
+
+	recorder = httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/fx/rates", nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin, util.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var rates []sqlc.FxRate
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rates))
+	require.Equal(t, []sqlc.FxRate{created}, rates)
+}
+
+func TestCreateFxTransfer(t *testing.T) {
+	server := newTestServer(t, testStore)
+	admin := CreateUniqueRandomAccount(t)
+	jpyAccount := createAccountWithBalance(t, util.JPY, 20_000)
+	eurAccount := createAccountWithBalance(t, util.EUR, 0)
+
+	require.Equal(t, http.StatusOK, postJSON(t, server, "/admin/fx/rates", admin, util.AdminRole,
+		gin.H{"base_currency": util.EUR, "quote_currency": util.JPY, "rate": "162"}).Code)
+
+	// only the owner of the account can get a quote for it
+	quoteRequest := gin.H{"account_id": jpyAccount.ID, "to_currency": util.EUR, "amount": 10_000}
+	require.Equal(t, http.StatusForbidden, postJSON(t, server, "/fx/quotes", eurAccount, util.DepositorRole, quoteRequest).Code)
+	require.Equal(t, http.StatusNotFound, postJSON(t, server, "/fx/quotes", jpyAccount, util.DepositorRole,
+		gin.H{"account_id": jpyAccount.ID, "to_currency": util.INR, "amount": 10_000}).Code)
+
+	recorder := postJSON(t, server, "/fx/quotes", jpyAccount, util.DepositorRole, quoteRequest)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var quote sqlc.FxQuote
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &quote))
+	require.Equal(t, "0.006172839506", quote.Rate)
+	require.Equal(t, int64(6172), quote.ConvertedAmount)
+
+	transfer := gin.H{
+		"from_account_id": jpyAccount.ID,
+		"to_account_id":   eurAccount.ID,
+		"amount":          10_000,
+		"currency":        util.JPY,
+	}
+	// without the quote the currencies don't match
+	require.Equal(t, http.StatusBadRequest, postTransfer(t, server, jpyAccount, transfer).Code)
+
+	transfer["fx_quote_id"] = quote.ID.String()
+	recorder = postTransfer(t, server, jpyAccount, transfer)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var result transferResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	require.Equal(t, quote.Rate, result.Transfer.FxRate.String)
+	require.Equal(t, int64(6172), result.Transfer.ConvertedAmount.Int64)
+	require.Equal(t, jpyAccount.Balance-10_000, result.FromAccount.Balance)
+
+	received, err := testStore.GetAccount(context.Background(), eurAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, eurAccount.Balance+6172, received.Balance)
+
+	// the quote is burned
+	require.Equal(t, http.StatusConflict, postTransfer(t, server, jpyAccount, transfer).Code)
+
+	transfer["fx_quote_id"] = "not-a-uuid"
+	require.Equal(t, http.StatusBadRequest, postTransfer(t, server, jpyAccount, transfer).Code)
+}
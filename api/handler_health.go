@@ -0,0 +1,186 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Version is the version of the build, set with -ldflags "-X bank-api/api.Version=v1.2.3"
+var Version = "dev"
+
+// readinessTimeout bounds the checks of a single probe, a database that hangs is not ready
+const readinessTimeout = 2 * time.Second
+
+// Statuses of a check
+const (
+	checkOK      = "ok"
+	checkFailing = "failing"
+)
+
+var (
+	errShuttingDown = errors.New("shutting down")
+	errNotRunning   = errors.New("not running")
+)
+
+type checkResult struct {
+	Status string `json:"status"`
+	// why the check fails, only shown to admins as it may tell about the infrastructure
+	Error string `json:"error,omitempty"`
+}
+
+type readinessResponse struct {
+	Ready  bool                   `json:"ready"`
+	Checks map[string]checkResult `json:"checks"`
+}
+
+// healthz tells that the process is alive. It checks nothing else on purpose, a database
+// outage must not get every container of the API restarted.
+func (server *Server) healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": checkOK})
+}
+
+// readyz tells whether the server should get requests: the database answers and is migrated,
+// the background workers run and the server is not shutting down
+func (server *Server) readyz(ctx *gin.Context) {
+	ready, checks := server.checkReadiness(ctx)
+	for name, check := range checks {
+		if check.Error != "" {
+			log.Printf("readiness check %s: %s", name, check.Error)
+		}
+		check.Error = ""
+		checks[name] = check
+	}
+
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, readinessResponse{Ready: ready, Checks: checks})
+}
+
+// checkReadiness runs every readiness check, the server is ready when all of them pass
+func (server *Server) checkReadiness(ctx context.Context) (bool, map[string]checkResult) {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	ready := true
+	checks := map[string]checkResult{}
+	record := func(name string, err error) {
+		if err != nil {
+			ready = false
+			checks[name] = checkResult{Status: checkFailing, Error: err.Error()}
+			return
+		}
+		checks[name] = checkResult{Status: checkOK}
+	}
+
+	var err error
+	if server.shuttingDown.Load() {
+		err = errShuttingDown
+	}
+	record("shutdown", err)
+	record("database", server.store.Ping(ctx))
+	record("migrations", server.checkMigrations(ctx))
+	for name, running := range server.workers {
+		err = nil
+		if !running.Load() {
+			err = errNotRunning
+		}
+		record(name, err)
+	}
+	return ready, checks
+}
+
+// checkMigrations fails when the database lags behind the migrations of this build. A database
+// that is ahead is fine: during a rolling deploy the new build migrates it while the old one
+// still serves.
+func (server *Server) checkMigrations(ctx context.Context) error {
+	version, err := server.store.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version < server.schemaVersion {
+		return fmt.Errorf("database is at migration %d, want %d", version, server.schemaVersion)
+	}
+	return nil
+}
+
+type poolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	// total time spent waiting for a connection
+	WaitDuration      string `json:"wait_duration"`
+	MaxIdleClosed     int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64  `json:"max_lifetime_closed"`
+}
+
+type statusResponse struct {
+	Version string `json:"version"`
+	// VCS revision the binary was built from, empty when unknown
+	Revision    string    `json:"revision,omitempty"`
+	GoVersion   string    `json:"go_version"`
+	Environment string    `json:"environment"`
+	StartedAt   time.Time `json:"started_at"`
+	Uptime      string    `json:"uptime"`
+	readinessResponse
+	SchemaVersion         int64     `json:"schema_version"`
+	ExpectedSchemaVersion int64     `json:"expected_schema_version"`
+	Pool                  poolStats `json:"pool"`
+}
+
+// getStatus is the status page of admins: the build, the readiness checks with the reasons they
+// fail, and the state of the connection pool
+func (server *Server) getStatus(ctx *gin.Context) {
+	ready, checks := server.checkReadiness(ctx)
+	// a failure is in the checks already
+	schemaVersion, _ := server.store.SchemaVersion(ctx)
+	stats := server.store.Stats()
+
+	ctx.JSON(http.StatusOK, statusResponse{
+		Version:               Version,
+		Revision:              revision(),
+		GoVersion:             runtime.Version(),
+		Environment:           server.config.Environment,
+		StartedAt:             server.startedAt,
+		Uptime:                time.Since(server.startedAt).Round(time.Second).String(),
+		readinessResponse:     readinessResponse{Ready: ready, Checks: checks},
+		SchemaVersion:         schemaVersion,
+		ExpectedSchemaVersion: server.schemaVersion,
+		Pool: poolStats{
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDuration:       stats.WaitDuration.String(),
+			MaxIdleClosed:      stats.MaxIdleClosed,
+			MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		},
+	})
+}
+
+// revision is the VCS revision go build stamped into the binary
+func revision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
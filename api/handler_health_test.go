@@ -0,0 +1,172 @@
+package api
+
+import (
+	mockdb "bank-api/db/mock"
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func getReadiness(t *testing.T, server *Server) (int, readinessResponse) {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+
+	var rsp readinessResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	return recorder.Code, rsp
+}
+
+// markWorkersRunning lets the readiness checks pass without starting the background work
+func markWorkersRunning(server *Server) {
+	for _, running := range server.workers {
+		running.Store(true)
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	server := newTestServer(t, sqlc.NewMemoryStore())
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
+}
+
+func TestReadyz(t *testing.T) {
+	server := newTestServer(t, sqlc.NewMemoryStore())
+
+	// nothing runs in the background before Serve
+	status, rsp := getReadiness(t, server)
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.False(t, rsp.Ready)
+	require.Equal(t, checkResult{Status: checkFailing}, rsp.Checks[workerOutboxRelay])
+	require.Equal(t, checkResult{Status: checkOK}, rsp.Checks["database"])
+
+	markWorkersRunning(server)
+	status, rsp = getReadiness(t, server)
+	require.Equal(t, http.StatusOK, status)
+	require.True(t, rsp.Ready)
+	for _, name := range []string{"shutdown", "database", "migrations", workerScheduler, workerOutboxRelay, workerWebhooks} {
+		require.Equal(t, checkOK, rsp.Checks[name].Status, name)
+	}
+}
+
+func TestReadyzStoreFailures(t *testing.T) {
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		check      string
+		err        string
+	}{
+		{
+			name: "DatabaseDown",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).AnyTimes().Return(errors.New("dial tcp 10.0.0.5:5432: connection refused"))
+				store.EXPECT().SchemaVersion(gomock.Any()).AnyTimes().Return(int64(0), errors.New("dial tcp 10.0.0.5:5432: connection refused"))
+			},
+			check: "database",
+			err:   "dial tcp 10.0.0.5:5432: connection refused",
+		},
+		{
+			name: "MigrationsBehind",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).AnyTimes().Return(nil)
+				store.EXPECT().SchemaVersion(gomock.Any()).AnyTimes().Return(int64(3), nil)
+			},
+			check: "migrations",
+			err:   "database is at migration 3, want",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newMockStore(t)
+			tc.buildStubs(store)
+			store.EXPECT().Stats().AnyTimes().Return(sql.DBStats{MaxOpenConnections: 25, OpenConnections: 2, InUse: 1, Idle: 1})
+			server := newTestServer(t, store)
+			markWorkersRunning(server)
+
+			// the reason is kept from anonymous callers
+			status, rsp := getReadiness(t, server)
+			require.Equal(t, http.StatusServiceUnavailable, status)
+			require.Equal(t, checkResult{Status: checkFailing}, rsp.Checks[tc.check])
+
+			// but shown on the status page of admins
+			admin := randomAccount(util.JPY)
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/admin/status", nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin, util.AdminRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var page statusResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+			require.False(t, page.Ready)
+			require.Equal(t, checkFailing, page.Checks[tc.check].Status)
+			require.Contains(t, page.Checks[tc.check].Error, tc.err)
+			require.Equal(t, Version, page.Version)
+			require.Equal(t, 25, page.Pool.MaxOpenConnections)
+			require.Equal(t, 1, page.Pool.InUse)
+			require.Equal(t, server.schemaVersion, page.ExpectedSchemaVersion)
+		})
+	}
+}
+
+func TestStatusAdminOnly(t *testing.T) {
+	server := newTestServer(t, sqlc.NewMemoryStore())
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/admin/status", nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, randomAccount(util.JPY), util.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestReadyzWhileShuttingDown(t *testing.T) {
+	server := newTestServer(t, sqlc.NewMemoryStore())
+	server.config.HTTP.ShutdownDelay = 300 * time.Millisecond
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ctx, listener)
+	}()
+	url := "http://" + listener.Addr().String() + "/readyz"
+
+	readiness := func() int {
+		rsp, err := http.Get(url)
+		if err != nil {
+			return 0
+		}
+		rsp.Body.Close()
+		return rsp.StatusCode
+	}
+
+	// ready once the background workers run
+	require.Eventually(t, func() bool { return readiness() == http.StatusOK }, time.Second, 10*time.Millisecond)
+
+	// not ready as soon as the shutdown begins, while requests are still served
+	cancel()
+	require.Eventually(t, func() bool { return readiness() == http.StatusServiceUnavailable }, time.Second, 10*time.Millisecond)
+	require.NoError(t, <-served)
+	require.False(t, server.workers[workerOutboxRelay].Load())
+	require.False(t, server.workers[workerScheduler].Load())
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/scheduler"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type jobRequest struct {
+	Name string `uri:"name" binding:"required"`
+}
+
+type listJobRunsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listJobRuns(ctx *gin.Context) {
+	var uriReq jobRequest
+	if err := ctx.ShouldBindUri(&uriReq); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	var req listJobRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	if !server.scheduler.HasJob(uriReq.Name) {
+		ctx.Error(scheduler.ErrUnknownJob)
+		return
+	}
+
+	runs, err := server.store.ListJobRuns(ctx, sqlc.ListJobRunsParams{
+		JobName: uriReq.Name,
+		Limit:   req.PageSize,
+		Offset:  (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+type runJobRequest struct {
+	Period string `json:"period" binding:"required"`
+}
+
+// runJob runs a job for a period by hand, e.g. one the server was down for or whose run failed.
+// A period that already ran successfully is left alone.
+func (server *Server) runJob(ctx *gin.Context) {
+	var uriReq jobRequest
+	if err := ctx.ShouldBindUri(&uriReq); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	var req runJobRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	run, err := server.scheduler.Run(ctx, uriReq.Name, req.Period, authPayload(ctx).Email)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, run)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/scheduler"
+	"bank-api/util"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRunJob(t *testing.T) {
+	admin := CreateUniqueRandomAccount(t)
+	server := newTestServer(t, sqlc.NewMemoryStore())
+
+	runJob := func(name string, period string, role string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		body := fmt.Sprintf(`{"period": %q}`, period)
+		request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/admin/jobs/%s/runs", name), bytes.NewBufferString(body))
+		require.NoError(t, err)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin, role, time.Minute)
+
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	require.Equal(t, http.StatusForbidden, runJob(scheduler.ReferralInterestJobName, "2024-07", util.DepositorRole).Code)
+	require.Equal(t, http.StatusNotFound, runJob("unknown", "2024-07", util.AdminRole).Code)
+	require.Equal(t, http.StatusBadRequest, runJob(scheduler.ReferralInterestJobName, "July", util.AdminRole).Code)
+
+	recorder := runJob(scheduler.ReferralInterestJobName, "2024-07", util.AdminRole)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var run sqlc.JobRun
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &run))
+	require.Equal(t, scheduler.StatusSucceeded, run.Status)
+	require.Equal(t, "2024-07", run.Period)
+	require.Equal(t, admin.Email, run.TriggeredBy)
+
+	// a rerun of the same period does nothing
+	require.Equal(t, http.StatusConflict, runJob(scheduler.ReferralInterestJobName, "2024-07", util.AdminRole).Code)
+	require.Equal(t, http.StatusOK, runJob(scheduler.ReferralInterestJobName, "2024-06", util.AdminRole).Code)
+
+	recorder = httptest.NewRecorder()
+	url := fmt.Sprintf("/admin/jobs/%s/runs?page_id=1&page_size=5", scheduler.ReferralInterestJobName)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin, util.AdminRole, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var runs []sqlc.JobRun
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &runs))
+	require.Len(t, runs, 2)
+	require.Equal(t, "2024-07", runs[0].Period)
+	require.Equal(t, "2024-06", runs[1].Period)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	mockdb "bank-api/db/mock"
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func randomAccount(currency string) sqlc.Account {
+	return sqlc.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    util.RandomOwner(),
+		Email:    util.RandomEmail(),
+		Balance:  util.RandomMoney(),
+		Currency: currency,
+		// the customer addAuthorization signs in as
+		CustomerID: sql.NullInt64{Int64: util.RandomInt(1, 1000), Valid: true},
+	}
+}
+
+// newMockStore returns a mock store whose customers never changed their password, which
+// authMiddleware looks up for every signed in request
+func newMockStore(t *testing.T) *mockdb.MockStore {
+	store := mockdb.NewMockStore(gomock.NewController(t))
+	store.EXPECT().GetCustomerCredential(gomock.Any(), gomock.Any()).AnyTimes().Return(sqlc.CustomerCredential{}, sql.ErrNoRows)
+	return store
+}
+
+func TestGetAccountStoreErrors(t *testing.T) {
+	account := randomAccount(util.JPY)
+
+	testCases := []struct {
+		name         string
+		buildStubs   func(store *mockdb.MockStore)
+		expectedCode int
+	}{
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(sqlc.Account{}, sql.ErrNoRows)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(sqlc.Account{}, sql.ErrConnDone)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newMockStore(t)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d", account.ID), nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, util.DepositorRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
+
+func TestCreateTransferStoreErrors(t *testing.T) {
+	account1 := randomAccount(util.JPY)
+	account2 := randomAccount(util.JPY)
+	account2.ID = account1.ID + 1
+
+	testCases := []struct {
+		name         string
+		buildStubs   func(store *mockdb.MockStore)
+		expectedCode int
+	}{
+		{
+			name: "GetAccountError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(sqlc.Account{}, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name: "InsufficientFunds",
+			buildStubs: func(store *mockdb.MockStore) {
+				// once to authorize the caller and once to check the transfer
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(2).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(sqlc.TransferTxResult{}, fmt.Errorf("account [%d]: %w", account1.ID, sqlc.ErrInsufficientFunds))
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "TransferTxError",
+			buildStubs: func(store *mockdb.MockStore) {
+				// once to authorize the caller and once to check the transfer
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(2).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(sqlc.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        10,
+				})).Times(1).Return(sqlc.TransferTxResult{}, sql.ErrTxDone)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newMockStore(t)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			body, err := json.Marshal(gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        util.JPY,
+			})
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(body))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account1, util.DepositorRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"bank-api/apperr"
+	"bank-api/db/sqlc"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type listOutboxEventsRequest struct {
+	Status   string `form:"status" binding:"required,oneof=pending delivered dead"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+// listOutboxEvents lists the domain events of a status, the dead ones are what the relay gave up on
+func (server *Server) listOutboxEvents(ctx *gin.Context) {
+	var req listOutboxEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	events, err := server.store.ListOutboxEvents(ctx, sqlc.ListOutboxEventsParams{
+		Status: req.Status,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, events)
+}
+
+type outboxEventRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+var errOutboxEventNotDead = apperr.New(apperr.KindNotFound, "outbox_event_not_found", "outbox event not found or not dead")
+
+// requeueOutboxEvent hands a dead event back to the relay with a fresh set of attempts, once
+// whatever kept it from being delivered is fixed
+func (server *Server) requeueOutboxEvent(ctx *gin.Context) {
+	var req outboxEventRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	event, err := server.store.RequeueOutboxEvent(ctx, sqlc.RequeueOutboxEventParams{
+		ID:            req.ID,
+		NextAttemptAt: time.Now(),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Error(errOutboxEventNotDead)
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, event)
+}
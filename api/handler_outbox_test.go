@@ -0,0 +1,77 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/outbox"
+	"bank-api/util"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOutboxDeadLetter(t *testing.T) {
+	admin := CreateUniqueRandomAccount(t)
+	store := sqlc.NewMemoryStore()
+	server := newTestServer(t, store)
+
+	send := func(method string, url string, role string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(method, url, nil)
+		require.NoError(t, err)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin, role, time.Minute)
+
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	event, err := store.CreateOutboxEvent(context.Background(), sqlc.CreateOutboxEventParams{
+		EventType:     sqlc.EventAccountCreated,
+		AggregateType: sqlc.AggregateAccount,
+		AggregateID:   admin.ID,
+		Payload:       []byte(`{}`),
+	})
+	require.NoError(t, err)
+
+	// a pending event cannot be requeued
+	requeueURL := fmt.Sprintf("/admin/outbox/%d/requeue", event.ID)
+	require.Equal(t, http.StatusNotFound, send(http.MethodPost, requeueURL, util.AdminRole).Code)
+
+	err = store.MarkOutboxEventFailed(context.Background(), sqlc.MarkOutboxEventFailedParams{
+		ID:            event.ID,
+		Status:        outbox.StatusDead,
+		NextAttemptAt: time.Now(),
+		LastError:     sql.NullString{String: "sink is down", Valid: true},
+	})
+	require.NoError(t, err)
+
+	listURL := "/admin/outbox?status=dead&page_id=1&page_size=10"
+	require.Equal(t, http.StatusForbidden, send(http.MethodGet, listURL, util.DepositorRole).Code)
+	require.Equal(t, http.StatusBadRequest, send(http.MethodGet, "/admin/outbox?status=lost&page_id=1&page_size=10", util.AdminRole).Code)
+
+	recorder := send(http.MethodGet, listURL, util.AdminRole)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var events []sqlc.OutboxEvent
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &events))
+	require.Len(t, events, 1)
+	require.Equal(t, event.ID, events[0].ID)
+	require.Equal(t, "sink is down", events[0].LastError.String)
+
+	require.Equal(t, http.StatusForbidden, send(http.MethodPost, requeueURL, util.DepositorRole).Code)
+
+	recorder = send(http.MethodPost, requeueURL, util.AdminRole)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var requeued sqlc.OutboxEvent
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &requeued))
+	require.Equal(t, outbox.StatusPending, requeued.Status)
+	require.Zero(t, requeued.Attempts)
+
+	require.Equal(t, http.StatusNotFound, send(http.MethodPost, requeueURL, util.AdminRole).Code)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"database/sql"
+	"errors"
+	"github.com/Meenachinmay/microservice-shared/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// passwordResetDuration is how long an emailed reset token can be used for
+const passwordResetDuration = time.Hour
+
+type requestPasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// requestPasswordReset emails the customer a token to choose a new password with; the email is
+// sent by the notifier from the event the reset publishes. The answer is the same whether or not
+// there is a customer with the email, so it cannot be used to find out who banks here.
+func (server *Server) requestPasswordReset(ctx *gin.Context) {
+	var req requestPasswordResetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	customer, err := server.store.GetCustomerByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Status(http.StatusAccepted)
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+
+	now := utils.ConvertToTokyoTime()
+	_, err = server.store.RequestPasswordResetTx(ctx, sqlc.RequestPasswordResetTxParams{
+		CustomerID: customer.ID,
+		ExpiresAt:  now.Add(passwordResetDuration),
+		CreatedAt:  now,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8,max=72,bcrypt"`
+}
+
+// resetPassword sets the password of the customer the token was emailed to. The customer signs
+// in again afterwards, every session they had is blocked and authMiddleware refuses the access
+// tokens issued before.
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	_, err = server.store.ResetPasswordTx(ctx, sqlc.ResetPasswordTxParams{
+		TokenHash:      util.HashResetToken(req.Token),
+		HashedPassword: hashedPassword,
+		Now:            utils.ConvertToTokyoTime(),
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bank-api/config"
+	"bank-api/db/sqlc"
+	"bank-api/notification"
+	"bank-api/token"
+	"bank-api/util"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// postAnonymous posts the body as JSON without signing in
+func postAnonymous(t *testing.T, server *Server, url string, body gin.H) *httptest.ResponseRecorder {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// emailedResetToken sends the pending emails and returns the reset token of the one sent to email
+func emailedResetToken(t *testing.T, server *Server, sender *notification.MemorySender, email string) string {
+	_, err := server.relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	_, err = server.emails.SendBatch(context.Background())
+	require.NoError(t, err)
+	messages := sender.Messages()
+	require.NotEmpty(t, messages)
+	message := messages[len(messages)-1]
+	require.Equal(t, email, message.To)
+	return strings.Fields(strings.SplitN(message.Body, "\n\n", 4)[2])[0]
+}
+
+func TestPasswordReset(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	sender := notification.NewMemorySender()
+	tokenMaker, err := token.NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+	server, err := NewServer(config.Defaults(config.EnvTest), store, tokenMaker, sender)
+	require.NoError(t, err)
+
+	// a customer from before passwords, with an account but no credential
+	customer, err := store.CreateCustomer(context.Background(), sqlc.CreateCustomerParams{
+		Email:     util.RandomEmail(),
+		Name:      util.RandomOwner(),
+		CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	_, err = store.CreateAccount(context.Background(), sqlc.CreateAccountParams{
+		Owner:      customer.Name,
+		Email:      customer.Email,
+		Currency:   util.JPY,
+		CreatedAt:  time.Now(),
+		CustomerID: sql.NullInt64{Int64: customer.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	password := util.RandomString(12)
+	login := gin.H{"email": customer.Email, "password": password}
+	requireErrorBody(t, postAnonymous(t, server, "/accounts/login", login), http.StatusUnauthorized, "invalid_credentials")
+
+	// an unknown email is answered the same, and nothing is sent
+	recorder := postAnonymous(t, server, "/password-reset", gin.H{"email": util.RandomEmail()})
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	recorder = postAnonymous(t, server, "/password-reset", gin.H{"email": customer.Email})
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	resetToken := emailedResetToken(t, server, sender, customer.Email)
+
+	// too long for bcrypt to hash
+	recorder = postAnonymous(t, server, "/password-reset/confirm", gin.H{"token": resetToken, "password": util.RandomString(73)})
+	requireErrorBody(t, recorder, http.StatusBadRequest, "invalid_request")
+
+	recorder = postAnonymous(t, server, "/password-reset/confirm", gin.H{"token": resetToken, "password": password})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	recorder = postAnonymous(t, server, "/accounts/login", login)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// a token is good for one reset only
+	recorder = postAnonymous(t, server, "/password-reset/confirm", gin.H{"token": resetToken, "password": util.RandomString(12)})
+	requireErrorBody(t, recorder, http.StatusBadRequest, "invalid_reset_token")
+	recorder = postAnonymous(t, server, "/password-reset/confirm", gin.H{"token": util.RandomString(64), "password": util.RandomString(12)})
+	requireErrorBody(t, recorder, http.StatusBadRequest, "invalid_reset_token")
+}
+
+func TestPasswordResetRevokesAccessTokens(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	sender := notification.NewMemorySender()
+	tokenMaker, err := token.NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+	server, err := NewServer(config.Defaults(config.EnvTest), store, tokenMaker, sender)
+	require.NoError(t, err)
+
+	email := util.RandomEmail()
+	password := util.RandomString(12)
+	recorder := postAnonymous(t, server, "/accounts", gin.H{
+		"owner":    util.RandomOwner(),
+		"currency": util.JPY,
+		"email":    email,
+		"password": password,
+	})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	login := func(password string) loginAccountResponse {
+		recorder := postAnonymous(t, server, "/accounts/login", gin.H{"email": email, "password": password})
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var rsp loginAccountResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+		return rsp
+	}
+	getAccounts := func(rsp loginAccountResponse) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/customers/%d/accounts", rsp.Customer.ID), nil)
+		require.NoError(t, err)
+		request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+rsp.AccessToken)
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	before := login(password)
+	require.Equal(t, http.StatusOK, getAccounts(before).Code)
+
+	recorder = postAnonymous(t, server, "/password-reset", gin.H{"email": email})
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+	resetToken := emailedResetToken(t, server, sender, email)
+
+	password = util.RandomString(12)
+	recorder = postAnonymous(t, server, "/password-reset/confirm", gin.H{"token": resetToken, "password": password})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// the access token from before the reset is refused even though it has not expired yet
+	requireErrorBody(t, getAccounts(before), http.StatusUnauthorized, "token_revoked")
+	require.Equal(t, http.StatusOK, getAccounts(login(password)).Code)
+}
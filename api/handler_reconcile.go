@@ -0,0 +1,49 @@
+package api
+
+import (
+	"bank-api/apperr"
+	"bank-api/reconcile"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+)
+
+type reconcileRequest struct {
+	Format    string `form:"format" binding:"omitempty,oneof=json csv"`
+	BatchSize int32  `form:"batch_size" binding:"omitempty,min=1,max=10000"`
+}
+
+var reconcileContentTypes = map[string]string{
+	reconcile.FormatJSON: "application/json; charset=utf-8",
+	reconcile.FormatCSV:  "text/csv; charset=utf-8",
+}
+
+// reconcileLedger streams the reconciliation report of the whole ledger. The status is sent
+// before the scan starts, so whether the ledger is consistent is told by the summary of a JSON
+// report, not by the status.
+func (server *Server) reconcileLedger(ctx *gin.Context) {
+	var req reconcileRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+	if req.Format == "" {
+		req.Format = reconcile.FormatJSON
+	}
+
+	writer, err := reconcile.NewWriter(req.Format, ctx.Writer)
+	if err != nil {
+		ctx.Error(apperr.Wrap(apperr.KindValidation, "invalid_request", err))
+		return
+	}
+
+	ctx.Header("Content-Type", reconcileContentTypes[req.Format])
+	ctx.Status(http.StatusOK)
+
+	summary, err := reconcile.Run(ctx, server.store, req.BatchSize, writer)
+	if err != nil {
+		log.Printf("reconciliation failed: %v", err)
+		return
+	}
+	log.Printf("reconciliation by %s: %d mismatches", authPayload(ctx).Email, summary.Mismatches)
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/reconcile"
+	"bank-api/util"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReconcileLedger(t *testing.T) {
+	admin := CreateUniqueRandomAccount(t)
+	store := sqlc.NewMemoryStore()
+	server := newTestServer(t, store)
+
+	// a balance that no entry accounts for
+	account, err := store.CreateAccount(context.Background(), sqlc.CreateAccountParams{
+		Owner:     util.RandomOwner(),
+		Balance:   100,
+		Email:     util.RandomEmail(),
+		Currency:  util.JPY,
+		CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	reconcileLedger := func(query string, role string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/admin/reconciliation"+query, nil)
+		require.NoError(t, err)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin, role, time.Minute)
+
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	require.Equal(t, http.StatusForbidden, reconcileLedger("", util.DepositorRole).Code)
+	require.Equal(t, http.StatusBadRequest, reconcileLedger("?format=xml", util.AdminRole).Code)
+	require.Equal(t, http.StatusBadRequest, reconcileLedger("?batch_size=20000", util.AdminRole).Code)
+
+	recorder := reconcileLedger("?batch_size=1", util.AdminRole)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Content-Type"), "application/json")
+
+	var report struct {
+		Mismatches []reconcile.Mismatch `json:"mismatches"`
+		Summary    reconcile.Summary    `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	require.Equal(t, int64(1), report.Summary.Mismatches)
+	require.Equal(t, []reconcile.Mismatch{
+		{Kind: reconcile.BalanceDrift, ID: account.ID, Expected: 0, Actual: 100, Detail: "balance is off by 100"},
+	}, report.Mismatches)
+
+	recorder = reconcileLedger("?format=csv", util.AdminRole)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Content-Type"), "text/csv")
+	require.True(t, strings.HasPrefix(recorder.Body.String(), "kind,id,expected,actual,detail\nbalance_drift,"))
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"bank-api/apperr"
 	"bank-api/db/sqlc"
+	"bank-api/metrics"
 	"bank-api/util"
 	"database/sql"
 	"errors"
@@ -15,6 +17,9 @@ type generateReferralRequest struct {
 	ID int64 `uri:"account" binding:"required,min=1"`
 }
 
+// errUnusedCodeExists is returned for a referrer asking for a new code while holding an unused one
+var errUnusedCodeExists = apperr.New(apperr.KindUnprocessable, "unused_referral_code_exists", "cannot create a new code, before using it")
+
 type generateReferralResponse struct {
 	ReferralCode sqlc.ReferralCode `json:"referral_code"`
 }
@@ -22,21 +27,19 @@ type generateReferralResponse struct {
 func (server *Server) createReferral(ctx *gin.Context) {
 	var req generateReferralRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.Error(invalidRequest(err))
 		return
 	}
 
 	//TODO: Check for any un-used code by this user.
 	hasUnUsedCode, err := server.store.HasUnUsedCodeForReferrerAccount(ctx, req.ID)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.Error(err)
 		return
 	}
 
 	if hasUnUsedCode {
-		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
-			"error": "cannot create a new code, before using it",
-		})
+		ctx.Error(errUnusedCodeExists)
 		return
 	}
 
@@ -49,9 +52,10 @@ func (server *Server) createReferral(ctx *gin.Context) {
 
 	referralCode, err := server.store.CreateReferralCode(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.Error(err)
 		return
 	}
+	metrics.ReferralCodesCreated.Inc()
 
 	ctx.JSON(http.StatusOK, referralCode)
 	return
@@ -64,114 +68,79 @@ type useReferralRequestAccountID struct {
 	ReferredAccount int64 `json:"referred_account_id" binding:"required,min=1"`
 }
 
+// referrerState is what the referred account gets to see of its referrer
+type referrerState struct {
+	AccountID              int64        `json:"account_id"`
+	ExtraInterest          float64      `json:"extra_interest"`
+	ExtraInterestStartDate sql.NullTime `json:"extra_interest_start_date"`
+	ExtraInterestDuration  int32        `json:"extra_interest_duration"`
+}
+
+type useReferralCodeResponse struct {
+	ReferralHistory sqlc.ReferralHistory `json:"referral_history"`
+	Referrer        referrerState        `json:"referrer"`
+}
+
 func (server *Server) useReferralCode(ctx *gin.Context) {
 	var req useReferralRequestCode
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.Error(invalidRequest(err))
 		return
 	}
 
 	var jsonReq useReferralRequestAccountID
 	if err := ctx.ShouldBindJSON(&jsonReq); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.Error(invalidRequest(err))
 		return
 	}
-
-	args := sqlc.MarkReferralCodeUsedParams{
-		ReferralCode: req.ReferralCode,
-		UsedAt:       sql.NullTime{Time: utils.ConvertToTokyoTime(), Valid: true},
-	}
-
-	// find the code in table and mark it true
-	referralCode, err := server.store.MarkReferralCodeUsed(ctx, args)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	if !authorizedAccount(ctx, jsonReq.ReferredAccount) {
+		ctx.Error(errForbidden)
 		return
 	}
 
-	// create record in referral codes history table
-	_, err = server.store.CreateReferralHistory(ctx, sqlc.CreateReferralHistoryParams{
-		ReferrerAccountID: referralCode.ReferrerAccountID,
+	result, err := server.store.RedeemReferralCodeTx(ctx, sqlc.RedeemReferralCodeTxParams{
+		ReferralCode:      req.ReferralCode,
 		ReferredAccountID: jsonReq.ReferredAccount,
-		ReferralCodeID:    referralCode.ID,
-		ReferralDate:      referralCode.CreatedAt,
+		RedeemedAt:        utils.ConvertToTokyoTime(),
+		Terms:             referralTerms(server.config.Referral),
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.Error(err)
 		return
 	}
 
-	referrerAccount, err := server.store.GetAccount(ctx, referralCode.ReferrerAccountID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	currentExtraInterest := 0.0
-	if referrerAccount.ExtraInterest.Valid {
-		currentExtraInterest = referrerAccount.ExtraInterest.Float64
-	}
-
-	expectedExtraInterest := currentExtraInterest + 1.0
-	if expectedExtraInterest > 10.0 {
-		expectedExtraInterest = 10.0
-	}
-
-	ctx.JSON(http.StatusOK, referralCode)
-
-}
-
-type calculateReferralRequest struct {
-	ReferrerAccountID int64 `uri:"account" binding:"required,min=1"`
-}
-
-// CalculateInterest - This method runs on 21st of every month to calculate extra interest for
-// following month
-func (server *Server) calculateInterest(ctx *gin.Context) {
-	var req calculateReferralRequest
-	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-
-	args := sqlc.UseReferralCodeTxParams{
-		ReferrerAccountID: req.ReferrerAccountID,
-	}
-
-	txResult, err := server.store.UseReferralCodeTx(ctx, args)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	ctx.JSON(http.StatusOK, txResult.ReferrerAccountUpdate)
-
+	referrer := result.ReferrerAccount
+	ctx.JSON(http.StatusOK, useReferralCodeResponse{
+		ReferralHistory: result.ReferralHistory,
+		Referrer: referrerState{
+			AccountID:              referrer.ID,
+			ExtraInterest:          referrer.ExtraInterest.Float64,
+			ExtraInterestStartDate: referrer.ExtraInterestStartDate,
+			ExtraInterestDuration:  referrer.ExtraInterestDuration,
+		},
+	})
 }
 
 func (server *Server) getReferralCodesForAccount(ctx *gin.Context) {
 	accountIDStr := ctx.Query("account")
 	if accountIDStr == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "query params are missing",
-		})
+		ctx.Error(invalidParam("account"))
 		return
 	}
 
 	accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errors.New("invalid account id"))
+		ctx.Error(invalidParam("account"))
 		return
 	}
 
 	referralCodes, err := server.store.GetReferralCodesForReferrerAccount(ctx, accountID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, gin.H{
-				"error": "no referral codes are available",
-			})
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Error(apperr.New(apperr.KindNotFound, "referral_codes_not_found", "no referral codes are available"))
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.Error(err)
 		return
 	}
 
@@ -2,7 +2,10 @@ package api
 
 import (
 	"4d63.com/tz"
+	"bank-api/config"
 	"bank-api/db/sqlc"
+	"bank-api/notification"
+	"bank-api/token"
 	"bank-api/util"
 	"bytes"
 	"context"
@@ -20,11 +23,25 @@ import (
 	"time"
 )
 
-func newTestServer(t *testing.T, store *sqlc.Store) *Server {
-	server := NewServer(store)
+func newTestServer(t *testing.T, store sqlc.Store) *Server {
+	tokenMaker, err := token.NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	server, err := NewServer(config.Defaults(config.EnvTest), store, tokenMaker, notification.NewMemorySender())
+	require.NoError(t, err)
 	return server
 }
 
+// addAuthorization signs the request in as the customer holding the given account, with the role
+func addAuthorization(t *testing.T, request *http.Request, tokenMaker token.Maker, authorizationType string, account sqlc.Account, role string, duration time.Duration) {
+	accessToken, payload, err := tokenMaker.CreateToken(account.CustomerID.Int64, account.Email, role, token.TokenTypeAccessToken, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, accessToken)
+	request.Header.Set(authorizationHeaderKey, authorizationHeader)
+}
+
 var (
 	generatedAccounts = make(map[int64]struct{})
 	generatedCodes    = make(map[int64]struct{})
@@ -89,13 +106,27 @@ func randomTimeBetween(t *testing.T, start, end time.Time) time.Time {
 	return randomDate
 }
 
-func CreateRandomAccount(t *testing.T) sqlc.Account {
-	args := sqlc.CreateAccountParams{
-		Owner:     util.RandomOwner(),
-		Balance:   util.RandomMoney(),
+// createRandomCustomer creates a customer without accounts or a credential
+func createRandomCustomer(t *testing.T) sqlc.Customer {
+	customer, err := testStore.CreateCustomer(context.Background(), sqlc.CreateCustomerParams{
 		Email:     util.RandomEmail(),
-		Currency:  util.RandomCurrency(),
+		Name:      util.RandomOwner(),
 		CreatedAt: utils.ConvertToTokyoTime(),
+	})
+	require.NoError(t, err)
+	return customer
+}
+
+// CreateRandomAccount creates the only account of a new random customer
+func CreateRandomAccount(t *testing.T) sqlc.Account {
+	customer := createRandomCustomer(t)
+	args := sqlc.CreateAccountParams{
+		Owner:      customer.Name,
+		Balance:    util.RandomMoney(),
+		Email:      customer.Email,
+		Currency:   util.RandomCurrency(),
+		CreatedAt:  utils.ConvertToTokyoTime(),
+		CustomerID: sql.NullInt64{Int64: customer.ID, Valid: true},
 	}
 
 	account, err := testStore.CreateAccount(context.Background(), args)
@@ -106,6 +137,7 @@ func CreateRandomAccount(t *testing.T) sqlc.Account {
 	require.Equal(t, args.Balance, account.Balance)
 	require.Equal(t, args.Email, account.Email)
 	require.Equal(t, args.Currency, account.Currency)
+	require.Equal(t, args.CustomerID, account.CustomerID)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
@@ -113,6 +145,27 @@ func CreateRandomAccount(t *testing.T) sqlc.Account {
 	return account
 }
 
+// CreateRandomAccountWithPassword creates a random account that can sign in with the password
+func CreateRandomAccountWithPassword(t *testing.T, password string) sqlc.Account {
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	result, err := testStore.CreateAccountTx(context.Background(), sqlc.CreateAccountTxParams{
+		CreateAccountParams: sqlc.CreateAccountParams{
+			Owner:     util.RandomOwner(),
+			Balance:   util.RandomMoney(),
+			Email:     util.RandomEmail(),
+			Currency:  util.RandomCurrency(),
+			CreatedAt: utils.ConvertToTokyoTime(),
+		},
+		HashedPassword: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Account)
+
+	return result.Account
+}
+
 func TestCreateAccount(t *testing.T) {
 	server := newTestServer(t, testStore)
 
@@ -120,13 +173,14 @@ func TestCreateAccount(t *testing.T) {
 		Owner:     "John Doe",
 		Balance:   0,
 		Email:     "johndoe@gmail.com",
-		Currency:  "YEN",
+		Currency:  util.JPY,
 		CreatedAt: utils.ConvertToTokyoTime(),
 	}
 
 	data, err := json.Marshal(createAccountRequest{
 		Owner:     account.Owner,
 		Email:     account.Email,
+		Password:  util.RandomString(8),
 		Currency:  account.Currency,
 		CreatedAt: account.CreatedAt,
 	})
@@ -151,6 +205,27 @@ func TestCreateAccount(t *testing.T) {
 	require.NotZero(t, createdAccount.CreatedAt)
 }
 
+func TestCreateAccountUnsupportedCurrency(t *testing.T) {
+	server := newTestServer(t, testStore)
+
+	for _, currency := range []string{"YEN", "eur", "GBP"} {
+		data, err := json.Marshal(createAccountRequest{
+			Owner:    util.RandomOwner(),
+			Email:    util.RandomEmail(),
+			Password: util.RandomString(8),
+			Currency: currency,
+		})
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("POST", "/accounts", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusBadRequest, recorder.Code, currency)
+	}
+}
+
 func TestCreateAccountWithReferralCode(t *testing.T) {
 	account := CreateUniqueRandomAccount(t)
 	referralCode := CreateUniqueRandomReferralCode(t, account.ID)
@@ -163,8 +238,9 @@ func TestCreateAccountWithReferralCode(t *testing.T) {
 	// Define the request body
 	reqBody := createAccountRequest{
 		Owner:        "John Doe",
-		Currency:     "YEN",
+		Currency:     util.JPY,
 		Email:        "johndoe@example.com",
+		Password:     util.RandomString(8),
 		ReferralCode: referralCode.ReferralCode,
 	}
 	jsonReq, err := json.Marshal(reqBody)
@@ -183,7 +259,7 @@ func TestCreateAccountWithReferralCode(t *testing.T) {
 
 	require.NotZero(t, createdAccount.ID)
 	require.Equal(t, "John Doe", createdAccount.Owner)
-	require.Equal(t, "YEN", createdAccount.Currency)
+	require.Equal(t, util.JPY, createdAccount.Currency)
 	require.Equal(t, "johndoe@example.com", createdAccount.Email)
 	require.Equal(t, int64(1000), createdAccount.Balance)
 
@@ -192,6 +268,75 @@ func TestCreateAccountWithReferralCode(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, usedReferralCode.IsUsed)
 	require.NotZero(t, usedReferralCode.UsedAt)
+
+	// the referral is recorded and the bonus is booked as a transfer from the bonus account
+	history, err := server.store.GetReferralHistory(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, createdAccount.ID, history[0].ReferredAccountID)
+	require.Equal(t, referralCode.ID, history[0].ReferralCodeID)
+
+	entries, err := server.store.ListEntries(context.Background(), sqlc.ListEntriesParams{AccountID: createdAccount.ID, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, server.config.Referral.WelcomeBonus, entries[0].Amount)
+
+	transfers, err := server.store.ListTransfers(context.Background(), sqlc.ListTransfersParams{
+		FromAccountID: createdAccount.ID,
+		ToAccountID:   createdAccount.ID,
+		Limit:         5,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+
+	bonusAccount, err := server.store.GetAccount(context.Background(), transfers[0].FromAccountID)
+	require.NoError(t, err)
+	require.Equal(t, util.BonusAccount, bonusAccount.AccountType)
+	require.Equal(t, util.JPY, bonusAccount.Currency)
+}
+
+func TestCreateAccountWithReferralCodeErrors(t *testing.T) {
+	referrer := CreateUniqueRandomAccount(t)
+	usedCode := CreateUniqueRandomReferralCode(t, referrer.ID)
+	_, err := testStore.MarkReferralCodeUsed(context.Background(), sqlc.MarkReferralCodeUsedParams{
+		ReferralCode: usedCode.ReferralCode,
+		UsedAt:       sql.NullTime{Time: utils.ConvertToTokyoTime(), Valid: true},
+	})
+	require.NoError(t, err)
+
+	server := newTestServer(t, testStore)
+
+	for name, tc := range map[string]struct {
+		code      string
+		status    int
+		errorCode string
+	}{
+		"UnknownCode": {util.RandomString(12), http.StatusNotFound, "referral_code_not_found"},
+		"UsedCode":    {usedCode.ReferralCode, http.StatusConflict, "referral_code_used"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			email := util.RandomEmail()
+			jsonReq, err := json.Marshal(createAccountRequest{
+				Owner:        util.RandomOwner(),
+				Currency:     util.JPY,
+				Email:        email,
+				Password:     util.RandomString(8),
+				ReferralCode: tc.code,
+			})
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest("POST", "/accounts", bytes.NewBuffer(jsonReq))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			requireErrorBody(t, recorder, tc.status, tc.errorCode)
+
+			// nothing of the signup is left behind
+			_, err = testStore.GetCustomerByEmail(context.Background(), email)
+			require.ErrorIs(t, err, sql.ErrNoRows)
+		})
+	}
 }
 
 func TestGetAccount(t *testing.T) {
@@ -201,16 +346,18 @@ func TestGetAccount(t *testing.T) {
 	account := sqlc.Account{
 		Owner:     "John Doe",
 		Balance:   0,
-		Currency:  "YEN",
+		Currency:  util.JPY,
 		CreatedAt: utils.ConvertToTokyoTime(),
 	}
 
 	// Create account using the store directly to ensure it exists
+	customer := createRandomCustomer(t)
 	arg := sqlc.CreateAccountParams{
-		Owner:     account.Owner,
-		Currency:  account.Currency,
-		Balance:   account.Balance,
-		CreatedAt: account.CreatedAt,
+		Owner:      account.Owner,
+		Currency:   account.Currency,
+		Balance:    account.Balance,
+		CreatedAt:  account.CreatedAt,
+		CustomerID: sql.NullInt64{Int64: customer.ID, Valid: true},
 	}
 	createdAccount, err := testStore.CreateAccount(context.Background(), arg)
 	require.NoError(t, err)
@@ -219,6 +366,7 @@ func TestGetAccount(t *testing.T) {
 	url := fmt.Sprintf("/accounts/%d", createdAccount.ID)
 	request, err := http.NewRequest("GET", url, nil)
 	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, createdAccount, util.DepositorRole, time.Minute)
 
 	server.router.ServeHTTP(recorder, request)
 	require.Equal(t, http.StatusOK, recorder.Code)
@@ -234,6 +382,41 @@ func TestGetAccount(t *testing.T) {
 	require.NotZero(t, retrievedAccount.CreatedAt)
 }
 
+func TestGetAccountEffectiveInterest(t *testing.T) {
+	server := newTestServer(t, testStore)
+	account := CreateUniqueRandomAccount(t)
+
+	// the extra interest started last month and runs for 9 months
+	now := utils.ConvertToTokyoTime()
+	start := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+	account, err := testStore.UpdateAccountInterest(context.Background(), sqlc.UpdateAccountInterestParams{
+		ID:                     account.ID,
+		ExtraInterest:          sql.NullFloat64{Float64: 2, Valid: true},
+		ExtraInterestStartDate: sql.NullTime{Time: start, Valid: true},
+		ExtraInterestDuration:  9,
+	})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", fmt.Sprintf("/accounts/%d", account.ID), nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, util.DepositorRole, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response accountResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Equal(t, account.ID, response.ID)
+	require.Equal(t, account.Interest+2, response.EffectiveInterest)
+	require.True(t, response.ExtraInterestExpiresAt.Valid)
+	require.True(t, start.AddDate(0, 9, 0).Equal(response.ExtraInterestExpiresAt.Time))
+
+	currency, ok := util.LookupCurrency(account.Currency)
+	require.True(t, ok)
+	require.Equal(t, currency.Format(account.Balance), response.FormattedBalance)
+}
+
 func TestCreateReferral(t *testing.T) {
 	account := CreateUniqueRandomAccount(t)
 
@@ -244,6 +427,7 @@ func TestCreateReferral(t *testing.T) {
 
 	request, err := http.NewRequest("POST", url, nil)
 	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, util.DepositorRole, time.Minute)
 
 	server.router.ServeHTTP(recorder, request)
 	require.Equal(t, http.StatusOK, recorder.Code)
@@ -271,28 +455,117 @@ func TestUseReferralCode(t *testing.T) {
 
 	request, err := http.NewRequest("POST", url, bytes.NewBuffer([]byte(jsonReq)))
 	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, referredAccount, util.DepositorRole, time.Minute)
 
 	server.router.ServeHTTP(recorder, request)
 	require.Equal(t, http.StatusOK, recorder.Code)
 
-	var result sqlc.ReferralCode
+	var result useReferralCodeResponse
 	err = json.Unmarshal(recorder.Body.Bytes(), &result)
 	require.NoError(t, err)
 
-	require.Equal(t, referrerAccount.ID, result.ReferrerAccountID)
-	require.Equal(t, referrerAccount.ID, result.ReferrerAccountID)
-	require.NotZero(t, result.UsedAt.Time)
+	require.Equal(t, referrerAccount.ID, result.ReferralHistory.ReferrerAccountID)
+	require.Equal(t, referredAccount.ID, result.ReferralHistory.ReferredAccountID)
+	require.Equal(t, referralCode.ID, result.ReferralHistory.ReferralCodeID)
 
+	require.Equal(t, referrerAccount.ID, result.Referrer.AccountID)
+	require.Equal(t, referrerAccount.ExtraInterest.Float64+1, result.Referrer.ExtraInterest)
+	require.True(t, result.Referrer.ExtraInterestStartDate.Valid)
+	require.Equal(t, 1, result.Referrer.ExtraInterestStartDate.Time.Day())
+
+	usedCode, err := testStore.GetReferralCode(context.Background(), referralCode.ReferralCode)
+	require.NoError(t, err)
+	require.True(t, usedCode.IsUsed)
+	require.NotZero(t, usedCode.UsedAt.Time)
+}
+
+func TestUseReferralCodeErrors(t *testing.T) {
+	referrerAccount := CreateUniqueRandomAccount(t)
+	referredAccount := CreateUniqueRandomAccount(t)
+	usedCode := CreateUniqueRandomReferralCode(t, referrerAccount.ID)
+	_, err := testStore.MarkReferralCodeUsed(context.Background(), sqlc.MarkReferralCodeUsedParams{
+		ReferralCode: usedCode.ReferralCode,
+		UsedAt:       sql.NullTime{Time: utils.ConvertToTokyoTime(), Valid: true},
+	})
+	require.NoError(t, err)
+
+	server := newTestServer(t, testStore)
+
+	testCases := []struct {
+		name            string
+		code            string
+		referredAccount sqlc.Account
+		expectedCode    int
+	}{
+		{
+			name:            "UnknownCode",
+			code:            util.RandomString(12),
+			referredAccount: referredAccount,
+			expectedCode:    http.StatusNotFound,
+		},
+		{
+			name:            "UsedCode",
+			code:            usedCode.ReferralCode,
+			referredAccount: referredAccount,
+			expectedCode:    http.StatusConflict,
+		},
+		{
+			name:            "SelfReferral",
+			code:            CreateUniqueRandomReferralCode(t, referrerAccount.ID).ReferralCode,
+			referredAccount: referrerAccount,
+			expectedCode:    http.StatusUnprocessableEntity,
+		},
+		{
+			// admins may act on any account, so the referred account can be one that doesn't exist
+			name:            "UnknownReferredAccount",
+			code:            CreateUniqueRandomReferralCode(t, referrerAccount.ID).ReferralCode,
+			referredAccount: sqlc.Account{ID: referredAccount.ID + 1000, Email: util.RandomEmail()},
+			expectedCode:    http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/referral/code/%s", tc.code)
+			jsonReq := fmt.Sprintf(`{"referred_account_id": %d}`, tc.referredAccount.ID)
+
+			request, err := http.NewRequest("POST", url, bytes.NewBufferString(jsonReq))
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.referredAccount, util.AdminRole, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+
+	// none of the failed attempts changed the referrer
+	account, err := testStore.GetAccount(context.Background(), referrerAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, referrerAccount.ExtraInterest, account.ExtraInterest)
 }
 
 func TestLoginAccount(t *testing.T) {
-	account := CreateUniqueRandomAccount(t)
+	password := util.RandomString(8)
+	account := CreateRandomAccountWithPassword(t, password)
 
 	log.Printf(">> account: %+v", account)
 
+	currency := util.USD
+	if account.Currency == util.USD {
+		currency = util.EUR
+	}
+	secondAccount, err := testStore.OpenAccountTx(context.Background(), sqlc.OpenAccountTxParams{
+		CustomerID: account.CustomerID.Int64,
+		Currency:   currency,
+		CreatedAt:  utils.ConvertToTokyoTime(),
+	})
+	require.NoError(t, err)
+
 	server := newTestServer(t, testStore)
 	loginReq := loginAccountRequest{
-		Email: account.Email,
+		Email:    account.Email,
+		Password: password,
 	}
 
 	recorder := httptest.NewRecorder()
@@ -300,21 +573,153 @@ func TestLoginAccount(t *testing.T) {
 	jsonReq, err := json.Marshal(loginReq)
 	require.NoError(t, err)
 
-	log.Printf(">> json request: %+v", string(jsonReq))
-
 	request, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonReq))
 	require.NoError(t, err)
 
 	server.router.ServeHTTP(recorder, request)
 	require.Equal(t, http.StatusOK, recorder.Code)
 
-	var result sqlc.Account
+	var result loginAccountResponse
 	err = json.Unmarshal(recorder.Body.Bytes(), &result)
 	require.NoError(t, err)
 
-	require.Equal(t, account.ID, result.ID)
-	require.Equal(t, account.Email, result.Email)
-	require.Equal(t, account.Currency, result.Currency)
+	require.Equal(t, account.CustomerID.Int64, result.Customer.ID)
+	require.Equal(t, account.Email, result.Customer.Email)
+	require.NotEmpty(t, result.SessionID)
+
+	// all of the customer's accounts come along
+	require.Len(t, result.Accounts, 2)
+	require.Equal(t, account.ID, result.Accounts[0].ID)
+	require.Equal(t, account.Currency, result.Accounts[0].Currency)
+	require.Equal(t, secondAccount.Account.ID, result.Accounts[1].ID)
+	require.Equal(t, currency, result.Accounts[1].Currency)
+
+	// the access token signs the customer in
+	payload, err := server.tokenMaker.VerifyToken(result.AccessToken, token.TokenTypeAccessToken)
+	require.NoError(t, err)
+	require.Equal(t, account.CustomerID.Int64, payload.CustomerID)
+
+	// and the refresh token can be exchanged for a new one
+	jsonReq, err = json.Marshal(renewAccessTokenRequest{RefreshToken: result.RefreshToken})
+	require.NoError(t, err)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("POST", "/tokens/renew_access", bytes.NewBuffer(jsonReq))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var renewed renewAccessTokenResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &renewed)
+	require.NoError(t, err)
+	require.NotEmpty(t, renewed.AccessToken)
+}
+
+func TestLoginAccountInvalidCredentials(t *testing.T) {
+	password := util.RandomString(8)
+	account := CreateRandomAccountWithPassword(t, password)
+
+	server := newTestServer(t, testStore)
+
+	testCases := []struct {
+		name     string
+		loginReq loginAccountRequest
+	}{
+		{
+			name:     "WrongPassword",
+			loginReq: loginAccountRequest{Email: account.Email, Password: password + "x"},
+		},
+		{
+			name:     "UnknownEmail",
+			loginReq: loginAccountRequest{Email: util.RandomEmail(), Password: password},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			jsonReq, err := json.Marshal(tc.loginReq)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest("POST", "/accounts/login", bytes.NewBuffer(jsonReq))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		})
+	}
+}
+
+func TestGetAccountOfAnotherAccount(t *testing.T) {
+	account := CreateUniqueRandomAccount(t)
+	otherAccount := CreateUniqueRandomAccount(t)
+
+	server := newTestServer(t, testStore)
+
+	testCases := []struct {
+		name         string
+		role         string
+		expectedCode int
+	}{
+		{
+			name:         "Depositor",
+			role:         util.DepositorRole,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Admin",
+			role:         util.AdminRole,
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest("GET", fmt.Sprintf("/accounts/%d", account.ID), nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, otherAccount, tc.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
+
+func TestGetAccountsAdminOnly(t *testing.T) {
+	account := CreateUniqueRandomAccount(t)
+
+	server := newTestServer(t, testStore)
+
+	testCases := []struct {
+		name         string
+		role         string
+		expectedCode int
+	}{
+		{
+			name:         "Depositor",
+			role:         util.DepositorRole,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Admin",
+			role:         util.AdminRole,
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest("GET", "/accounts?page_id=1&page_size=5", nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, tc.role, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
 }
 
 func TestGetReferralCodesForAccount(t *testing.T) {
@@ -331,6 +736,7 @@ func TestGetReferralCodesForAccount(t *testing.T) {
 
 	request, err := http.NewRequest("GET", url, nil)
 	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, util.DepositorRole, time.Minute)
 
 	server.router.ServeHTTP(recorder, request)
 	require.Equal(t, http.StatusOK, recorder.Code)
@@ -368,15 +774,13 @@ func TestUserCannotCreateANewReferralCodeBeforeUsingTheCurrentOne(t *testing.T)
 	url := fmt.Sprintf("/referral/account/%d", account.ID)
 	request, err := http.NewRequest("POST", url, nil)
 	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, util.DepositorRole, time.Minute)
 
 	server.router.ServeHTTP(recorder, request)
 	require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
 
-	var errorMessage sqlc.CreateReferralCodeErrorMsg
-	err = json.Unmarshal(recorder.Body.Bytes(), &errorMessage)
-	require.NoError(t, err)
-
-	require.Equal(t, errorMessage.ErrorMessage, "cannot create a new code, before using it")
+	body := requireErrorBody(t, recorder, http.StatusUnprocessableEntity, "unused_referral_code_exists")
+	require.Equal(t, "cannot create a new code, before using it", body.Message)
 
 	// use the existing code now
 	args := sqlc.MarkReferralCodeUsedParams{
@@ -393,6 +797,7 @@ func TestUserCannotCreateANewReferralCodeBeforeUsingTheCurrentOne(t *testing.T)
 	recorder = httptest.NewRecorder()
 	request, err = http.NewRequest("POST", url, nil)
 	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, util.DepositorRole, time.Minute)
 
 	server.router.ServeHTTP(recorder, request)
 	require.Equal(t, http.StatusOK, recorder.Code)
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bank-api/apperr"
+	"bank-api/token"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// Errors of a refresh token whose session cannot issue access tokens
+var (
+	errSessionNotFound = apperr.New(apperr.KindNotFound, "session_not_found", "session not found")
+	errSessionBlocked  = apperr.New(apperr.KindUnauthorized, "session_blocked", "blocked session")
+	errSessionCustomer = apperr.New(apperr.KindUnauthorized, "session_mismatch", "incorrect session customer")
+	errSessionToken    = apperr.New(apperr.KindUnauthorized, "session_mismatch", "mismatched session token")
+	errSessionExpired  = apperr.New(apperr.KindUnauthorized, "session_expired", "expired session")
+)
+
+type renewAccessTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type renewAccessTokenResponse struct {
+	AccessToken          string    `json:"access_token"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+}
+
+// renewAccessToken issues a new access token for a refresh token whose session is still valid
+func (server *Server) renewAccessToken(ctx *gin.Context) {
+	var req renewAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken, token.TokenTypeRefreshToken)
+	if err != nil {
+		ctx.Error(apperr.Wrap(apperr.KindUnauthorized, "invalid_token", err))
+		return
+	}
+
+	session, err := server.store.GetSession(ctx, refreshPayload.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Error(errSessionNotFound)
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+
+	if session.IsBlocked {
+		ctx.Error(errSessionBlocked)
+		return
+	}
+
+	if session.CustomerID != refreshPayload.CustomerID {
+		ctx.Error(errSessionCustomer)
+		return
+	}
+
+	if session.RefreshToken != req.RefreshToken {
+		ctx.Error(errSessionToken)
+		return
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		ctx.Error(errSessionExpired)
+		return
+	}
+
+	// read the role again, so a role change takes effect with the next access token
+	credential, err := server.store.GetCustomerCredential(ctx, session.CustomerID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(refreshPayload.CustomerID, refreshPayload.Email, credential.Role, token.TokenTypeAccessToken, server.config.AccessTokenDuration)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	rsp := renewAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
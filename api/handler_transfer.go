@@ -0,0 +1,165 @@
+package api
+
+import (
+	"bank-api/apperr"
+	"bank-api/db/sqlc"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type transferRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	// converts the amount into the currency of the destination account at the quoted rate
+	FxQuoteID string `json:"fx_quote_id" binding:"omitempty,uuid"`
+}
+
+func (server *Server) createTransfer(ctx *gin.Context) {
+	var req transferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+	if !authorizedAccount(ctx, req.FromAccountID) {
+		ctx.Error(errForbidden)
+		return
+	}
+
+	if _, valid := server.validAccount(ctx, req.FromAccountID, req.Currency); !valid {
+		return
+	}
+
+	arg := sqlc.TransferTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+	}
+
+	// the currency is that of the amount; with a quote the destination account may hold another
+	// one, the transaction checks it against the quote
+	if req.FxQuoteID != "" {
+		arg.FxQuoteID = uuid.NullUUID{UUID: uuid.MustParse(req.FxQuoteID), Valid: true}
+		if _, valid := server.existingAccount(ctx, req.ToAccountID); !valid {
+			return
+		}
+	} else if _, valid := server.validAccount(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	// the funds check happens inside the transaction, against the locked source account
+	result, err := server.store.TransferTx(ctx, arg)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transferResponse{
+		Transfer:    result.Transfer,
+		FromAccount: result.FromAccount,
+		FromEntry:   result.FromEntry,
+	})
+}
+
+// transferResponse is the sender's side of a transfer; the destination account belongs to
+// someone else, so only its ID on the transfer is shown
+type transferResponse struct {
+	Transfer    sqlc.Transfer `json:"transfer"`
+	FromAccount sqlc.Account  `json:"from_account"`
+	FromEntry   sqlc.Entry    `json:"from_entry"`
+}
+
+// validAccount checks that the account exists and holds the given currency. It records the
+// error itself, so callers only need to return when it reports false.
+func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (sqlc.Account, bool) {
+	account, valid := server.existingAccount(ctx, accountID)
+	if !valid {
+		return account, false
+	}
+
+	if account.Currency != currency {
+		ctx.Error(fmt.Errorf("%w: account [%d] holds %s, not %s", sqlc.ErrCurrencyMismatch, account.ID, account.Currency, currency))
+		return account, false
+	}
+
+	return account, true
+}
+
+// existingAccount checks that the account exists, recording the error like validAccount
+func (server *Server) existingAccount(ctx *gin.Context, accountID int64) (sqlc.Account, bool) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Error(&apperr.Error{
+				Kind:    apperr.KindNotFound,
+				Code:    errAccountNotFound.Code,
+				Message: fmt.Sprintf("account [%d] not found", accountID),
+				Err:     err,
+			})
+			return account, false
+		}
+		ctx.Error(err)
+		return account, false
+	}
+
+	return account, true
+}
+
+type getTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var req getTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.Error(errTransferNotFound)
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+type listTransfersRequest struct {
+	AccountID int64 `form:"account_id" binding:"required,min=1"`
+	PageID    int32 `form:"page_id" binding:"required,min=1"`
+	PageSize  int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listTransfers returns the transfers an account took part in, either as sender or receiver.
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var req listTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	arg := sqlc.ListTransfersParams{
+		FromAccountID: req.AccountID,
+		ToAccountID:   req.AccountID,
+		Limit:         req.PageSize,
+		Offset:        (req.PageID - 1) * req.PageSize,
+	}
+
+	transfers, err := server.store.ListTransfers(ctx, arg)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfers)
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/Meenachinmay/microservice-shared/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func createAccountWithBalance(t *testing.T, currency string, balance int64) sqlc.Account {
+	customer := createRandomCustomer(t)
+	account, err := testStore.CreateAccount(context.Background(), sqlc.CreateAccountParams{
+		Owner:      customer.Name,
+		Balance:    balance,
+		Email:      customer.Email,
+		Currency:   currency,
+		CreatedAt:  utils.ConvertToTokyoTime(),
+		CustomerID: sql.NullInt64{Int64: customer.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func postTransfer(t *testing.T, server *Server, account sqlc.Account, body gin.H) *httptest.ResponseRecorder {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("POST", "/transfers", bytes.NewReader(data))
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, util.DepositorRole, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestCreateTransfer(t *testing.T) {
+	server := newTestServer(t, testStore)
+
+	account1 := createAccountWithBalance(t, util.JPY, 500)
+	account2 := createAccountWithBalance(t, util.JPY, 100)
+	amount := int64(200)
+
+	recorder := postTransfer(t, server, account1, gin.H{
+		"from_account_id": account1.ID,
+		"to_account_id":   account2.ID,
+		"amount":          amount,
+		"currency":        util.JPY,
+	})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var result transferResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &result)
+	require.NoError(t, err)
+
+	require.NotZero(t, result.Transfer.ID)
+	require.Equal(t, amount, result.Transfer.Amount)
+	require.Equal(t, account2.ID, result.Transfer.ToAccountID)
+	require.Equal(t, account1.Balance-amount, result.FromAccount.Balance)
+	require.Equal(t, -amount, result.FromEntry.Amount)
+
+	// nothing of the recipient's account leaks to the sender
+	var body map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.NotContains(t, body, "to_account")
+	require.NotContains(t, body, "to_entry")
+
+	received, err := testStore.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+amount, received.Balance)
+}
+
+func TestCreateTransferFromAnotherCustomersAccount(t *testing.T) {
+	server := newTestServer(t, testStore)
+
+	own := createAccountWithBalance(t, util.JPY, 500)
+	victim := createAccountWithBalance(t, util.JPY, 500)
+
+	// the handler binds the last key that matches from_account_id whatever its case
+	body := fmt.Sprintf(`{"from_account_id": %d, "From_Account_ID": %d, "to_account_id": %d, "amount": 5, "currency": %q}`,
+		own.ID, victim.ID, own.ID, util.JPY)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, own, util.DepositorRole, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	account, err := testStore.GetAccount(context.Background(), victim.ID)
+	require.NoError(t, err)
+	require.Equal(t, victim.Balance, account.Balance)
+}
+
+func TestCreateTransferErrors(t *testing.T) {
+	server := newTestServer(t, testStore)
+
+	yenAccount1 := createAccountWithBalance(t, util.JPY, 100)
+	yenAccount2 := createAccountWithBalance(t, util.JPY, 100)
+	usdAccount := createAccountWithBalance(t, "USD", 100)
+
+	testCases := []struct {
+		name         string
+		body         gin.H
+		expectedCode int
+	}{
+		{
+			name: "InsufficientFunds",
+			body: gin.H{
+				"from_account_id": yenAccount1.ID,
+				"to_account_id":   yenAccount2.ID,
+				"amount":          yenAccount1.Balance + 1,
+				"currency":        util.JPY,
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "CurrencyMismatch",
+			body: gin.H{
+				"from_account_id": yenAccount1.ID,
+				"to_account_id":   usdAccount.ID,
+				"amount":          10,
+				"currency":        util.JPY,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "UnsupportedCurrency",
+			body: gin.H{
+				"from_account_id": yenAccount1.ID,
+				"to_account_id":   yenAccount2.ID,
+				"amount":          10,
+				"currency":        "YEN",
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "UnknownAccount",
+			body: gin.H{
+				"from_account_id": yenAccount1.ID,
+				"to_account_id":   yenAccount2.ID + 100000,
+				"amount":          10,
+				"currency":        util.JPY,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "NegativeAmount",
+			body: gin.H{
+				"from_account_id": yenAccount1.ID,
+				"to_account_id":   yenAccount2.ID,
+				"amount":          -10,
+				"currency":        util.JPY,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "AccountNotOwned",
+			body: gin.H{
+				"from_account_id": yenAccount2.ID,
+				"to_account_id":   yenAccount1.ID,
+				"amount":          10,
+				"currency":        util.JPY,
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name: "SameAccount",
+			body: gin.H{
+				"from_account_id": yenAccount1.ID,
+				"to_account_id":   yenAccount1.ID,
+				"amount":          10,
+				"currency":        util.JPY,
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := postTransfer(t, server, yenAccount1, tc.body)
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+
+	// no money moved
+	account, err := testStore.GetAccount(context.Background(), yenAccount1.ID)
+	require.NoError(t, err)
+	require.Equal(t, yenAccount1.Balance, account.Balance)
+}
+
+func TestGetAndListTransfers(t *testing.T) {
+	server := newTestServer(t, testStore)
+
+	account1 := createAccountWithBalance(t, "EUR", 1000)
+	account2 := createAccountWithBalance(t, "EUR", 1000)
+
+	n := 5
+	transfers := make([]sqlc.Transfer, n)
+	for i := 0; i < n; i++ {
+		result, err := testStore.TransferTx(context.Background(), sqlc.TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+		transfers[i] = result.Transfer
+	}
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", fmt.Sprintf("/transfers/%d", transfers[0].ID), nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account1, util.DepositorRole, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var gotTransfer sqlc.Transfer
+	err = json.Unmarshal(recorder.Body.Bytes(), &gotTransfer)
+	require.NoError(t, err)
+	require.Equal(t, transfers[0].ID, gotTransfer.ID)
+	require.Equal(t, transfers[0].Amount, gotTransfer.Amount)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest("GET", fmt.Sprintf("/transfers?account_id=%d&page_id=1&page_size=5", account2.ID), nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account2, util.DepositorRole, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var gotTransfers []sqlc.Transfer
+	err = json.Unmarshal(recorder.Body.Bytes(), &gotTransfers)
+	require.NoError(t, err)
+	require.Len(t, gotTransfers, n)
+	for i := range transfers {
+		require.Equal(t, transfers[i].ID, gotTransfers[i].ID)
+	}
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"bank-api/apperr"
+	"bank-api/db/sqlc"
+	"bank-api/webhook"
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type createWebhookRequest struct {
+	URL string `json:"url" binding:"required,http_url"`
+	// empty for every event type
+	EventTypes []string `json:"event_types" binding:"dive,event_type"`
+	// generated when left out
+	Secret string `json:"secret" binding:"omitempty,min=16,max=128"`
+}
+
+// webhookResponse is a subscription as it is listed, without its secret
+type webhookResponse struct {
+	ID         int64     `json:"id"`
+	AccountID  *int64    `json:"account_id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// createWebhookResponse is the only place the secret is ever shown
+type createWebhookResponse struct {
+	webhookResponse
+	Secret string `json:"secret"`
+}
+
+func newWebhookResponse(subscription sqlc.WebhookSubscription) webhookResponse {
+	response := webhookResponse{
+		ID:         subscription.ID,
+		URL:        subscription.Url,
+		EventTypes: subscription.EventTypes,
+		CreatedAt:  subscription.CreatedAt,
+	}
+	if subscription.AccountID.Valid {
+		response.AccountID = &subscription.AccountID.Int64
+	}
+	return response
+}
+
+type accountWebhooksURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// createAccountWebhook subscribes a URL to the events about an account
+func (server *Server) createAccountWebhook(ctx *gin.Context) {
+	var uri accountWebhooksURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	server.createWebhook(ctx, sql.NullInt64{Int64: uri.ID, Valid: true})
+}
+
+// createGlobalWebhook subscribes a URL to the events about every account
+func (server *Server) createGlobalWebhook(ctx *gin.Context) {
+	server.createWebhook(ctx, sql.NullInt64{})
+}
+
+func (server *Server) createWebhook(ctx *gin.Context, accountID sql.NullInt64) {
+	var req createWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	if !server.config.Webhook.AllowPrivateURLs {
+		if err := webhook.CheckURL(ctx, req.URL); err != nil {
+			ctx.Error(&apperr.Error{
+				Kind:    apperr.KindValidation,
+				Code:    "invalid_webhook_url",
+				Message: "webhook URL must point to a public address",
+				Details: []apperr.Detail{{Field: "url", Message: "must resolve to a public address"}},
+				Err:     err,
+			})
+			return
+		}
+	}
+
+	if req.Secret == "" {
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		req.Secret = secret
+	}
+
+	subscription, err := server.store.CreateWebhookSubscription(ctx, sqlc.CreateWebhookSubscriptionParams{
+		AccountID:  accountID,
+		Url:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createWebhookResponse{
+		webhookResponse: newWebhookResponse(subscription),
+		Secret:          subscription.Secret,
+	})
+}
+
+// listAccountWebhooks lists the subscriptions to the events about an account
+func (server *Server) listAccountWebhooks(ctx *gin.Context) {
+	var uri accountWebhooksURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	server.listWebhooks(ctx, sql.NullInt64{Int64: uri.ID, Valid: true})
+}
+
+// listGlobalWebhooks lists the subscriptions to the events about every account
+func (server *Server) listGlobalWebhooks(ctx *gin.Context) {
+	server.listWebhooks(ctx, sql.NullInt64{})
+}
+
+func (server *Server) listWebhooks(ctx *gin.Context, accountID sql.NullInt64) {
+	subscriptions, err := server.store.ListWebhookSubscriptions(ctx, accountID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	response := make([]webhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		response = append(response, newWebhookResponse(subscription))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+type webhookRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// deleteWebhook ends a subscription; deliveries not sent yet are dropped along with its log
+func (server *Server) deleteWebhook(ctx *gin.Context) {
+	var req webhookRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	if err := server.store.DeleteWebhookSubscription(ctx, req.ID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type listWebhookDeliveriesRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=100"`
+}
+
+// listWebhookDeliveries is the delivery log of a subscription, newest first: every event sent or
+// still to be sent, with the outcome of its last attempt
+func (server *Server) listWebhookDeliveries(ctx *gin.Context) {
+	var uri webhookRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	var req listWebhookDeliveriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.Error(invalidRequest(err))
+		return
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(ctx, sqlc.ListWebhookDeliveriesParams{
+		SubscriptionID: uri.ID,
+		Limit:          req.PageSize,
+		Offset:         (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
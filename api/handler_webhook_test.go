@@ -0,0 +1,156 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"bank-api/webhook/webhooktest"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookSubscriptions(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	server := newTestServer(t, store)
+
+	signup := func() sqlc.Account {
+		result, err := store.CreateAccountTx(context.Background(), sqlc.CreateAccountTxParams{
+			CreateAccountParams: sqlc.CreateAccountParams{
+				Owner:     util.RandomOwner(),
+				Email:     util.RandomEmail(),
+				Balance:   1000,
+				Currency:  util.JPY,
+				CreatedAt: time.Now(),
+			},
+			HashedPassword: "secret",
+		})
+		require.NoError(t, err)
+		return result.Account
+	}
+	send := func(method string, url string, account sqlc.Account, role string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(method, url, nil)
+		require.NoError(t, err)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, role, time.Minute)
+
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	owner := signup()
+	other := signup()
+	receiver := webhooktest.NewReceiver("whsec_0123456789abcdef")
+	defer receiver.Close()
+
+	webhooksURL := fmt.Sprintf("/accounts/%d/webhooks", owner.ID)
+	body := gin.H{"url": receiver.URL, "event_types": []string{sqlc.EventBalanceChanged}, "secret": "whsec_0123456789abcdef"}
+
+	require.Equal(t, http.StatusForbidden, postJSON(t, server, webhooksURL, other, util.DepositorRole, body).Code)
+	require.Equal(t, http.StatusBadRequest, postJSON(t, server, webhooksURL, owner, util.DepositorRole, gin.H{"url": "ftp://example.com"}).Code)
+	require.Equal(t, http.StatusBadRequest, postJSON(t, server, webhooksURL, owner, util.DepositorRole, gin.H{"url": receiver.URL, "event_types": []string{"account.deleted"}}).Code)
+	require.Equal(t, http.StatusForbidden, postJSON(t, server, "/admin/webhooks", owner, util.DepositorRole, body).Code)
+
+	recorder := postJSON(t, server, webhooksURL, owner, util.DepositorRole, body)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	var created createWebhookResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &created))
+	require.Equal(t, owner.ID, *created.AccountID)
+	require.Equal(t, receiver.URL, created.URL)
+	require.Equal(t, "whsec_0123456789abcdef", created.Secret)
+
+	// a secret is generated when none is given
+	recorder = postJSON(t, server, "/admin/webhooks", owner, util.AdminRole, gin.H{"url": receiver.URL + "/partner"})
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	var global createWebhookResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &global))
+	require.Nil(t, global.AccountID)
+	require.Empty(t, global.EventTypes)
+	require.Regexp(t, "^whsec_[0-9a-f]{64}$", global.Secret)
+
+	// listing never shows the secret
+	recorder = send(http.MethodGet, webhooksURL, owner, util.DepositorRole)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), "secret")
+	var listed []webhookResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &listed))
+	require.Equal(t, []webhookResponse{created.webhookResponse}, listed)
+
+	// money arriving in the account is pushed to the receiver
+	_, err := store.TransferTx(context.Background(), sqlc.TransferTxParams{
+		FromAccountID: other.ID,
+		ToAccountID:   owner.ID,
+		Amount:        250,
+	})
+	require.NoError(t, err)
+	_, err = server.relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	_, err = server.webhooks.SendBatch(context.Background())
+	require.NoError(t, err)
+
+	received := receiver.Deliveries()
+	require.Len(t, received, 1)
+	require.Equal(t, sqlc.EventBalanceChanged, received[0].Event)
+	var changed sqlc.BalanceChangedEvent
+	require.NoError(t, json.Unmarshal(received[0].Payload.Data, &changed))
+	require.Equal(t, owner.ID, changed.AccountID)
+	require.Equal(t, int64(250), changed.Amount)
+	require.Equal(t, int64(1250), changed.Balance)
+
+	deliveriesURL := fmt.Sprintf("/webhooks/%d/deliveries?page_id=1&page_size=10", created.ID)
+	require.Equal(t, http.StatusForbidden, send(http.MethodGet, deliveriesURL, other, util.DepositorRole).Code)
+	require.Equal(t, http.StatusForbidden, send(http.MethodGet, fmt.Sprintf("/webhooks/%d/deliveries?page_id=1&page_size=10", global.ID), owner, util.DepositorRole).Code)
+	require.Equal(t, http.StatusNotFound, send(http.MethodGet, "/webhooks/999999/deliveries?page_id=1&page_size=10", owner, util.DepositorRole).Code)
+
+	recorder = send(http.MethodGet, deliveriesURL, owner, util.DepositorRole)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var deliveries []sqlc.WebhookDelivery
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &deliveries))
+	require.Len(t, deliveries, 1)
+	require.Equal(t, "delivered", deliveries[0].Status)
+	require.Equal(t, int32(http.StatusNoContent), deliveries[0].ResponseStatus.Int32)
+
+	// the subscription for every account got every event: both signups, the transfer and both
+	// balance changes
+	globalDeliveries, err := store.ListWebhookDeliveries(context.Background(), sqlc.ListWebhookDeliveriesParams{
+		SubscriptionID: global.ID,
+		Limit:          10,
+	})
+	require.NoError(t, err)
+	require.Len(t, globalDeliveries, 5)
+
+	deleteURL := fmt.Sprintf("/webhooks/%d", created.ID)
+	require.Equal(t, http.StatusForbidden, send(http.MethodDelete, deleteURL, other, util.DepositorRole).Code)
+	require.Equal(t, http.StatusNoContent, send(http.MethodDelete, deleteURL, owner, util.DepositorRole).Code)
+	require.Equal(t, http.StatusNotFound, send(http.MethodDelete, deleteURL, owner, util.DepositorRole).Code)
+
+	recorder = send(http.MethodGet, webhooksURL, owner, util.DepositorRole)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, "[]", recorder.Body.String())
+}
+
+func TestWebhookPrivateURLs(t *testing.T) {
+	server := newTestServer(t, testStore)
+	server.config.Webhook.AllowPrivateURLs = false
+	owner := createAccountWithBalance(t, util.USD, 0)
+	webhooksURL := fmt.Sprintf("/accounts/%d/webhooks", owner.ID)
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/hook",
+	} {
+		recorder := postJSON(t, server, webhooksURL, owner, util.DepositorRole, gin.H{"url": url})
+		requireErrorBody(t, recorder, http.StatusBadRequest, "invalid_webhook_url")
+	}
+
+	recorder := postJSON(t, server, webhooksURL, owner, util.DepositorRole, gin.H{"url": "https://203.0.113.10/hook"})
+	require.Equal(t, http.StatusCreated, recorder.Code)
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bank-api/apperr"
+	"bank-api/db/sqlc"
+	"bank-api/token"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+var (
+	errIdempotencyKeyTooLong = apperr.New(apperr.KindValidation, "idempotency_key_too_long",
+		fmt.Sprintf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength))
+	errIdempotencyKeyReused = apperr.New(apperr.KindUnprocessable, "idempotency_key_reused",
+		fmt.Sprintf("%s was already used for a different request", idempotencyKeyHeader))
+	errIdempotencyKeyInProgress = apperr.New(apperr.KindConflict, "idempotency_key_in_progress",
+		fmt.Sprintf("a request with the same %s is still in progress", idempotencyKeyHeader))
+)
+
+// idempotent lets a client retry a request that has an Idempotency-Key header without running
+// it twice. The first request with a key runs and its response is stored; a retry with the same
+// method, URI and body gets the stored response back, a request that reuses the key for
+// anything else gets 422. While the first request runs, a duplicate gets 409. A 5xx response is
+// not stored, so the request can be retried for real. Requests without the header run as usual.
+//
+// A key still held after the lock timeout of the config belongs to a request that died and a
+// retry takes it over. The request is cut off at the write timeout, which the lock timeout is
+// longer than, so one that is merely slow never runs twice.
+//
+// It goes after authorize on routes that need a signed in customer, whose keys are kept apart
+// from those of other customers.
+func (server *Server) idempotent() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(ctx, errIdempotencyKeyTooLong)
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			abortWithError(ctx, apperr.Wrap(apperr.KindValidation, "invalid_request", err))
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := idempotencyScope(ctx, body)
+		hash := requestFingerprint(ctx.Request, body)
+		now := time.Now()
+		_, err = server.store.ClaimIdempotencyKey(ctx, sqlc.ClaimIdempotencyKeyParams{
+			Scope:       scope,
+			Key:         key,
+			RequestHash: hash,
+			LockedAt:    now,
+			StaleBefore: now.Add(-server.config.Idempotency.LockTimeout),
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				server.replay(ctx, scope, key, hash)
+				return
+			}
+			abortWithError(ctx, err)
+			return
+		}
+
+		// no response reaches the client after the write timeout, the transactions of the request
+		// and their retries are cancelled by then rather than outlive the lock
+		requestCtx, cancel := context.WithTimeout(ctx.Request.Context(), server.config.HTTP.WriteTimeout)
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(requestCtx)
+
+		writer := &bodyWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+		// an error the handler recorded is written now, so it is stored like any other response
+		writeError(ctx)
+
+		// the response is out, a client that went away must not keep it from being stored
+		storeCtx := context.WithoutCancel(ctx.Request.Context())
+		if writer.Status() >= http.StatusInternalServerError {
+			err = server.store.DeleteIdempotencyKey(storeCtx, sqlc.DeleteIdempotencyKeyParams{Scope: scope, Key: key})
+		} else {
+			err = server.store.CompleteIdempotencyKey(storeCtx, sqlc.CompleteIdempotencyKeyParams{
+				Scope:        scope,
+				Key:          key,
+				ResponseCode: sql.NullInt32{Int32: int32(writer.Status()), Valid: true},
+				ResponseBody: writer.body.Bytes(),
+				CompletedAt:  sql.NullTime{Time: time.Now(), Valid: true},
+			})
+		}
+		if err != nil {
+			log.Printf("idempotency key %s of %s: %v", key, scope, err)
+		}
+	}
+}
+
+// replay answers a request whose key is already taken
+func (server *Server) replay(ctx *gin.Context, scope string, key string, hash string) {
+	record, err := server.store.GetIdempotencyKey(ctx, sqlc.GetIdempotencyKeyParams{Scope: scope, Key: key})
+	if err != nil {
+		// the key was released by a failed request in the meantime
+		if errors.Is(err, sql.ErrNoRows) {
+			abortWithError(ctx, errIdempotencyKeyInProgress)
+			return
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	switch {
+	case record.RequestHash != hash:
+		abortWithError(ctx, errIdempotencyKeyReused)
+	case !record.CompletedAt.Valid:
+		abortWithError(ctx, errIdempotencyKeyInProgress)
+	default:
+		ctx.Header(idempotentReplayedHeader, "true")
+		ctx.Data(int(record.ResponseCode.Int32), gin.MIMEJSON+"; charset=utf-8", record.ResponseBody)
+		ctx.Abort()
+	}
+}
+
+// idempotencyScope keeps the keys of different customers apart. Signups have no customer yet,
+// their keys are kept apart by the address of the client and the email signed up with, hashed
+// rather than stored as they are.
+func idempotencyScope(ctx *gin.Context, body []byte) string {
+	if payload, ok := ctx.Get(authorizationPayloadKey); ok {
+		return fmt.Sprintf("customer:%d", payload.(*token.Payload).CustomerID)
+	}
+
+	var signup struct {
+		Email string `json:"email"`
+	}
+	// a body without an email still gets a scope, the handler turns it down
+	_ = json.Unmarshal(body, &signup)
+	hash := sha256.Sum256([]byte(ctx.ClientIP() + "\n" + signup.Email))
+	return "anonymous:" + hex.EncodeToString(hash[:])
+}
+
+// requestFingerprint tells requests apart that reuse a key for something else
+func requestFingerprint(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", request.Method, request.URL.RequestURI())
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// bodyWriter keeps a copy of the response body written through it
+type bodyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func postIdempotent(t *testing.T, server *Server, url string, account *sqlc.Account, key string, body gin.H) *httptest.ResponseRecorder {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	require.NoError(t, err)
+	if key != "" {
+		request.Header.Set(idempotencyKeyHeader, key)
+	}
+	if account != nil {
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, *account, util.DepositorRole, time.Minute)
+	}
+
+	server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIdempotentTransfer(t *testing.T) {
+	server := newTestServer(t, testStore)
+	from := createAccountWithBalance(t, util.JPY, 1000)
+	to := createAccountWithBalance(t, util.JPY, 0)
+	transfer := gin.H{"from_account_id": from.ID, "to_account_id": to.ID, "amount": 100, "currency": util.JPY}
+
+	first := postIdempotent(t, server, "/transfers", &from, "transfer-1", transfer)
+	require.Equal(t, http.StatusOK, first.Code)
+	require.Empty(t, first.Header().Get(idempotentReplayedHeader))
+
+	// the retry gets the same answer without moving the money again
+	retry := postIdempotent(t, server, "/transfers", &from, "transfer-1", transfer)
+	require.Equal(t, http.StatusOK, retry.Code)
+	require.Equal(t, "true", retry.Header().Get(idempotentReplayedHeader))
+	require.JSONEq(t, first.Body.String(), retry.Body.String())
+
+	account, err := server.store.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(900), account.Balance)
+
+	// the key cannot be reused for another transfer
+	transfer["amount"] = 200
+	require.Equal(t, http.StatusUnprocessableEntity, postIdempotent(t, server, "/transfers", &from, "transfer-1", transfer).Code)
+
+	// a rejected transfer is answered the same way on a retry
+	transfer["amount"] = 5000
+	require.Equal(t, http.StatusUnprocessableEntity, postIdempotent(t, server, "/transfers", &from, "transfer-2", transfer).Code)
+	retry = postIdempotent(t, server, "/transfers", &from, "transfer-2", transfer)
+	require.Equal(t, http.StatusUnprocessableEntity, retry.Code)
+	require.Equal(t, "true", retry.Header().Get(idempotentReplayedHeader))
+
+	// without a key every request runs
+	transfer["amount"] = 100
+	require.Equal(t, http.StatusOK, postIdempotent(t, server, "/transfers", &from, "", transfer).Code)
+	require.Equal(t, http.StatusOK, postIdempotent(t, server, "/transfers", &from, "", transfer).Code)
+
+	account, err = server.store.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(700), account.Balance)
+
+	require.Equal(t, http.StatusBadRequest, postIdempotent(t, server, "/transfers", &from, util.RandomString(256), transfer).Code)
+}
+
+func TestIdempotentTransferConcurrentDuplicates(t *testing.T) {
+	server := newTestServer(t, testStore)
+	from := createAccountWithBalance(t, util.JPY, 1000)
+	to := createAccountWithBalance(t, util.JPY, 0)
+	transfer := gin.H{"from_account_id": from.ID, "to_account_id": to.ID, "amount": 100, "currency": util.JPY}
+
+	n := 10
+	codes := make(chan int, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- postIdempotent(t, server, "/transfers", &from, "concurrent", transfer).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	// duplicates are either told to wait or get the stored answer
+	for code := range codes {
+		require.Contains(t, []int{http.StatusOK, http.StatusConflict}, code)
+	}
+
+	account, err := server.store.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(900), account.Balance)
+}
+
+func TestIdempotentKeyInProgress(t *testing.T) {
+	server := newTestServer(t, testStore)
+	from := createAccountWithBalance(t, util.JPY, 1000)
+	to := createAccountWithBalance(t, util.JPY, 0)
+	transfer := gin.H{"from_account_id": from.ID, "to_account_id": to.ID, "amount": 100, "currency": util.JPY}
+
+	// the first request with the key still runs, or died a moment ago
+	request := httptest.NewRequest(http.MethodPost, "/transfers", nil)
+	data, err := json.Marshal(transfer)
+	require.NoError(t, err)
+	_, err = server.store.ClaimIdempotencyKey(context.Background(), sqlc.ClaimIdempotencyKeyParams{
+		Scope:       fmt.Sprintf("customer:%d", from.CustomerID.Int64),
+		Key:         "in-progress",
+		RequestHash: requestFingerprint(request, data),
+		LockedAt:    time.Now().Add(-server.config.Idempotency.LockTimeout / 2),
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusConflict, postIdempotent(t, server, "/transfers", &from, "in-progress", transfer).Code)
+
+	// a key held for too long belongs to a request that died, the retry takes it over
+	_, err = server.store.ClaimIdempotencyKey(context.Background(), sqlc.ClaimIdempotencyKeyParams{
+		Scope:       fmt.Sprintf("customer:%d", from.CustomerID.Int64),
+		Key:         "stale",
+		RequestHash: requestFingerprint(request, data),
+		LockedAt:    time.Now().Add(-2 * server.config.Idempotency.LockTimeout),
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, postIdempotent(t, server, "/transfers", &from, "stale", transfer).Code)
+}
+
+func TestIdempotentScopes(t *testing.T) {
+	server := newTestServer(t, testStore)
+	account1 := createAccountWithBalance(t, util.JPY, 1000)
+	account2 := createAccountWithBalance(t, util.JPY, 1000)
+
+	// customers may pick the same key without seeing each other's answers
+	require.Equal(t, http.StatusOK, postIdempotent(t, server, "/transfers", &account1, "same-key",
+		gin.H{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": 100, "currency": util.JPY}).Code)
+	recorder := postIdempotent(t, server, "/transfers", &account2, "same-key",
+		gin.H{"from_account_id": account2.ID, "to_account_id": account1.ID, "amount": 300, "currency": util.JPY})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, recorder.Header().Get(idempotentReplayedHeader))
+
+	account, err := server.store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1200), account.Balance)
+}
+
+func TestIdempotentSignup(t *testing.T) {
+	server := newTestServer(t, testStore)
+	signup := gin.H{"owner": util.RandomOwner(), "email": util.RandomEmail(), "password": util.RandomString(8), "currency": util.EUR}
+
+	key := util.RandomUUID()
+
+	first := postIdempotent(t, server, "/accounts", nil, key, signup)
+	require.Equal(t, http.StatusOK, first.Code)
+
+	// a retry does not run into the email being taken by the first attempt
+	retry := postIdempotent(t, server, "/accounts", nil, key, signup)
+	require.Equal(t, http.StatusOK, retry.Code)
+	require.JSONEq(t, first.Body.String(), retry.Body.String())
+}
+
+func TestIdempotentSignupScopes(t *testing.T) {
+	server := newTestServer(t, testStore)
+	key := util.RandomUUID()
+
+	// strangers that happen to pick the same key both sign up
+	signup := gin.H{"owner": util.RandomOwner(), "email": util.RandomEmail(), "password": util.RandomString(8), "currency": util.EUR}
+	first := postIdempotent(t, server, "/accounts", nil, key, signup)
+	require.Equal(t, http.StatusOK, first.Code)
+
+	other := gin.H{"owner": util.RandomOwner(), "email": util.RandomEmail(), "password": util.RandomString(8), "currency": util.EUR}
+	recorder := postIdempotent(t, server, "/accounts", nil, key, other)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, recorder.Header().Get(idempotentReplayedHeader))
+
+	// a client elsewhere sending the same signup does not get the answer of the first one
+	data, err := json.Marshal(signup)
+	require.NoError(t, err)
+	request := httptest.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(data))
+	request.Header.Set(idempotencyKeyHeader, key)
+	request.RemoteAddr = "203.0.113.7:4321"
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Empty(t, recorder.Header().Get(idempotentReplayedHeader))
+	require.NotEqual(t, first.Body.String(), recorder.Body.String())
+}
+
+func TestIdempotentRequestIsCutOff(t *testing.T) {
+	server := newTestServer(t, testStore)
+
+	var deadline time.Time
+	server.router.POST("/slow", server.idempotent(), func(ctx *gin.Context) {
+		deadline, _ = ctx.Deadline()
+		ctx.JSON(http.StatusCreated, gin.H{})
+	})
+
+	// a request with a key ends before a retry may take the key over
+	require.Equal(t, http.StatusCreated, postIdempotent(t, server, "/slow", nil, util.RandomUUID(), gin.H{}).Code)
+	require.WithinDuration(t, time.Now().Add(server.config.HTTP.WriteTimeout), deadline, 5*time.Second)
+	require.Less(t, server.config.HTTP.WriteTimeout, server.config.Idempotency.LockTimeout)
+}
+
+func TestIdempotentServerErrorIsNotStored(t *testing.T) {
+	server := newTestServer(t, testStore)
+
+	key := util.RandomUUID()
+	calls := 0
+	server.router.POST("/flaky", server.idempotent(), func(ctx *gin.Context) {
+		calls++
+		if calls == 1 {
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{})
+			return
+		}
+		ctx.JSON(http.StatusCreated, gin.H{"calls": calls})
+	})
+
+	require.Equal(t, http.StatusServiceUnavailable, postIdempotent(t, server, "/flaky", nil, key, gin.H{}).Code)
+	require.Equal(t, http.StatusCreated, postIdempotent(t, server, "/flaky", nil, key, gin.H{}).Code)
+
+	recorder := postIdempotent(t, server, "/flaky", nil, key, gin.H{})
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	require.JSONEq(t, `{"calls": 2}`, recorder.Body.String())
+	require.Equal(t, 2, calls)
+}
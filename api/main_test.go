@@ -0,0 +1,18 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testStore sqlc.Store
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	testStore = sqlc.NewMemoryStore()
+
+	os.Exit(m.Run())
+}
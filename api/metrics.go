@@ -0,0 +1,56 @@
+package api
+
+import (
+	"bank-api/apperr"
+	"bank-api/metrics"
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errInvalidMetricsToken = apperr.New(apperr.KindUnauthorized, "invalid_metrics_token", "invalid metrics token")
+
+// instrument records how long every request took under the route template it matched, so
+// /accounts/1 and /accounts/2 are both counted as /accounts/:id. It goes first so that the
+// status is the one of the response handleErrors wrote.
+func instrument() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		metrics.HTTPRequestsInFlight.Inc()
+		defer metrics.HTTPRequestsInFlight.Dec()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = metrics.UnmatchedRoute
+		}
+		metrics.HTTPRequestDuration.
+			WithLabelValues(metrics.MethodLabel(ctx.Request.Method), route, strconv.Itoa(ctx.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// serveMetrics serves the metrics of the registry to Prometheus. With a token configured, a
+// scrape must send it as its bearer token.
+func (server *Server) serveMetrics(registry *prometheus.Registry) gin.HandlerFunc {
+	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	return func(ctx *gin.Context) {
+		if want := server.config.Metrics.Token; want != "" {
+			fields := strings.Fields(ctx.GetHeader(authorizationHeaderKey))
+			if len(fields) != 2 || strings.ToLower(fields[0]) != authorizationTypeBearer {
+				abortWithError(ctx, errMissingAuthorization)
+				return
+			}
+			if subtle.ConstantTimeCompare([]byte(fields[1]), []byte(want)) != 1 {
+				abortWithError(ctx, errInvalidMetricsToken)
+				return
+			}
+		}
+		handler.ServeHTTP(ctx.Writer, ctx.Request)
+	}
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/metrics"
+	"bank-api/util"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func getMetrics(t *testing.T, server *Server, token string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+	require.NoError(t, err)
+	if token != "" {
+		request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+	}
+	server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestMetricsRouteLabels(t *testing.T) {
+	server := newTestServer(t, testStore)
+	account := createAccountWithBalance(t, util.JPY, 100)
+
+	for _, url := range []string{
+		fmt.Sprintf("/accounts/%d", account.ID),
+		"/accounts/999999999",
+		"/no/such/route",
+	} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, account, util.DepositorRole, time.Minute)
+		server.router.ServeHTTP(recorder, request)
+	}
+
+	recorder := getMetrics(t, server, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+
+	// requests are told apart by route template and status, never by the ID in the path
+	require.Contains(t, body, `bankapi_http_request_duration_seconds_count{method="GET",route="/accounts/:id",status="200"}`)
+	require.Contains(t, body, `bankapi_http_request_duration_seconds_count{method="GET",route="/accounts/:id",status="404"}`)
+	require.Contains(t, body, `bankapi_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"}`)
+	require.NotContains(t, body, fmt.Sprintf("/accounts/%d", account.ID))
+	require.NotContains(t, body, "/no/such/route")
+
+	// the pool of the store, the memory store has none
+	require.Contains(t, body, "bankapi_db_open_connections 0")
+}
+
+func TestMetricsToken(t *testing.T) {
+	server := newTestServer(t, testStore)
+	server.config.Metrics.Token = util.RandomString(32)
+
+	requireErrorBody(t, getMetrics(t, server, ""), http.StatusUnauthorized, "missing_authorization")
+	requireErrorBody(t, getMetrics(t, server, util.RandomString(32)), http.StatusUnauthorized, "invalid_metrics_token")
+
+	recorder := getMetrics(t, server, server.config.Metrics.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.True(t, strings.Contains(recorder.Body.String(), "bankapi_http_requests_in_flight"))
+}
+
+func TestMetricsBusinessCounters(t *testing.T) {
+	server := newTestServer(t, testStore)
+	counter := func(name string) float64 {
+		switch name {
+		case "accounts":
+			return testutil.ToFloat64(metrics.AccountsCreated.WithLabelValues(util.USD))
+		case "transfers":
+			return testutil.ToFloat64(metrics.Transfers.WithLabelValues(util.USD))
+		case "volume":
+			return testutil.ToFloat64(metrics.TransferVolume.WithLabelValues(util.USD))
+		case "codes":
+			return testutil.ToFloat64(metrics.ReferralCodesCreated)
+		case "redeemed":
+			return testutil.ToFloat64(metrics.ReferralCodesRedeemed.WithLabelValues("true"))
+		}
+		panic(name)
+	}
+	before := map[string]float64{}
+	for _, name := range []string{"accounts", "transfers", "volume", "codes", "redeemed"} {
+		before[name] = counter(name)
+	}
+
+	referrer := createAccountWithBalance(t, util.USD, 500)
+	recorder := postJSON(t, server, fmt.Sprintf("/referral/account/%d", referrer.ID), referrer, util.DepositorRole, nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var code sqlc.ReferralCode
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &code))
+
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, signupRequest(t, gin.H{
+		"owner":         util.RandomOwner(),
+		"email":         util.RandomEmail(),
+		"password":      util.RandomString(8),
+		"currency":      util.USD,
+		"referral_code": code.ReferralCode,
+	}))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	to := createAccountWithBalance(t, util.USD, 0)
+	recorder = postTransfer(t, server, referrer, gin.H{
+		"from_account_id": referrer.ID,
+		"to_account_id":   to.ID,
+		"amount":          120,
+		"currency":        util.USD,
+	})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// a failed transfer is not counted
+	recorder = postTransfer(t, server, referrer, gin.H{
+		"from_account_id": referrer.ID,
+		"to_account_id":   to.ID,
+		"amount":          1_000_000,
+		"currency":        util.USD,
+	})
+	require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	require.Equal(t, 1.0, counter("accounts")-before["accounts"])
+	require.Equal(t, 1.0, counter("codes")-before["codes"])
+	require.Equal(t, 1.0, counter("redeemed")-before["redeemed"])
+	require.Equal(t, 1.0, counter("transfers")-before["transfers"])
+	require.Equal(t, 120.0, counter("volume")-before["volume"])
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"bank-api/apperr"
+	"bank-api/db/sqlc"
+	"bank-api/token"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationPayloadKey = "authorization_payload"
+)
+
+var (
+	errMissingAuthorization = apperr.New(apperr.KindUnauthorized, "missing_authorization", "authorization header is not provided")
+	errInvalidAuthorization = apperr.New(apperr.KindUnauthorized, "invalid_authorization", "invalid authorization header format")
+	errTokenRevoked         = apperr.New(apperr.KindUnauthorized, "token_revoked", "token was issued before the password was last changed")
+)
+
+// authMiddleware verifies the bearer access token of the request and stores its payload in the
+// context, so handlers know which customer is calling. Tokens issued before the customer last
+// changed their password are refused, so a password reset signs out access tokens too and not
+// only the sessions they were renewed from.
+func authMiddleware(tokenMaker token.Maker, store sqlc.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
+		if len(authorizationHeader) == 0 {
+			abortWithError(ctx, errMissingAuthorization)
+			return
+		}
+
+		fields := strings.Fields(authorizationHeader)
+		if len(fields) < 2 {
+			abortWithError(ctx, errInvalidAuthorization)
+			return
+		}
+
+		authorizationType := strings.ToLower(fields[0])
+		if authorizationType != authorizationTypeBearer {
+			abortWithError(ctx, apperr.New(apperr.KindUnauthorized, "unsupported_authorization_type",
+				fmt.Sprintf("unsupported authorization type %s", authorizationType)))
+			return
+		}
+
+		accessToken := fields[1]
+		payload, err := tokenMaker.VerifyToken(accessToken, token.TokenTypeAccessToken)
+		if err != nil {
+			abortWithError(ctx, apperr.Wrap(apperr.KindUnauthorized, "invalid_token", err))
+			return
+		}
+
+		// customers without a credential never set a password, so never changed it either
+		credential, err := store.GetCustomerCredential(ctx, payload.CustomerID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			abortWithError(ctx, err)
+			return
+		}
+		if err == nil && payload.IssuedAt.Before(credential.PasswordChangedAt) {
+			abortWithError(ctx, errTokenRevoked)
+			return
+		}
+
+		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Next()
+	}
+}
+
+// authPayload returns the payload authMiddleware stored for the calling customer
+func authPayload(ctx *gin.Context) *token.Payload {
+	return ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+}
@@ -1,50 +1,333 @@
 package api
 
 import (
+	"4d63.com/tz"
+	"bank-api/config"
 	"bank-api/db/sqlc"
+	"bank-api/interest"
+	"bank-api/metrics"
+	"bank-api/notification"
+	"bank-api/outbox"
+	"bank-api/scheduler"
+	"bank-api/sql/schema"
+	"bank-api/token"
+	"bank-api/webhook"
+	"context"
+	"errors"
+	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Server struct {
-	store  *sqlc.Store
+	config     config.Config
+	store      sqlc.Store
+	tokenMaker token.Maker
+	scheduler  *scheduler.Scheduler
+	relay      *outbox.Relay
+	webhooks   *webhook.Worker
+	// nil without a sender
+	emails *notification.Worker
 	router *gin.Engine
+	// dates in requests are Tokyo calendar dates
+	loc *time.Location
+
+	// what the health checks look at
+	startedAt     time.Time
+	schemaVersion int64
+	workers       map[string]*atomic.Bool
+	shuttingDown  atomic.Bool
 }
 
-func NewServer(store *sqlc.Store) *Server {
-	server := &Server{store: store}
+// Names of the background workers, as the health checks report them
+const (
+	workerScheduler   = "scheduler"
+	workerOutboxRelay = "outbox_relay"
+	workerWebhooks    = "webhook_worker"
+	workerEmails      = "email_worker"
+)
+
+// NewServer sets up the API, its scheduled jobs and the outbox relay. Customers are emailed
+// through sender; with a nil sender domain events are only logged.
+func NewServer(config config.Config, store sqlc.Store, tokenMaker token.Maker, sender notification.Sender) (*Server, error) {
+	loc, err := tz.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := scheduler.New(store)
+	if err != nil {
+		return nil, err
+	}
+
+	// extra interest for the following month is recalculated on the cutoff day of every month
+	if err := jobs.Register(scheduler.ReferralInterestJob(store, referralTerms(config.Referral))); err != nil {
+		return nil, err
+	}
+
+	// interest accrues every day and is paid out every month, each for the period that just ended;
+	// extra interest that ran out is reset once the last day it applied to accrued
+	engine, err := interest.NewEngine(store)
+	if err != nil {
+		return nil, err
+	}
+	if err := jobs.Register(scheduler.InterestAccrualJob(engine)); err != nil {
+		return nil, err
+	}
+	if err := jobs.Register(scheduler.InterestPayoutJob(engine)); err != nil {
+		return nil, err
+	}
+	if err := jobs.Register(scheduler.ExtraInterestExpiryJob(engine)); err != nil {
+		return nil, err
+	}
+
+	// idempotency keys are only needed for as long as clients retry
+	if err := jobs.Register(scheduler.IdempotencyKeyCleanupJob(store)); err != nil {
+		return nil, err
+	}
+
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		if err := v.RegisterValidation("currency", validCurrency); err != nil {
+			return nil, err
+		}
+		if err := v.RegisterValidation("event_type", validEventType); err != nil {
+			return nil, err
+		}
+		if err := v.RegisterValidation("bcrypt", validBcrypt); err != nil {
+			return nil, err
+		}
+		// validation errors name fields the way clients send them
+		v.RegisterTagNameFunc(fieldName)
+	}
+
+	// domain events are logged, sent to the webhooks subscribed to them, and customers emailed
+	// about the ones that concern them. The relay only records the webhook and email deliveries,
+	// workers of their own send them.
+	sink := outbox.FanOut(outbox.LogSink{}, webhook.NewDispatcher(store))
+	var emails *notification.Worker
+	if sender != nil {
+		sink = outbox.FanOut(sink, notification.NewNotifier(store))
+		emails, err = notification.NewWorker(store, sender, notification.Config{})
+		if err != nil {
+			return nil, err
+		}
+	}
+	relay := outbox.NewRelay(store, sink, outbox.Config{})
+
+	webhooks := webhook.NewWorker(store, webhook.Config{AllowPrivateNetworks: config.Webhook.AllowPrivateURLs})
+
+	// the database must be migrated at least as far as the migrations this build ships with
+	schemaVersion, err := schema.LatestVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := metrics.NewRegistry(metrics.NewDBStatsCollector(store.Stats))
+	if err != nil {
+		return nil, err
+	}
+
+	server := &Server{config: config, store: store, tokenMaker: tokenMaker, scheduler: jobs, relay: relay, webhooks: webhooks, emails: emails, loc: loc}
+	server.startedAt = time.Now()
+	server.schemaVersion = schemaVersion
+	server.workers = map[string]*atomic.Bool{}
+	for _, name := range []string{workerScheduler, workerOutboxRelay, workerWebhooks} {
+		server.workers[name] = new(atomic.Bool)
+	}
+	if emails != nil {
+		server.workers[workerEmails] = new(atomic.Bool)
+	}
 	router := gin.Default()
+	// the context of a handler is the one of its request, so the database work of a request is
+	// cancelled with it, see idempotent
+	router.ContextWithFallback = true
+
+	// every error response is written by handleErrors, in the shape of errorEnvelope; instrument
+	// times the requests and sees the status handleErrors set
+	router.Use(instrument(), requestID(), handleErrors())
 
 	// Configure CORS
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "http://localhost", "https://*", "http://*"}, // Specify the exact origin of your Next.js app
+		AllowOrigins:     config.CORSAllowedOrigins, // the exact origins of the Next.js app
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", idempotencyKeyHeader, requestIDHeader},
+		ExposeHeaders:    []string{idempotentReplayedHeader, requestIDHeader},
 		AllowCredentials: true, // Important: Must be true when credentials are included
 		MaxAge:           12 * time.Hour,
 	}))
 
+	// probes of the orchestrator and the load balancer, and the scrapes of Prometheus
+	router.GET("/healthz", server.healthz)                // liveness, the process is up and serving
+	router.GET("/readyz", server.readyz)                  // readiness, database, migrations and background workers; fails while shutting down
+	router.GET("/metrics", server.serveMetrics(registry)) // Prometheus metrics, with METRICS_TOKEN as the bearer token when it is set
+
+	// signups, referral redemptions and transfers can be retried safely with an Idempotency-Key
+	// header, see idempotent
 	// account related routes (login, signup, fetch)
-	router.POST("/accounts", server.createAccount)      // create a account (email, name, referral_code?)
-	router.POST("/accounts/login", server.loginAccount) // login using email
-	router.GET("/accounts/:id", server.getAccount)      // get account detail for a user
-	router.GET("/accounts", server.getAccounts)
+	router.POST("/accounts", server.idempotent(), server.createAccount) // sign up a customer with their first account (email, owner, password, currency, referral_code?)
+	router.POST("/accounts/login", server.loginAccount)                 // login using email and password, returns all of the customer's accounts
+	router.POST("/tokens/renew_access", server.renewAccessToken)        // exchange a refresh token for a new access token
+
+	// password resets, also how customers from before passwords choose their first one
+	router.POST("/password-reset", server.requestPasswordReset)  // email a reset token to the customer (email), 202 whether or not the email is known
+	router.POST("/password-reset/confirm", server.resetPassword) // choose a new password with the emailed token (token, password), signs out every session
+
+	// everything below acts on behalf of the signed in customer; each route declares which
+	// accounts the caller may act on, admins may act on any
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.store))
+
+	authRoutes.GET("/accounts/:id", server.authorize(server.accountOwner(uriAccountID("id"))), server.getAccount) // get account detail for a user
+	authRoutes.GET("/accounts", server.authorize(adminOnly()), server.getAccounts)
+	authRoutes.GET("/accounts/:id/entries", server.authorize(server.accountOwner(uriAccountID("id"))), server.listAccountEntries) // movements of an account with running balance (cursor, page_size, from, to, direction)
+
+	// webhook routes, subscriptions push the events about an account to a URL instead of it being polled
+	authRoutes.POST("/accounts/:id/webhooks", server.authorize(server.accountOwner(uriAccountID("id"))), server.createAccountWebhook) // subscribe a URL to the events about the account (url, event_types?, secret?), the secret is only shown here
+	authRoutes.GET("/accounts/:id/webhooks", server.authorize(server.accountOwner(uriAccountID("id"))), server.listAccountWebhooks)   // subscriptions to the events about the account
+	authRoutes.DELETE("/webhooks/:id", server.authorize(server.webhookSubscriber("id")), server.deleteWebhook)                        // end a subscription
+	authRoutes.GET("/webhooks/:id/deliveries", server.authorize(server.webhookSubscriber("id")), server.listWebhookDeliveries)        // delivery log of a subscription, newest first (page_id, page_size)
+
+	// customer routes, a customer holds one account per currency
+	authRoutes.GET("/customers/:id/accounts", server.authorize(customerSelf("id")), server.listCustomerAccounts) // every account of the customer
+	authRoutes.POST("/customers/:id/accounts", server.authorize(customerSelf("id")), server.openCustomerAccount) // open another account for the customer (currency), one per currency
 
 	// referral_Code feature routes
-	router.POST("/referral/account/:account", server.createReferral)    // create a new referral code
-	router.POST("/referral/code/:code", server.useReferralCode)         //
-	router.GET("referral/calculate/:account", server.calculateInterest) // refresh extra interest for the following month
-	router.GET("/referral-codes", server.getReferralCodesForAccount)    // get all the referrals code for a user
+	authRoutes.POST("/referral/account/:account", server.authorize(server.accountOwner(uriAccountID("account"))), server.createReferral)                              // create a new referral code
+	authRoutes.POST("/referral/code/:code", server.authorize(server.accountOwner(jsonAccountID("referred_account_id"))), server.idempotent(), server.useReferralCode) //
+	authRoutes.GET("/referral-codes", server.authorize(server.accountOwner(queryAccountID("account"))), server.getReferralCodesForAccount)                            // get all the referrals code for a user
+
+	// money transfer routes
+	authRoutes.POST("/transfers", server.authorize(server.accountOwner(jsonAccountID("from_account_id"))), server.idempotent(), server.createTransfer) // move money between two accounts of the same currency, or of two currencies with fx_quote_id
+	authRoutes.GET("/transfers/:id", server.authorize(server.transferParticipant("id")), server.getTransfer)                                           // get a single transfer
+	authRoutes.GET("/transfers", server.authorize(server.accountOwner(queryAccountID("account_id"))), server.listTransfers)                            // list transfers of an account (account_id, page_id, page_size)
+
+	// currency conversion routes
+	authRoutes.GET("/fx/rates", server.listFxRates)                                                                         // current rate of every currency pair
+	authRoutes.POST("/fx/quotes", server.authorize(server.accountOwner(jsonAccountID("account_id"))), server.createFxQuote) // lock the current rate for a transfer out of the account (account_id, to_currency, amount)
+
+	// admin routes
+	authRoutes.GET("/admin/jobs/:name/runs", server.authorize(adminOnly()), server.listJobRuns)            // run history of a scheduled job
+	authRoutes.POST("/admin/jobs/:name/runs", server.authorize(adminOnly()), server.runJob)                // run a scheduled job for a period by hand
+	authRoutes.GET("/admin/reconciliation", server.authorize(adminOnly()), server.reconcileLedger)         // check balances, transfers and journals against the entries (format, batch_size)
+	authRoutes.POST("/admin/fx/rates", server.authorize(adminOnly()), server.setFxRate)                    // set the current rate of a currency pair (base_currency, quote_currency, rate)
+	authRoutes.GET("/admin/outbox", server.authorize(adminOnly()), server.listOutboxEvents)                // domain events of a status (status, page_id, page_size), dead ones ran out of attempts
+	authRoutes.POST("/admin/outbox/:id/requeue", server.authorize(adminOnly()), server.requeueOutboxEvent) // give a dead event a fresh set of attempts
+	authRoutes.POST("/admin/webhooks", server.authorize(adminOnly()), server.createGlobalWebhook)          // subscribe a partner URL to the events about every account (url, event_types?, secret?)
+	authRoutes.GET("/admin/webhooks", server.authorize(adminOnly()), server.listGlobalWebhooks)            // subscriptions to the events about every account
+	authRoutes.GET("/admin/status", server.authorize(adminOnly()), server.getStatus)                       // build, readiness checks with their errors and connection pool stats
+
+	router.NoRoute(func(ctx *gin.Context) {
+		ctx.Error(errRouteNotFound)
+	})
 
 	server.router = router
-	return server
+	return server, nil
 }
 
-func (server *Server) Start(addr string) error {
-	return server.router.Run(addr)
+// Start serves the API on the configured address until ctx is done, see Serve
+func (server *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", server.config.HTTPServerAddress)
+	if err != nil {
+		return err
+	}
+	return server.Serve(ctx, listener)
+}
+
+// Serve runs the scheduled jobs, the outbox relay and the webhook and email workers in the
+// background and serves the API on listener until ctx is done. It then shuts down gracefully:
+// no new connections are accepted, the requests in flight get up to the shutdown timeout to
+// finish, and only then the background work is stopped and waited for, so that a transfer that
+// was accepted is also completed. Jobs still running when the shutdown timeout is over are cancelled
+// and waited for, so that their runs are recorded as failed rather than left running.
+func (server *Server) Serve(ctx context.Context, listener net.Listener) error {
+	httpServer := &http.Server{
+		Handler:           server.router,
+		ReadHeaderTimeout: server.config.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       server.config.HTTP.ReadTimeout,
+		WriteTimeout:      server.config.HTTP.WriteTimeout,
+		IdleTimeout:       server.config.HTTP.IdleTimeout,
+	}
+
+	server.scheduler.Start()
+	server.workers[workerScheduler].Store(true)
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	runs := map[string]func(context.Context){
+		workerOutboxRelay: server.relay.Run,
+		workerWebhooks:    server.webhooks.Run,
+	}
+	if server.emails != nil {
+		runs[workerEmails] = server.emails.Run
+	}
+	for name, run := range runs {
+		running := server.workers[name]
+		running.Store(true)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			defer running.Store(false)
+			run(workersCtx)
+		}()
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- httpServer.Serve(listener)
+	}()
+	log.Printf("serving the API on %s", listener.Addr())
+
+	var err error
+	// requests and jobs share the shutdown timeout, so that the whole shutdown stays within it
+	var shutdownCtx context.Context
+	var cancel context.CancelFunc
+	select {
+	case err = <-served:
+		// the listener failed, there is nothing to drain
+		shutdownCtx, cancel = context.WithTimeout(context.Background(), server.config.HTTP.ShutdownTimeout)
+		defer cancel()
+	case <-ctx.Done():
+		// readiness fails from now on; requests keep being served for a while, until the load
+		// balancer noticed
+		server.shuttingDown.Store(true)
+		if delay := server.config.HTTP.ShutdownDelay; delay > 0 {
+			log.Printf("shutting down, reporting not ready for %s", delay)
+			time.Sleep(delay)
+		}
+		log.Printf("shutting down, draining requests for up to %s", server.config.HTTP.ShutdownTimeout)
+		shutdownCtx, cancel = context.WithTimeout(context.Background(), server.config.HTTP.ShutdownTimeout)
+		defer cancel()
+		if err = httpServer.Shutdown(shutdownCtx); err != nil {
+			err = fmt.Errorf("draining requests: %w", err)
+		}
+	}
+
+	stopWorkers()
+	workers.Wait()
+	if server.scheduler.Shutdown(shutdownCtx) != nil {
+		log.Println("scheduled jobs still running, cancelled them")
+	}
+	server.workers[workerScheduler].Store(false)
+	log.Println("background workers stopped")
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
+// referralTerms are the terms of the referral program the store applies
+func referralTerms(referral config.ReferralConfig) sqlc.ReferralTerms {
+	return sqlc.ReferralTerms{
+		ExtraInterest:         referral.ExtraInterest,
+		MaxExtraInterest:      referral.MaxExtraInterest,
+		ExtraInterestDuration: referral.ExtraInterestDuration,
+		CutoffDay:             referral.CutoffDay,
+	}
 }
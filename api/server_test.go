@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bank-api/db/sqlc"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// serveSlow serves the API with a route that blocks until release is closed. It returns the
+// address, a channel closed once the slow request came in, and the result of Serve.
+func serveSlow(t *testing.T, ctx context.Context, server *Server, release chan struct{}) (string, chan struct{}, chan error) {
+	started := make(chan struct{})
+	server.router.GET("/slow", func(ctx *gin.Context) {
+		close(started)
+		<-release
+		ctx.String(http.StatusOK, "done")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ctx, listener)
+	}()
+	return listener.Addr().String(), started, served
+}
+
+func TestServeDrainsRequestsOnShutdown(t *testing.T) {
+	server := newTestServer(t, sqlc.NewMemoryStore())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	release := make(chan struct{})
+	addr, started, served := serveSlow(t, ctx, server, release)
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		rsp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer rsp.Body.Close()
+		body, err := io.ReadAll(rsp.Body)
+		responses <- result{status: rsp.StatusCode, body: string(body), err: err}
+	}()
+
+	<-started
+	cancel()
+
+	// new connections are turned away while the request in flight is still running
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case err := <-served:
+		t.Fatalf("Serve returned before the request finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	rsp := <-responses
+	require.NoError(t, rsp.err)
+	require.Equal(t, http.StatusOK, rsp.status)
+	require.Equal(t, "done", rsp.body)
+	require.NoError(t, <-served)
+}
+
+func TestServeGivesUpAfterShutdownTimeout(t *testing.T) {
+	server := newTestServer(t, sqlc.NewMemoryStore())
+	server.config.HTTP.ShutdownTimeout = 100 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+	addr, started, served := serveSlow(t, ctx, server, release)
+
+	go http.Get("http://" + addr + "/slow")
+	<-started
+	cancel()
+
+	select {
+	case err := <-served:
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not give up on the request in flight")
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"bank-api/util"
+	"bank-api/webhook"
+	"github.com/go-playground/validator/v10"
+)
+
+// validCurrency accepts the ISO 4217 codes of the currency registry, used as binding:"currency"
+var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if currency, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsSupportedCurrency(currency)
+	}
+	return false
+}
+
+// validEventType accepts the types of domain event a webhook can subscribe to, used as binding:"event_type"
+var validEventType validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if eventType, ok := fieldLevel.Field().Interface().(string); ok {
+		return webhook.IsEventType(eventType)
+	}
+	return false
+}
+
+// maxPasswordBytes is as much of a password as bcrypt hashes, it refuses longer ones
+const maxPasswordBytes = 72
+
+// validBcrypt accepts passwords bcrypt can hash, used as binding:"bcrypt" next to max=72, which
+// counts characters rather than bytes
+var validBcrypt validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if password, ok := fieldLevel.Field().Interface().(string); ok {
+		return len(password) <= maxPasswordBytes
+	}
+	return false
+}
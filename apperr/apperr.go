@@ -0,0 +1,150 @@
+// Package apperr holds the errors the domain reports to callers: what went wrong, as a kind the
+// API maps to a status code and a machine readable code clients can switch on, with a message
+// that is safe to show. Everything else is an internal error whose details stay in the logs.
+//
+// The sentinel errors of the store and the other packages are *Error values, so callers still
+// compare them with errors.Is and may wrap them with more context using %w.
+package apperr
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Kind is the class of an error, it decides the status code of the response
+type Kind string
+
+const (
+	// KindValidation is a request that is malformed or asks for something that can never work
+	KindValidation Kind = "validation"
+	// KindUnauthorized is a caller that is not signed in, or failed to sign in
+	KindUnauthorized Kind = "unauthorized"
+	// KindForbidden is a signed in caller that may not do what it asked for
+	KindForbidden Kind = "forbidden"
+	KindNotFound  Kind = "not_found"
+	// KindConflict is a request at odds with the current state, e.g. a code that is already used
+	KindConflict Kind = "conflict"
+	// KindInsufficientFunds is a debit the account cannot cover
+	KindInsufficientFunds Kind = "insufficient_funds"
+	// KindUnprocessable is a well formed request that breaks a business rule, e.g. referring oneself
+	KindUnprocessable Kind = "unprocessable"
+	// KindInternal is anything the caller cannot do anything about
+	KindInternal Kind = "internal"
+)
+
+// Detail points at a field of the request that is at fault
+type Detail struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an error a caller may be told about
+type Error struct {
+	Kind Kind
+	// Code tells errors of a kind apart, e.g. referral_code_used; it never changes once published
+	Code    string
+	Message string
+	Details []Detail
+	// Err is the error this one was made from, kept for errors.Is and the logs but never shown
+	Err error
+}
+
+// New makes an error, usually a sentinel
+func New(kind Kind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Wrap makes an error of err, whose message is shown as is
+func Wrap(kind Kind, code string, err error) *Error {
+	return &Error{Kind: kind, Code: code, Message: err.Error(), Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// KindOf returns the kind of the first *Error in the chain of err, KindInternal when there is none
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+	return KindInternal
+}
+
+// Postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+	checkViolation      = "23514"
+)
+
+// keyDetail picks the columns out of the detail of a violation, e.g. Key (email)=(a@b.c) already exists.
+var keyDetail = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// FromDB translates the errors of the database a caller may be told about: a missing row, and
+// unique, foreign key and check violations. Other errors come back as they are, and an *Error
+// in the chain is left alone.
+func FromDB(err error) error {
+	var appErr *Error
+	if err == nil || errors.As(err, &appErr) {
+		return err
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &Error{Kind: KindNotFound, Code: "not_found", Message: "not found", Err: err}
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	field := pqErr.Column
+	if match := keyDetail.FindStringSubmatch(pqErr.Detail); match != nil {
+		field = match[1]
+	}
+	if field == "" {
+		field = pqErr.Constraint
+	}
+
+	switch pqErr.Code {
+	case uniqueViolation:
+		return &Error{
+			Kind:    KindConflict,
+			Code:    "already_exists",
+			Message: "already exists",
+			Details: []Detail{{Field: field, Message: "is already taken"}},
+			Err:     err,
+		}
+	case foreignKeyViolation:
+		// the row that is referred to is missing, or a row being deleted is still referred to
+		if strings.HasPrefix(pqErr.Message, "update or delete") {
+			return &Error{Kind: KindConflict, Code: "still_referenced", Message: "still referred to by other records", Err: err}
+		}
+		return &Error{
+			Kind:    KindValidation,
+			Code:    "invalid_reference",
+			Message: "refers to a record that does not exist",
+			Details: []Detail{{Field: field, Message: "does not exist"}},
+			Err:     err,
+		}
+	case checkViolation:
+		return &Error{
+			Kind:    KindValidation,
+			Code:    "check_violation",
+			Message: "violates a constraint",
+			Details: []Detail{{Field: field, Message: "is out of range"}},
+			Err:     err,
+		}
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package apperr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromDB(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		kind   Kind
+		code   string
+		fields []string
+	}{
+		{
+			name: "NoRows",
+			err:  fmt.Errorf("get account: %w", sql.ErrNoRows),
+			kind: KindNotFound,
+			code: "not_found",
+		},
+		{
+			name:   "UniqueViolation",
+			err:    &pq.Error{Code: "23505", Detail: "Key (owner, currency)=(bob, JPY) already exists.", Constraint: "owner_currency_key"},
+			kind:   KindConflict,
+			code:   "already_exists",
+			fields: []string{"owner, currency"},
+		},
+		{
+			name:   "MissingReference",
+			err:    &pq.Error{Code: "23503", Message: `insert or update on table "entries" violates foreign key constraint "entries_account_id_fkey"`, Constraint: "entries_account_id_fkey"},
+			kind:   KindValidation,
+			code:   "invalid_reference",
+			fields: []string{"entries_account_id_fkey"},
+		},
+		{
+			name: "StillReferenced",
+			err:  &pq.Error{Code: "23503", Message: `update or delete on table "accounts" violates foreign key constraint "entries_account_id_fkey" on table "entries"`},
+			kind: KindConflict,
+			code: "still_referenced",
+		},
+		{
+			name:   "CheckViolation",
+			err:    &pq.Error{Code: "23514", Constraint: "accounts_balance_check"},
+			kind:   KindValidation,
+			code:   "check_violation",
+			fields: []string{"accounts_balance_check"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := FromDB(tc.err)
+			require.ErrorIs(t, err, tc.err)
+
+			var appErr *Error
+			require.ErrorAs(t, err, &appErr)
+			require.Equal(t, tc.kind, appErr.Kind)
+			require.Equal(t, tc.code, appErr.Code)
+
+			var fields []string
+			for _, detail := range appErr.Details {
+				fields = append(fields, detail.Field)
+			}
+			require.Equal(t, tc.fields, fields)
+		})
+	}
+}
+
+func TestFromDBLeavesOtherErrors(t *testing.T) {
+	require.NoError(t, FromDB(nil))
+
+	internal := errors.New("connection refused")
+	require.Equal(t, internal, FromDB(internal))
+	require.Equal(t, KindInternal, KindOf(FromDB(internal)))
+
+	deadlock := &pq.Error{Code: "40P01"}
+	require.Equal(t, error(deadlock), FromDB(deadlock))
+
+	// a domain error wrapping a missing row keeps its own kind
+	notFound := New(KindNotFound, "account_not_found", "account not found")
+	wrapped := fmt.Errorf("%w: %w", notFound, sql.ErrNoRows)
+	require.Equal(t, wrapped, FromDB(wrapped))
+}
@@ -0,0 +1,64 @@
+// Command fxrates records the rates of a CSV file of base,quote,rate rows as the current fx
+// rates, e.g. from a daily rate sheet. A file with a bad row records nothing.
+package main
+
+import (
+	"bank-api/config"
+	"bank-api/db/sqlc"
+	"bank-api/fx"
+	"context"
+	"database/sql"
+	"flag"
+	_ "github.com/lib/pq"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	path := flag.String("file", "", "CSV file of base,quote,rate rows")
+	flag.Parse()
+
+	if *path == "" {
+		log.Fatal("missing -file")
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		log.Fatal("cannot open rates: ", err)
+	}
+	defer file.Close()
+
+	conn, err := openDB()
+	if err != nil {
+		log.Fatal("cannot connect to database: ", err)
+	}
+	defer conn.Close()
+
+	loaded, err := fx.LoadRates(context.Background(), sqlc.New(conn), file, "file:"+filepath.Base(*path))
+	if err != nil {
+		log.Printf("loaded %d rates before failing: %v", loaded, err)
+		os.Exit(1)
+	}
+	log.Printf("loaded %d rates from %s", loaded, *path)
+}
+
+func openDB() (*sql.DB, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("postgres", cfg.DBSource)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
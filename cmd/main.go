@@ -2,75 +2,119 @@ package main
 
 import (
 	"bank-api/api"
+	"bank-api/config"
 	"bank-api/db/sqlc"
+	"bank-api/notification"
+	"bank-api/token"
+	"context"
 	"database/sql"
-	"errors"
+	"fmt"
 	_ "github.com/lib/pq"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
-const (
-	serverAddress = ":8080"
-)
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("cannot load config:", err)
+	}
+	log.Printf("running in %s", cfg.Environment)
 
-var counts int64
+	// SIGTERM is how docker stops the container, SIGINT is ctrl-c
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
 
-func main() {
-	// connect to database
-	conn := connectToDB()
+	conn, err := connectToDB(ctx, cfg)
+	if err != nil {
+		log.Fatal("cannot connect to database: ", err)
+	}
 	defer conn.Close()
 
+	tokenMaker, err := token.NewJWTMaker(cfg.TokenSymmetricKey)
+	if err != nil {
+		log.Fatal("cannot create token maker:", err)
+	}
+
+	sender, err := newSender(cfg.Mail)
+	if err != nil {
+		log.Fatal("cannot create notification sender:", err)
+	}
+
 	store := sqlc.NewStore(conn)
-	server := api.NewServer(store)
+	server, err := api.NewServer(cfg, store, tokenMaker, sender)
+	if err != nil {
+		log.Fatal("cannot create server:", err)
+	}
 
-	err := server.Start(serverAddress)
+	// returns once a signal came in and the server drained its requests and stopped its workers
+	err = server.Start(ctx)
 	if err != nil {
-		log.Fatal("cannot start server:", err)
+		log.Fatal("server stopped: ", err)
 	}
+	log.Println("server stopped")
 }
 
-func openDB() (*sql.DB, error) {
-	dbURL := os.Getenv("DB_SOURCE_PROD")
-	if dbURL == "" {
-		dbURL = os.Getenv("DB_SOURCE")
+// newSender picks how customers are emailed: through the SMTP server at SMTP_HOST, into .eml
+// files in MAIL_DIR for development, or not at all when neither is set
+func newSender(mail config.MailConfig) (notification.Sender, error) {
+	if mail.SMTPHost != "" {
+		return notification.NewSMTPSender(mail.SMTPHost, mail.SMTPPort, mail.SMTPUsername, mail.SMTPPassword, mail.From), nil
 	}
-	if dbURL == "" {
-		return nil, errors.New("missing DATABASE_URL")
+
+	if mail.Dir != "" {
+		return notification.NewFileSender(mail.Dir, mail.From)
 	}
 
-	db, err := sql.Open("postgres", dbURL)
+	log.Println("neither SMTP_HOST nor MAIL_DIR is set, customers are not emailed")
+	return nil, nil
+}
+
+// openDB opens a pool sized by the config and checks that the database answers
+func openDB(ctx context.Context, cfg config.Config) (*sql.DB, error) {
+	db, err := sql.Open("postgres", cfg.DBSource)
 	if err != nil {
 		return nil, err
 	}
+	db.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DB.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.DB.ConnMaxIdleTime)
 
-	err = db.Ping()
-	if err != nil {
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return db, nil
 }
 
-func connectToDB() *sql.DB {
-	for {
-		connection, err := openDB()
-		if err != nil {
-			log.Println("Could not connect to database, Postgres is not ready...")
-			counts += 1
-		} else {
+// connectToDB waits for the database to become ready, e.g. while its container starts. It
+// gives up after the configured number of attempts, or when ctx is done.
+func connectToDB(ctx context.Context, cfg config.Config) (*sql.DB, error) {
+	var err error
+	for attempt := 1; attempt <= cfg.DB.ConnectAttempts; attempt++ {
+		var db *sql.DB
+		db, err = openDB(ctx, cfg)
+		if err == nil {
 			log.Println("Connected to database...")
-			return connection
+			return db, nil
 		}
-
-		if counts > 10 {
-			log.Println(err)
-			return nil
+		log.Printf("Could not connect to database, attempt %d of %d: %v", attempt, cfg.DB.ConnectAttempts, err)
+		if attempt == cfg.DB.ConnectAttempts {
+			break
 		}
 
-		log.Println("Waiting for database to become ready...")
-		time.Sleep(2 * time.Second)
-		continue
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(cfg.DB.ConnectInterval):
+		}
 	}
+	return nil, fmt.Errorf("database not reachable after %d attempts: %w", cfg.DB.ConnectAttempts, err)
 }
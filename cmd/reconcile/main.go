@@ -0,0 +1,91 @@
+// Command reconcile checks that every account balance equals the sum of its entries, that every
+// transfer is booked by its entries and that every journal sums to zero. It writes the
+// mismatches as JSON or CSV and exits 1 when it found any, 2 when it could not finish.
+package main
+
+import (
+	"bank-api/config"
+	"bank-api/db/sqlc"
+	"bank-api/reconcile"
+	"context"
+	"database/sql"
+	"flag"
+	_ "github.com/lib/pq"
+	"log"
+	"os"
+)
+
+// Exit codes
+const (
+	exitOK       = 0
+	exitMismatch = 1
+	exitError    = 2
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	format := flag.String("format", reconcile.FormatJSON, "output format, json or csv")
+	batchSize := flag.Int("batch", reconcile.DefaultBatchSize, "rows read per query")
+	output := flag.String("out", "", "file to write the report to, stdout when empty")
+	flag.Parse()
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Println("cannot create report:", err)
+			return exitError
+		}
+		defer file.Close()
+		out = file
+	}
+
+	writer, err := reconcile.NewWriter(*format, out)
+	if err != nil {
+		log.Println(err)
+		return exitError
+	}
+
+	conn, err := openDB()
+	if err != nil {
+		log.Println("cannot connect to database:", err)
+		return exitError
+	}
+	defer conn.Close()
+
+	summary, err := reconcile.Run(context.Background(), sqlc.New(conn), int32(*batchSize), writer)
+	if err != nil {
+		log.Println("reconciliation failed:", err)
+		return exitError
+	}
+
+	log.Printf("checked %d accounts, %d transfers and %d journals: %d mismatches",
+		summary.Accounts, summary.Transfers, summary.Journals, summary.Mismatches)
+	if !summary.OK() {
+		return exitMismatch
+	}
+	return exitOK
+}
+
+func openDB() (*sql.DB, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("postgres", cfg.DBSource)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
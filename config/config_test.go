@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// envOf looks variables up in a map rather than the environment of the test process
+func envOf(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "app.env")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	config, err := load(envOf(nil), "")
+	require.NoError(t, err)
+	require.Equal(t, Defaults(EnvDevelopment), config)
+
+	require.Equal(t, EnvDevelopment, config.Environment)
+	require.Equal(t, ":8080", config.HTTPServerAddress)
+	require.Equal(t, int64(1000), config.Referral.WelcomeBonus)
+	require.Equal(t, 1.0, config.Referral.ExtraInterest)
+	require.Equal(t, 10.0, config.Referral.MaxExtraInterest)
+	require.Equal(t, int32(9), config.Referral.ExtraInterestDuration)
+	require.Equal(t, 21, config.Referral.CutoffDay)
+	require.Equal(t, 15*time.Minute, config.AccessTokenDuration)
+	require.Equal(t, 25, config.DB.MaxOpenConns)
+	require.Equal(t, 10, config.DB.ConnectAttempts)
+	require.Equal(t, 20*time.Second, config.HTTP.ShutdownTimeout)
+	require.Equal(t, 10*time.Minute, config.Idempotency.LockTimeout)
+}
+
+func TestLoadFileAndEnv(t *testing.T) {
+	path := writeFile(t, `
+# settings of a staging box
+HTTP_SERVER_ADDRESS=:9090
+CORS_ALLOWED_ORIGINS = https://app.example.com, https://admin.example.com
+export DB_SOURCE="postgres://file@db:5432/bankapi"
+REFERRAL_WELCOME_BONUS=500
+REFERRAL_CUTOFF_DAY=15
+ACCESS_TOKEN_DURATION=5m
+MAIL_FROM='Bank <bank@example.com>'
+`)
+
+	config, err := load(envOf(map[string]string{
+		"DB_SOURCE_PROD":         "postgres://env@db:5432/bankapi",
+		"REFERRAL_WELCOME_BONUS": "750",
+	}), path)
+	require.NoError(t, err)
+
+	require.Equal(t, ":9090", config.HTTPServerAddress)
+	require.Equal(t, []string{"https://app.example.com", "https://admin.example.com"}, config.CORSAllowedOrigins)
+	// DB_SOURCE_PROD is read before DB_SOURCE, and the environment wins over the file
+	require.Equal(t, "postgres://env@db:5432/bankapi", config.DBSource)
+	require.Equal(t, int64(750), config.Referral.WelcomeBonus)
+	require.Equal(t, 15, config.Referral.CutoffDay)
+	require.Equal(t, 5*time.Minute, config.AccessTokenDuration)
+	require.Equal(t, "Bank <bank@example.com>", config.Mail.From)
+	// the rest keeps the defaults
+	require.Equal(t, 10.0, config.Referral.MaxExtraInterest)
+}
+
+func TestLoadProduction(t *testing.T) {
+	_, err := load(envOf(map[string]string{EnvironmentVar: EnvProduction}), "")
+	require.Error(t, err)
+	require.ErrorContains(t, err, "DB_SOURCE is required")
+	require.ErrorContains(t, err, "TOKEN_SYMMETRIC_KEY")
+	require.ErrorContains(t, err, "CORS_ALLOWED_ORIGINS is required")
+
+	config, err := load(envOf(map[string]string{
+		EnvironmentVar:         EnvProduction,
+		"DB_SOURCE":            "postgres://prod@db:5432/bankapi",
+		"TOKEN_SYMMETRIC_KEY":  "0123456789abcdef0123456789abcdef",
+		"CORS_ALLOWED_ORIGINS": "https://bank.example.com",
+	}), "")
+	require.NoError(t, err)
+	require.Equal(t, EnvProduction, config.Environment)
+	require.Empty(t, config.TestDBSource)
+	require.False(t, config.Webhook.AllowPrivateURLs)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	testCases := map[string]struct {
+		vars map[string]string
+		err  string
+	}{
+		"UnknownEnvironment": {map[string]string{EnvironmentVar: "staging"}, `APP_ENV "staging"`},
+		"NotANumber":         {map[string]string{"SMTP_PORT": "smtp"}, `SMTP_PORT "smtp"`},
+		"PortOutOfRange":     {map[string]string{"SMTP_PORT": "70000"}, "SMTP_PORT 70000 is out of range"},
+		"BadDuration":        {map[string]string{"ACCESS_TOKEN_DURATION": "15"}, `ACCESS_TOKEN_DURATION "15"`},
+		"ShortKey":           {map[string]string{"TOKEN_SYMMETRIC_KEY": "short"}, "TOKEN_SYMMETRIC_KEY"},
+		"BadOrigin":          {map[string]string{"CORS_ALLOWED_ORIGINS": "example.com"}, `origin "example.com"`},
+		"NegativeBonus":      {map[string]string{"REFERRAL_WELCOME_BONUS": "-1"}, "REFERRAL_WELCOME_BONUS"},
+		"CapBelowStep":       {map[string]string{"REFERRAL_MAX_EXTRA_INTEREST": "0.5"}, "REFERRAL_MAX_EXTRA_INTEREST"},
+		"CutoffDay":          {map[string]string{"REFERRAL_CUTOFF_DAY": "31"}, "REFERRAL_CUTOFF_DAY"},
+		"IdleAboveOpen":      {map[string]string{"DB_MAX_OPEN_CONNS": "5", "DB_MAX_IDLE_CONNS": "10"}, "DB_MAX_IDLE_CONNS"},
+		"NoShutdownTimeout":  {map[string]string{"HTTP_SHUTDOWN_TIMEOUT": "0s"}, "HTTP_SHUTDOWN_TIMEOUT"},
+		"ShortMetricsToken":  {map[string]string{"METRICS_TOKEN": "secret"}, "METRICS_TOKEN"},
+		"IdempotencyLockBelowWriteTimeout": {map[string]string{
+			"IDEMPOTENCY_LOCK_TIMEOUT": "1m", "HTTP_WRITE_TIMEOUT": "2m"}, "IDEMPOTENCY_LOCK_TIMEOUT"},
+		"DevelopmentKeyInProduction": {map[string]string{
+			EnvironmentVar: EnvProduction, "TOKEN_SYMMETRIC_KEY": developmentTokenKey}, "must not be the development key"},
+		"PrivateWebhooksInProduction": {map[string]string{
+			EnvironmentVar: EnvProduction, "WEBHOOK_ALLOW_PRIVATE_URLS": "true"}, "WEBHOOK_ALLOW_PRIVATE_URLS"},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := load(envOf(tc.vars), "")
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, err := load(envOf(nil), filepath.Join(t.TempDir(), "missing.env"))
+	require.ErrorContains(t, err, "config file")
+
+	_, err = load(envOf(nil), writeFile(t, "NOT A SETTING\n"))
+	require.ErrorContains(t, err, "want KEY=VALUE")
+}
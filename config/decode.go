@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// decode sets every field with an env tag whose variable lookup finds, recursing into nested
+// structs. Fields whose variables are all unset keep their value.
+func decode(config *Config, lookup func(string) (string, bool)) error {
+	return decodeStruct(reflect.ValueOf(config).Elem(), lookup)
+}
+
+func decodeStruct(value reflect.Value, lookup func(string) (string, bool)) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag := field.Tag.Get("env")
+		if tag == "" {
+			if field.Type.Kind() == reflect.Struct {
+				if err := decodeStruct(value.Field(i), lookup); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		for _, name := range strings.Split(tag, ",") {
+			raw, ok := lookup(name)
+			if !ok {
+				continue
+			}
+			if err := set(value.Field(i), strings.TrimSpace(raw)); err != nil {
+				return fmt.Errorf("%s %q: %w", name, raw, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func set(field reflect.Value, raw string) error {
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// readFile reads a file of KEY=VALUE lines, as docker's env_file: blank lines and lines
+// starting with # are skipped, and a value may be quoted
+func readFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+	defer file.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(text, "=")
+		if !found {
+			return nil, fmt.Errorf("config file %s:%d: want KEY=VALUE", path, line)
+		}
+		key = strings.TrimSpace(strings.TrimPrefix(key, "export "))
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+	return values, nil
+}
@@ -0,0 +1,1826 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: bank-api/db/sqlc (interfaces: Store)
+//
+// Generated by this command:
+//
+//	mockgen -package mockdb -destination db/mock/store.go bank-api/db/sqlc Store
+//
+
+// Package mockdb is a generated GoMock package.
+package mockdb
+
+import (
+	sqlc "bank-api/db/sqlc"
+	context "context"
+	sql "database/sql"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreMockRecorder
+}
+
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
+	return m.recorder
+}
+
+// AddAccountBalance mocks base method.
+func (m *MockStore) AddAccountBalance(arg0 context.Context, arg1 sqlc.AddAccountBalanceParams) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAccountBalance", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAccountBalance indicates an expected call of AddAccountBalance.
+func (mr *MockStoreMockRecorder) AddAccountBalance(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockStore)(nil).AddAccountBalance), arg0, arg1)
+}
+
+// AdjustBalanceTx mocks base method.
+func (m *MockStore) AdjustBalanceTx(arg0 context.Context, arg1 sqlc.AdjustBalanceTxParams) (sqlc.AdjustBalanceTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalanceTx", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.AdjustBalanceTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustBalanceTx indicates an expected call of AdjustBalanceTx.
+func (mr *MockStoreMockRecorder) AdjustBalanceTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalanceTx", reflect.TypeOf((*MockStore)(nil).AdjustBalanceTx), arg0, arg1)
+}
+
+// BlockCustomerSessions mocks base method.
+func (m *MockStore) BlockCustomerSessions(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockCustomerSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockCustomerSessions indicates an expected call of BlockCustomerSessions.
+func (mr *MockStoreMockRecorder) BlockCustomerSessions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockCustomerSessions", reflect.TypeOf((*MockStore)(nil).BlockCustomerSessions), arg0, arg1)
+}
+
+// BlockSession mocks base method.
+func (m *MockStore) BlockSession(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockSession indicates an expected call of BlockSession.
+func (mr *MockStoreMockRecorder) BlockSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSession", reflect.TypeOf((*MockStore)(nil).BlockSession), arg0, arg1)
+}
+
+// ClaimEmailDeliveries mocks base method.
+func (m *MockStore) ClaimEmailDeliveries(arg0 context.Context, arg1 sqlc.ClaimEmailDeliveriesParams) ([]sqlc.EmailDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimEmailDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.EmailDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimEmailDeliveries indicates an expected call of ClaimEmailDeliveries.
+func (mr *MockStoreMockRecorder) ClaimEmailDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimEmailDeliveries", reflect.TypeOf((*MockStore)(nil).ClaimEmailDeliveries), arg0, arg1)
+}
+
+// ClaimIdempotencyKey mocks base method.
+func (m *MockStore) ClaimIdempotencyKey(arg0 context.Context, arg1 sqlc.ClaimIdempotencyKeyParams) (sqlc.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimIdempotencyKey indicates an expected call of ClaimIdempotencyKey.
+func (mr *MockStoreMockRecorder) ClaimIdempotencyKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimIdempotencyKey", reflect.TypeOf((*MockStore)(nil).ClaimIdempotencyKey), arg0, arg1)
+}
+
+// ClaimJobRun mocks base method.
+func (m *MockStore) ClaimJobRun(arg0 context.Context, arg1 sqlc.ClaimJobRunParams) (sqlc.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimJobRun", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimJobRun indicates an expected call of ClaimJobRun.
+func (mr *MockStoreMockRecorder) ClaimJobRun(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimJobRun", reflect.TypeOf((*MockStore)(nil).ClaimJobRun), arg0, arg1)
+}
+
+// ClaimOutboxEvents mocks base method.
+func (m *MockStore) ClaimOutboxEvents(arg0 context.Context, arg1 sqlc.ClaimOutboxEventsParams) ([]sqlc.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimOutboxEvents", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimOutboxEvents indicates an expected call of ClaimOutboxEvents.
+func (mr *MockStoreMockRecorder) ClaimOutboxEvents(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxEvents", reflect.TypeOf((*MockStore)(nil).ClaimOutboxEvents), arg0, arg1)
+}
+
+// ClaimWebhookDeliveries mocks base method.
+func (m *MockStore) ClaimWebhookDeliveries(arg0 context.Context, arg1 sqlc.ClaimWebhookDeliveriesParams) ([]sqlc.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDeliveries indicates an expected call of ClaimWebhookDeliveries.
+func (mr *MockStoreMockRecorder) ClaimWebhookDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).ClaimWebhookDeliveries), arg0, arg1)
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockStore) CompleteIdempotencyKey(arg0 context.Context, arg1 sqlc.CompleteIdempotencyKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockStoreMockRecorder) CompleteIdempotencyKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockStore)(nil).CompleteIdempotencyKey), arg0, arg1)
+}
+
+// CreateAccount mocks base method.
+func (m *MockStore) CreateAccount(arg0 context.Context, arg1 sqlc.CreateAccountParams) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
+}
+
+// CreateAccountInterestChange mocks base method.
+func (m *MockStore) CreateAccountInterestChange(arg0 context.Context, arg1 sqlc.CreateAccountInterestChangeParams) (sqlc.AccountInterestChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountInterestChange", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.AccountInterestChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountInterestChange indicates an expected call of CreateAccountInterestChange.
+func (mr *MockStoreMockRecorder) CreateAccountInterestChange(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountInterestChange", reflect.TypeOf((*MockStore)(nil).CreateAccountInterestChange), arg0, arg1)
+}
+
+// CreateAccountTx mocks base method.
+func (m *MockStore) CreateAccountTx(arg0 context.Context, arg1 sqlc.CreateAccountTxParams) (sqlc.CreateAccountTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountTx", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.CreateAccountTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountTx indicates an expected call of CreateAccountTx.
+func (mr *MockStoreMockRecorder) CreateAccountTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountTx", reflect.TypeOf((*MockStore)(nil).CreateAccountTx), arg0, arg1)
+}
+
+// CreateAuditEntry mocks base method.
+func (m *MockStore) CreateAuditEntry(arg0 context.Context, arg1 sqlc.CreateAuditEntryParams) (sqlc.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEntry", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditEntry indicates an expected call of CreateAuditEntry.
+func (mr *MockStoreMockRecorder) CreateAuditEntry(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEntry", reflect.TypeOf((*MockStore)(nil).CreateAuditEntry), arg0, arg1)
+}
+
+// CreateCustomer mocks base method.
+func (m *MockStore) CreateCustomer(arg0 context.Context, arg1 sqlc.CreateCustomerParams) (sqlc.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCustomer", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCustomer indicates an expected call of CreateCustomer.
+func (mr *MockStoreMockRecorder) CreateCustomer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCustomer", reflect.TypeOf((*MockStore)(nil).CreateCustomer), arg0, arg1)
+}
+
+// CreateCustomerCredential mocks base method.
+func (m *MockStore) CreateCustomerCredential(arg0 context.Context, arg1 sqlc.CreateCustomerCredentialParams) (sqlc.CustomerCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCustomerCredential", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.CustomerCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCustomerCredential indicates an expected call of CreateCustomerCredential.
+func (mr *MockStoreMockRecorder) CreateCustomerCredential(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCustomerCredential", reflect.TypeOf((*MockStore)(nil).CreateCustomerCredential), arg0, arg1)
+}
+
+// CreateEmailDelivery mocks base method.
+func (m *MockStore) CreateEmailDelivery(arg0 context.Context, arg1 sqlc.CreateEmailDeliveryParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEmailDelivery indicates an expected call of CreateEmailDelivery.
+func (mr *MockStoreMockRecorder) CreateEmailDelivery(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailDelivery", reflect.TypeOf((*MockStore)(nil).CreateEmailDelivery), arg0, arg1)
+}
+
+// CreateEntry mocks base method.
+func (m *MockStore) CreateEntry(arg0 context.Context, arg1 sqlc.CreateEntryParams) (sqlc.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEntry", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEntry indicates an expected call of CreateEntry.
+func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
+}
+
+// CreateFxQuote mocks base method.
+func (m *MockStore) CreateFxQuote(arg0 context.Context, arg1 sqlc.CreateFxQuoteParams) (sqlc.FxQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFxQuote", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.FxQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFxQuote indicates an expected call of CreateFxQuote.
+func (mr *MockStoreMockRecorder) CreateFxQuote(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFxQuote", reflect.TypeOf((*MockStore)(nil).CreateFxQuote), arg0, arg1)
+}
+
+// CreateFxRate mocks base method.
+func (m *MockStore) CreateFxRate(arg0 context.Context, arg1 sqlc.CreateFxRateParams) (sqlc.FxRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFxRate", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.FxRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFxRate indicates an expected call of CreateFxRate.
+func (mr *MockStoreMockRecorder) CreateFxRate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFxRate", reflect.TypeOf((*MockStore)(nil).CreateFxRate), arg0, arg1)
+}
+
+// CreateInterestAccrual mocks base method.
+func (m *MockStore) CreateInterestAccrual(arg0 context.Context, arg1 sqlc.CreateInterestAccrualParams) (sqlc.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInterestAccrual", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInterestAccrual indicates an expected call of CreateInterestAccrual.
+func (mr *MockStoreMockRecorder) CreateInterestAccrual(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInterestAccrual", reflect.TypeOf((*MockStore)(nil).CreateInterestAccrual), arg0, arg1)
+}
+
+// CreateInterestPayout mocks base method.
+func (m *MockStore) CreateInterestPayout(arg0 context.Context, arg1 sqlc.CreateInterestPayoutParams) (sqlc.InterestPayout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInterestPayout", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.InterestPayout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInterestPayout indicates an expected call of CreateInterestPayout.
+func (mr *MockStoreMockRecorder) CreateInterestPayout(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInterestPayout", reflect.TypeOf((*MockStore)(nil).CreateInterestPayout), arg0, arg1)
+}
+
+// CreateJournal mocks base method.
+func (m *MockStore) CreateJournal(arg0 context.Context, arg1 string) (sqlc.Journal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJournal", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Journal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJournal indicates an expected call of CreateJournal.
+func (mr *MockStoreMockRecorder) CreateJournal(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJournal", reflect.TypeOf((*MockStore)(nil).CreateJournal), arg0, arg1)
+}
+
+// CreateOutboxEvent mocks base method.
+func (m *MockStore) CreateOutboxEvent(arg0 context.Context, arg1 sqlc.CreateOutboxEventParams) (sqlc.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOutboxEvent", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOutboxEvent indicates an expected call of CreateOutboxEvent.
+func (mr *MockStoreMockRecorder) CreateOutboxEvent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxEvent", reflect.TypeOf((*MockStore)(nil).CreateOutboxEvent), arg0, arg1)
+}
+
+// CreatePasswordReset mocks base method.
+func (m *MockStore) CreatePasswordReset(arg0 context.Context, arg1 sqlc.CreatePasswordResetParams) (sqlc.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordReset indicates an expected call of CreatePasswordReset.
+func (mr *MockStoreMockRecorder) CreatePasswordReset(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordReset", reflect.TypeOf((*MockStore)(nil).CreatePasswordReset), arg0, arg1)
+}
+
+// CreateReferralCode mocks base method.
+func (m *MockStore) CreateReferralCode(arg0 context.Context, arg1 sqlc.CreateReferralCodeParams) (sqlc.ReferralCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReferralCode", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.ReferralCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReferralCode indicates an expected call of CreateReferralCode.
+func (mr *MockStoreMockRecorder) CreateReferralCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReferralCode", reflect.TypeOf((*MockStore)(nil).CreateReferralCode), arg0, arg1)
+}
+
+// CreateReferralHistory mocks base method.
+func (m *MockStore) CreateReferralHistory(arg0 context.Context, arg1 sqlc.CreateReferralHistoryParams) (sqlc.ReferralHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReferralHistory", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.ReferralHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReferralHistory indicates an expected call of CreateReferralHistory.
+func (mr *MockStoreMockRecorder) CreateReferralHistory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReferralHistory", reflect.TypeOf((*MockStore)(nil).CreateReferralHistory), arg0, arg1)
+}
+
+// CreateSession mocks base method.
+func (m *MockStore) CreateSession(arg0 context.Context, arg1 sqlc.CreateSessionParams) (sqlc.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 sqlc.CreateTransferParams) (sqlc.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockStoreMockRecorder) CreateTransfer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), arg0, arg1)
+}
+
+// CreateWebhookDelivery mocks base method.
+func (m *MockStore) CreateWebhookDelivery(arg0 context.Context, arg1 sqlc.CreateWebhookDeliveryParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookDelivery indicates an expected call of CreateWebhookDelivery.
+func (mr *MockStoreMockRecorder) CreateWebhookDelivery(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDelivery", reflect.TypeOf((*MockStore)(nil).CreateWebhookDelivery), arg0, arg1)
+}
+
+// CreateWebhookSubscription mocks base method.
+func (m *MockStore) CreateWebhookSubscription(arg0 context.Context, arg1 sqlc.CreateWebhookSubscriptionParams) (sqlc.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookSubscription", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookSubscription indicates an expected call of CreateWebhookSubscription.
+func (mr *MockStoreMockRecorder) CreateWebhookSubscription(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookSubscription", reflect.TypeOf((*MockStore)(nil).CreateWebhookSubscription), arg0, arg1)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+}
+
+// DeleteIdempotencyKey mocks base method.
+func (m *MockStore) DeleteIdempotencyKey(arg0 context.Context, arg1 sqlc.DeleteIdempotencyKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockStoreMockRecorder) DeleteIdempotencyKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockStore)(nil).DeleteIdempotencyKey), arg0, arg1)
+}
+
+// DeleteIdempotencyKeysBefore mocks base method.
+func (m *MockStore) DeleteIdempotencyKeysBefore(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKeysBefore", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteIdempotencyKeysBefore indicates an expected call of DeleteIdempotencyKeysBefore.
+func (mr *MockStoreMockRecorder) DeleteIdempotencyKeysBefore(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKeysBefore", reflect.TypeOf((*MockStore)(nil).DeleteIdempotencyKeysBefore), arg0, arg1)
+}
+
+// DeleteWebhookSubscription mocks base method.
+func (m *MockStore) DeleteWebhookSubscription(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookSubscription", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhookSubscription indicates an expected call of DeleteWebhookSubscription.
+func (mr *MockStoreMockRecorder) DeleteWebhookSubscription(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookSubscription", reflect.TypeOf((*MockStore)(nil).DeleteWebhookSubscription), arg0, arg1)
+}
+
+// ExpireExtraInterestTx mocks base method.
+func (m *MockStore) ExpireExtraInterestTx(arg0 context.Context, arg1 sqlc.ExpireExtraInterestTxParams) (sqlc.ExpireExtraInterestTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireExtraInterestTx", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.ExpireExtraInterestTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireExtraInterestTx indicates an expected call of ExpireExtraInterestTx.
+func (mr *MockStoreMockRecorder) ExpireExtraInterestTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireExtraInterestTx", reflect.TypeOf((*MockStore)(nil).ExpireExtraInterestTx), arg0, arg1)
+}
+
+// FinishJobRun mocks base method.
+func (m *MockStore) FinishJobRun(arg0 context.Context, arg1 sqlc.FinishJobRunParams) (sqlc.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishJobRun", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishJobRun indicates an expected call of FinishJobRun.
+func (mr *MockStoreMockRecorder) FinishJobRun(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishJobRun", reflect.TypeOf((*MockStore)(nil).FinishJobRun), arg0, arg1)
+}
+
+// GetAccount mocks base method.
+func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+}
+
+// GetAccountBalanceAt mocks base method.
+func (m *MockStore) GetAccountBalanceAt(arg0 context.Context, arg1 sqlc.GetAccountBalanceAtParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountBalanceAt", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountBalanceAt indicates an expected call of GetAccountBalanceAt.
+func (mr *MockStoreMockRecorder) GetAccountBalanceAt(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountBalanceAt", reflect.TypeOf((*MockStore)(nil).GetAccountBalanceAt), arg0, arg1)
+}
+
+// GetAccountForUpdate mocks base method.
+func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
+func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+}
+
+// GetAccountInterestChangeSince mocks base method.
+func (m *MockStore) GetAccountInterestChangeSince(arg0 context.Context, arg1 sqlc.GetAccountInterestChangeSinceParams) (sqlc.AccountInterestChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountInterestChangeSince", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.AccountInterestChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountInterestChangeSince indicates an expected call of GetAccountInterestChangeSince.
+func (mr *MockStoreMockRecorder) GetAccountInterestChangeSince(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountInterestChangeSince", reflect.TypeOf((*MockStore)(nil).GetAccountInterestChangeSince), arg0, arg1)
+}
+
+// GetCustomer mocks base method.
+func (m *MockStore) GetCustomer(arg0 context.Context, arg1 int64) (sqlc.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomer", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomer indicates an expected call of GetCustomer.
+func (mr *MockStoreMockRecorder) GetCustomer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomer", reflect.TypeOf((*MockStore)(nil).GetCustomer), arg0, arg1)
+}
+
+// GetCustomerByEmail mocks base method.
+func (m *MockStore) GetCustomerByEmail(arg0 context.Context, arg1 string) (sqlc.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomerByEmail", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomerByEmail indicates an expected call of GetCustomerByEmail.
+func (mr *MockStoreMockRecorder) GetCustomerByEmail(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerByEmail", reflect.TypeOf((*MockStore)(nil).GetCustomerByEmail), arg0, arg1)
+}
+
+// GetCustomerCredential mocks base method.
+func (m *MockStore) GetCustomerCredential(arg0 context.Context, arg1 int64) (sqlc.CustomerCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomerCredential", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.CustomerCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomerCredential indicates an expected call of GetCustomerCredential.
+func (mr *MockStoreMockRecorder) GetCustomerCredential(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerCredential", reflect.TypeOf((*MockStore)(nil).GetCustomerCredential), arg0, arg1)
+}
+
+// GetCustomerForUpdate mocks base method.
+func (m *MockStore) GetCustomerForUpdate(arg0 context.Context, arg1 int64) (sqlc.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomerForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomerForUpdate indicates an expected call of GetCustomerForUpdate.
+func (mr *MockStoreMockRecorder) GetCustomerForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomerForUpdate", reflect.TypeOf((*MockStore)(nil).GetCustomerForUpdate), arg0, arg1)
+}
+
+// GetEntry mocks base method.
+func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (sqlc.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+}
+
+// GetFxQuoteForUpdate mocks base method.
+func (m *MockStore) GetFxQuoteForUpdate(arg0 context.Context, arg1 uuid.UUID) (sqlc.FxQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFxQuoteForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.FxQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFxQuoteForUpdate indicates an expected call of GetFxQuoteForUpdate.
+func (mr *MockStoreMockRecorder) GetFxQuoteForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFxQuoteForUpdate", reflect.TypeOf((*MockStore)(nil).GetFxQuoteForUpdate), arg0, arg1)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockStore) GetIdempotencyKey(arg0 context.Context, arg1 sqlc.GetIdempotencyKeyParams) (sqlc.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockStoreMockRecorder) GetIdempotencyKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockStore)(nil).GetIdempotencyKey), arg0, arg1)
+}
+
+// GetInterestPayout mocks base method.
+func (m *MockStore) GetInterestPayout(arg0 context.Context, arg1 sqlc.GetInterestPayoutParams) (sqlc.InterestPayout, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInterestPayout", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.InterestPayout)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInterestPayout indicates an expected call of GetInterestPayout.
+func (mr *MockStoreMockRecorder) GetInterestPayout(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInterestPayout", reflect.TypeOf((*MockStore)(nil).GetInterestPayout), arg0, arg1)
+}
+
+// GetJobRun mocks base method.
+func (m *MockStore) GetJobRun(arg0 context.Context, arg1 sqlc.GetJobRunParams) (sqlc.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobRun", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobRun indicates an expected call of GetJobRun.
+func (mr *MockStoreMockRecorder) GetJobRun(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobRun", reflect.TypeOf((*MockStore)(nil).GetJobRun), arg0, arg1)
+}
+
+// GetJournal mocks base method.
+func (m *MockStore) GetJournal(arg0 context.Context, arg1 int64) (sqlc.Journal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJournal", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Journal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJournal indicates an expected call of GetJournal.
+func (mr *MockStoreMockRecorder) GetJournal(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJournal", reflect.TypeOf((*MockStore)(nil).GetJournal), arg0, arg1)
+}
+
+// GetLatestFxRate mocks base method.
+func (m *MockStore) GetLatestFxRate(arg0 context.Context, arg1 sqlc.GetLatestFxRateParams) (sqlc.FxRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestFxRate", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.FxRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestFxRate indicates an expected call of GetLatestFxRate.
+func (mr *MockStoreMockRecorder) GetLatestFxRate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestFxRate", reflect.TypeOf((*MockStore)(nil).GetLatestFxRate), arg0, arg1)
+}
+
+// GetPasswordResetByTokenForUpdate mocks base method.
+func (m *MockStore) GetPasswordResetByTokenForUpdate(arg0 context.Context, arg1 sql.NullString) (sqlc.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetByTokenForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetByTokenForUpdate indicates an expected call of GetPasswordResetByTokenForUpdate.
+func (mr *MockStoreMockRecorder) GetPasswordResetByTokenForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetByTokenForUpdate", reflect.TypeOf((*MockStore)(nil).GetPasswordResetByTokenForUpdate), arg0, arg1)
+}
+
+// GetReferralCode mocks base method.
+func (m *MockStore) GetReferralCode(arg0 context.Context, arg1 string) (sqlc.ReferralCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferralCode", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.ReferralCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferralCode indicates an expected call of GetReferralCode.
+func (mr *MockStoreMockRecorder) GetReferralCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferralCode", reflect.TypeOf((*MockStore)(nil).GetReferralCode), arg0, arg1)
+}
+
+// GetReferralCodeForUpdate mocks base method.
+func (m *MockStore) GetReferralCodeForUpdate(arg0 context.Context, arg1 string) (sqlc.ReferralCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferralCodeForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.ReferralCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferralCodeForUpdate indicates an expected call of GetReferralCodeForUpdate.
+func (mr *MockStoreMockRecorder) GetReferralCodeForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferralCodeForUpdate", reflect.TypeOf((*MockStore)(nil).GetReferralCodeForUpdate), arg0, arg1)
+}
+
+// GetReferralCodesForReferrerAccount mocks base method.
+func (m *MockStore) GetReferralCodesForReferrerAccount(arg0 context.Context, arg1 int64) ([]sqlc.ReferralCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferralCodesForReferrerAccount", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.ReferralCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferralCodesForReferrerAccount indicates an expected call of GetReferralCodesForReferrerAccount.
+func (mr *MockStoreMockRecorder) GetReferralCodesForReferrerAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferralCodesForReferrerAccount", reflect.TypeOf((*MockStore)(nil).GetReferralCodesForReferrerAccount), arg0, arg1)
+}
+
+// GetReferralHistory mocks base method.
+func (m *MockStore) GetReferralHistory(arg0 context.Context, arg1 int64) ([]sqlc.ReferralHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferralHistory", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.ReferralHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferralHistory indicates an expected call of GetReferralHistory.
+func (mr *MockStoreMockRecorder) GetReferralHistory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferralHistory", reflect.TypeOf((*MockStore)(nil).GetReferralHistory), arg0, arg1)
+}
+
+// GetReferralHistoryByDate mocks base method.
+func (m *MockStore) GetReferralHistoryByDate(arg0 context.Context, arg1 sqlc.GetReferralHistoryByDateParams) ([]sqlc.ReferralHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferralHistoryByDate", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.ReferralHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferralHistoryByDate indicates an expected call of GetReferralHistoryByDate.
+func (mr *MockStoreMockRecorder) GetReferralHistoryByDate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferralHistoryByDate", reflect.TypeOf((*MockStore)(nil).GetReferralHistoryByDate), arg0, arg1)
+}
+
+// GetReferralsByDateRange mocks base method.
+func (m *MockStore) GetReferralsByDateRange(arg0 context.Context, arg1 sqlc.GetReferralsByDateRangeParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferralsByDateRange", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferralsByDateRange indicates an expected call of GetReferralsByDateRange.
+func (mr *MockStoreMockRecorder) GetReferralsByDateRange(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferralsByDateRange", reflect.TypeOf((*MockStore)(nil).GetReferralsByDateRange), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (sqlc.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
+// GetSystemAccountForUpdate mocks base method.
+func (m *MockStore) GetSystemAccountForUpdate(arg0 context.Context, arg1 sqlc.GetSystemAccountForUpdateParams) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSystemAccountForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSystemAccountForUpdate indicates an expected call of GetSystemAccountForUpdate.
+func (mr *MockStoreMockRecorder) GetSystemAccountForUpdate(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetSystemAccountForUpdate), arg0, arg1)
+}
+
+// GetTransfer mocks base method.
+func (m *MockStore) GetTransfer(arg0 context.Context, arg1 int64) (sqlc.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfer", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfer indicates an expected call of GetTransfer.
+func (mr *MockStoreMockRecorder) GetTransfer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), arg0, arg1)
+}
+
+// GetUnusedReferralCodes mocks base method.
+func (m *MockStore) GetUnusedReferralCodes(arg0 context.Context, arg1 sqlc.GetUnusedReferralCodesParams) ([]sqlc.ReferralCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnusedReferralCodes", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.ReferralCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnusedReferralCodes indicates an expected call of GetUnusedReferralCodes.
+func (mr *MockStoreMockRecorder) GetUnusedReferralCodes(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnusedReferralCodes", reflect.TypeOf((*MockStore)(nil).GetUnusedReferralCodes), arg0, arg1)
+}
+
+// GetWebhookSubscription mocks base method.
+func (m *MockStore) GetWebhookSubscription(arg0 context.Context, arg1 int64) (sqlc.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscription", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscription indicates an expected call of GetWebhookSubscription.
+func (mr *MockStoreMockRecorder) GetWebhookSubscription(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscription", reflect.TypeOf((*MockStore)(nil).GetWebhookSubscription), arg0, arg1)
+}
+
+// HasBeenReferred mocks base method.
+func (m *MockStore) HasBeenReferred(arg0 context.Context, arg1 sqlc.HasBeenReferredParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasBeenReferred", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasBeenReferred indicates an expected call of HasBeenReferred.
+func (mr *MockStoreMockRecorder) HasBeenReferred(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasBeenReferred", reflect.TypeOf((*MockStore)(nil).HasBeenReferred), arg0, arg1)
+}
+
+// HasInterestAccrualSince mocks base method.
+func (m *MockStore) HasInterestAccrualSince(arg0 context.Context, arg1 sqlc.HasInterestAccrualSinceParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasInterestAccrualSince", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasInterestAccrualSince indicates an expected call of HasInterestAccrualSince.
+func (mr *MockStoreMockRecorder) HasInterestAccrualSince(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasInterestAccrualSince", reflect.TypeOf((*MockStore)(nil).HasInterestAccrualSince), arg0, arg1)
+}
+
+// HasUnUsedCodeForReferrerAccount mocks base method.
+func (m *MockStore) HasUnUsedCodeForReferrerAccount(arg0 context.Context, arg1 int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasUnUsedCodeForReferrerAccount", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasUnUsedCodeForReferrerAccount indicates an expected call of HasUnUsedCodeForReferrerAccount.
+func (mr *MockStoreMockRecorder) HasUnUsedCodeForReferrerAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasUnUsedCodeForReferrerAccount", reflect.TypeOf((*MockStore)(nil).HasUnUsedCodeForReferrerAccount), arg0, arg1)
+}
+
+// ListAccountEntries mocks base method.
+func (m *MockStore) ListAccountEntries(arg0 context.Context, arg1 sqlc.ListAccountEntriesParams) ([]sqlc.ListAccountEntriesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountEntries", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.ListAccountEntriesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountEntries indicates an expected call of ListAccountEntries.
+func (mr *MockStoreMockRecorder) ListAccountEntries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountEntries", reflect.TypeOf((*MockStore)(nil).ListAccountEntries), arg0, arg1)
+}
+
+// ListAccountEntrySums mocks base method.
+func (m *MockStore) ListAccountEntrySums(arg0 context.Context, arg1 sqlc.ListAccountEntrySumsParams) ([]sqlc.ListAccountEntrySumsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountEntrySums", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.ListAccountEntrySumsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountEntrySums indicates an expected call of ListAccountEntrySums.
+func (mr *MockStoreMockRecorder) ListAccountEntrySums(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountEntrySums", reflect.TypeOf((*MockStore)(nil).ListAccountEntrySums), arg0, arg1)
+}
+
+// ListAccounts mocks base method.
+func (m *MockStore) ListAccounts(arg0 context.Context, arg1 sqlc.ListAccountsParams) ([]sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+}
+
+// ListAccountsForInterest mocks base method.
+func (m *MockStore) ListAccountsForInterest(arg0 context.Context, arg1 sqlc.ListAccountsForInterestParams) ([]sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountsForInterest", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountsForInterest indicates an expected call of ListAccountsForInterest.
+func (mr *MockStoreMockRecorder) ListAccountsForInterest(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountsForInterest", reflect.TypeOf((*MockStore)(nil).ListAccountsForInterest), arg0, arg1)
+}
+
+// ListAccountsWithExpiredExtraInterest mocks base method.
+func (m *MockStore) ListAccountsWithExpiredExtraInterest(arg0 context.Context, arg1 sqlc.ListAccountsWithExpiredExtraInterestParams) ([]sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountsWithExpiredExtraInterest", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountsWithExpiredExtraInterest indicates an expected call of ListAccountsWithExpiredExtraInterest.
+func (mr *MockStoreMockRecorder) ListAccountsWithExpiredExtraInterest(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountsWithExpiredExtraInterest", reflect.TypeOf((*MockStore)(nil).ListAccountsWithExpiredExtraInterest), arg0, arg1)
+}
+
+// ListAccountsWithUnpaidInterestAccruals mocks base method.
+func (m *MockStore) ListAccountsWithUnpaidInterestAccruals(arg0 context.Context, arg1 time.Time) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountsWithUnpaidInterestAccruals", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountsWithUnpaidInterestAccruals indicates an expected call of ListAccountsWithUnpaidInterestAccruals.
+func (mr *MockStoreMockRecorder) ListAccountsWithUnpaidInterestAccruals(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountsWithUnpaidInterestAccruals", reflect.TypeOf((*MockStore)(nil).ListAccountsWithUnpaidInterestAccruals), arg0, arg1)
+}
+
+// ListAuditEntries mocks base method.
+func (m *MockStore) ListAuditEntries(arg0 context.Context, arg1 sqlc.ListAuditEntriesParams) ([]sqlc.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEntries", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEntries indicates an expected call of ListAuditEntries.
+func (mr *MockStoreMockRecorder) ListAuditEntries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEntries", reflect.TypeOf((*MockStore)(nil).ListAuditEntries), arg0, arg1)
+}
+
+// ListCustomerAccounts mocks base method.
+func (m *MockStore) ListCustomerAccounts(arg0 context.Context, arg1 sql.NullInt64) ([]sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCustomerAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCustomerAccounts indicates an expected call of ListCustomerAccounts.
+func (mr *MockStoreMockRecorder) ListCustomerAccounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCustomerAccounts", reflect.TypeOf((*MockStore)(nil).ListCustomerAccounts), arg0, arg1)
+}
+
+// ListEmailDeliveries mocks base method.
+func (m *MockStore) ListEmailDeliveries(arg0 context.Context, arg1 sqlc.ListEmailDeliveriesParams) ([]sqlc.EmailDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEmailDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.EmailDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEmailDeliveries indicates an expected call of ListEmailDeliveries.
+func (mr *MockStoreMockRecorder) ListEmailDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEmailDeliveries", reflect.TypeOf((*MockStore)(nil).ListEmailDeliveries), arg0, arg1)
+}
+
+// ListEntries mocks base method.
+func (m *MockStore) ListEntries(arg0 context.Context, arg1 sqlc.ListEntriesParams) ([]sqlc.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries.
+func (mr *MockStoreMockRecorder) ListEntries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0, arg1)
+}
+
+// ListInterestAccruals mocks base method.
+func (m *MockStore) ListInterestAccruals(arg0 context.Context, arg1 sqlc.ListInterestAccrualsParams) ([]sqlc.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInterestAccruals", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInterestAccruals indicates an expected call of ListInterestAccruals.
+func (mr *MockStoreMockRecorder) ListInterestAccruals(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInterestAccruals", reflect.TypeOf((*MockStore)(nil).ListInterestAccruals), arg0, arg1)
+}
+
+// ListJobRuns mocks base method.
+func (m *MockStore) ListJobRuns(arg0 context.Context, arg1 sqlc.ListJobRunsParams) ([]sqlc.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJobRuns", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJobRuns indicates an expected call of ListJobRuns.
+func (mr *MockStoreMockRecorder) ListJobRuns(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRuns", reflect.TypeOf((*MockStore)(nil).ListJobRuns), arg0, arg1)
+}
+
+// ListJournalEntries mocks base method.
+func (m *MockStore) ListJournalEntries(arg0 context.Context, arg1 sql.NullInt64) ([]sqlc.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJournalEntries", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJournalEntries indicates an expected call of ListJournalEntries.
+func (mr *MockStoreMockRecorder) ListJournalEntries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJournalEntries", reflect.TypeOf((*MockStore)(nil).ListJournalEntries), arg0, arg1)
+}
+
+// ListJournalEntrySums mocks base method.
+func (m *MockStore) ListJournalEntrySums(arg0 context.Context, arg1 sqlc.ListJournalEntrySumsParams) ([]sqlc.ListJournalEntrySumsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListJournalEntrySums", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.ListJournalEntrySumsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListJournalEntrySums indicates an expected call of ListJournalEntrySums.
+func (mr *MockStoreMockRecorder) ListJournalEntrySums(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJournalEntrySums", reflect.TypeOf((*MockStore)(nil).ListJournalEntrySums), arg0, arg1)
+}
+
+// ListLatestFxRates mocks base method.
+func (m *MockStore) ListLatestFxRates(arg0 context.Context) ([]sqlc.FxRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLatestFxRates", arg0)
+	ret0, _ := ret[0].([]sqlc.FxRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLatestFxRates indicates an expected call of ListLatestFxRates.
+func (mr *MockStoreMockRecorder) ListLatestFxRates(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLatestFxRates", reflect.TypeOf((*MockStore)(nil).ListLatestFxRates), arg0)
+}
+
+// ListOutboxEvents mocks base method.
+func (m *MockStore) ListOutboxEvents(arg0 context.Context, arg1 sqlc.ListOutboxEventsParams) ([]sqlc.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOutboxEvents", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOutboxEvents indicates an expected call of ListOutboxEvents.
+func (mr *MockStoreMockRecorder) ListOutboxEvents(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOutboxEvents", reflect.TypeOf((*MockStore)(nil).ListOutboxEvents), arg0, arg1)
+}
+
+// ListReferrerAccountsByDateRange mocks base method.
+func (m *MockStore) ListReferrerAccountsByDateRange(arg0 context.Context, arg1 sqlc.ListReferrerAccountsByDateRangeParams) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReferrerAccountsByDateRange", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReferrerAccountsByDateRange indicates an expected call of ListReferrerAccountsByDateRange.
+func (mr *MockStoreMockRecorder) ListReferrerAccountsByDateRange(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReferrerAccountsByDateRange", reflect.TypeOf((*MockStore)(nil).ListReferrerAccountsByDateRange), arg0, arg1)
+}
+
+// ListTransferEntryCounts mocks base method.
+func (m *MockStore) ListTransferEntryCounts(arg0 context.Context, arg1 sqlc.ListTransferEntryCountsParams) ([]sqlc.ListTransferEntryCountsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransferEntryCounts", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.ListTransferEntryCountsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransferEntryCounts indicates an expected call of ListTransferEntryCounts.
+func (mr *MockStoreMockRecorder) ListTransferEntryCounts(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransferEntryCounts", reflect.TypeOf((*MockStore)(nil).ListTransferEntryCounts), arg0, arg1)
+}
+
+// ListTransfers mocks base method.
+func (m *MockStore) ListTransfers(arg0 context.Context, arg1 sqlc.ListTransfersParams) ([]sqlc.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransfers indicates an expected call of ListTransfers.
+func (mr *MockStoreMockRecorder) ListTransfers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockStore)(nil).ListTransfers), arg0, arg1)
+}
+
+// ListUnappliedReferrals mocks base method.
+func (m *MockStore) ListUnappliedReferrals(arg0 context.Context, arg1 sqlc.ListUnappliedReferralsParams) ([]sqlc.ReferralHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUnappliedReferrals", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.ReferralHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUnappliedReferrals indicates an expected call of ListUnappliedReferrals.
+func (mr *MockStoreMockRecorder) ListUnappliedReferrals(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUnappliedReferrals", reflect.TypeOf((*MockStore)(nil).ListUnappliedReferrals), arg0, arg1)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockStore) ListWebhookDeliveries(arg0 context.Context, arg1 sqlc.ListWebhookDeliveriesParams) ([]sqlc.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockStoreMockRecorder) ListWebhookDeliveries(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).ListWebhookDeliveries), arg0, arg1)
+}
+
+// ListWebhookSubscriptions mocks base method.
+func (m *MockStore) ListWebhookSubscriptions(arg0 context.Context, arg1 sql.NullInt64) ([]sqlc.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookSubscriptions", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookSubscriptions indicates an expected call of ListWebhookSubscriptions.
+func (mr *MockStoreMockRecorder) ListWebhookSubscriptions(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookSubscriptions", reflect.TypeOf((*MockStore)(nil).ListWebhookSubscriptions), arg0, arg1)
+}
+
+// ListWebhookSubscriptionsForEvent mocks base method.
+func (m *MockStore) ListWebhookSubscriptionsForEvent(arg0 context.Context, arg1 sqlc.ListWebhookSubscriptionsForEventParams) ([]sqlc.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookSubscriptionsForEvent", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookSubscriptionsForEvent indicates an expected call of ListWebhookSubscriptionsForEvent.
+func (mr *MockStoreMockRecorder) ListWebhookSubscriptionsForEvent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookSubscriptionsForEvent", reflect.TypeOf((*MockStore)(nil).ListWebhookSubscriptionsForEvent), arg0, arg1)
+}
+
+// MarkEmailDeliveryFailed mocks base method.
+func (m *MockStore) MarkEmailDeliveryFailed(arg0 context.Context, arg1 sqlc.MarkEmailDeliveryFailedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailDeliveryFailed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEmailDeliveryFailed indicates an expected call of MarkEmailDeliveryFailed.
+func (mr *MockStoreMockRecorder) MarkEmailDeliveryFailed(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailDeliveryFailed", reflect.TypeOf((*MockStore)(nil).MarkEmailDeliveryFailed), arg0, arg1)
+}
+
+// MarkEmailDeliverySent mocks base method.
+func (m *MockStore) MarkEmailDeliverySent(arg0 context.Context, arg1 sqlc.MarkEmailDeliverySentParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailDeliverySent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEmailDeliverySent indicates an expected call of MarkEmailDeliverySent.
+func (mr *MockStoreMockRecorder) MarkEmailDeliverySent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailDeliverySent", reflect.TypeOf((*MockStore)(nil).MarkEmailDeliverySent), arg0, arg1)
+}
+
+// MarkFxQuoteUsed mocks base method.
+func (m *MockStore) MarkFxQuoteUsed(arg0 context.Context, arg1 sqlc.MarkFxQuoteUsedParams) (sqlc.FxQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkFxQuoteUsed", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.FxQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkFxQuoteUsed indicates an expected call of MarkFxQuoteUsed.
+func (mr *MockStoreMockRecorder) MarkFxQuoteUsed(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkFxQuoteUsed", reflect.TypeOf((*MockStore)(nil).MarkFxQuoteUsed), arg0, arg1)
+}
+
+// MarkInterestAccrualsPaid mocks base method.
+func (m *MockStore) MarkInterestAccrualsPaid(arg0 context.Context, arg1 sqlc.MarkInterestAccrualsPaidParams) ([]sqlc.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkInterestAccrualsPaid", arg0, arg1)
+	ret0, _ := ret[0].([]sqlc.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkInterestAccrualsPaid indicates an expected call of MarkInterestAccrualsPaid.
+func (mr *MockStoreMockRecorder) MarkInterestAccrualsPaid(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkInterestAccrualsPaid", reflect.TypeOf((*MockStore)(nil).MarkInterestAccrualsPaid), arg0, arg1)
+}
+
+// MarkOutboxEventDelivered mocks base method.
+func (m *MockStore) MarkOutboxEventDelivered(arg0 context.Context, arg1 sqlc.MarkOutboxEventDeliveredParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventDelivered", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventDelivered indicates an expected call of MarkOutboxEventDelivered.
+func (mr *MockStoreMockRecorder) MarkOutboxEventDelivered(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventDelivered", reflect.TypeOf((*MockStore)(nil).MarkOutboxEventDelivered), arg0, arg1)
+}
+
+// MarkOutboxEventFailed mocks base method.
+func (m *MockStore) MarkOutboxEventFailed(arg0 context.Context, arg1 sqlc.MarkOutboxEventFailedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventFailed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventFailed indicates an expected call of MarkOutboxEventFailed.
+func (mr *MockStoreMockRecorder) MarkOutboxEventFailed(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventFailed", reflect.TypeOf((*MockStore)(nil).MarkOutboxEventFailed), arg0, arg1)
+}
+
+// MarkReferralCodeUsed mocks base method.
+func (m *MockStore) MarkReferralCodeUsed(arg0 context.Context, arg1 sqlc.MarkReferralCodeUsedParams) (sqlc.ReferralCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkReferralCodeUsed", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.ReferralCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkReferralCodeUsed indicates an expected call of MarkReferralCodeUsed.
+func (mr *MockStoreMockRecorder) MarkReferralCodeUsed(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkReferralCodeUsed", reflect.TypeOf((*MockStore)(nil).MarkReferralCodeUsed), arg0, arg1)
+}
+
+// MarkReferralExtraInterestApplied mocks base method.
+func (m *MockStore) MarkReferralExtraInterestApplied(arg0 context.Context, arg1 sqlc.MarkReferralExtraInterestAppliedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkReferralExtraInterestApplied", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkReferralExtraInterestApplied indicates an expected call of MarkReferralExtraInterestApplied.
+func (mr *MockStoreMockRecorder) MarkReferralExtraInterestApplied(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkReferralExtraInterestApplied", reflect.TypeOf((*MockStore)(nil).MarkReferralExtraInterestApplied), arg0, arg1)
+}
+
+// MarkWebhookDeliveryDelivered mocks base method.
+func (m *MockStore) MarkWebhookDeliveryDelivered(arg0 context.Context, arg1 sqlc.MarkWebhookDeliveryDeliveredParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWebhookDeliveryDelivered", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWebhookDeliveryDelivered indicates an expected call of MarkWebhookDeliveryDelivered.
+func (mr *MockStoreMockRecorder) MarkWebhookDeliveryDelivered(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWebhookDeliveryDelivered", reflect.TypeOf((*MockStore)(nil).MarkWebhookDeliveryDelivered), arg0, arg1)
+}
+
+// MarkWebhookDeliveryFailed mocks base method.
+func (m *MockStore) MarkWebhookDeliveryFailed(arg0 context.Context, arg1 sqlc.MarkWebhookDeliveryFailedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWebhookDeliveryFailed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWebhookDeliveryFailed indicates an expected call of MarkWebhookDeliveryFailed.
+func (mr *MockStoreMockRecorder) MarkWebhookDeliveryFailed(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWebhookDeliveryFailed", reflect.TypeOf((*MockStore)(nil).MarkWebhookDeliveryFailed), arg0, arg1)
+}
+
+// OpenAccountTx mocks base method.
+func (m *MockStore) OpenAccountTx(arg0 context.Context, arg1 sqlc.OpenAccountTxParams) (sqlc.OpenAccountTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenAccountTx", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.OpenAccountTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenAccountTx indicates an expected call of OpenAccountTx.
+func (mr *MockStoreMockRecorder) OpenAccountTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenAccountTx", reflect.TypeOf((*MockStore)(nil).OpenAccountTx), arg0, arg1)
+}
+
+// PayInterestTx mocks base method.
+func (m *MockStore) PayInterestTx(arg0 context.Context, arg1 sqlc.PayInterestTxParams) (sqlc.PayInterestTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PayInterestTx", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.PayInterestTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PayInterestTx indicates an expected call of PayInterestTx.
+func (mr *MockStoreMockRecorder) PayInterestTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayInterestTx", reflect.TypeOf((*MockStore)(nil).PayInterestTx), arg0, arg1)
+}
+
+// Ping mocks base method.
+func (m *MockStore) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockStoreMockRecorder) Ping(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStore)(nil).Ping), arg0)
+}
+
+// RedeemReferralCodeTx mocks base method.
+func (m *MockStore) RedeemReferralCodeTx(arg0 context.Context, arg1 sqlc.RedeemReferralCodeTxParams) (sqlc.RedeemReferralCodeTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemReferralCodeTx", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.RedeemReferralCodeTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemReferralCodeTx indicates an expected call of RedeemReferralCodeTx.
+func (mr *MockStoreMockRecorder) RedeemReferralCodeTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemReferralCodeTx", reflect.TypeOf((*MockStore)(nil).RedeemReferralCodeTx), arg0, arg1)
+}
+
+// RequestPasswordResetTx mocks base method.
+func (m *MockStore) RequestPasswordResetTx(arg0 context.Context, arg1 sqlc.RequestPasswordResetTxParams) (sqlc.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordResetTx", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestPasswordResetTx indicates an expected call of RequestPasswordResetTx.
+func (mr *MockStoreMockRecorder) RequestPasswordResetTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordResetTx", reflect.TypeOf((*MockStore)(nil).RequestPasswordResetTx), arg0, arg1)
+}
+
+// RequeueOutboxEvent mocks base method.
+func (m *MockStore) RequeueOutboxEvent(arg0 context.Context, arg1 sqlc.RequeueOutboxEventParams) (sqlc.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueOutboxEvent", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueOutboxEvent indicates an expected call of RequeueOutboxEvent.
+func (mr *MockStoreMockRecorder) RequeueOutboxEvent(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueOutboxEvent", reflect.TypeOf((*MockStore)(nil).RequeueOutboxEvent), arg0, arg1)
+}
+
+// ResetExtraInterest mocks base method.
+func (m *MockStore) ResetExtraInterest(arg0 context.Context, arg1 int64) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetExtraInterest", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetExtraInterest indicates an expected call of ResetExtraInterest.
+func (mr *MockStoreMockRecorder) ResetExtraInterest(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetExtraInterest", reflect.TypeOf((*MockStore)(nil).ResetExtraInterest), arg0, arg1)
+}
+
+// ResetPasswordTx mocks base method.
+func (m *MockStore) ResetPasswordTx(arg0 context.Context, arg1 sqlc.ResetPasswordTxParams) (sqlc.CustomerCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.CustomerCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPasswordTx indicates an expected call of ResetPasswordTx.
+func (mr *MockStoreMockRecorder) ResetPasswordTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPasswordTx", reflect.TypeOf((*MockStore)(nil).ResetPasswordTx), arg0, arg1)
+}
+
+// SchemaVersion mocks base method.
+func (m *MockStore) SchemaVersion(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchemaVersion", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SchemaVersion indicates an expected call of SchemaVersion.
+func (mr *MockStoreMockRecorder) SchemaVersion(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchemaVersion", reflect.TypeOf((*MockStore)(nil).SchemaVersion), arg0)
+}
+
+// SetCustomerPassword mocks base method.
+func (m *MockStore) SetCustomerPassword(arg0 context.Context, arg1 sqlc.SetCustomerPasswordParams) (sqlc.CustomerCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCustomerPassword", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.CustomerCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCustomerPassword indicates an expected call of SetCustomerPassword.
+func (mr *MockStoreMockRecorder) SetCustomerPassword(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCustomerPassword", reflect.TypeOf((*MockStore)(nil).SetCustomerPassword), arg0, arg1)
+}
+
+// SetPasswordResetToken mocks base method.
+func (m *MockStore) SetPasswordResetToken(arg0 context.Context, arg1 sqlc.SetPasswordResetTokenParams) (sqlc.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPasswordResetToken", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPasswordResetToken indicates an expected call of SetPasswordResetToken.
+func (mr *MockStoreMockRecorder) SetPasswordResetToken(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPasswordResetToken", reflect.TypeOf((*MockStore)(nil).SetPasswordResetToken), arg0, arg1)
+}
+
+// SignupWithReferralTx mocks base method.
+func (m *MockStore) SignupWithReferralTx(arg0 context.Context, arg1 sqlc.SignupWithReferralTxParams) (sqlc.SignupWithReferralTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignupWithReferralTx", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.SignupWithReferralTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignupWithReferralTx indicates an expected call of SignupWithReferralTx.
+func (mr *MockStoreMockRecorder) SignupWithReferralTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignupWithReferralTx", reflect.TypeOf((*MockStore)(nil).SignupWithReferralTx), arg0, arg1)
+}
+
+// Stats mocks base method.
+func (m *MockStore) Stats() sql.DBStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(sql.DBStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockStoreMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockStore)(nil).Stats))
+}
+
+// TransferTx mocks base method.
+func (m *MockStore) TransferTx(arg0 context.Context, arg1 sqlc.TransferTxParams) (sqlc.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferTx", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferTx indicates an expected call of TransferTx.
+func (mr *MockStoreMockRecorder) TransferTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTx", reflect.TypeOf((*MockStore)(nil).TransferTx), arg0, arg1)
+}
+
+// UpdateAccount mocks base method.
+func (m *MockStore) UpdateAccount(arg0 context.Context, arg1 sqlc.UpdateAccountParams) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccount", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccount indicates an expected call of UpdateAccount.
+func (mr *MockStoreMockRecorder) UpdateAccount(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockStore)(nil).UpdateAccount), arg0, arg1)
+}
+
+// UpdateAccountInterest mocks base method.
+func (m *MockStore) UpdateAccountInterest(arg0 context.Context, arg1 sqlc.UpdateAccountInterestParams) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountInterest", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccountInterest indicates an expected call of UpdateAccountInterest.
+func (mr *MockStoreMockRecorder) UpdateAccountInterest(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountInterest", reflect.TypeOf((*MockStore)(nil).UpdateAccountInterest), arg0, arg1)
+}
+
+// UpdateAccountOverdraftLimit mocks base method.
+func (m *MockStore) UpdateAccountOverdraftLimit(arg0 context.Context, arg1 sqlc.UpdateAccountOverdraftLimitParams) (sqlc.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccountOverdraftLimit", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAccountOverdraftLimit indicates an expected call of UpdateAccountOverdraftLimit.
+func (mr *MockStoreMockRecorder) UpdateAccountOverdraftLimit(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccountOverdraftLimit", reflect.TypeOf((*MockStore)(nil).UpdateAccountOverdraftLimit), arg0, arg1)
+}
+
+// UpdateCustomerCredentialRole mocks base method.
+func (m *MockStore) UpdateCustomerCredentialRole(arg0 context.Context, arg1 sqlc.UpdateCustomerCredentialRoleParams) (sqlc.CustomerCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCustomerCredentialRole", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.CustomerCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCustomerCredentialRole indicates an expected call of UpdateCustomerCredentialRole.
+func (mr *MockStoreMockRecorder) UpdateCustomerCredentialRole(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCustomerCredentialRole", reflect.TypeOf((*MockStore)(nil).UpdateCustomerCredentialRole), arg0, arg1)
+}
+
+// UsePasswordReset mocks base method.
+func (m *MockStore) UsePasswordReset(arg0 context.Context, arg1 sqlc.UsePasswordResetParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsePasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UsePasswordReset indicates an expected call of UsePasswordReset.
+func (mr *MockStoreMockRecorder) UsePasswordReset(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsePasswordReset", reflect.TypeOf((*MockStore)(nil).UsePasswordReset), arg0, arg1)
+}
+
+// UseReferralCodeTx mocks base method.
+func (m *MockStore) UseReferralCodeTx(arg0 context.Context, arg1 sqlc.UseReferralCodeTxParams) (sqlc.UseReferralCodeTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseReferralCodeTx", arg0, arg1)
+	ret0, _ := ret[0].(sqlc.UseReferralCodeTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseReferralCodeTx indicates an expected call of UseReferralCodeTx.
+func (mr *MockStoreMockRecorder) UseReferralCodeTx(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseReferralCodeTx", reflect.TypeOf((*MockStore)(nil).UseReferralCodeTx), arg0, arg1)
+}
@@ -15,7 +15,7 @@ const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at
+RETURNING id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at, overdraft_limit, account_type, customer_id
 `
 
 type AddAccountBalanceParams struct {
@@ -37,22 +37,26 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AccountType,
+		&i.CustomerID,
 	)
 	return i, err
 }
 
 const createAccount = `-- name: CreateAccount :one
-INSERT INTO accounts (owner, balance, email, currency, created_at)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at
+INSERT INTO accounts (owner, balance, email, currency, created_at, customer_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at, overdraft_limit, account_type, customer_id
 `
 
 type CreateAccountParams struct {
-	Owner     string    `json:"owner"`
-	Balance   int64     `json:"balance"`
-	Email     string    `json:"email"`
-	Currency  string    `json:"currency"`
-	CreatedAt time.Time `json:"created_at"`
+	Owner      string        `json:"owner"`
+	Balance    int64         `json:"balance"`
+	Email      string        `json:"email"`
+	Currency   string        `json:"currency"`
+	CreatedAt  time.Time     `json:"created_at"`
+	CustomerID sql.NullInt64 `json:"customer_id"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
@@ -62,6 +66,7 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		arg.Email,
 		arg.Currency,
 		arg.CreatedAt,
+		arg.CustomerID,
 	)
 	var i Account
 	err := row.Scan(
@@ -75,6 +80,42 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AccountType,
+		&i.CustomerID,
+	)
+	return i, err
+}
+
+const createAccountInterestChange = `-- name: CreateAccountInterestChange :one
+INSERT INTO account_interest_changes (account_id, extra_interest, extra_interest_start_date, extra_interest_duration)
+VALUES ($1, $2, $3, $4)
+RETURNING id, account_id, extra_interest, extra_interest_start_date, extra_interest_duration, changed_at
+`
+
+type CreateAccountInterestChangeParams struct {
+	AccountID              int64           `json:"account_id"`
+	ExtraInterest          sql.NullFloat64 `json:"extra_interest"`
+	ExtraInterestStartDate sql.NullTime    `json:"extra_interest_start_date"`
+	ExtraInterestDuration  int32           `json:"extra_interest_duration"`
+}
+
+// records the extra interest terms an account had until now, before they are changed
+func (q *Queries) CreateAccountInterestChange(ctx context.Context, arg CreateAccountInterestChangeParams) (AccountInterestChange, error) {
+	row := q.queryRow(ctx, q.createAccountInterestChangeStmt, createAccountInterestChange,
+		arg.AccountID,
+		arg.ExtraInterest,
+		arg.ExtraInterestStartDate,
+		arg.ExtraInterestDuration,
+	)
+	var i AccountInterestChange
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.ExtraInterest,
+		&i.ExtraInterestStartDate,
+		&i.ExtraInterestDuration,
+		&i.ChangedAt,
 	)
 	return i, err
 }
@@ -90,7 +131,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at FROM accounts
+SELECT id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at, overdraft_limit, account_type, customer_id FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -108,12 +149,36 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AccountType,
+		&i.CustomerID,
 	)
 	return i, err
 }
 
+const getAccountBalanceAt = `-- name: GetAccountBalanceAt :one
+SELECT (balance - COALESCE((
+    SELECT SUM(amount) FROM entries
+    WHERE entries.account_id = accounts.id AND entries.created_at >= $1
+), 0))::bigint FROM accounts
+WHERE id = $2
+`
+
+type GetAccountBalanceAtParams struct {
+	At time.Time `json:"at"`
+	ID int64     `json:"id"`
+}
+
+// the balance as it was at the given time, the current balance minus every entry since
+func (q *Queries) GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error) {
+	row := q.queryRow(ctx, q.getAccountBalanceAtStmt, getAccountBalanceAt, arg.At, arg.ID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at FROM accounts
+SELECT id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at, overdraft_limit, account_type, customer_id FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -132,17 +197,57 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AccountType,
+		&i.CustomerID,
+	)
+	return i, err
+}
+
+const getAccountInterestChangeSince = `-- name: GetAccountInterestChangeSince :one
+SELECT id, account_id, extra_interest, extra_interest_start_date, extra_interest_duration, changed_at FROM account_interest_changes
+WHERE account_id = $1 AND changed_at >= $2
+ORDER BY changed_at, id
+LIMIT 1
+`
+
+type GetAccountInterestChangeSinceParams struct {
+	AccountID int64     `json:"account_id"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// the first change of the extra interest terms of the account at or after the given time, which
+// holds the terms the account had then
+func (q *Queries) GetAccountInterestChangeSince(ctx context.Context, arg GetAccountInterestChangeSinceParams) (AccountInterestChange, error) {
+	row := q.queryRow(ctx, q.getAccountInterestChangeSinceStmt, getAccountInterestChangeSince, arg.AccountID, arg.ChangedAt)
+	var i AccountInterestChange
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.ExtraInterest,
+		&i.ExtraInterestStartDate,
+		&i.ExtraInterestDuration,
+		&i.ChangedAt,
 	)
 	return i, err
 }
 
-const getAccountWithEmail = `-- name: GetAccountWithEmail :one
-SELECT id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at FROM accounts
-WHERE email = $1 LIMIT 1
+const getSystemAccountForUpdate = `-- name: GetSystemAccountForUpdate :one
+INSERT INTO accounts (owner, balance, email, currency, account_type)
+VALUES ($1, 0, $1 || '.' || lower($2) || '@system.bank-api', $2, $1)
+ON CONFLICT ("account_type", "currency") WHERE "account_type" <> 'customer'
+DO UPDATE SET account_type = EXCLUDED.account_type
+RETURNING id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at, overdraft_limit, account_type, customer_id
 `
 
-func (q *Queries) GetAccountWithEmail(ctx context.Context, email string) (Account, error) {
-	row := q.queryRow(ctx, q.getAccountWithEmailStmt, getAccountWithEmail, email)
+type GetSystemAccountForUpdateParams struct {
+	AccountType string `json:"account_type"`
+	Currency    string `json:"currency"`
+}
+
+// creates the system account of the type and currency on first use
+func (q *Queries) GetSystemAccountForUpdate(ctx context.Context, arg GetSystemAccountForUpdateParams) (Account, error) {
+	row := q.queryRow(ctx, q.getSystemAccountForUpdateStmt, getSystemAccountForUpdate, arg.AccountType, arg.Currency)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -155,12 +260,15 @@ func (q *Queries) GetAccountWithEmail(ctx context.Context, email string) (Accoun
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AccountType,
+		&i.CustomerID,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at FROM accounts
+SELECT id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at, overdraft_limit, account_type, customer_id FROM accounts
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -191,6 +299,9 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.AccountType,
+			&i.CustomerID,
 		); err != nil {
 			return nil, err
 		}
@@ -205,11 +316,189 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
+const listAccountsForInterest = `-- name: ListAccountsForInterest :many
+SELECT id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at, overdraft_limit, account_type, customer_id FROM accounts
+WHERE account_type = 'customer'
+  AND created_at < $1
+  AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListAccountsForInterestParams struct {
+	CreatedBefore time.Time `json:"created_before"`
+	AfterID       int64     `json:"after_id"`
+	BatchSize     int32     `json:"batch_size"`
+}
+
+// customer accounts that existed before the given time, in batches after the given ID
+func (q *Queries) ListAccountsForInterest(ctx context.Context, arg ListAccountsForInterestParams) ([]Account, error) {
+	rows, err := q.query(ctx, q.listAccountsForInterestStmt, listAccountsForInterest, arg.CreatedBefore, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Email,
+			&i.ExtraInterest,
+			&i.ExtraInterestStartDate,
+			&i.ExtraInterestDuration,
+			&i.Interest,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.AccountType,
+			&i.CustomerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsWithExpiredExtraInterest = `-- name: ListAccountsWithExpiredExtraInterest :many
+SELECT id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at, overdraft_limit, account_type, customer_id FROM accounts
+WHERE extra_interest > 0
+  AND extra_interest_start_date IS NOT NULL
+  AND (extra_interest_start_date + make_interval(months => extra_interest_duration))::date <= $1::date
+  AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListAccountsWithExpiredExtraInterestParams struct {
+	AsOf      time.Time `json:"as_of"`
+	AfterID   int64     `json:"after_id"`
+	BatchSize int32     `json:"batch_size"`
+}
+
+// accounts whose extra interest ended on or before the given date, in batches after the given ID
+func (q *Queries) ListAccountsWithExpiredExtraInterest(ctx context.Context, arg ListAccountsWithExpiredExtraInterestParams) ([]Account, error) {
+	rows, err := q.query(ctx, q.listAccountsWithExpiredExtraInterestStmt, listAccountsWithExpiredExtraInterest, arg.AsOf, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Email,
+			&i.ExtraInterest,
+			&i.ExtraInterestStartDate,
+			&i.ExtraInterestDuration,
+			&i.Interest,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.AccountType,
+			&i.CustomerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCustomerAccounts = `-- name: ListCustomerAccounts :many
+SELECT id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at, overdraft_limit, account_type, customer_id FROM accounts
+WHERE customer_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListCustomerAccounts(ctx context.Context, customerID sql.NullInt64) ([]Account, error) {
+	rows, err := q.query(ctx, q.listCustomerAccountsStmt, listCustomerAccounts, customerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Email,
+			&i.ExtraInterest,
+			&i.ExtraInterestStartDate,
+			&i.ExtraInterestDuration,
+			&i.Interest,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.AccountType,
+			&i.CustomerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resetExtraInterest = `-- name: ResetExtraInterest :one
+UPDATE accounts
+SET extra_interest = 0, extra_interest_start_date = NULL
+WHERE id = $1
+RETURNING id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at, overdraft_limit, account_type, customer_id
+`
+
+func (q *Queries) ResetExtraInterest(ctx context.Context, id int64) (Account, error) {
+	row := q.queryRow(ctx, q.resetExtraInterestStmt, resetExtraInterest, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Email,
+		&i.ExtraInterest,
+		&i.ExtraInterestStartDate,
+		&i.ExtraInterestDuration,
+		&i.Interest,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AccountType,
+		&i.CustomerID,
+	)
+	return i, err
+}
+
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at
+RETURNING id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at, overdraft_limit, account_type, customer_id
 `
 
 type UpdateAccountParams struct {
@@ -231,6 +520,9 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AccountType,
+		&i.CustomerID,
 	)
 	return i, err
 }
@@ -239,7 +531,7 @@ const updateAccountInterest = `-- name: UpdateAccountInterest :one
 UPDATE accounts
 SET extra_interest = $2, extra_interest_start_date = $3, extra_interest_duration = $4
 WHERE id = $1
-RETURNING id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at
+RETURNING id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at, overdraft_limit, account_type, customer_id
 `
 
 type UpdateAccountInterestParams struct {
@@ -268,6 +560,42 @@ func (q *Queries) UpdateAccountInterest(ctx context.Context, arg UpdateAccountIn
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AccountType,
+		&i.CustomerID,
+	)
+	return i, err
+}
+
+const updateAccountOverdraftLimit = `-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = $2
+WHERE id = $1
+RETURNING id, owner, email, extra_interest, extra_interest_start_date, extra_interest_duration, interest, balance, currency, created_at, overdraft_limit, account_type, customer_id
+`
+
+type UpdateAccountOverdraftLimitParams struct {
+	ID             int64 `json:"id"`
+	OverdraftLimit int64 `json:"overdraft_limit"`
+}
+
+func (q *Queries) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	row := q.queryRow(ctx, q.updateAccountOverdraftLimitStmt, updateAccountOverdraftLimit, arg.ID, arg.OverdraftLimit)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Email,
+		&i.ExtraInterest,
+		&i.ExtraInterestStartDate,
+		&i.ExtraInterestDuration,
+		&i.Interest,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.AccountType,
+		&i.CustomerID,
 	)
 	return i, err
 }
@@ -78,11 +78,17 @@ func randomTimeBetween(t *testing.T, start, end time.Time) time.Time {
 }
 
 func CreateRandomAccount(t *testing.T) Account {
+	return createRandomAccountIn(t, util.RandomCurrency())
+}
+
+// createRandomAccountIn creates a random account held in the currency, for tests moving money
+// between accounts, which have to share a currency.
+func createRandomAccountIn(t *testing.T, currency string) Account {
 	args := CreateAccountParams{
 		Owner:     util.RandomOwner(),
 		Balance:   util.RandomMoney(),
 		Email:     util.RandomEmail(),
-		Currency:  util.RandomCurrency(),
+		Currency:  currency,
 		CreatedAt: utils.ConvertToTokyoTime(),
 	}
 
@@ -27,39 +27,159 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.addAccountBalanceStmt, err = db.PrepareContext(ctx, addAccountBalance); err != nil {
 		return nil, fmt.Errorf("error preparing query AddAccountBalance: %w", err)
 	}
+	if q.blockCustomerSessionsStmt, err = db.PrepareContext(ctx, blockCustomerSessions); err != nil {
+		return nil, fmt.Errorf("error preparing query BlockCustomerSessions: %w", err)
+	}
+	if q.blockSessionStmt, err = db.PrepareContext(ctx, blockSession); err != nil {
+		return nil, fmt.Errorf("error preparing query BlockSession: %w", err)
+	}
+	if q.claimEmailDeliveriesStmt, err = db.PrepareContext(ctx, claimEmailDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimEmailDeliveries: %w", err)
+	}
+	if q.claimIdempotencyKeyStmt, err = db.PrepareContext(ctx, claimIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimIdempotencyKey: %w", err)
+	}
+	if q.claimJobRunStmt, err = db.PrepareContext(ctx, claimJobRun); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimJobRun: %w", err)
+	}
+	if q.claimOutboxEventsStmt, err = db.PrepareContext(ctx, claimOutboxEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimOutboxEvents: %w", err)
+	}
+	if q.claimWebhookDeliveriesStmt, err = db.PrepareContext(ctx, claimWebhookDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query ClaimWebhookDeliveries: %w", err)
+	}
+	if q.completeIdempotencyKeyStmt, err = db.PrepareContext(ctx, completeIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query CompleteIdempotencyKey: %w", err)
+	}
 	if q.createAccountStmt, err = db.PrepareContext(ctx, createAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateAccount: %w", err)
 	}
+	if q.createAccountInterestChangeStmt, err = db.PrepareContext(ctx, createAccountInterestChange); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAccountInterestChange: %w", err)
+	}
+	if q.createAuditEntryStmt, err = db.PrepareContext(ctx, createAuditEntry); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateAuditEntry: %w", err)
+	}
+	if q.createCustomerStmt, err = db.PrepareContext(ctx, createCustomer); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateCustomer: %w", err)
+	}
+	if q.createCustomerCredentialStmt, err = db.PrepareContext(ctx, createCustomerCredential); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateCustomerCredential: %w", err)
+	}
+	if q.createEmailDeliveryStmt, err = db.PrepareContext(ctx, createEmailDelivery); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateEmailDelivery: %w", err)
+	}
 	if q.createEntryStmt, err = db.PrepareContext(ctx, createEntry); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateEntry: %w", err)
 	}
+	if q.createFxQuoteStmt, err = db.PrepareContext(ctx, createFxQuote); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFxQuote: %w", err)
+	}
+	if q.createFxRateStmt, err = db.PrepareContext(ctx, createFxRate); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateFxRate: %w", err)
+	}
+	if q.createInterestAccrualStmt, err = db.PrepareContext(ctx, createInterestAccrual); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateInterestAccrual: %w", err)
+	}
+	if q.createInterestPayoutStmt, err = db.PrepareContext(ctx, createInterestPayout); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateInterestPayout: %w", err)
+	}
+	if q.createJournalStmt, err = db.PrepareContext(ctx, createJournal); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateJournal: %w", err)
+	}
+	if q.createOutboxEventStmt, err = db.PrepareContext(ctx, createOutboxEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateOutboxEvent: %w", err)
+	}
+	if q.createPasswordResetStmt, err = db.PrepareContext(ctx, createPasswordReset); err != nil {
+		return nil, fmt.Errorf("error preparing query CreatePasswordReset: %w", err)
+	}
 	if q.createReferralCodeStmt, err = db.PrepareContext(ctx, createReferralCode); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateReferralCode: %w", err)
 	}
 	if q.createReferralHistoryStmt, err = db.PrepareContext(ctx, createReferralHistory); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateReferralHistory: %w", err)
 	}
+	if q.createSessionStmt, err = db.PrepareContext(ctx, createSession); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateSession: %w", err)
+	}
 	if q.createTransferStmt, err = db.PrepareContext(ctx, createTransfer); err != nil {
 		return nil, fmt.Errorf("error preparing query CreateTransfer: %w", err)
 	}
+	if q.createWebhookDeliveryStmt, err = db.PrepareContext(ctx, createWebhookDelivery); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWebhookDelivery: %w", err)
+	}
+	if q.createWebhookSubscriptionStmt, err = db.PrepareContext(ctx, createWebhookSubscription); err != nil {
+		return nil, fmt.Errorf("error preparing query CreateWebhookSubscription: %w", err)
+	}
 	if q.deleteAccountStmt, err = db.PrepareContext(ctx, deleteAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query DeleteAccount: %w", err)
 	}
+	if q.deleteIdempotencyKeyStmt, err = db.PrepareContext(ctx, deleteIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteIdempotencyKey: %w", err)
+	}
+	if q.deleteIdempotencyKeysBeforeStmt, err = db.PrepareContext(ctx, deleteIdempotencyKeysBefore); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteIdempotencyKeysBefore: %w", err)
+	}
+	if q.deleteWebhookSubscriptionStmt, err = db.PrepareContext(ctx, deleteWebhookSubscription); err != nil {
+		return nil, fmt.Errorf("error preparing query DeleteWebhookSubscription: %w", err)
+	}
+	if q.finishJobRunStmt, err = db.PrepareContext(ctx, finishJobRun); err != nil {
+		return nil, fmt.Errorf("error preparing query FinishJobRun: %w", err)
+	}
 	if q.getAccountStmt, err = db.PrepareContext(ctx, getAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAccount: %w", err)
 	}
+	if q.getAccountBalanceAtStmt, err = db.PrepareContext(ctx, getAccountBalanceAt); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAccountBalanceAt: %w", err)
+	}
 	if q.getAccountForUpdateStmt, err = db.PrepareContext(ctx, getAccountForUpdate); err != nil {
 		return nil, fmt.Errorf("error preparing query GetAccountForUpdate: %w", err)
 	}
-	if q.getAccountWithEmailStmt, err = db.PrepareContext(ctx, getAccountWithEmail); err != nil {
-		return nil, fmt.Errorf("error preparing query GetAccountWithEmail: %w", err)
+	if q.getAccountInterestChangeSinceStmt, err = db.PrepareContext(ctx, getAccountInterestChangeSince); err != nil {
+		return nil, fmt.Errorf("error preparing query GetAccountInterestChangeSince: %w", err)
+	}
+	if q.getCustomerStmt, err = db.PrepareContext(ctx, getCustomer); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCustomer: %w", err)
+	}
+	if q.getCustomerByEmailStmt, err = db.PrepareContext(ctx, getCustomerByEmail); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCustomerByEmail: %w", err)
+	}
+	if q.getCustomerCredentialStmt, err = db.PrepareContext(ctx, getCustomerCredential); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCustomerCredential: %w", err)
+	}
+	if q.getCustomerForUpdateStmt, err = db.PrepareContext(ctx, getCustomerForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetCustomerForUpdate: %w", err)
 	}
 	if q.getEntryStmt, err = db.PrepareContext(ctx, getEntry); err != nil {
 		return nil, fmt.Errorf("error preparing query GetEntry: %w", err)
 	}
+	if q.getFxQuoteForUpdateStmt, err = db.PrepareContext(ctx, getFxQuoteForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetFxQuoteForUpdate: %w", err)
+	}
+	if q.getIdempotencyKeyStmt, err = db.PrepareContext(ctx, getIdempotencyKey); err != nil {
+		return nil, fmt.Errorf("error preparing query GetIdempotencyKey: %w", err)
+	}
+	if q.getInterestPayoutStmt, err = db.PrepareContext(ctx, getInterestPayout); err != nil {
+		return nil, fmt.Errorf("error preparing query GetInterestPayout: %w", err)
+	}
+	if q.getJobRunStmt, err = db.PrepareContext(ctx, getJobRun); err != nil {
+		return nil, fmt.Errorf("error preparing query GetJobRun: %w", err)
+	}
+	if q.getJournalStmt, err = db.PrepareContext(ctx, getJournal); err != nil {
+		return nil, fmt.Errorf("error preparing query GetJournal: %w", err)
+	}
+	if q.getLatestFxRateStmt, err = db.PrepareContext(ctx, getLatestFxRate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetLatestFxRate: %w", err)
+	}
+	if q.getPasswordResetByTokenForUpdateStmt, err = db.PrepareContext(ctx, getPasswordResetByTokenForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetPasswordResetByTokenForUpdate: %w", err)
+	}
 	if q.getReferralCodeStmt, err = db.PrepareContext(ctx, getReferralCode); err != nil {
 		return nil, fmt.Errorf("error preparing query GetReferralCode: %w", err)
 	}
+	if q.getReferralCodeForUpdateStmt, err = db.PrepareContext(ctx, getReferralCodeForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetReferralCodeForUpdate: %w", err)
+	}
 	if q.getReferralCodesForReferrerAccountStmt, err = db.PrepareContext(ctx, getReferralCodesForReferrerAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query GetReferralCodesForReferrerAccount: %w", err)
 	}
@@ -72,33 +192,156 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getReferralsByDateRangeStmt, err = db.PrepareContext(ctx, getReferralsByDateRange); err != nil {
 		return nil, fmt.Errorf("error preparing query GetReferralsByDateRange: %w", err)
 	}
+	if q.getSessionStmt, err = db.PrepareContext(ctx, getSession); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSession: %w", err)
+	}
+	if q.getSystemAccountForUpdateStmt, err = db.PrepareContext(ctx, getSystemAccountForUpdate); err != nil {
+		return nil, fmt.Errorf("error preparing query GetSystemAccountForUpdate: %w", err)
+	}
 	if q.getTransferStmt, err = db.PrepareContext(ctx, getTransfer); err != nil {
 		return nil, fmt.Errorf("error preparing query GetTransfer: %w", err)
 	}
 	if q.getUnusedReferralCodesStmt, err = db.PrepareContext(ctx, getUnusedReferralCodes); err != nil {
 		return nil, fmt.Errorf("error preparing query GetUnusedReferralCodes: %w", err)
 	}
+	if q.getWebhookSubscriptionStmt, err = db.PrepareContext(ctx, getWebhookSubscription); err != nil {
+		return nil, fmt.Errorf("error preparing query GetWebhookSubscription: %w", err)
+	}
+	if q.hasBeenReferredStmt, err = db.PrepareContext(ctx, hasBeenReferred); err != nil {
+		return nil, fmt.Errorf("error preparing query HasBeenReferred: %w", err)
+	}
+	if q.hasInterestAccrualSinceStmt, err = db.PrepareContext(ctx, hasInterestAccrualSince); err != nil {
+		return nil, fmt.Errorf("error preparing query HasInterestAccrualSince: %w", err)
+	}
 	if q.hasUnUsedCodeForReferrerAccountStmt, err = db.PrepareContext(ctx, hasUnUsedCodeForReferrerAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query HasUnUsedCodeForReferrerAccount: %w", err)
 	}
+	if q.listAccountEntriesStmt, err = db.PrepareContext(ctx, listAccountEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAccountEntries: %w", err)
+	}
+	if q.listAccountEntrySumsStmt, err = db.PrepareContext(ctx, listAccountEntrySums); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAccountEntrySums: %w", err)
+	}
 	if q.listAccountsStmt, err = db.PrepareContext(ctx, listAccounts); err != nil {
 		return nil, fmt.Errorf("error preparing query ListAccounts: %w", err)
 	}
+	if q.listAccountsForInterestStmt, err = db.PrepareContext(ctx, listAccountsForInterest); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAccountsForInterest: %w", err)
+	}
+	if q.listAccountsWithExpiredExtraInterestStmt, err = db.PrepareContext(ctx, listAccountsWithExpiredExtraInterest); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAccountsWithExpiredExtraInterest: %w", err)
+	}
+	if q.listAccountsWithUnpaidInterestAccrualsStmt, err = db.PrepareContext(ctx, listAccountsWithUnpaidInterestAccruals); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAccountsWithUnpaidInterestAccruals: %w", err)
+	}
+	if q.listAuditEntriesStmt, err = db.PrepareContext(ctx, listAuditEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAuditEntries: %w", err)
+	}
+	if q.listCustomerAccountsStmt, err = db.PrepareContext(ctx, listCustomerAccounts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListCustomerAccounts: %w", err)
+	}
+	if q.listEmailDeliveriesStmt, err = db.PrepareContext(ctx, listEmailDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListEmailDeliveries: %w", err)
+	}
 	if q.listEntriesStmt, err = db.PrepareContext(ctx, listEntries); err != nil {
 		return nil, fmt.Errorf("error preparing query ListEntries: %w", err)
 	}
+	if q.listInterestAccrualsStmt, err = db.PrepareContext(ctx, listInterestAccruals); err != nil {
+		return nil, fmt.Errorf("error preparing query ListInterestAccruals: %w", err)
+	}
+	if q.listJobRunsStmt, err = db.PrepareContext(ctx, listJobRuns); err != nil {
+		return nil, fmt.Errorf("error preparing query ListJobRuns: %w", err)
+	}
+	if q.listJournalEntriesStmt, err = db.PrepareContext(ctx, listJournalEntries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListJournalEntries: %w", err)
+	}
+	if q.listJournalEntrySumsStmt, err = db.PrepareContext(ctx, listJournalEntrySums); err != nil {
+		return nil, fmt.Errorf("error preparing query ListJournalEntrySums: %w", err)
+	}
+	if q.listLatestFxRatesStmt, err = db.PrepareContext(ctx, listLatestFxRates); err != nil {
+		return nil, fmt.Errorf("error preparing query ListLatestFxRates: %w", err)
+	}
+	if q.listOutboxEventsStmt, err = db.PrepareContext(ctx, listOutboxEvents); err != nil {
+		return nil, fmt.Errorf("error preparing query ListOutboxEvents: %w", err)
+	}
+	if q.listReferrerAccountsByDateRangeStmt, err = db.PrepareContext(ctx, listReferrerAccountsByDateRange); err != nil {
+		return nil, fmt.Errorf("error preparing query ListReferrerAccountsByDateRange: %w", err)
+	}
+	if q.listTransferEntryCountsStmt, err = db.PrepareContext(ctx, listTransferEntryCounts); err != nil {
+		return nil, fmt.Errorf("error preparing query ListTransferEntryCounts: %w", err)
+	}
 	if q.listTransfersStmt, err = db.PrepareContext(ctx, listTransfers); err != nil {
 		return nil, fmt.Errorf("error preparing query ListTransfers: %w", err)
 	}
+	if q.listUnappliedReferralsStmt, err = db.PrepareContext(ctx, listUnappliedReferrals); err != nil {
+		return nil, fmt.Errorf("error preparing query ListUnappliedReferrals: %w", err)
+	}
+	if q.listWebhookDeliveriesStmt, err = db.PrepareContext(ctx, listWebhookDeliveries); err != nil {
+		return nil, fmt.Errorf("error preparing query ListWebhookDeliveries: %w", err)
+	}
+	if q.listWebhookSubscriptionsStmt, err = db.PrepareContext(ctx, listWebhookSubscriptions); err != nil {
+		return nil, fmt.Errorf("error preparing query ListWebhookSubscriptions: %w", err)
+	}
+	if q.listWebhookSubscriptionsForEventStmt, err = db.PrepareContext(ctx, listWebhookSubscriptionsForEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query ListWebhookSubscriptionsForEvent: %w", err)
+	}
+	if q.markEmailDeliveryFailedStmt, err = db.PrepareContext(ctx, markEmailDeliveryFailed); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkEmailDeliveryFailed: %w", err)
+	}
+	if q.markEmailDeliverySentStmt, err = db.PrepareContext(ctx, markEmailDeliverySent); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkEmailDeliverySent: %w", err)
+	}
+	if q.markFxQuoteUsedStmt, err = db.PrepareContext(ctx, markFxQuoteUsed); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkFxQuoteUsed: %w", err)
+	}
+	if q.markInterestAccrualsPaidStmt, err = db.PrepareContext(ctx, markInterestAccrualsPaid); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkInterestAccrualsPaid: %w", err)
+	}
+	if q.markOutboxEventDeliveredStmt, err = db.PrepareContext(ctx, markOutboxEventDelivered); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkOutboxEventDelivered: %w", err)
+	}
+	if q.markOutboxEventFailedStmt, err = db.PrepareContext(ctx, markOutboxEventFailed); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkOutboxEventFailed: %w", err)
+	}
 	if q.markReferralCodeUsedStmt, err = db.PrepareContext(ctx, markReferralCodeUsed); err != nil {
 		return nil, fmt.Errorf("error preparing query MarkReferralCodeUsed: %w", err)
 	}
+	if q.markReferralExtraInterestAppliedStmt, err = db.PrepareContext(ctx, markReferralExtraInterestApplied); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkReferralExtraInterestApplied: %w", err)
+	}
+	if q.markWebhookDeliveryDeliveredStmt, err = db.PrepareContext(ctx, markWebhookDeliveryDelivered); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkWebhookDeliveryDelivered: %w", err)
+	}
+	if q.markWebhookDeliveryFailedStmt, err = db.PrepareContext(ctx, markWebhookDeliveryFailed); err != nil {
+		return nil, fmt.Errorf("error preparing query MarkWebhookDeliveryFailed: %w", err)
+	}
+	if q.requeueOutboxEventStmt, err = db.PrepareContext(ctx, requeueOutboxEvent); err != nil {
+		return nil, fmt.Errorf("error preparing query RequeueOutboxEvent: %w", err)
+	}
+	if q.resetExtraInterestStmt, err = db.PrepareContext(ctx, resetExtraInterest); err != nil {
+		return nil, fmt.Errorf("error preparing query ResetExtraInterest: %w", err)
+	}
+	if q.setCustomerPasswordStmt, err = db.PrepareContext(ctx, setCustomerPassword); err != nil {
+		return nil, fmt.Errorf("error preparing query SetCustomerPassword: %w", err)
+	}
+	if q.setPasswordResetTokenStmt, err = db.PrepareContext(ctx, setPasswordResetToken); err != nil {
+		return nil, fmt.Errorf("error preparing query SetPasswordResetToken: %w", err)
+	}
 	if q.updateAccountStmt, err = db.PrepareContext(ctx, updateAccount); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateAccount: %w", err)
 	}
 	if q.updateAccountInterestStmt, err = db.PrepareContext(ctx, updateAccountInterest); err != nil {
 		return nil, fmt.Errorf("error preparing query UpdateAccountInterest: %w", err)
 	}
+	if q.updateAccountOverdraftLimitStmt, err = db.PrepareContext(ctx, updateAccountOverdraftLimit); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateAccountOverdraftLimit: %w", err)
+	}
+	if q.updateCustomerCredentialRoleStmt, err = db.PrepareContext(ctx, updateCustomerCredentialRole); err != nil {
+		return nil, fmt.Errorf("error preparing query UpdateCustomerCredentialRole: %w", err)
+	}
+	if q.usePasswordResetStmt, err = db.PrepareContext(ctx, usePasswordReset); err != nil {
+		return nil, fmt.Errorf("error preparing query UsePasswordReset: %w", err)
+	}
 	return &q, nil
 }
 
@@ -109,16 +352,116 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing addAccountBalanceStmt: %w", cerr)
 		}
 	}
+	if q.blockCustomerSessionsStmt != nil {
+		if cerr := q.blockCustomerSessionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing blockCustomerSessionsStmt: %w", cerr)
+		}
+	}
+	if q.blockSessionStmt != nil {
+		if cerr := q.blockSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing blockSessionStmt: %w", cerr)
+		}
+	}
+	if q.claimEmailDeliveriesStmt != nil {
+		if cerr := q.claimEmailDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimEmailDeliveriesStmt: %w", cerr)
+		}
+	}
+	if q.claimIdempotencyKeyStmt != nil {
+		if cerr := q.claimIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimIdempotencyKeyStmt: %w", cerr)
+		}
+	}
+	if q.claimJobRunStmt != nil {
+		if cerr := q.claimJobRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimJobRunStmt: %w", cerr)
+		}
+	}
+	if q.claimOutboxEventsStmt != nil {
+		if cerr := q.claimOutboxEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimOutboxEventsStmt: %w", cerr)
+		}
+	}
+	if q.claimWebhookDeliveriesStmt != nil {
+		if cerr := q.claimWebhookDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing claimWebhookDeliveriesStmt: %w", cerr)
+		}
+	}
+	if q.completeIdempotencyKeyStmt != nil {
+		if cerr := q.completeIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing completeIdempotencyKeyStmt: %w", cerr)
+		}
+	}
 	if q.createAccountStmt != nil {
 		if cerr := q.createAccountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createAccountStmt: %w", cerr)
 		}
 	}
+	if q.createAccountInterestChangeStmt != nil {
+		if cerr := q.createAccountInterestChangeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAccountInterestChangeStmt: %w", cerr)
+		}
+	}
+	if q.createAuditEntryStmt != nil {
+		if cerr := q.createAuditEntryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createAuditEntryStmt: %w", cerr)
+		}
+	}
+	if q.createCustomerStmt != nil {
+		if cerr := q.createCustomerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createCustomerStmt: %w", cerr)
+		}
+	}
+	if q.createCustomerCredentialStmt != nil {
+		if cerr := q.createCustomerCredentialStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createCustomerCredentialStmt: %w", cerr)
+		}
+	}
+	if q.createEmailDeliveryStmt != nil {
+		if cerr := q.createEmailDeliveryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createEmailDeliveryStmt: %w", cerr)
+		}
+	}
 	if q.createEntryStmt != nil {
 		if cerr := q.createEntryStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createEntryStmt: %w", cerr)
 		}
 	}
+	if q.createFxQuoteStmt != nil {
+		if cerr := q.createFxQuoteStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFxQuoteStmt: %w", cerr)
+		}
+	}
+	if q.createFxRateStmt != nil {
+		if cerr := q.createFxRateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createFxRateStmt: %w", cerr)
+		}
+	}
+	if q.createInterestAccrualStmt != nil {
+		if cerr := q.createInterestAccrualStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createInterestAccrualStmt: %w", cerr)
+		}
+	}
+	if q.createInterestPayoutStmt != nil {
+		if cerr := q.createInterestPayoutStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createInterestPayoutStmt: %w", cerr)
+		}
+	}
+	if q.createJournalStmt != nil {
+		if cerr := q.createJournalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createJournalStmt: %w", cerr)
+		}
+	}
+	if q.createOutboxEventStmt != nil {
+		if cerr := q.createOutboxEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createOutboxEventStmt: %w", cerr)
+		}
+	}
+	if q.createPasswordResetStmt != nil {
+		if cerr := q.createPasswordResetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createPasswordResetStmt: %w", cerr)
+		}
+	}
 	if q.createReferralCodeStmt != nil {
 		if cerr := q.createReferralCodeStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createReferralCodeStmt: %w", cerr)
@@ -129,29 +472,89 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing createReferralHistoryStmt: %w", cerr)
 		}
 	}
+	if q.createSessionStmt != nil {
+		if cerr := q.createSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createSessionStmt: %w", cerr)
+		}
+	}
 	if q.createTransferStmt != nil {
 		if cerr := q.createTransferStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing createTransferStmt: %w", cerr)
 		}
 	}
+	if q.createWebhookDeliveryStmt != nil {
+		if cerr := q.createWebhookDeliveryStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWebhookDeliveryStmt: %w", cerr)
+		}
+	}
+	if q.createWebhookSubscriptionStmt != nil {
+		if cerr := q.createWebhookSubscriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing createWebhookSubscriptionStmt: %w", cerr)
+		}
+	}
 	if q.deleteAccountStmt != nil {
 		if cerr := q.deleteAccountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing deleteAccountStmt: %w", cerr)
 		}
 	}
+	if q.deleteIdempotencyKeyStmt != nil {
+		if cerr := q.deleteIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteIdempotencyKeyStmt: %w", cerr)
+		}
+	}
+	if q.deleteIdempotencyKeysBeforeStmt != nil {
+		if cerr := q.deleteIdempotencyKeysBeforeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteIdempotencyKeysBeforeStmt: %w", cerr)
+		}
+	}
+	if q.deleteWebhookSubscriptionStmt != nil {
+		if cerr := q.deleteWebhookSubscriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing deleteWebhookSubscriptionStmt: %w", cerr)
+		}
+	}
+	if q.finishJobRunStmt != nil {
+		if cerr := q.finishJobRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing finishJobRunStmt: %w", cerr)
+		}
+	}
 	if q.getAccountStmt != nil {
 		if cerr := q.getAccountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAccountStmt: %w", cerr)
 		}
 	}
+	if q.getAccountBalanceAtStmt != nil {
+		if cerr := q.getAccountBalanceAtStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAccountBalanceAtStmt: %w", cerr)
+		}
+	}
 	if q.getAccountForUpdateStmt != nil {
 		if cerr := q.getAccountForUpdateStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getAccountForUpdateStmt: %w", cerr)
 		}
 	}
-	if q.getAccountWithEmailStmt != nil {
-		if cerr := q.getAccountWithEmailStmt.Close(); cerr != nil {
-			err = fmt.Errorf("error closing getAccountWithEmailStmt: %w", cerr)
+	if q.getAccountInterestChangeSinceStmt != nil {
+		if cerr := q.getAccountInterestChangeSinceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getAccountInterestChangeSinceStmt: %w", cerr)
+		}
+	}
+	if q.getCustomerStmt != nil {
+		if cerr := q.getCustomerStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCustomerStmt: %w", cerr)
+		}
+	}
+	if q.getCustomerByEmailStmt != nil {
+		if cerr := q.getCustomerByEmailStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCustomerByEmailStmt: %w", cerr)
+		}
+	}
+	if q.getCustomerCredentialStmt != nil {
+		if cerr := q.getCustomerCredentialStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCustomerCredentialStmt: %w", cerr)
+		}
+	}
+	if q.getCustomerForUpdateStmt != nil {
+		if cerr := q.getCustomerForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getCustomerForUpdateStmt: %w", cerr)
 		}
 	}
 	if q.getEntryStmt != nil {
@@ -159,11 +562,51 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getEntryStmt: %w", cerr)
 		}
 	}
+	if q.getFxQuoteForUpdateStmt != nil {
+		if cerr := q.getFxQuoteForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getFxQuoteForUpdateStmt: %w", cerr)
+		}
+	}
+	if q.getIdempotencyKeyStmt != nil {
+		if cerr := q.getIdempotencyKeyStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getIdempotencyKeyStmt: %w", cerr)
+		}
+	}
+	if q.getInterestPayoutStmt != nil {
+		if cerr := q.getInterestPayoutStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getInterestPayoutStmt: %w", cerr)
+		}
+	}
+	if q.getJobRunStmt != nil {
+		if cerr := q.getJobRunStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getJobRunStmt: %w", cerr)
+		}
+	}
+	if q.getJournalStmt != nil {
+		if cerr := q.getJournalStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getJournalStmt: %w", cerr)
+		}
+	}
+	if q.getLatestFxRateStmt != nil {
+		if cerr := q.getLatestFxRateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getLatestFxRateStmt: %w", cerr)
+		}
+	}
+	if q.getPasswordResetByTokenForUpdateStmt != nil {
+		if cerr := q.getPasswordResetByTokenForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getPasswordResetByTokenForUpdateStmt: %w", cerr)
+		}
+	}
 	if q.getReferralCodeStmt != nil {
 		if cerr := q.getReferralCodeStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getReferralCodeStmt: %w", cerr)
 		}
 	}
+	if q.getReferralCodeForUpdateStmt != nil {
+		if cerr := q.getReferralCodeForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getReferralCodeForUpdateStmt: %w", cerr)
+		}
+	}
 	if q.getReferralCodesForReferrerAccountStmt != nil {
 		if cerr := q.getReferralCodesForReferrerAccountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getReferralCodesForReferrerAccountStmt: %w", cerr)
@@ -184,6 +627,16 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getReferralsByDateRangeStmt: %w", cerr)
 		}
 	}
+	if q.getSessionStmt != nil {
+		if cerr := q.getSessionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSessionStmt: %w", cerr)
+		}
+	}
+	if q.getSystemAccountForUpdateStmt != nil {
+		if cerr := q.getSystemAccountForUpdateStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getSystemAccountForUpdateStmt: %w", cerr)
+		}
+	}
 	if q.getTransferStmt != nil {
 		if cerr := q.getTransferStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing getTransferStmt: %w", cerr)
@@ -194,31 +647,211 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getUnusedReferralCodesStmt: %w", cerr)
 		}
 	}
+	if q.getWebhookSubscriptionStmt != nil {
+		if cerr := q.getWebhookSubscriptionStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing getWebhookSubscriptionStmt: %w", cerr)
+		}
+	}
+	if q.hasBeenReferredStmt != nil {
+		if cerr := q.hasBeenReferredStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing hasBeenReferredStmt: %w", cerr)
+		}
+	}
+	if q.hasInterestAccrualSinceStmt != nil {
+		if cerr := q.hasInterestAccrualSinceStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing hasInterestAccrualSinceStmt: %w", cerr)
+		}
+	}
 	if q.hasUnUsedCodeForReferrerAccountStmt != nil {
 		if cerr := q.hasUnUsedCodeForReferrerAccountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing hasUnUsedCodeForReferrerAccountStmt: %w", cerr)
 		}
 	}
+	if q.listAccountEntriesStmt != nil {
+		if cerr := q.listAccountEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAccountEntriesStmt: %w", cerr)
+		}
+	}
+	if q.listAccountEntrySumsStmt != nil {
+		if cerr := q.listAccountEntrySumsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAccountEntrySumsStmt: %w", cerr)
+		}
+	}
 	if q.listAccountsStmt != nil {
 		if cerr := q.listAccountsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listAccountsStmt: %w", cerr)
 		}
 	}
+	if q.listAccountsForInterestStmt != nil {
+		if cerr := q.listAccountsForInterestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAccountsForInterestStmt: %w", cerr)
+		}
+	}
+	if q.listAccountsWithExpiredExtraInterestStmt != nil {
+		if cerr := q.listAccountsWithExpiredExtraInterestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAccountsWithExpiredExtraInterestStmt: %w", cerr)
+		}
+	}
+	if q.listAccountsWithUnpaidInterestAccrualsStmt != nil {
+		if cerr := q.listAccountsWithUnpaidInterestAccrualsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAccountsWithUnpaidInterestAccrualsStmt: %w", cerr)
+		}
+	}
+	if q.listAuditEntriesStmt != nil {
+		if cerr := q.listAuditEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAuditEntriesStmt: %w", cerr)
+		}
+	}
+	if q.listCustomerAccountsStmt != nil {
+		if cerr := q.listCustomerAccountsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listCustomerAccountsStmt: %w", cerr)
+		}
+	}
+	if q.listEmailDeliveriesStmt != nil {
+		if cerr := q.listEmailDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listEmailDeliveriesStmt: %w", cerr)
+		}
+	}
 	if q.listEntriesStmt != nil {
 		if cerr := q.listEntriesStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listEntriesStmt: %w", cerr)
 		}
 	}
+	if q.listInterestAccrualsStmt != nil {
+		if cerr := q.listInterestAccrualsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listInterestAccrualsStmt: %w", cerr)
+		}
+	}
+	if q.listJobRunsStmt != nil {
+		if cerr := q.listJobRunsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listJobRunsStmt: %w", cerr)
+		}
+	}
+	if q.listJournalEntriesStmt != nil {
+		if cerr := q.listJournalEntriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listJournalEntriesStmt: %w", cerr)
+		}
+	}
+	if q.listJournalEntrySumsStmt != nil {
+		if cerr := q.listJournalEntrySumsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listJournalEntrySumsStmt: %w", cerr)
+		}
+	}
+	if q.listLatestFxRatesStmt != nil {
+		if cerr := q.listLatestFxRatesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listLatestFxRatesStmt: %w", cerr)
+		}
+	}
+	if q.listOutboxEventsStmt != nil {
+		if cerr := q.listOutboxEventsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listOutboxEventsStmt: %w", cerr)
+		}
+	}
+	if q.listReferrerAccountsByDateRangeStmt != nil {
+		if cerr := q.listReferrerAccountsByDateRangeStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listReferrerAccountsByDateRangeStmt: %w", cerr)
+		}
+	}
+	if q.listTransferEntryCountsStmt != nil {
+		if cerr := q.listTransferEntryCountsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listTransferEntryCountsStmt: %w", cerr)
+		}
+	}
 	if q.listTransfersStmt != nil {
 		if cerr := q.listTransfersStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listTransfersStmt: %w", cerr)
 		}
 	}
+	if q.listUnappliedReferralsStmt != nil {
+		if cerr := q.listUnappliedReferralsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listUnappliedReferralsStmt: %w", cerr)
+		}
+	}
+	if q.listWebhookDeliveriesStmt != nil {
+		if cerr := q.listWebhookDeliveriesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listWebhookDeliveriesStmt: %w", cerr)
+		}
+	}
+	if q.listWebhookSubscriptionsStmt != nil {
+		if cerr := q.listWebhookSubscriptionsStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listWebhookSubscriptionsStmt: %w", cerr)
+		}
+	}
+	if q.listWebhookSubscriptionsForEventStmt != nil {
+		if cerr := q.listWebhookSubscriptionsForEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listWebhookSubscriptionsForEventStmt: %w", cerr)
+		}
+	}
+	if q.markEmailDeliveryFailedStmt != nil {
+		if cerr := q.markEmailDeliveryFailedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markEmailDeliveryFailedStmt: %w", cerr)
+		}
+	}
+	if q.markEmailDeliverySentStmt != nil {
+		if cerr := q.markEmailDeliverySentStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markEmailDeliverySentStmt: %w", cerr)
+		}
+	}
+	if q.markFxQuoteUsedStmt != nil {
+		if cerr := q.markFxQuoteUsedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markFxQuoteUsedStmt: %w", cerr)
+		}
+	}
+	if q.markInterestAccrualsPaidStmt != nil {
+		if cerr := q.markInterestAccrualsPaidStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markInterestAccrualsPaidStmt: %w", cerr)
+		}
+	}
+	if q.markOutboxEventDeliveredStmt != nil {
+		if cerr := q.markOutboxEventDeliveredStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markOutboxEventDeliveredStmt: %w", cerr)
+		}
+	}
+	if q.markOutboxEventFailedStmt != nil {
+		if cerr := q.markOutboxEventFailedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markOutboxEventFailedStmt: %w", cerr)
+		}
+	}
 	if q.markReferralCodeUsedStmt != nil {
 		if cerr := q.markReferralCodeUsedStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing markReferralCodeUsedStmt: %w", cerr)
 		}
 	}
+	if q.markReferralExtraInterestAppliedStmt != nil {
+		if cerr := q.markReferralExtraInterestAppliedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markReferralExtraInterestAppliedStmt: %w", cerr)
+		}
+	}
+	if q.markWebhookDeliveryDeliveredStmt != nil {
+		if cerr := q.markWebhookDeliveryDeliveredStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markWebhookDeliveryDeliveredStmt: %w", cerr)
+		}
+	}
+	if q.markWebhookDeliveryFailedStmt != nil {
+		if cerr := q.markWebhookDeliveryFailedStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing markWebhookDeliveryFailedStmt: %w", cerr)
+		}
+	}
+	if q.requeueOutboxEventStmt != nil {
+		if cerr := q.requeueOutboxEventStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing requeueOutboxEventStmt: %w", cerr)
+		}
+	}
+	if q.resetExtraInterestStmt != nil {
+		if cerr := q.resetExtraInterestStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing resetExtraInterestStmt: %w", cerr)
+		}
+	}
+	if q.setCustomerPasswordStmt != nil {
+		if cerr := q.setCustomerPasswordStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setCustomerPasswordStmt: %w", cerr)
+		}
+	}
+	if q.setPasswordResetTokenStmt != nil {
+		if cerr := q.setPasswordResetTokenStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing setPasswordResetTokenStmt: %w", cerr)
+		}
+	}
 	if q.updateAccountStmt != nil {
 		if cerr := q.updateAccountStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing updateAccountStmt: %w", cerr)
@@ -229,6 +862,21 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing updateAccountInterestStmt: %w", cerr)
 		}
 	}
+	if q.updateAccountOverdraftLimitStmt != nil {
+		if cerr := q.updateAccountOverdraftLimitStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateAccountOverdraftLimitStmt: %w", cerr)
+		}
+	}
+	if q.updateCustomerCredentialRoleStmt != nil {
+		if cerr := q.updateCustomerCredentialRoleStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing updateCustomerCredentialRoleStmt: %w", cerr)
+		}
+	}
+	if q.usePasswordResetStmt != nil {
+		if cerr := q.usePasswordResetStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing usePasswordResetStmt: %w", cerr)
+		}
+	}
 	return err
 }
 
@@ -266,63 +914,225 @@ func (q *Queries) queryRow(ctx context.Context, stmt *sql.Stmt, query string, ar
 }
 
 type Queries struct {
-	db                                     DBTX
-	tx                                     *sql.Tx
-	addAccountBalanceStmt                  *sql.Stmt
-	createAccountStmt                      *sql.Stmt
-	createEntryStmt                        *sql.Stmt
-	createReferralCodeStmt                 *sql.Stmt
-	createReferralHistoryStmt              *sql.Stmt
-	createTransferStmt                     *sql.Stmt
-	deleteAccountStmt                      *sql.Stmt
-	getAccountStmt                         *sql.Stmt
-	getAccountForUpdateStmt                *sql.Stmt
-	getAccountWithEmailStmt                *sql.Stmt
-	getEntryStmt                           *sql.Stmt
-	getReferralCodeStmt                    *sql.Stmt
-	getReferralCodesForReferrerAccountStmt *sql.Stmt
-	getReferralHistoryStmt                 *sql.Stmt
-	getReferralHistoryByDateStmt           *sql.Stmt
-	getReferralsByDateRangeStmt            *sql.Stmt
-	getTransferStmt                        *sql.Stmt
-	getUnusedReferralCodesStmt             *sql.Stmt
-	hasUnUsedCodeForReferrerAccountStmt    *sql.Stmt
-	listAccountsStmt                       *sql.Stmt
-	listEntriesStmt                        *sql.Stmt
-	listTransfersStmt                      *sql.Stmt
-	markReferralCodeUsedStmt               *sql.Stmt
-	updateAccountStmt                      *sql.Stmt
-	updateAccountInterestStmt              *sql.Stmt
+	db                                         DBTX
+	tx                                         *sql.Tx
+	addAccountBalanceStmt                      *sql.Stmt
+	blockCustomerSessionsStmt                  *sql.Stmt
+	blockSessionStmt                           *sql.Stmt
+	claimEmailDeliveriesStmt                   *sql.Stmt
+	claimIdempotencyKeyStmt                    *sql.Stmt
+	claimJobRunStmt                            *sql.Stmt
+	claimOutboxEventsStmt                      *sql.Stmt
+	claimWebhookDeliveriesStmt                 *sql.Stmt
+	completeIdempotencyKeyStmt                 *sql.Stmt
+	createAccountStmt                          *sql.Stmt
+	createAccountInterestChangeStmt            *sql.Stmt
+	createAuditEntryStmt                       *sql.Stmt
+	createCustomerStmt                         *sql.Stmt
+	createCustomerCredentialStmt               *sql.Stmt
+	createEmailDeliveryStmt                    *sql.Stmt
+	createEntryStmt                            *sql.Stmt
+	createFxQuoteStmt                          *sql.Stmt
+	createFxRateStmt                           *sql.Stmt
+	createInterestAccrualStmt                  *sql.Stmt
+	createInterestPayoutStmt                   *sql.Stmt
+	createJournalStmt                          *sql.Stmt
+	createOutboxEventStmt                      *sql.Stmt
+	createPasswordResetStmt                    *sql.Stmt
+	createReferralCodeStmt                     *sql.Stmt
+	createReferralHistoryStmt                  *sql.Stmt
+	createSessionStmt                          *sql.Stmt
+	createTransferStmt                         *sql.Stmt
+	createWebhookDeliveryStmt                  *sql.Stmt
+	createWebhookSubscriptionStmt              *sql.Stmt
+	deleteAccountStmt                          *sql.Stmt
+	deleteIdempotencyKeyStmt                   *sql.Stmt
+	deleteIdempotencyKeysBeforeStmt            *sql.Stmt
+	deleteWebhookSubscriptionStmt              *sql.Stmt
+	finishJobRunStmt                           *sql.Stmt
+	getAccountStmt                             *sql.Stmt
+	getAccountBalanceAtStmt                    *sql.Stmt
+	getAccountForUpdateStmt                    *sql.Stmt
+	getAccountInterestChangeSinceStmt          *sql.Stmt
+	getCustomerStmt                            *sql.Stmt
+	getCustomerByEmailStmt                     *sql.Stmt
+	getCustomerCredentialStmt                  *sql.Stmt
+	getCustomerForUpdateStmt                   *sql.Stmt
+	getEntryStmt                               *sql.Stmt
+	getFxQuoteForUpdateStmt                    *sql.Stmt
+	getIdempotencyKeyStmt                      *sql.Stmt
+	getInterestPayoutStmt                      *sql.Stmt
+	getJobRunStmt                              *sql.Stmt
+	getJournalStmt                             *sql.Stmt
+	getLatestFxRateStmt                        *sql.Stmt
+	getPasswordResetByTokenForUpdateStmt       *sql.Stmt
+	getReferralCodeStmt                        *sql.Stmt
+	getReferralCodeForUpdateStmt               *sql.Stmt
+	getReferralCodesForReferrerAccountStmt     *sql.Stmt
+	getReferralHistoryStmt                     *sql.Stmt
+	getReferralHistoryByDateStmt               *sql.Stmt
+	getReferralsByDateRangeStmt                *sql.Stmt
+	getSessionStmt                             *sql.Stmt
+	getSystemAccountForUpdateStmt              *sql.Stmt
+	getTransferStmt                            *sql.Stmt
+	getUnusedReferralCodesStmt                 *sql.Stmt
+	getWebhookSubscriptionStmt                 *sql.Stmt
+	hasBeenReferredStmt                        *sql.Stmt
+	hasInterestAccrualSinceStmt                *sql.Stmt
+	hasUnUsedCodeForReferrerAccountStmt        *sql.Stmt
+	listAccountEntriesStmt                     *sql.Stmt
+	listAccountEntrySumsStmt                   *sql.Stmt
+	listAccountsStmt                           *sql.Stmt
+	listAccountsForInterestStmt                *sql.Stmt
+	listAccountsWithExpiredExtraInterestStmt   *sql.Stmt
+	listAccountsWithUnpaidInterestAccrualsStmt *sql.Stmt
+	listAuditEntriesStmt                       *sql.Stmt
+	listCustomerAccountsStmt                   *sql.Stmt
+	listEmailDeliveriesStmt                    *sql.Stmt
+	listEntriesStmt                            *sql.Stmt
+	listInterestAccrualsStmt                   *sql.Stmt
+	listJobRunsStmt                            *sql.Stmt
+	listJournalEntriesStmt                     *sql.Stmt
+	listJournalEntrySumsStmt                   *sql.Stmt
+	listLatestFxRatesStmt                      *sql.Stmt
+	listOutboxEventsStmt                       *sql.Stmt
+	listReferrerAccountsByDateRangeStmt        *sql.Stmt
+	listTransferEntryCountsStmt                *sql.Stmt
+	listTransfersStmt                          *sql.Stmt
+	listUnappliedReferralsStmt                 *sql.Stmt
+	listWebhookDeliveriesStmt                  *sql.Stmt
+	listWebhookSubscriptionsStmt               *sql.Stmt
+	listWebhookSubscriptionsForEventStmt       *sql.Stmt
+	markEmailDeliveryFailedStmt                *sql.Stmt
+	markEmailDeliverySentStmt                  *sql.Stmt
+	markFxQuoteUsedStmt                        *sql.Stmt
+	markInterestAccrualsPaidStmt               *sql.Stmt
+	markOutboxEventDeliveredStmt               *sql.Stmt
+	markOutboxEventFailedStmt                  *sql.Stmt
+	markReferralCodeUsedStmt                   *sql.Stmt
+	markReferralExtraInterestAppliedStmt       *sql.Stmt
+	markWebhookDeliveryDeliveredStmt           *sql.Stmt
+	markWebhookDeliveryFailedStmt              *sql.Stmt
+	requeueOutboxEventStmt                     *sql.Stmt
+	resetExtraInterestStmt                     *sql.Stmt
+	setCustomerPasswordStmt                    *sql.Stmt
+	setPasswordResetTokenStmt                  *sql.Stmt
+	updateAccountStmt                          *sql.Stmt
+	updateAccountInterestStmt                  *sql.Stmt
+	updateAccountOverdraftLimitStmt            *sql.Stmt
+	updateCustomerCredentialRoleStmt           *sql.Stmt
+	usePasswordResetStmt                       *sql.Stmt
 }
 
 func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 	return &Queries{
-		db:                                     tx,
-		tx:                                     tx,
-		addAccountBalanceStmt:                  q.addAccountBalanceStmt,
-		createAccountStmt:                      q.createAccountStmt,
-		createEntryStmt:                        q.createEntryStmt,
-		createReferralCodeStmt:                 q.createReferralCodeStmt,
-		createReferralHistoryStmt:              q.createReferralHistoryStmt,
-		createTransferStmt:                     q.createTransferStmt,
-		deleteAccountStmt:                      q.deleteAccountStmt,
-		getAccountStmt:                         q.getAccountStmt,
-		getAccountForUpdateStmt:                q.getAccountForUpdateStmt,
-		getAccountWithEmailStmt:                q.getAccountWithEmailStmt,
-		getEntryStmt:                           q.getEntryStmt,
-		getReferralCodeStmt:                    q.getReferralCodeStmt,
-		getReferralCodesForReferrerAccountStmt: q.getReferralCodesForReferrerAccountStmt,
-		getReferralHistoryStmt:                 q.getReferralHistoryStmt,
-		getReferralHistoryByDateStmt:           q.getReferralHistoryByDateStmt,
-		getReferralsByDateRangeStmt:            q.getReferralsByDateRangeStmt,
-		getTransferStmt:                        q.getTransferStmt,
-		getUnusedReferralCodesStmt:             q.getUnusedReferralCodesStmt,
-		hasUnUsedCodeForReferrerAccountStmt:    q.hasUnUsedCodeForReferrerAccountStmt,
-		listAccountsStmt:                       q.listAccountsStmt,
-		listEntriesStmt:                        q.listEntriesStmt,
-		listTransfersStmt:                      q.listTransfersStmt,
-		markReferralCodeUsedStmt:               q.markReferralCodeUsedStmt,
-		updateAccountStmt:                      q.updateAccountStmt,
-		updateAccountInterestStmt:              q.updateAccountInterestStmt,
+		db:                                         tx,
+		tx:                                         tx,
+		addAccountBalanceStmt:                      q.addAccountBalanceStmt,
+		blockCustomerSessionsStmt:                  q.blockCustomerSessionsStmt,
+		blockSessionStmt:                           q.blockSessionStmt,
+		claimEmailDeliveriesStmt:                   q.claimEmailDeliveriesStmt,
+		claimIdempotencyKeyStmt:                    q.claimIdempotencyKeyStmt,
+		claimJobRunStmt:                            q.claimJobRunStmt,
+		claimOutboxEventsStmt:                      q.claimOutboxEventsStmt,
+		claimWebhookDeliveriesStmt:                 q.claimWebhookDeliveriesStmt,
+		completeIdempotencyKeyStmt:                 q.completeIdempotencyKeyStmt,
+		createAccountStmt:                          q.createAccountStmt,
+		createAccountInterestChangeStmt:            q.createAccountInterestChangeStmt,
+		createAuditEntryStmt:                       q.createAuditEntryStmt,
+		createCustomerStmt:                         q.createCustomerStmt,
+		createCustomerCredentialStmt:               q.createCustomerCredentialStmt,
+		createEmailDeliveryStmt:                    q.createEmailDeliveryStmt,
+		createEntryStmt:                            q.createEntryStmt,
+		createFxQuoteStmt:                          q.createFxQuoteStmt,
+		createFxRateStmt:                           q.createFxRateStmt,
+		createInterestAccrualStmt:                  q.createInterestAccrualStmt,
+		createInterestPayoutStmt:                   q.createInterestPayoutStmt,
+		createJournalStmt:                          q.createJournalStmt,
+		createOutboxEventStmt:                      q.createOutboxEventStmt,
+		createPasswordResetStmt:                    q.createPasswordResetStmt,
+		createReferralCodeStmt:                     q.createReferralCodeStmt,
+		createReferralHistoryStmt:                  q.createReferralHistoryStmt,
+		createSessionStmt:                          q.createSessionStmt,
+		createTransferStmt:                         q.createTransferStmt,
+		createWebhookDeliveryStmt:                  q.createWebhookDeliveryStmt,
+		createWebhookSubscriptionStmt:              q.createWebhookSubscriptionStmt,
+		deleteAccountStmt:                          q.deleteAccountStmt,
+		deleteIdempotencyKeyStmt:                   q.deleteIdempotencyKeyStmt,
+		deleteIdempotencyKeysBeforeStmt:            q.deleteIdempotencyKeysBeforeStmt,
+		deleteWebhookSubscriptionStmt:              q.deleteWebhookSubscriptionStmt,
+		finishJobRunStmt:                           q.finishJobRunStmt,
+		getAccountStmt:                             q.getAccountStmt,
+		getAccountBalanceAtStmt:                    q.getAccountBalanceAtStmt,
+		getAccountForUpdateStmt:                    q.getAccountForUpdateStmt,
+		getAccountInterestChangeSinceStmt:          q.getAccountInterestChangeSinceStmt,
+		getCustomerStmt:                            q.getCustomerStmt,
+		getCustomerByEmailStmt:                     q.getCustomerByEmailStmt,
+		getCustomerCredentialStmt:                  q.getCustomerCredentialStmt,
+		getCustomerForUpdateStmt:                   q.getCustomerForUpdateStmt,
+		getEntryStmt:                               q.getEntryStmt,
+		getFxQuoteForUpdateStmt:                    q.getFxQuoteForUpdateStmt,
+		getIdempotencyKeyStmt:                      q.getIdempotencyKeyStmt,
+		getInterestPayoutStmt:                      q.getInterestPayoutStmt,
+		getJobRunStmt:                              q.getJobRunStmt,
+		getJournalStmt:                             q.getJournalStmt,
+		getLatestFxRateStmt:                        q.getLatestFxRateStmt,
+		getPasswordResetByTokenForUpdateStmt:       q.getPasswordResetByTokenForUpdateStmt,
+		getReferralCodeStmt:                        q.getReferralCodeStmt,
+		getReferralCodeForUpdateStmt:               q.getReferralCodeForUpdateStmt,
+		getReferralCodesForReferrerAccountStmt:     q.getReferralCodesForReferrerAccountStmt,
+		getReferralHistoryStmt:                     q.getReferralHistoryStmt,
+		getReferralHistoryByDateStmt:               q.getReferralHistoryByDateStmt,
+		getReferralsByDateRangeStmt:                q.getReferralsByDateRangeStmt,
+		getSessionStmt:                             q.getSessionStmt,
+		getSystemAccountForUpdateStmt:              q.getSystemAccountForUpdateStmt,
+		getTransferStmt:                            q.getTransferStmt,
+		getUnusedReferralCodesStmt:                 q.getUnusedReferralCodesStmt,
+		getWebhookSubscriptionStmt:                 q.getWebhookSubscriptionStmt,
+		hasBeenReferredStmt:                        q.hasBeenReferredStmt,
+		hasInterestAccrualSinceStmt:                q.hasInterestAccrualSinceStmt,
+		hasUnUsedCodeForReferrerAccountStmt:        q.hasUnUsedCodeForReferrerAccountStmt,
+		listAccountEntriesStmt:                     q.listAccountEntriesStmt,
+		listAccountEntrySumsStmt:                   q.listAccountEntrySumsStmt,
+		listAccountsStmt:                           q.listAccountsStmt,
+		listAccountsForInterestStmt:                q.listAccountsForInterestStmt,
+		listAccountsWithExpiredExtraInterestStmt:   q.listAccountsWithExpiredExtraInterestStmt,
+		listAccountsWithUnpaidInterestAccrualsStmt: q.listAccountsWithUnpaidInterestAccrualsStmt,
+		listAuditEntriesStmt:                       q.listAuditEntriesStmt,
+		listCustomerAccountsStmt:                   q.listCustomerAccountsStmt,
+		listEmailDeliveriesStmt:                    q.listEmailDeliveriesStmt,
+		listEntriesStmt:                            q.listEntriesStmt,
+		listInterestAccrualsStmt:                   q.listInterestAccrualsStmt,
+		listJobRunsStmt:                            q.listJobRunsStmt,
+		listJournalEntriesStmt:                     q.listJournalEntriesStmt,
+		listJournalEntrySumsStmt:                   q.listJournalEntrySumsStmt,
+		listLatestFxRatesStmt:                      q.listLatestFxRatesStmt,
+		listOutboxEventsStmt:                       q.listOutboxEventsStmt,
+		listReferrerAccountsByDateRangeStmt:        q.listReferrerAccountsByDateRangeStmt,
+		listTransferEntryCountsStmt:                q.listTransferEntryCountsStmt,
+		listTransfersStmt:                          q.listTransfersStmt,
+		listUnappliedReferralsStmt:                 q.listUnappliedReferralsStmt,
+		listWebhookDeliveriesStmt:                  q.listWebhookDeliveriesStmt,
+		listWebhookSubscriptionsStmt:               q.listWebhookSubscriptionsStmt,
+		listWebhookSubscriptionsForEventStmt:       q.listWebhookSubscriptionsForEventStmt,
+		markEmailDeliveryFailedStmt:                q.markEmailDeliveryFailedStmt,
+		markEmailDeliverySentStmt:                  q.markEmailDeliverySentStmt,
+		markFxQuoteUsedStmt:                        q.markFxQuoteUsedStmt,
+		markInterestAccrualsPaidStmt:               q.markInterestAccrualsPaidStmt,
+		markOutboxEventDeliveredStmt:               q.markOutboxEventDeliveredStmt,
+		markOutboxEventFailedStmt:                  q.markOutboxEventFailedStmt,
+		markReferralCodeUsedStmt:                   q.markReferralCodeUsedStmt,
+		markReferralExtraInterestAppliedStmt:       q.markReferralExtraInterestAppliedStmt,
+		markWebhookDeliveryDeliveredStmt:           q.markWebhookDeliveryDeliveredStmt,
+		markWebhookDeliveryFailedStmt:              q.markWebhookDeliveryFailedStmt,
+		requeueOutboxEventStmt:                     q.requeueOutboxEventStmt,
+		resetExtraInterestStmt:                     q.resetExtraInterestStmt,
+		setCustomerPasswordStmt:                    q.setCustomerPasswordStmt,
+		setPasswordResetTokenStmt:                  q.setPasswordResetTokenStmt,
+		updateAccountStmt:                          q.updateAccountStmt,
+		updateAccountInterestStmt:                  q.updateAccountInterestStmt,
+		updateAccountOverdraftLimitStmt:            q.updateAccountOverdraftLimitStmt,
+		updateCustomerCredentialRoleStmt:           q.updateCustomerCredentialRoleStmt,
+		usePasswordResetStmt:                       q.usePasswordResetStmt,
 	}
 }
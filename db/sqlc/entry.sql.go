@@ -7,36 +7,53 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
 account_id,
-amount
+amount,
+transfer_id,
+journal_id,
+reference_type
 ) VALUES (
-$1, $2
-) RETURNING id, account_id, amount, created_at
+$1, $2, $3, $4, $5
+) RETURNING id, account_id, amount, created_at, transfer_id, journal_id, reference_type
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID     int64         `json:"account_id"`
+	Amount        int64         `json:"amount"`
+	TransferID    sql.NullInt64 `json:"transfer_id"`
+	JournalID     sql.NullInt64 `json:"journal_id"`
+	ReferenceType string        `json:"reference_type"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.queryRow(ctx, q.createEntryStmt, createEntry, arg.AccountID, arg.Amount)
+	row := q.queryRow(ctx, q.createEntryStmt, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.JournalID,
+		arg.ReferenceType,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalID,
+		&i.ReferenceType,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, journal_id, reference_type FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,12 +65,107 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.JournalID,
+		&i.ReferenceType,
 	)
 	return i, err
 }
 
+const listAccountEntries = `-- name: ListAccountEntries :many
+SELECT e.id, e.account_id, e.amount, e.created_at, e.transfer_id, e.journal_id, e.reference_type,
+       c.id AS counterparty_account_id,
+       c.account_type AS counterparty_account_type,
+       p.period AS interest_period,
+       (a.balance - COALESCE((
+           SELECT SUM(later.amount) FROM entries later
+           WHERE later.account_id = e.account_id AND later.id > e.id
+       ), 0))::bigint AS running_balance
+FROM entries e
+JOIN accounts a ON a.id = e.account_id
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts c ON c.id = CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END
+LEFT JOIN interest_payouts p ON p.transfer_id = e.transfer_id
+WHERE e.account_id = $1
+  AND ($2::bigint IS NULL OR e.id < $2)
+  AND ($3::timestamptz IS NULL OR e.created_at >= $3)
+  AND ($4::timestamptz IS NULL OR e.created_at < $4)
+  AND ($5::text IS NULL
+    OR ($5 = 'credit' AND e.amount > 0)
+    OR ($5 = 'debit' AND e.amount < 0))
+ORDER BY e.id DESC
+LIMIT $6
+`
+
+type ListAccountEntriesParams struct {
+	AccountID int64          `json:"account_id"`
+	BeforeID  sql.NullInt64  `json:"before_id"`
+	FromTime  sql.NullTime   `json:"from_time"`
+	ToTime    sql.NullTime   `json:"to_time"`
+	Direction sql.NullString `json:"direction"`
+	PageSize  int32          `json:"page_size"`
+}
+
+type ListAccountEntriesRow struct {
+	ID                      int64          `json:"id"`
+	AccountID               int64          `json:"account_id"`
+	Amount                  int64          `json:"amount"`
+	CreatedAt               time.Time      `json:"created_at"`
+	TransferID              sql.NullInt64  `json:"transfer_id"`
+	JournalID               sql.NullInt64  `json:"journal_id"`
+	ReferenceType           string         `json:"reference_type"`
+	CounterpartyAccountID   sql.NullInt64  `json:"counterparty_account_id"`
+	CounterpartyAccountType sql.NullString `json:"counterparty_account_type"`
+	InterestPeriod          sql.NullString `json:"interest_period"`
+	RunningBalance          int64          `json:"running_balance"`
+}
+
+// newest first, the page after the before_id cursor; every row carries the balance right after
+// it, and the counterparty and interest period of the transfer that caused it
+func (q *Queries) ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error) {
+	rows, err := q.query(ctx, q.listAccountEntriesStmt, listAccountEntries,
+		arg.AccountID,
+		arg.BeforeID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.Direction,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountEntriesRow{}
+	for rows.Next() {
+		var i ListAccountEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalID,
+			&i.ReferenceType,
+			&i.CounterpartyAccountID,
+			&i.CounterpartyAccountType,
+			&i.InterestPeriod,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, journal_id, reference_type FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -80,6 +192,46 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalID,
+			&i.ReferenceType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJournalEntries = `-- name: ListJournalEntries :many
+SELECT id, account_id, amount, created_at, transfer_id, journal_id, reference_type FROM entries
+WHERE journal_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListJournalEntries(ctx context.Context, journalID sql.NullInt64) ([]Entry, error) {
+	rows, err := q.query(ctx, q.listJournalEntriesStmt, listJournalEntries, journalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.JournalID,
+			&i.ReferenceType,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,220 @@
+package sqlc
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Types of the domain events written to the outbox
+const (
+	EventAccountCreated       = "account.created"
+	EventTransferCompleted    = "transfer.completed"
+	EventReferralRedeemed     = "referral.redeemed"
+	EventExtraInterestUpdated = "account.extra_interest_updated"
+	EventBalanceChanged       = "account.balance_changed"
+)
+
+// EventPasswordResetRequested is an event only the bank itself handles, by emailing the customer
+// a token; it is not in EventTypes, so no webhook gets it
+const EventPasswordResetRequested = "customer.password_reset_requested"
+
+// EventTypes lists every type of domain event subscribers may get
+var EventTypes = []string{
+	EventAccountCreated,
+	EventTransferCompleted,
+	EventReferralRedeemed,
+	EventExtraInterestUpdated,
+	EventBalanceChanged,
+}
+
+// Aggregate types, the kind of row an event is about
+const (
+	AggregateAccount  = "account"
+	AggregateTransfer = "transfer"
+	AggregateReferral = "referral"
+	AggregateCustomer = "customer"
+)
+
+// Reasons the extra interest of an account changed
+const (
+	ExtraInterestReferralRedeemed = "referral_redeemed"
+	ExtraInterestRecalculated     = "referrals_recalculated"
+	ExtraInterestExpired          = "expired"
+)
+
+// AccountCreatedEvent is the payload of account.created, for a signup as well as for another
+// account opened by an existing customer
+type AccountCreatedEvent struct {
+	AccountID  int64     `json:"account_id"`
+	CustomerID int64     `json:"customer_id"`
+	Owner      string    `json:"owner"`
+	Email      string    `json:"email"`
+	Currency   string    `json:"currency"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TransferCompletedEvent is the payload of transfer.completed. Amount is in the currency of the
+// source account, ConvertedAmount in the currency of the destination account.
+type TransferCompletedEvent struct {
+	TransferID      int64     `json:"transfer_id"`
+	FromAccountID   int64     `json:"from_account_id"`
+	ToAccountID     int64     `json:"to_account_id"`
+	Amount          int64     `json:"amount"`
+	Currency        string    `json:"currency"`
+	ConvertedAmount int64     `json:"converted_amount"`
+	ToCurrency      string    `json:"to_currency"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ReferralRedeemedEvent is the payload of referral.redeemed, about the referral history row
+type ReferralRedeemedEvent struct {
+	ReferralHistoryID int64 `json:"referral_history_id"`
+	ReferralCodeID    int64 `json:"referral_code_id"`
+	ReferrerAccountID int64 `json:"referrer_account_id"`
+	ReferredAccountID int64 `json:"referred_account_id"`
+	// the referred account signed up with the code, rather than redeeming it later
+	Signup bool `json:"signup"`
+	// welcome bonus credited to the referred account in its currency, zero when there was none
+	BonusAmount int64     `json:"bonus_amount"`
+	RedeemedAt  time.Time `json:"redeemed_at"`
+}
+
+// ExtraInterestUpdatedEvent is the payload of account.extra_interest_updated. An extra interest
+// of zero means the account has none any more.
+type ExtraInterestUpdatedEvent struct {
+	AccountID     int64   `json:"account_id"`
+	ExtraInterest float64 `json:"extra_interest"`
+	// first day the extra interest applies to, empty when there is none
+	StartDate string `json:"start_date,omitempty"`
+	// months the extra interest stays in effect
+	Duration int32  `json:"duration"`
+	Reason   string `json:"reason"`
+}
+
+// BalanceChangedEvent is the payload of account.balance_changed, published for every entry
+// posted to a customer account: transfers in and out, bonuses, interest and adjustments
+type BalanceChangedEvent struct {
+	AccountID int64  `json:"account_id"`
+	Currency  string `json:"currency"`
+	// credited to the account, debited when negative
+	Amount int64 `json:"amount"`
+	// balance after the change
+	Balance int64 `json:"balance"`
+	// what caused the change, the reference type of the entry
+	ReferenceType string `json:"reference_type"`
+	JournalID     int64  `json:"journal_id"`
+	// zero when the change was not a transfer, e.g. an adjustment
+	TransferID int64     `json:"transfer_id,omitempty"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// PasswordResetRequestedEvent is the payload of customer.password_reset_requested. It carries no
+// token: the token is made when the email is sent, so it is never stored in the clear.
+type PasswordResetRequestedEvent struct {
+	PasswordResetID int64     `json:"password_reset_id"`
+	CustomerID      int64     `json:"customer_id"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+// publishEvent writes a domain event to the outbox. It runs in the transaction of the change
+// the event is about, so the event is recorded exactly when the change commits; the relay
+// delivers it from there.
+func publishEvent(ctx context.Context, q Querier, eventType string, aggregateType string, aggregateID int64, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       data,
+	})
+	return err
+}
+
+func publishAccountCreated(ctx context.Context, q Querier, account Account) error {
+	return publishEvent(ctx, q, EventAccountCreated, AggregateAccount, account.ID, AccountCreatedEvent{
+		AccountID:  account.ID,
+		CustomerID: account.CustomerID.Int64,
+		Owner:      account.Owner,
+		Email:      account.Email,
+		Currency:   account.Currency,
+		CreatedAt:  account.CreatedAt,
+	})
+}
+
+func publishTransferCompleted(ctx context.Context, q Querier, result TransferTxResult) error {
+	transfer := result.Transfer
+	converted := transfer.Amount
+	if transfer.ConvertedAmount.Valid {
+		converted = transfer.ConvertedAmount.Int64
+	}
+
+	return publishEvent(ctx, q, EventTransferCompleted, AggregateTransfer, transfer.ID, TransferCompletedEvent{
+		TransferID:      transfer.ID,
+		FromAccountID:   transfer.FromAccountID,
+		ToAccountID:     transfer.ToAccountID,
+		Amount:          transfer.Amount,
+		Currency:        result.FromAccount.Currency,
+		ConvertedAmount: converted,
+		ToCurrency:      result.ToAccount.Currency,
+		CreatedAt:       transfer.CreatedAt,
+	})
+}
+
+func publishReferralRedeemed(ctx context.Context, q Querier, history ReferralHistory, signup bool, bonusAmount int64) error {
+	return publishEvent(ctx, q, EventReferralRedeemed, AggregateReferral, history.ID, ReferralRedeemedEvent{
+		ReferralHistoryID: history.ID,
+		ReferralCodeID:    history.ReferralCodeID,
+		ReferrerAccountID: history.ReferrerAccountID,
+		ReferredAccountID: history.ReferredAccountID,
+		Signup:            signup,
+		BonusAmount:       bonusAmount,
+		RedeemedAt:        history.ReferralDate,
+	})
+}
+
+// publishExtraInterestUpdated takes the account as it is after the change
+func publishExtraInterestUpdated(ctx context.Context, q Querier, account Account, reason string) error {
+	event := ExtraInterestUpdatedEvent{
+		AccountID: account.ID,
+		Reason:    reason,
+	}
+	if account.ExtraInterest.Valid && account.ExtraInterest.Float64 > 0 {
+		event.ExtraInterest = account.ExtraInterest.Float64
+		event.Duration = account.ExtraInterestDuration
+		if account.ExtraInterestStartDate.Valid {
+			event.StartDate = account.ExtraInterestStartDate.Time.Format(time.DateOnly)
+		}
+	}
+
+	return publishEvent(ctx, q, EventExtraInterestUpdated, AggregateAccount, account.ID, event)
+}
+
+// publishBalanceChanged publishes the change of every customer account a journal posted to.
+// System accounts are left out, their balances are of no interest outside the bank.
+func publishBalanceChanged(ctx context.Context, q Querier, arg journalParams, result journalResult) error {
+	for i, account := range result.Accounts {
+		if !account.CustomerID.Valid {
+			continue
+		}
+
+		err := publishEvent(ctx, q, EventBalanceChanged, AggregateAccount, account.ID, BalanceChangedEvent{
+			AccountID:     account.ID,
+			Currency:      account.Currency,
+			Amount:        arg.Legs[i].Amount,
+			Balance:       account.Balance,
+			ReferenceType: arg.ReferenceType,
+			JournalID:     result.Journal.ID,
+			TransferID:    arg.TransferID.Int64,
+			ChangedAt:     result.Journal.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Reference types of an entry, what caused the balance change
+const (
+	ReferenceTransfer   = "transfer"
+	ReferenceBonus      = "bonus"
+	ReferenceInterest   = "interest"
+	ReferenceAdjustment = "adjustment"
+)
+
+// ErrInvalidJournal is returned for a journal whose legs don't sum to zero, or that has a leg
+// moving nothing.
+var ErrInvalidJournal = errors.New("invalid journal")
+
+// journalLeg is the amount an account is credited, or debited when negative
+type journalLeg struct {
+	AccountID int64
+	Amount    int64
+}
+
+type journalParams struct {
+	ReferenceType string
+	// the transfer the journal books, if any
+	TransferID sql.NullInt64
+	Memo       string
+	Legs       []journalLeg
+}
+
+type journalResult struct {
+	Journal Journal
+	// the entries and updated accounts, in the order of the legs
+	Entries  []Entry
+	Accounts []Account
+}
+
+// postJournal is the only way a balance changes. It records a journal with one entry per leg
+// and adds each leg to the balance of its account, so every balance is the sum of its entries.
+// Balances are updated lowest account ID first, so that journals over the same accounts running
+// concurrently cannot deadlock. Every customer account it posted to gets an
+// account.balance_changed event.
+func postJournal(ctx context.Context, q Querier, arg journalParams) (result journalResult, err error) {
+	var sum int64
+	for _, leg := range arg.Legs {
+		if leg.Amount == 0 {
+			return result, fmt.Errorf("%w: leg of account [%d] is zero", ErrInvalidJournal, leg.AccountID)
+		}
+		sum += leg.Amount
+	}
+	if len(arg.Legs) < 2 || sum != 0 {
+		return result, fmt.Errorf("%w: %d legs sum to %d", ErrInvalidJournal, len(arg.Legs), sum)
+	}
+
+	result.Journal, err = q.CreateJournal(ctx, arg.Memo)
+	if err != nil {
+		return
+	}
+
+	journalID := sql.NullInt64{Int64: result.Journal.ID, Valid: true}
+	result.Entries = make([]Entry, len(arg.Legs))
+	for i, leg := range arg.Legs {
+		result.Entries[i], err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:     leg.AccountID,
+			Amount:        leg.Amount,
+			TransferID:    arg.TransferID,
+			JournalID:     journalID,
+			ReferenceType: arg.ReferenceType,
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	order := make([]int, len(arg.Legs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return arg.Legs[order[a]].AccountID < arg.Legs[order[b]].AccountID
+	})
+
+	result.Accounts = make([]Account, len(arg.Legs))
+	for _, i := range order {
+		result.Accounts[i], err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     arg.Legs[i].AccountID,
+			Amount: arg.Legs[i].Amount,
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	err = publishBalanceChanged(ctx, q, arg, result)
+	return
+}
@@ -11,7 +11,7 @@ import (
 
 var testQueries *Queries
 var testDB *sql.DB
-var testStore *Store
+var testStore Store
 
 func TestMain(m *testing.M) {
 
@@ -0,0 +1,1861 @@
+package sqlc
+
+import (
+	"bank-api/apperr"
+	"bank-api/metrics"
+	"bank-api/sql/schema"
+	"context"
+	"database/sql"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// memData holds the rows of every table, keyed by primary key
+type memData struct {
+	seq             map[string]int64
+	accounts        map[int64]Account
+	customers       map[int64]Customer
+	credentials     map[int64]CustomerCredential
+	sessions        map[uuid.UUID]Session
+	entries         map[int64]Entry
+	transfers       map[int64]Transfer
+	referralCodes   map[int64]ReferralCode
+	referralHistory map[int64]ReferralHistory
+	jobRuns         map[int64]JobRun
+	accruals        map[int64]InterestAccrual
+	payouts         map[int64]InterestPayout
+	interestChanges map[int64]AccountInterestChange
+	auditEntries    map[int64]AuditEntry
+	journals        map[int64]Journal
+	fxRates         map[int64]FxRate
+	fxQuotes        map[uuid.UUID]FxQuote
+	idempotencyKeys map[idempotencyKeyID]IdempotencyKey
+	outboxEvents    map[int64]OutboxEvent
+	webhooks        map[int64]WebhookSubscription
+	deliveries      map[int64]WebhookDelivery
+	passwordResets  map[int64]PasswordReset
+	emails          map[int64]EmailDelivery
+}
+
+// idempotencyKeyID is the primary key of idempotency_keys
+type idempotencyKeyID struct {
+	scope string
+	key   string
+}
+
+func newMemData() *memData {
+	return &memData{
+		seq:             make(map[string]int64),
+		accounts:        make(map[int64]Account),
+		customers:       make(map[int64]Customer),
+		credentials:     make(map[int64]CustomerCredential),
+		sessions:        make(map[uuid.UUID]Session),
+		entries:         make(map[int64]Entry),
+		transfers:       make(map[int64]Transfer),
+		referralCodes:   make(map[int64]ReferralCode),
+		referralHistory: make(map[int64]ReferralHistory),
+		jobRuns:         make(map[int64]JobRun),
+		accruals:        make(map[int64]InterestAccrual),
+		payouts:         make(map[int64]InterestPayout),
+		interestChanges: make(map[int64]AccountInterestChange),
+		auditEntries:    make(map[int64]AuditEntry),
+		journals:        make(map[int64]Journal),
+		fxRates:         make(map[int64]FxRate),
+		fxQuotes:        make(map[uuid.UUID]FxQuote),
+		idempotencyKeys: make(map[idempotencyKeyID]IdempotencyKey),
+		outboxEvents:    make(map[int64]OutboxEvent),
+		webhooks:        make(map[int64]WebhookSubscription),
+		deliveries:      make(map[int64]WebhookDelivery),
+		passwordResets:  make(map[int64]PasswordReset),
+		emails:          make(map[int64]EmailDelivery),
+	}
+}
+
+func (data *memData) clone() *memData {
+	return &memData{
+		seq:             maps.Clone(data.seq),
+		accounts:        maps.Clone(data.accounts),
+		customers:       maps.Clone(data.customers),
+		credentials:     maps.Clone(data.credentials),
+		sessions:        maps.Clone(data.sessions),
+		entries:         maps.Clone(data.entries),
+		transfers:       maps.Clone(data.transfers),
+		referralCodes:   maps.Clone(data.referralCodes),
+		referralHistory: maps.Clone(data.referralHistory),
+		jobRuns:         maps.Clone(data.jobRuns),
+		accruals:        maps.Clone(data.accruals),
+		payouts:         maps.Clone(data.payouts),
+		interestChanges: maps.Clone(data.interestChanges),
+		auditEntries:    maps.Clone(data.auditEntries),
+		journals:        maps.Clone(data.journals),
+		fxRates:         maps.Clone(data.fxRates),
+		fxQuotes:        maps.Clone(data.fxQuotes),
+		idempotencyKeys: maps.Clone(data.idempotencyKeys),
+		outboxEvents:    maps.Clone(data.outboxEvents),
+		webhooks:        maps.Clone(data.webhooks),
+		deliveries:      maps.Clone(data.deliveries),
+		passwordResets:  maps.Clone(data.passwordResets),
+		emails:          maps.Clone(data.emails),
+	}
+}
+
+// deleteWebhook deletes a subscription along with its deliveries, like ON DELETE CASCADE
+func (data *memData) deleteWebhook(id int64) {
+	for deliveryID, delivery := range data.deliveries {
+		if delivery.SubscriptionID == id {
+			delete(data.deliveries, deliveryID)
+		}
+	}
+	delete(data.webhooks, id)
+}
+
+// nextID works like a bigserial column of the given table
+func (data *memData) nextID(table string) int64 {
+	data.seq[table]++
+	return data.seq[table]
+}
+
+// memQueries implements Querier on top of memData. Outside a transaction mu guards every call;
+// inside one the transaction already holds the lock and mu is nil.
+type memQueries struct {
+	mu   *sync.Mutex
+	data *memData
+}
+
+var _ Querier = (*memQueries)(nil)
+
+func (q *memQueries) lock() func() {
+	if q.mu == nil {
+		return func() {}
+	}
+	q.mu.Lock()
+	return q.mu.Unlock
+}
+
+// MemoryStore is a Store that keeps everything in memory. It checks the same unique and foreign
+// key constraints as the schema and runs transactions one at a time, so the handlers can be
+// tested without a database.
+type MemoryStore struct {
+	*memQueries
+	txStore
+	mu sync.Mutex
+}
+
+func NewMemoryStore() Store {
+	store := &MemoryStore{}
+	store.memQueries = &memQueries{mu: &store.mu, data: newMemData()}
+	store.txStore = txStore{execTx: store.execTx}
+	return store
+}
+
+// Ping never fails, there is no database to reach
+func (store *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// SchemaVersion is always the latest, the memory store has every table there is
+func (store *MemoryStore) SchemaVersion(ctx context.Context) (int64, error) {
+	return schema.LatestVersion()
+}
+
+func (store *MemoryStore) Stats() sql.DBStats {
+	return sql.DBStats{}
+}
+
+// execTx runs fn against a copy of the data and only keeps the copy when fn succeeds. Its errors
+// are translated like those of SQLStore.execTx.
+func (store *MemoryStore) execTx(ctx context.Context, fn func(Querier) error) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	q := &memQueries{data: store.data.clone()}
+	if err := fn(q); err != nil {
+		metrics.DBTransactions.WithLabelValues(metrics.TxRolledBack).Inc()
+		return apperr.FromDB(err)
+	}
+
+	store.data = q.data
+	metrics.DBTransactions.WithLabelValues(metrics.TxCommitted).Inc()
+	return nil
+}
+
+func uniqueViolation(constraint string) error {
+	return &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint", Constraint: constraint}
+}
+
+func foreignKeyViolation(constraint string) error {
+	return &pq.Error{Code: "23503", Message: "insert or update violates foreign key constraint", Constraint: constraint}
+}
+
+// stillReferenced is the foreign key violation of deleting a row other rows refer to
+func stillReferenced(constraint string) error {
+	return &pq.Error{Code: "23503", Message: "update or delete violates foreign key constraint", Constraint: constraint}
+}
+
+// toDate drops the time of day like a DATE column does
+func toDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// sortedByID returns the rows ordered by their bigserial primary key
+func sortedByID[V any](rows map[int64]V) []V {
+	ids := make([]int64, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	items := make([]V, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, rows[id])
+	}
+	return items
+}
+
+// withinDates tells whether the DATE column value lies in the inclusive range
+func withinDates(date time.Time, from time.Time, to time.Time) bool {
+	date = toDate(date)
+	return !date.Before(toDate(from)) && !date.After(toDate(to))
+}
+
+// page applies LIMIT and OFFSET to the rows
+func page[V any](items []V, limit int32, offset int32) []V {
+	if int(offset) >= len(items) {
+		return []V{}
+	}
+	items = items[offset:]
+	if int(limit) < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+func (q *memQueries) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	defer q.lock()()
+	account, ok := q.data.accounts[arg.ID]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	account.Balance += arg.Amount
+	q.data.accounts[account.ID] = account
+	return account, nil
+}
+
+func (q *memQueries) BlockCustomerSessions(ctx context.Context, customerID int64) error {
+	defer q.lock()()
+	for id, session := range q.data.sessions {
+		if session.CustomerID == customerID {
+			session.IsBlocked = true
+			q.data.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func (q *memQueries) BlockSession(ctx context.Context, id uuid.UUID) error {
+	defer q.lock()()
+	if session, ok := q.data.sessions[id]; ok {
+		session.IsBlocked = true
+		q.data.sessions[id] = session
+	}
+	return nil
+}
+
+func (q *memQueries) ClaimEmailDeliveries(ctx context.Context, arg ClaimEmailDeliveriesParams) ([]EmailDelivery, error) {
+	defer q.lock()()
+	items := []EmailDelivery{}
+	for _, email := range sortedByID(q.data.emails) {
+		if len(items) == int(arg.BatchSize) {
+			break
+		}
+		if email.Status == "pending" && !email.NextAttemptAt.After(arg.DueAt) {
+			email.NextAttemptAt = arg.LeasedUntil
+			q.data.emails[email.ID] = email
+			items = append(items, email)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (IdempotencyKey, error) {
+	defer q.lock()()
+	id := idempotencyKeyID{scope: arg.Scope, key: arg.Key}
+	record, ok := q.data.idempotencyKeys[id]
+	if !ok {
+		record = IdempotencyKey{
+			Scope:       arg.Scope,
+			Key:         arg.Key,
+			RequestHash: arg.RequestHash,
+			LockedAt:    arg.LockedAt,
+			CreatedAt:   arg.LockedAt,
+		}
+		q.data.idempotencyKeys[id] = record
+		return record, nil
+	}
+
+	if record.CompletedAt.Valid || record.RequestHash != arg.RequestHash || !record.LockedAt.Before(arg.StaleBefore) {
+		return IdempotencyKey{}, sql.ErrNoRows
+	}
+	record.LockedAt = arg.LockedAt
+	q.data.idempotencyKeys[id] = record
+	return record, nil
+}
+
+func (q *memQueries) ClaimJobRun(ctx context.Context, arg ClaimJobRunParams) (JobRun, error) {
+	defer q.lock()()
+	for id, run := range q.data.jobRuns {
+		if run.JobName != arg.JobName || run.Period != arg.Period {
+			continue
+		}
+		stale := run.Status == "running" && run.StartedAt.Before(arg.StaleBefore)
+		if run.Status != "failed" && !stale {
+			return JobRun{}, sql.ErrNoRows
+		}
+
+		run.Status = "running"
+		run.TriggeredBy = arg.TriggeredBy
+		run.Attempts++
+		run.Processed = 0
+		run.Error = sql.NullString{}
+		run.StartedAt = arg.StartedAt
+		run.FinishedAt = sql.NullTime{}
+		q.data.jobRuns[id] = run
+		return run, nil
+	}
+
+	run := JobRun{
+		ID:          q.data.nextID("job_runs"),
+		JobName:     arg.JobName,
+		Period:      arg.Period,
+		Status:      "running",
+		TriggeredBy: arg.TriggeredBy,
+		Attempts:    1,
+		StartedAt:   arg.StartedAt,
+	}
+	q.data.jobRuns[run.ID] = run
+	return run, nil
+}
+
+func (q *memQueries) ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]OutboxEvent, error) {
+	defer q.lock()()
+	items := []OutboxEvent{}
+	for _, event := range sortedByID(q.data.outboxEvents) {
+		if len(items) == int(arg.BatchSize) {
+			break
+		}
+		if event.Status == "pending" && !event.NextAttemptAt.After(arg.DueAt) {
+			event.NextAttemptAt = arg.LeasedUntil
+			q.data.outboxEvents[event.ID] = event
+			items = append(items, event)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	defer q.lock()()
+	items := []WebhookDelivery{}
+	for _, delivery := range sortedByID(q.data.deliveries) {
+		if len(items) == int(arg.BatchSize) {
+			break
+		}
+		if delivery.Status == "pending" && !delivery.NextAttemptAt.After(arg.DueAt) {
+			delivery.NextAttemptAt = arg.LeasedUntil
+			q.data.deliveries[delivery.ID] = delivery
+			items = append(items, delivery)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	defer q.lock()()
+	id := idempotencyKeyID{scope: arg.Scope, key: arg.Key}
+	if record, ok := q.data.idempotencyKeys[id]; ok {
+		record.ResponseCode = arg.ResponseCode
+		record.ResponseBody = arg.ResponseBody
+		record.CompletedAt = arg.CompletedAt
+		q.data.idempotencyKeys[id] = record
+	}
+	return nil
+}
+
+func (q *memQueries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	defer q.lock()()
+	if arg.CustomerID.Valid {
+		if _, ok := q.data.customers[arg.CustomerID.Int64]; !ok {
+			return Account{}, foreignKeyViolation("accounts_customer_id_fkey")
+		}
+		for _, account := range q.data.accounts {
+			if account.CustomerID == arg.CustomerID && account.Currency == arg.Currency {
+				return Account{}, uniqueViolation("accounts_customer_id_currency_key")
+			}
+		}
+	}
+
+	account := Account{
+		ID:                    q.data.nextID("accounts"),
+		Owner:                 arg.Owner,
+		Email:                 arg.Email,
+		ExtraInterest:         sql.NullFloat64{Float64: 0, Valid: true},
+		ExtraInterestDuration: 9,
+		Interest:              4.5,
+		Balance:               arg.Balance,
+		Currency:              arg.Currency,
+		CreatedAt:             arg.CreatedAt,
+		AccountType:           "customer",
+		CustomerID:            arg.CustomerID,
+	}
+	q.data.accounts[account.ID] = account
+	return account, nil
+}
+
+func (q *memQueries) CreateAccountInterestChange(ctx context.Context, arg CreateAccountInterestChangeParams) (AccountInterestChange, error) {
+	defer q.lock()()
+	if _, ok := q.data.accounts[arg.AccountID]; !ok {
+		return AccountInterestChange{}, foreignKeyViolation("account_interest_changes_account_id_fkey")
+	}
+
+	change := AccountInterestChange{
+		ID:                     q.data.nextID("account_interest_changes"),
+		AccountID:              arg.AccountID,
+		ExtraInterest:          arg.ExtraInterest,
+		ExtraInterestStartDate: arg.ExtraInterestStartDate,
+		ExtraInterestDuration:  arg.ExtraInterestDuration,
+		ChangedAt:              time.Now(),
+	}
+	if arg.ExtraInterestStartDate.Valid {
+		change.ExtraInterestStartDate.Time = toDate(arg.ExtraInterestStartDate.Time)
+	}
+	q.data.interestChanges[change.ID] = change
+	return change, nil
+}
+
+func (q *memQueries) CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) (AuditEntry, error) {
+	defer q.lock()()
+	if _, ok := q.data.accounts[arg.AccountID]; !ok {
+		return AuditEntry{}, foreignKeyViolation("audit_entries_account_id_fkey")
+	}
+
+	entry := AuditEntry{
+		ID:        q.data.nextID("audit_entries"),
+		AccountID: arg.AccountID,
+		Action:    arg.Action,
+		Detail:    arg.Detail,
+		CreatedAt: time.Now(),
+	}
+	q.data.auditEntries[entry.ID] = entry
+	return entry, nil
+}
+
+func (q *memQueries) CreateCustomer(ctx context.Context, arg CreateCustomerParams) (Customer, error) {
+	defer q.lock()()
+	for _, customer := range q.data.customers {
+		if customer.Email == arg.Email {
+			return Customer{}, uniqueViolation("customers_email_key")
+		}
+	}
+
+	customer := Customer{
+		ID:        q.data.nextID("customers"),
+		Email:     arg.Email,
+		Name:      arg.Name,
+		CreatedAt: arg.CreatedAt,
+	}
+	q.data.customers[customer.ID] = customer
+	return customer, nil
+}
+
+func (q *memQueries) CreateCustomerCredential(ctx context.Context, arg CreateCustomerCredentialParams) (CustomerCredential, error) {
+	defer q.lock()()
+	if _, ok := q.data.customers[arg.CustomerID]; !ok {
+		return CustomerCredential{}, foreignKeyViolation("customer_credentials_customer_id_fkey")
+	}
+	if _, ok := q.data.credentials[arg.CustomerID]; ok {
+		return CustomerCredential{}, uniqueViolation("customer_credentials_pkey")
+	}
+
+	credential := CustomerCredential{
+		CustomerID:        arg.CustomerID,
+		HashedPassword:    arg.HashedPassword,
+		PasswordChangedAt: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+		Role:              "depositor",
+		CreatedAt:         time.Now(),
+	}
+	q.data.credentials[credential.CustomerID] = credential
+	return credential, nil
+}
+
+func (q *memQueries) CreateEmailDelivery(ctx context.Context, arg CreateEmailDeliveryParams) error {
+	defer q.lock()()
+	if _, ok := q.data.outboxEvents[arg.OutboxEventID]; !ok {
+		return foreignKeyViolation("email_deliveries_outbox_event_id_fkey")
+	}
+	if _, ok := q.data.customers[arg.CustomerID]; !ok {
+		return foreignKeyViolation("email_deliveries_customer_id_fkey")
+	}
+	if arg.AccountID.Valid {
+		if _, ok := q.data.accounts[arg.AccountID.Int64]; !ok {
+			return foreignKeyViolation("email_deliveries_account_id_fkey")
+		}
+	}
+	for _, email := range q.data.emails {
+		if email.OutboxEventID == arg.OutboxEventID && email.Kind == arg.Kind && email.CustomerID == arg.CustomerID {
+			return nil
+		}
+	}
+
+	now := time.Now()
+	email := EmailDelivery{
+		ID:            q.data.nextID("email_deliveries"),
+		OutboxEventID: arg.OutboxEventID,
+		Kind:          arg.Kind,
+		CustomerID:    arg.CustomerID,
+		AccountID:     arg.AccountID,
+		Payload:       arg.Payload,
+		Status:        "pending",
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	q.data.emails[email.ID] = email
+	return nil
+}
+
+func (q *memQueries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
+	defer q.lock()()
+	if _, ok := q.data.accounts[arg.AccountID]; !ok {
+		return Entry{}, foreignKeyViolation("entries_account_id_fkey")
+	}
+	if _, ok := q.data.transfers[arg.TransferID.Int64]; arg.TransferID.Valid && !ok {
+		return Entry{}, foreignKeyViolation("entries_transfer_id_fkey")
+	}
+	if _, ok := q.data.journals[arg.JournalID.Int64]; arg.JournalID.Valid && !ok {
+		return Entry{}, foreignKeyViolation("entries_journal_id_fkey")
+	}
+
+	entry := Entry{
+		ID:            q.data.nextID("entries"),
+		AccountID:     arg.AccountID,
+		Amount:        arg.Amount,
+		CreatedAt:     time.Now(),
+		TransferID:    arg.TransferID,
+		JournalID:     arg.JournalID,
+		ReferenceType: arg.ReferenceType,
+	}
+	q.data.entries[entry.ID] = entry
+	return entry, nil
+}
+
+func (q *memQueries) CreateFxQuote(ctx context.Context, arg CreateFxQuoteParams) (FxQuote, error) {
+	defer q.lock()()
+	if _, ok := q.data.accounts[arg.AccountID]; !ok {
+		return FxQuote{}, foreignKeyViolation("fx_quotes_account_id_fkey")
+	}
+	if _, ok := q.data.fxQuotes[arg.ID]; ok {
+		return FxQuote{}, uniqueViolation("fx_quotes_pkey")
+	}
+
+	quote := FxQuote{
+		ID:              arg.ID,
+		AccountID:       arg.AccountID,
+		FromCurrency:    arg.FromCurrency,
+		ToCurrency:      arg.ToCurrency,
+		Rate:            arg.Rate,
+		Amount:          arg.Amount,
+		ConvertedAmount: arg.ConvertedAmount,
+		ExpiresAt:       arg.ExpiresAt,
+		CreatedAt:       time.Now(),
+	}
+	q.data.fxQuotes[quote.ID] = quote
+	return quote, nil
+}
+
+func (q *memQueries) CreateFxRate(ctx context.Context, arg CreateFxRateParams) (FxRate, error) {
+	defer q.lock()()
+	rate := FxRate{
+		ID:            q.data.nextID("fx_rates"),
+		BaseCurrency:  arg.BaseCurrency,
+		QuoteCurrency: arg.QuoteCurrency,
+		Rate:          arg.Rate,
+		Source:        arg.Source,
+		CreatedAt:     time.Now(),
+	}
+	q.data.fxRates[rate.ID] = rate
+	return rate, nil
+}
+
+func (q *memQueries) CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error) {
+	defer q.lock()()
+	if _, ok := q.data.accounts[arg.AccountID]; !ok {
+		return InterestAccrual{}, foreignKeyViolation("interest_accruals_account_id_fkey")
+	}
+	for _, accrual := range q.data.accruals {
+		if accrual.AccountID == arg.AccountID && accrual.AccrualDate.Equal(toDate(arg.AccrualDate)) {
+			return InterestAccrual{}, sql.ErrNoRows
+		}
+	}
+
+	accrual := InterestAccrual{
+		ID:          q.data.nextID("interest_accruals"),
+		AccountID:   arg.AccountID,
+		AccrualDate: toDate(arg.AccrualDate),
+		Balance:     arg.Balance,
+		AnnualRate:  arg.AnnualRate,
+		AmountMicro: arg.AmountMicro,
+		CreatedAt:   time.Now(),
+	}
+	q.data.accruals[accrual.ID] = accrual
+	return accrual, nil
+}
+
+func (q *memQueries) CreateInterestPayout(ctx context.Context, arg CreateInterestPayoutParams) (InterestPayout, error) {
+	defer q.lock()()
+	if _, ok := q.data.accounts[arg.AccountID]; !ok {
+		return InterestPayout{}, foreignKeyViolation("interest_payouts_account_id_fkey")
+	}
+	if _, ok := q.data.transfers[arg.TransferID.Int64]; arg.TransferID.Valid && !ok {
+		return InterestPayout{}, foreignKeyViolation("interest_payouts_transfer_id_fkey")
+	}
+	for _, payout := range q.data.payouts {
+		if payout.AccountID == arg.AccountID && payout.Period == arg.Period {
+			return InterestPayout{}, sql.ErrNoRows
+		}
+	}
+
+	payout := InterestPayout{
+		ID:         q.data.nextID("interest_payouts"),
+		AccountID:  arg.AccountID,
+		Period:     arg.Period,
+		Amount:     arg.Amount,
+		TransferID: arg.TransferID,
+		CreatedAt:  time.Now(),
+	}
+	q.data.payouts[payout.ID] = payout
+	return payout, nil
+}
+
+func (q *memQueries) CreateJournal(ctx context.Context, memo string) (Journal, error) {
+	defer q.lock()()
+	journal := Journal{
+		ID:        q.data.nextID("journals"),
+		Memo:      memo,
+		CreatedAt: time.Now(),
+	}
+	q.data.journals[journal.ID] = journal
+	return journal, nil
+}
+
+func (q *memQueries) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error) {
+	defer q.lock()()
+	now := time.Now()
+	event := OutboxEvent{
+		ID:            q.data.nextID("outbox_events"),
+		EventType:     arg.EventType,
+		AggregateType: arg.AggregateType,
+		AggregateID:   arg.AggregateID,
+		Payload:       arg.Payload,
+		Status:        "pending",
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	q.data.outboxEvents[event.ID] = event
+	return event, nil
+}
+
+func (q *memQueries) CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error) {
+	defer q.lock()()
+	if _, ok := q.data.customers[arg.CustomerID]; !ok {
+		return PasswordReset{}, foreignKeyViolation("password_resets_customer_id_fkey")
+	}
+
+	reset := PasswordReset{
+		ID:         q.data.nextID("password_resets"),
+		CustomerID: arg.CustomerID,
+		ExpiresAt:  arg.ExpiresAt,
+		CreatedAt:  arg.CreatedAt,
+	}
+	q.data.passwordResets[reset.ID] = reset
+	return reset, nil
+}
+
+func (q *memQueries) CreateReferralCode(ctx context.Context, arg CreateReferralCodeParams) (ReferralCode, error) {
+	defer q.lock()()
+	if _, ok := q.data.accounts[arg.ReferrerAccountID]; !ok {
+		return ReferralCode{}, foreignKeyViolation("referral_codes_referrer_account_id_fkey")
+	}
+	for _, code := range q.data.referralCodes {
+		if code.ReferralCode == arg.ReferralCode {
+			return ReferralCode{}, uniqueViolation("referral_codes_referral_code_key")
+		}
+	}
+
+	code := ReferralCode{
+		ID:                q.data.nextID("referral_codes"),
+		ReferralCode:      arg.ReferralCode,
+		ReferrerAccountID: arg.ReferrerAccountID,
+		CreatedAt:         arg.CreatedAt,
+	}
+	q.data.referralCodes[code.ID] = code
+	return code, nil
+}
+
+func (q *memQueries) CreateReferralHistory(ctx context.Context, arg CreateReferralHistoryParams) (ReferralHistory, error) {
+	defer q.lock()()
+	if _, ok := q.data.accounts[arg.ReferrerAccountID]; !ok {
+		return ReferralHistory{}, foreignKeyViolation("referral_history_referrer_account_id_fkey")
+	}
+	if _, ok := q.data.accounts[arg.ReferredAccountID]; !ok {
+		return ReferralHistory{}, foreignKeyViolation("referral_history_referred_account_id_fkey")
+	}
+	if _, ok := q.data.referralCodes[arg.ReferralCodeID]; !ok {
+		return ReferralHistory{}, foreignKeyViolation("referral_history_referral_code_id_fkey")
+	}
+	if arg.ReferredCustomerID.Valid {
+		if _, ok := q.data.customers[arg.ReferredCustomerID.Int64]; !ok {
+			return ReferralHistory{}, foreignKeyViolation("referral_history_referred_customer_id_fkey")
+		}
+		for _, history := range q.data.referralHistory {
+			if history.ReferredCustomerID == arg.ReferredCustomerID {
+				return ReferralHistory{}, uniqueViolation("referral_history_referred_customer_id_key")
+			}
+		}
+	}
+
+	history := ReferralHistory{
+		ID:                     q.data.nextID("referral_history"),
+		ReferrerAccountID:      arg.ReferrerAccountID,
+		ReferredAccountID:      arg.ReferredAccountID,
+		ReferralCodeID:         arg.ReferralCodeID,
+		ReferralDate:           toDate(arg.ReferralDate),
+		CreatedAt:              arg.CreatedAt,
+		ReferredCustomerID:     arg.ReferredCustomerID,
+		ExtraInterestAppliedAt: arg.ExtraInterestAppliedAt,
+	}
+	q.data.referralHistory[history.ID] = history
+	return history, nil
+}
+
+func (q *memQueries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	defer q.lock()()
+	if _, ok := q.data.customers[arg.CustomerID]; !ok {
+		return Session{}, foreignKeyViolation("sessions_customer_id_fkey")
+	}
+	if _, ok := q.data.sessions[arg.ID]; ok {
+		return Session{}, uniqueViolation("sessions_pkey")
+	}
+
+	session := Session{
+		ID:           arg.ID,
+		RefreshToken: arg.RefreshToken,
+		UserAgent:    arg.UserAgent,
+		ClientIp:     arg.ClientIp,
+		IsBlocked:    arg.IsBlocked,
+		ExpiresAt:    arg.ExpiresAt,
+		CreatedAt:    time.Now(),
+		CustomerID:   arg.CustomerID,
+	}
+	q.data.sessions[session.ID] = session
+	return session, nil
+}
+
+func (q *memQueries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	defer q.lock()()
+	if _, ok := q.data.accounts[arg.FromAccountID]; !ok {
+		return Transfer{}, foreignKeyViolation("transfers_from_account_id_fkey")
+	}
+	if _, ok := q.data.accounts[arg.ToAccountID]; !ok {
+		return Transfer{}, foreignKeyViolation("transfers_to_account_id_fkey")
+	}
+
+	if arg.FxQuoteID.Valid {
+		if _, ok := q.data.fxQuotes[arg.FxQuoteID.UUID]; !ok {
+			return Transfer{}, foreignKeyViolation("transfers_fx_quote_id_fkey")
+		}
+		for _, transfer := range q.data.transfers {
+			if transfer.FxQuoteID == arg.FxQuoteID {
+				return Transfer{}, uniqueViolation("transfers_fx_quote_id_key")
+			}
+		}
+	}
+
+	transfer := Transfer{
+		ID:              q.data.nextID("transfers"),
+		FromAccountID:   arg.FromAccountID,
+		ToAccountID:     arg.ToAccountID,
+		Amount:          arg.Amount,
+		CreatedAt:       time.Now(),
+		FxQuoteID:       arg.FxQuoteID,
+		FxRate:          arg.FxRate,
+		ConvertedAmount: arg.ConvertedAmount,
+	}
+	q.data.transfers[transfer.ID] = transfer
+	return transfer, nil
+}
+
+func (q *memQueries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error {
+	defer q.lock()()
+	if _, ok := q.data.webhooks[arg.SubscriptionID]; !ok {
+		return foreignKeyViolation("webhook_deliveries_subscription_id_fkey")
+	}
+	if _, ok := q.data.outboxEvents[arg.OutboxEventID]; !ok {
+		return foreignKeyViolation("webhook_deliveries_outbox_event_id_fkey")
+	}
+	for _, delivery := range q.data.deliveries {
+		if delivery.SubscriptionID == arg.SubscriptionID && delivery.OutboxEventID == arg.OutboxEventID {
+			return nil
+		}
+	}
+
+	now := time.Now()
+	delivery := WebhookDelivery{
+		ID:             q.data.nextID("webhook_deliveries"),
+		SubscriptionID: arg.SubscriptionID,
+		OutboxEventID:  arg.OutboxEventID,
+		EventType:      arg.EventType,
+		Payload:        arg.Payload,
+		Status:         "pending",
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}
+	q.data.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (q *memQueries) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	defer q.lock()()
+	if arg.AccountID.Valid {
+		if _, ok := q.data.accounts[arg.AccountID.Int64]; !ok {
+			return WebhookSubscription{}, foreignKeyViolation("webhook_subscriptions_account_id_fkey")
+		}
+	}
+
+	webhook := WebhookSubscription{
+		ID:         q.data.nextID("webhook_subscriptions"),
+		AccountID:  arg.AccountID,
+		Url:        arg.Url,
+		EventTypes: slices.Clone(arg.EventTypes),
+		Secret:     arg.Secret,
+		CreatedAt:  time.Now(),
+	}
+	if webhook.EventTypes == nil {
+		webhook.EventTypes = []string{}
+	}
+	q.data.webhooks[webhook.ID] = webhook
+	return webhook, nil
+}
+
+func (q *memQueries) DeleteAccount(ctx context.Context, id int64) error {
+	defer q.lock()()
+	for _, entry := range q.data.entries {
+		if entry.AccountID == id {
+			return stillReferenced("entries_account_id_fkey")
+		}
+	}
+	for _, transfer := range q.data.transfers {
+		if transfer.FromAccountID == id || transfer.ToAccountID == id {
+			return stillReferenced("transfers_from_account_id_fkey")
+		}
+	}
+	for _, code := range q.data.referralCodes {
+		if code.ReferrerAccountID == id {
+			return stillReferenced("referral_codes_referrer_account_id_fkey")
+		}
+	}
+	for _, history := range q.data.referralHistory {
+		if history.ReferrerAccountID == id || history.ReferredAccountID == id {
+			return stillReferenced("referral_history_referrer_account_id_fkey")
+		}
+	}
+
+	for _, webhook := range q.data.webhooks {
+		if webhook.AccountID.Valid && webhook.AccountID.Int64 == id {
+			q.data.deleteWebhook(webhook.ID)
+		}
+	}
+	for emailID, email := range q.data.emails {
+		if email.AccountID.Valid && email.AccountID.Int64 == id {
+			delete(q.data.emails, emailID)
+		}
+	}
+
+	delete(q.data.accounts, id)
+	return nil
+}
+
+func (q *memQueries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	defer q.lock()()
+	delete(q.data.idempotencyKeys, idempotencyKeyID{scope: arg.Scope, key: arg.Key})
+	return nil
+}
+
+func (q *memQueries) DeleteIdempotencyKeysBefore(ctx context.Context, createdAt time.Time) (int64, error) {
+	defer q.lock()()
+	var deleted int64
+	for id, record := range q.data.idempotencyKeys {
+		if record.CreatedAt.Before(createdAt) {
+			delete(q.data.idempotencyKeys, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (q *memQueries) DeleteWebhookSubscription(ctx context.Context, id int64) error {
+	defer q.lock()()
+	q.data.deleteWebhook(id)
+	return nil
+}
+
+func (q *memQueries) FinishJobRun(ctx context.Context, arg FinishJobRunParams) (JobRun, error) {
+	defer q.lock()()
+	run, ok := q.data.jobRuns[arg.ID]
+	if !ok || run.Attempts != arg.Attempts || run.Status != "running" {
+		return JobRun{}, sql.ErrNoRows
+	}
+	run.Status = arg.Status
+	run.Processed = arg.Processed
+	run.Error = arg.Error
+	run.FinishedAt = arg.FinishedAt
+	q.data.jobRuns[run.ID] = run
+	return run, nil
+}
+
+func (q *memQueries) GetAccount(ctx context.Context, id int64) (Account, error) {
+	defer q.lock()()
+	account, ok := q.data.accounts[id]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	return account, nil
+}
+
+func (q *memQueries) GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error) {
+	defer q.lock()()
+	account, ok := q.data.accounts[arg.ID]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+
+	balance := account.Balance
+	for _, entry := range q.data.entries {
+		if entry.AccountID == arg.ID && !entry.CreatedAt.Before(arg.At) {
+			balance -= entry.Amount
+		}
+	}
+	return balance, nil
+}
+
+func (q *memQueries) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
+	return q.GetAccount(ctx, id)
+}
+
+func (q *memQueries) GetAccountInterestChangeSince(ctx context.Context, arg GetAccountInterestChangeSinceParams) (AccountInterestChange, error) {
+	defer q.lock()()
+	// IDs follow the order of the changes
+	for _, change := range sortedByID(q.data.interestChanges) {
+		if change.AccountID == arg.AccountID && !change.ChangedAt.Before(arg.ChangedAt) {
+			return change, nil
+		}
+	}
+	return AccountInterestChange{}, sql.ErrNoRows
+}
+
+func (q *memQueries) GetCustomer(ctx context.Context, id int64) (Customer, error) {
+	defer q.lock()()
+	customer, ok := q.data.customers[id]
+	if !ok {
+		return Customer{}, sql.ErrNoRows
+	}
+	return customer, nil
+}
+
+func (q *memQueries) GetCustomerByEmail(ctx context.Context, email string) (Customer, error) {
+	defer q.lock()()
+	for _, customer := range q.data.customers {
+		if customer.Email == email {
+			return customer, nil
+		}
+	}
+	return Customer{}, sql.ErrNoRows
+}
+
+func (q *memQueries) GetCustomerCredential(ctx context.Context, customerID int64) (CustomerCredential, error) {
+	defer q.lock()()
+	credential, ok := q.data.credentials[customerID]
+	if !ok {
+		return CustomerCredential{}, sql.ErrNoRows
+	}
+	return credential, nil
+}
+
+// GetCustomerForUpdate needs no row lock, since transactions already run one at a time
+func (q *memQueries) GetCustomerForUpdate(ctx context.Context, id int64) (Customer, error) {
+	return q.GetCustomer(ctx, id)
+}
+
+func (q *memQueries) GetEntry(ctx context.Context, id int64) (Entry, error) {
+	defer q.lock()()
+	entry, ok := q.data.entries[id]
+	if !ok {
+		return Entry{}, sql.ErrNoRows
+	}
+	return entry, nil
+}
+
+func (q *memQueries) GetFxQuoteForUpdate(ctx context.Context, id uuid.UUID) (FxQuote, error) {
+	defer q.lock()()
+	quote, ok := q.data.fxQuotes[id]
+	if !ok {
+		return FxQuote{}, sql.ErrNoRows
+	}
+	return quote, nil
+}
+
+func (q *memQueries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	defer q.lock()()
+	record, ok := q.data.idempotencyKeys[idempotencyKeyID{scope: arg.Scope, key: arg.Key}]
+	if !ok {
+		return IdempotencyKey{}, sql.ErrNoRows
+	}
+	return record, nil
+}
+
+func (q *memQueries) GetInterestPayout(ctx context.Context, arg GetInterestPayoutParams) (InterestPayout, error) {
+	defer q.lock()()
+	for _, payout := range q.data.payouts {
+		if payout.AccountID == arg.AccountID && payout.Period == arg.Period {
+			return payout, nil
+		}
+	}
+	return InterestPayout{}, sql.ErrNoRows
+}
+
+func (q *memQueries) GetJobRun(ctx context.Context, arg GetJobRunParams) (JobRun, error) {
+	defer q.lock()()
+	for _, run := range q.data.jobRuns {
+		if run.JobName == arg.JobName && run.Period == arg.Period {
+			return run, nil
+		}
+	}
+	return JobRun{}, sql.ErrNoRows
+}
+
+func (q *memQueries) GetJournal(ctx context.Context, id int64) (Journal, error) {
+	defer q.lock()()
+	journal, ok := q.data.journals[id]
+	if !ok {
+		return Journal{}, sql.ErrNoRows
+	}
+	return journal, nil
+}
+
+func (q *memQueries) GetLatestFxRate(ctx context.Context, arg GetLatestFxRateParams) (FxRate, error) {
+	defer q.lock()()
+	rates := sortedByID(q.data.fxRates)
+	for i := len(rates) - 1; i >= 0; i-- {
+		if rates[i].BaseCurrency == arg.BaseCurrency && rates[i].QuoteCurrency == arg.QuoteCurrency {
+			return rates[i], nil
+		}
+	}
+	return FxRate{}, sql.ErrNoRows
+}
+
+// GetPasswordResetByTokenForUpdate needs no row lock, since transactions already run one at a time
+func (q *memQueries) GetPasswordResetByTokenForUpdate(ctx context.Context, tokenHash sql.NullString) (PasswordReset, error) {
+	defer q.lock()()
+	for _, reset := range q.data.passwordResets {
+		if tokenHash.Valid && reset.TokenHash == tokenHash {
+			return reset, nil
+		}
+	}
+	return PasswordReset{}, sql.ErrNoRows
+}
+
+func (q *memQueries) GetReferralCode(ctx context.Context, referralCode string) (ReferralCode, error) {
+	defer q.lock()()
+	for _, code := range q.data.referralCodes {
+		if code.ReferralCode == referralCode {
+			return code, nil
+		}
+	}
+	return ReferralCode{}, sql.ErrNoRows
+}
+
+// GetReferralCodeForUpdate needs no row lock, since transactions already run one at a time
+func (q *memQueries) GetReferralCodeForUpdate(ctx context.Context, referralCode string) (ReferralCode, error) {
+	return q.GetReferralCode(ctx, referralCode)
+}
+
+func (q *memQueries) GetReferralCodesForReferrerAccount(ctx context.Context, referrerAccountID int64) ([]ReferralCode, error) {
+	defer q.lock()()
+	items := []ReferralCode{}
+	for _, code := range sortedByID(q.data.referralCodes) {
+		if code.ReferrerAccountID == referrerAccountID {
+			items = append(items, code)
+		}
+	}
+	return page(items, 10, 0), nil
+}
+
+func (q *memQueries) GetReferralHistory(ctx context.Context, referrerAccountID int64) ([]ReferralHistory, error) {
+	defer q.lock()()
+	items := []ReferralHistory{}
+	for _, history := range sortedByID(q.data.referralHistory) {
+		if history.ReferrerAccountID == referrerAccountID {
+			items = append(items, history)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b ReferralHistory) int {
+		return a.ReferralDate.Compare(b.ReferralDate)
+	})
+	return items, nil
+}
+
+func (q *memQueries) GetReferralHistoryByDate(ctx context.Context, arg GetReferralHistoryByDateParams) ([]ReferralHistory, error) {
+	histories, err := q.GetReferralHistory(ctx, arg.ReferrerAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []ReferralHistory{}
+	for _, history := range histories {
+		if !history.ReferralDate.Before(arg.ReferralDate) && !history.ReferralDate.After(arg.ReferralDate_2) {
+			items = append(items, history)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) GetReferralsByDateRange(ctx context.Context, arg GetReferralsByDateRangeParams) (int64, error) {
+	codes, err := q.GetUnusedReferralCodes(ctx, GetUnusedReferralCodesParams(arg))
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(codes)), nil
+}
+
+func (q *memQueries) GetSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	defer q.lock()()
+	session, ok := q.data.sessions[id]
+	if !ok {
+		return Session{}, sql.ErrNoRows
+	}
+	return session, nil
+}
+
+func (q *memQueries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
+	defer q.lock()()
+	transfer, ok := q.data.transfers[id]
+	if !ok {
+		return Transfer{}, sql.ErrNoRows
+	}
+	return transfer, nil
+}
+
+// GetSystemAccountForUpdate needs no row lock, since transactions already run one at a time
+func (q *memQueries) GetSystemAccountForUpdate(ctx context.Context, arg GetSystemAccountForUpdateParams) (Account, error) {
+	defer q.lock()()
+	for _, account := range q.data.accounts {
+		if account.AccountType == arg.AccountType && account.Currency == arg.Currency {
+			return account, nil
+		}
+	}
+
+	account := Account{
+		ID:                    q.data.nextID("accounts"),
+		Owner:                 arg.AccountType,
+		Email:                 arg.AccountType + "." + strings.ToLower(arg.Currency) + "@system.bank-api",
+		ExtraInterest:         sql.NullFloat64{Float64: 0, Valid: true},
+		ExtraInterestDuration: 9,
+		Interest:              4.5,
+		Currency:              arg.Currency,
+		CreatedAt:             time.Now(),
+		AccountType:           arg.AccountType,
+	}
+	q.data.accounts[account.ID] = account
+	return account, nil
+}
+
+// GetUnusedReferralCodes matches the query it emulates, which despite its name selects the used codes
+func (q *memQueries) GetUnusedReferralCodes(ctx context.Context, arg GetUnusedReferralCodesParams) ([]ReferralCode, error) {
+	defer q.lock()()
+	items := []ReferralCode{}
+	for _, code := range sortedByID(q.data.referralCodes) {
+		if code.IsUsed && code.ReferrerAccountID == arg.ReferrerAccountID &&
+			!code.CreatedAt.Before(arg.CreatedAt) && !code.CreatedAt.After(arg.CreatedAt_2) {
+			items = append(items, code)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error) {
+	defer q.lock()()
+	webhook, ok := q.data.webhooks[id]
+	if !ok {
+		return WebhookSubscription{}, sql.ErrNoRows
+	}
+	return webhook, nil
+}
+
+func (q *memQueries) HasBeenReferred(ctx context.Context, arg HasBeenReferredParams) (bool, error) {
+	defer q.lock()()
+	for _, history := range q.data.referralHistory {
+		if history.ReferredAccountID == arg.ReferredAccountID ||
+			(arg.ReferredCustomerID.Valid && history.ReferredCustomerID == arg.ReferredCustomerID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (q *memQueries) HasInterestAccrualSince(ctx context.Context, arg HasInterestAccrualSinceParams) (bool, error) {
+	defer q.lock()()
+	for _, accrual := range q.data.accruals {
+		if accrual.AccountID == arg.AccountID && !accrual.AccrualDate.Before(arg.AccrualDate) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (q *memQueries) HasUnUsedCodeForReferrerAccount(ctx context.Context, referrerAccountID int64) (bool, error) {
+	defer q.lock()()
+	for _, code := range q.data.referralCodes {
+		if code.ReferrerAccountID == referrerAccountID && !code.IsUsed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (q *memQueries) ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error) {
+	defer q.lock()()
+	account, ok := q.data.accounts[arg.AccountID]
+	if !ok {
+		return []ListAccountEntriesRow{}, nil
+	}
+
+	entries := sortedByID(q.data.entries)
+	slices.Reverse(entries)
+
+	items := []ListAccountEntriesRow{}
+	balance := account.Balance
+	for _, entry := range entries {
+		if entry.AccountID != arg.AccountID {
+			continue
+		}
+		// the balance right after the entry, before every newer entry is taken off
+		runningBalance := balance
+		balance -= entry.Amount
+
+		switch {
+		case arg.BeforeID.Valid && entry.ID >= arg.BeforeID.Int64,
+			arg.FromTime.Valid && entry.CreatedAt.Before(arg.FromTime.Time),
+			arg.ToTime.Valid && !entry.CreatedAt.Before(arg.ToTime.Time),
+			arg.Direction.Valid && arg.Direction.String == "credit" && entry.Amount <= 0,
+			arg.Direction.Valid && arg.Direction.String == "debit" && entry.Amount >= 0,
+			arg.Direction.Valid && arg.Direction.String != "credit" && arg.Direction.String != "debit":
+			continue
+		}
+
+		row := ListAccountEntriesRow{
+			ID:             entry.ID,
+			AccountID:      entry.AccountID,
+			Amount:         entry.Amount,
+			CreatedAt:      entry.CreatedAt,
+			TransferID:     entry.TransferID,
+			JournalID:      entry.JournalID,
+			ReferenceType:  entry.ReferenceType,
+			RunningBalance: runningBalance,
+		}
+		if transfer, ok := q.data.transfers[entry.TransferID.Int64]; entry.TransferID.Valid && ok {
+			counterpartyID := transfer.FromAccountID
+			if transfer.FromAccountID == entry.AccountID {
+				counterpartyID = transfer.ToAccountID
+			}
+			if counterparty, ok := q.data.accounts[counterpartyID]; ok {
+				row.CounterpartyAccountID = sql.NullInt64{Int64: counterparty.ID, Valid: true}
+				row.CounterpartyAccountType = sql.NullString{String: counterparty.AccountType, Valid: true}
+			}
+			for _, payout := range q.data.payouts {
+				if payout.TransferID == entry.TransferID {
+					row.InterestPeriod = sql.NullString{String: payout.Period, Valid: true}
+				}
+			}
+		}
+		items = append(items, row)
+	}
+	return page(items, arg.PageSize, 0), nil
+}
+
+func (q *memQueries) ListAccountEntrySums(ctx context.Context, arg ListAccountEntrySumsParams) ([]ListAccountEntrySumsRow, error) {
+	defer q.lock()()
+	sums := make(map[int64]int64)
+	for _, entry := range q.data.entries {
+		sums[entry.AccountID] += entry.Amount
+	}
+
+	items := []ListAccountEntrySumsRow{}
+	for _, account := range sortedByID(q.data.accounts) {
+		if account.ID > arg.AfterID {
+			items = append(items, ListAccountEntrySumsRow{
+				ID:         account.ID,
+				Balance:    account.Balance,
+				EntriesSum: sums[account.ID],
+			})
+		}
+	}
+	return page(items, arg.BatchSize, 0), nil
+}
+
+func (q *memQueries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
+	defer q.lock()()
+	return page(sortedByID(q.data.accounts), arg.Limit, arg.Offset), nil
+}
+
+func (q *memQueries) ListAccountsForInterest(ctx context.Context, arg ListAccountsForInterestParams) ([]Account, error) {
+	defer q.lock()()
+	items := []Account{}
+	for _, account := range sortedByID(q.data.accounts) {
+		if account.AccountType == "customer" && account.CreatedAt.Before(arg.CreatedBefore) && account.ID > arg.AfterID {
+			items = append(items, account)
+		}
+	}
+	return page(items, arg.BatchSize, 0), nil
+}
+
+func (q *memQueries) ListAccountsWithExpiredExtraInterest(ctx context.Context, arg ListAccountsWithExpiredExtraInterestParams) ([]Account, error) {
+	defer q.lock()()
+	items := []Account{}
+	for _, account := range sortedByID(q.data.accounts) {
+		if account.ID <= arg.AfterID {
+			continue
+		}
+		if expiry, ok := ExtraInterestExpiry(account); ok && !expiry.After(toDate(arg.AsOf)) {
+			items = append(items, account)
+		}
+	}
+	return page(items, arg.BatchSize, 0), nil
+}
+
+func (q *memQueries) ListAccountsWithUnpaidInterestAccruals(ctx context.Context, accrualDate time.Time) ([]int64, error) {
+	defer q.lock()()
+	accounts := make(map[int64]bool)
+	for _, accrual := range q.data.accruals {
+		if !accrual.AccrualDate.After(toDate(accrualDate)) && !accrual.PaidPeriod.Valid {
+			accounts[accrual.AccountID] = true
+		}
+	}
+
+	items := []int64{}
+	for id := range accounts {
+		items = append(items, id)
+	}
+	slices.Sort(items)
+	return items, nil
+}
+
+func (q *memQueries) ListAuditEntries(ctx context.Context, arg ListAuditEntriesParams) ([]AuditEntry, error) {
+	defer q.lock()()
+	items := []AuditEntry{}
+	for _, entry := range sortedByID(q.data.auditEntries) {
+		if entry.AccountID == arg.AccountID {
+			items = append(items, entry)
+		}
+	}
+	return page(items, arg.Limit, arg.Offset), nil
+}
+
+func (q *memQueries) ListCustomerAccounts(ctx context.Context, customerID sql.NullInt64) ([]Account, error) {
+	defer q.lock()()
+	items := []Account{}
+	for _, account := range sortedByID(q.data.accounts) {
+		if customerID.Valid && account.CustomerID == customerID {
+			items = append(items, account)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) ListEmailDeliveries(ctx context.Context, arg ListEmailDeliveriesParams) ([]EmailDelivery, error) {
+	defer q.lock()()
+	items := []EmailDelivery{}
+	for _, email := range sortedByID(q.data.emails) {
+		if email.Status == arg.Status {
+			items = append(items, email)
+		}
+	}
+	return page(items, arg.Limit, arg.Offset), nil
+}
+
+func (q *memQueries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
+	defer q.lock()()
+	items := []Entry{}
+	for _, entry := range sortedByID(q.data.entries) {
+		if entry.AccountID == arg.AccountID {
+			items = append(items, entry)
+		}
+	}
+	return page(items, arg.Limit, arg.Offset), nil
+}
+
+func (q *memQueries) ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error) {
+	defer q.lock()()
+	items := []InterestAccrual{}
+	for _, accrual := range q.data.accruals {
+		if accrual.AccountID == arg.AccountID && withinDates(accrual.AccrualDate, arg.AccrualDate, arg.AccrualDate_2) {
+			items = append(items, accrual)
+		}
+	}
+	slices.SortFunc(items, func(a, b InterestAccrual) int {
+		return a.AccrualDate.Compare(b.AccrualDate)
+	})
+	return items, nil
+}
+
+func (q *memQueries) ListJobRuns(ctx context.Context, arg ListJobRunsParams) ([]JobRun, error) {
+	defer q.lock()()
+	items := []JobRun{}
+	for _, run := range q.data.jobRuns {
+		if run.JobName == arg.JobName {
+			items = append(items, run)
+		}
+	}
+	slices.SortFunc(items, func(a, b JobRun) int {
+		return strings.Compare(b.Period, a.Period)
+	})
+	return page(items, arg.Limit, arg.Offset), nil
+}
+
+func (q *memQueries) ListJournalEntries(ctx context.Context, journalID sql.NullInt64) ([]Entry, error) {
+	defer q.lock()()
+	items := []Entry{}
+	for _, entry := range sortedByID(q.data.entries) {
+		// NULL never equals anything, as in SQL
+		if journalID.Valid && entry.JournalID == journalID {
+			items = append(items, entry)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) ListJournalEntrySums(ctx context.Context, arg ListJournalEntrySumsParams) ([]ListJournalEntrySumsRow, error) {
+	defer q.lock()()
+	counts := make(map[int64]int64)
+	sums := make(map[int64]int64)
+	for _, entry := range q.data.entries {
+		if entry.JournalID.Valid {
+			counts[entry.JournalID.Int64]++
+			sums[entry.JournalID.Int64] += entry.Amount
+		}
+	}
+
+	items := []ListJournalEntrySumsRow{}
+	for _, journal := range sortedByID(q.data.journals) {
+		if journal.ID > arg.AfterID {
+			items = append(items, ListJournalEntrySumsRow{
+				ID:         journal.ID,
+				EntryCount: counts[journal.ID],
+				EntriesSum: sums[journal.ID],
+			})
+		}
+	}
+	return page(items, arg.BatchSize, 0), nil
+}
+
+func (q *memQueries) ListLatestFxRates(ctx context.Context) ([]FxRate, error) {
+	defer q.lock()()
+	latest := make(map[[2]string]FxRate)
+	for _, rate := range sortedByID(q.data.fxRates) {
+		latest[[2]string{rate.BaseCurrency, rate.QuoteCurrency}] = rate
+	}
+
+	items := make([]FxRate, 0, len(latest))
+	for _, rate := range latest {
+		items = append(items, rate)
+	}
+	slices.SortFunc(items, func(a, b FxRate) int {
+		if c := strings.Compare(a.BaseCurrency, b.BaseCurrency); c != 0 {
+			return c
+		}
+		return strings.Compare(a.QuoteCurrency, b.QuoteCurrency)
+	})
+	return items, nil
+}
+
+func (q *memQueries) ListOutboxEvents(ctx context.Context, arg ListOutboxEventsParams) ([]OutboxEvent, error) {
+	defer q.lock()()
+	items := []OutboxEvent{}
+	for _, event := range sortedByID(q.data.outboxEvents) {
+		if event.Status == arg.Status {
+			items = append(items, event)
+		}
+	}
+	return page(items, arg.Limit, arg.Offset), nil
+}
+
+func (q *memQueries) ListReferrerAccountsByDateRange(ctx context.Context, arg ListReferrerAccountsByDateRangeParams) ([]int64, error) {
+	defer q.lock()()
+	referrers := make(map[int64]bool)
+	for _, history := range q.data.referralHistory {
+		if !history.ExtraInterestAppliedAt.Valid &&
+			!history.ReferralDate.Before(arg.ReferralDate) && !history.ReferralDate.After(arg.ReferralDate_2) {
+			referrers[history.ReferrerAccountID] = true
+		}
+	}
+
+	items := []int64{}
+	for id := range referrers {
+		items = append(items, id)
+	}
+	slices.Sort(items)
+	return items, nil
+}
+
+func (q *memQueries) ListTransferEntryCounts(ctx context.Context, arg ListTransferEntryCountsParams) ([]ListTransferEntryCountsRow, error) {
+	defer q.lock()()
+	items := []ListTransferEntryCountsRow{}
+	for _, transfer := range sortedByID(q.data.transfers) {
+		if transfer.ID <= arg.AfterID {
+			continue
+		}
+
+		row := ListTransferEntryCountsRow{
+			ID:              transfer.ID,
+			FromAccountID:   transfer.FromAccountID,
+			ToAccountID:     transfer.ToAccountID,
+			Amount:          transfer.Amount,
+			ConvertedAmount: transfer.ConvertedAmount,
+		}
+		credited := transfer.Amount
+		if transfer.ConvertedAmount.Valid {
+			credited = transfer.ConvertedAmount.Int64
+		}
+		for _, entry := range q.data.entries {
+			if !entry.TransferID.Valid || entry.TransferID.Int64 != transfer.ID {
+				continue
+			}
+			row.EntryCount++
+			if (entry.AccountID == transfer.FromAccountID && entry.Amount == -transfer.Amount) ||
+				(entry.AccountID == transfer.ToAccountID && entry.Amount == credited) {
+				row.MatchingEntryCount++
+			}
+		}
+		items = append(items, row)
+	}
+	return page(items, arg.BatchSize, 0), nil
+}
+
+func (q *memQueries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
+	defer q.lock()()
+	items := []Transfer{}
+	for _, transfer := range sortedByID(q.data.transfers) {
+		if transfer.FromAccountID == arg.FromAccountID || transfer.ToAccountID == arg.ToAccountID {
+			items = append(items, transfer)
+		}
+	}
+	return page(items, arg.Limit, arg.Offset), nil
+}
+
+func (q *memQueries) ListUnappliedReferrals(ctx context.Context, arg ListUnappliedReferralsParams) ([]ReferralHistory, error) {
+	defer q.lock()()
+	items := []ReferralHistory{}
+	for _, history := range sortedByID(q.data.referralHistory) {
+		if history.ReferrerAccountID == arg.ReferrerAccountID && !history.ExtraInterestAppliedAt.Valid &&
+			!history.ReferralDate.Before(arg.ReferralDate) && !history.ReferralDate.After(arg.ReferralDate_2) {
+			items = append(items, history)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b ReferralHistory) int {
+		return a.ReferralDate.Compare(b.ReferralDate)
+	})
+	return items, nil
+}
+
+func (q *memQueries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	defer q.lock()()
+	items := []WebhookDelivery{}
+	for _, delivery := range sortedByID(q.data.deliveries) {
+		if delivery.SubscriptionID == arg.SubscriptionID {
+			items = append(items, delivery)
+		}
+	}
+	slices.Reverse(items)
+	return page(items, arg.Limit, arg.Offset), nil
+}
+
+func (q *memQueries) ListWebhookSubscriptions(ctx context.Context, accountID sql.NullInt64) ([]WebhookSubscription, error) {
+	defer q.lock()()
+	items := []WebhookSubscription{}
+	for _, webhook := range sortedByID(q.data.webhooks) {
+		if webhook.AccountID == accountID {
+			items = append(items, webhook)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) ListWebhookSubscriptionsForEvent(ctx context.Context, arg ListWebhookSubscriptionsForEventParams) ([]WebhookSubscription, error) {
+	defer q.lock()()
+	items := []WebhookSubscription{}
+	for _, webhook := range sortedByID(q.data.webhooks) {
+		if webhook.AccountID.Valid && !slices.Contains(arg.AccountIds, webhook.AccountID.Int64) {
+			continue
+		}
+		if len(webhook.EventTypes) == 0 || slices.Contains(webhook.EventTypes, arg.EventType) {
+			items = append(items, webhook)
+		}
+	}
+	return items, nil
+}
+
+func (q *memQueries) MarkEmailDeliveryFailed(ctx context.Context, arg MarkEmailDeliveryFailedParams) error {
+	defer q.lock()()
+	if email, ok := q.data.emails[arg.ID]; ok {
+		email.Status = arg.Status
+		email.Attempts++
+		email.NextAttemptAt = arg.NextAttemptAt
+		email.LastError = arg.LastError
+		q.data.emails[email.ID] = email
+	}
+	return nil
+}
+
+func (q *memQueries) MarkEmailDeliverySent(ctx context.Context, arg MarkEmailDeliverySentParams) error {
+	defer q.lock()()
+	if email, ok := q.data.emails[arg.ID]; ok {
+		email.Status = "sent"
+		email.Attempts++
+		email.LastError = sql.NullString{}
+		email.SentAt = arg.SentAt
+		q.data.emails[email.ID] = email
+	}
+	return nil
+}
+
+func (q *memQueries) MarkFxQuoteUsed(ctx context.Context, arg MarkFxQuoteUsedParams) (FxQuote, error) {
+	defer q.lock()()
+	quote, ok := q.data.fxQuotes[arg.ID]
+	if !ok {
+		return FxQuote{}, sql.ErrNoRows
+	}
+	quote.UsedAt = arg.UsedAt
+	q.data.fxQuotes[quote.ID] = quote
+	return quote, nil
+}
+
+func (q *memQueries) MarkInterestAccrualsPaid(ctx context.Context, arg MarkInterestAccrualsPaidParams) ([]InterestAccrual, error) {
+	defer q.lock()()
+	items := []InterestAccrual{}
+	for _, accrual := range sortedByID(q.data.accruals) {
+		if accrual.AccountID != arg.AccountID || accrual.AccrualDate.After(toDate(arg.AccrualDate)) || accrual.PaidPeriod.Valid {
+			continue
+		}
+		accrual.PaidPeriod = arg.PaidPeriod
+		q.data.accruals[accrual.ID] = accrual
+		items = append(items, accrual)
+	}
+	return items, nil
+}
+
+func (q *memQueries) MarkOutboxEventDelivered(ctx context.Context, arg MarkOutboxEventDeliveredParams) error {
+	defer q.lock()()
+	if event, ok := q.data.outboxEvents[arg.ID]; ok {
+		event.Status = "delivered"
+		event.Attempts++
+		event.LastError = sql.NullString{}
+		event.DeliveredAt = arg.DeliveredAt
+		q.data.outboxEvents[event.ID] = event
+	}
+	return nil
+}
+
+func (q *memQueries) MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error {
+	defer q.lock()()
+	if event, ok := q.data.outboxEvents[arg.ID]; ok {
+		event.Status = arg.Status
+		event.Attempts++
+		event.NextAttemptAt = arg.NextAttemptAt
+		event.LastError = arg.LastError
+		q.data.outboxEvents[event.ID] = event
+	}
+	return nil
+}
+
+func (q *memQueries) MarkReferralCodeUsed(ctx context.Context, arg MarkReferralCodeUsedParams) (ReferralCode, error) {
+	defer q.lock()()
+	for id, code := range q.data.referralCodes {
+		if code.ReferralCode == arg.ReferralCode {
+			code.IsUsed = true
+			code.UsedAt = arg.UsedAt
+			q.data.referralCodes[id] = code
+			return code, nil
+		}
+	}
+	return ReferralCode{}, sql.ErrNoRows
+}
+
+func (q *memQueries) MarkReferralExtraInterestApplied(ctx context.Context, arg MarkReferralExtraInterestAppliedParams) error {
+	defer q.lock()()
+	if history, ok := q.data.referralHistory[arg.ID]; ok {
+		history.ExtraInterestAppliedAt = arg.ExtraInterestAppliedAt
+		q.data.referralHistory[arg.ID] = history
+	}
+	return nil
+}
+
+func (q *memQueries) MarkWebhookDeliveryDelivered(ctx context.Context, arg MarkWebhookDeliveryDeliveredParams) error {
+	defer q.lock()()
+	if delivery, ok := q.data.deliveries[arg.ID]; ok {
+		delivery.Status = "delivered"
+		delivery.Attempts++
+		delivery.ResponseStatus = arg.ResponseStatus
+		delivery.LastError = sql.NullString{}
+		delivery.DeliveredAt = arg.DeliveredAt
+		q.data.deliveries[delivery.ID] = delivery
+	}
+	return nil
+}
+
+func (q *memQueries) MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) error {
+	defer q.lock()()
+	if delivery, ok := q.data.deliveries[arg.ID]; ok {
+		delivery.Status = arg.Status
+		delivery.Attempts++
+		delivery.NextAttemptAt = arg.NextAttemptAt
+		delivery.ResponseStatus = arg.ResponseStatus
+		delivery.LastError = arg.LastError
+		q.data.deliveries[delivery.ID] = delivery
+	}
+	return nil
+}
+
+func (q *memQueries) RequeueOutboxEvent(ctx context.Context, arg RequeueOutboxEventParams) (OutboxEvent, error) {
+	defer q.lock()()
+	event, ok := q.data.outboxEvents[arg.ID]
+	if !ok || event.Status != "dead" {
+		return OutboxEvent{}, sql.ErrNoRows
+	}
+	event.Status = "pending"
+	event.Attempts = 0
+	event.NextAttemptAt = arg.NextAttemptAt
+	q.data.outboxEvents[event.ID] = event
+	return event, nil
+}
+
+func (q *memQueries) ResetExtraInterest(ctx context.Context, id int64) (Account, error) {
+	defer q.lock()()
+	account, ok := q.data.accounts[id]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	account.ExtraInterest = sql.NullFloat64{Float64: 0, Valid: true}
+	account.ExtraInterestStartDate = sql.NullTime{}
+	q.data.accounts[account.ID] = account
+	return account, nil
+}
+
+func (q *memQueries) SetCustomerPassword(ctx context.Context, arg SetCustomerPasswordParams) (CustomerCredential, error) {
+	defer q.lock()()
+	if _, ok := q.data.customers[arg.CustomerID]; !ok {
+		return CustomerCredential{}, foreignKeyViolation("customer_credentials_customer_id_fkey")
+	}
+
+	credential, ok := q.data.credentials[arg.CustomerID]
+	if !ok {
+		credential = CustomerCredential{
+			CustomerID: arg.CustomerID,
+			Role:       "depositor",
+			CreatedAt:  time.Now(),
+		}
+	}
+	credential.HashedPassword = arg.HashedPassword
+	credential.PasswordChangedAt = arg.PasswordChangedAt
+	q.data.credentials[credential.CustomerID] = credential
+	return credential, nil
+}
+
+func (q *memQueries) SetPasswordResetToken(ctx context.Context, arg SetPasswordResetTokenParams) (PasswordReset, error) {
+	defer q.lock()()
+	reset, ok := q.data.passwordResets[arg.ID]
+	if !ok || reset.UsedAt.Valid || !reset.ExpiresAt.After(arg.Now) {
+		return PasswordReset{}, sql.ErrNoRows
+	}
+	for id, other := range q.data.passwordResets {
+		if id != arg.ID && arg.TokenHash.Valid && other.TokenHash == arg.TokenHash {
+			return PasswordReset{}, uniqueViolation("password_resets_token_hash_key")
+		}
+	}
+
+	reset.TokenHash = arg.TokenHash
+	q.data.passwordResets[reset.ID] = reset
+	return reset, nil
+}
+
+func (q *memQueries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	defer q.lock()()
+	account, ok := q.data.accounts[arg.ID]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	account.Balance = arg.Balance
+	q.data.accounts[account.ID] = account
+	return account, nil
+}
+
+func (q *memQueries) UpdateAccountInterest(ctx context.Context, arg UpdateAccountInterestParams) (Account, error) {
+	defer q.lock()()
+	account, ok := q.data.accounts[arg.ID]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	account.ExtraInterest = arg.ExtraInterest
+	account.ExtraInterestStartDate = arg.ExtraInterestStartDate
+	if arg.ExtraInterestStartDate.Valid {
+		account.ExtraInterestStartDate.Time = toDate(arg.ExtraInterestStartDate.Time)
+	}
+	account.ExtraInterestDuration = arg.ExtraInterestDuration
+	q.data.accounts[account.ID] = account
+	return account, nil
+}
+
+func (q *memQueries) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	defer q.lock()()
+	if arg.OverdraftLimit < 0 {
+		return Account{}, &pq.Error{Code: "23514", Message: "new row violates check constraint", Constraint: "accounts_overdraft_limit_check"}
+	}
+	account, ok := q.data.accounts[arg.ID]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	account.OverdraftLimit = arg.OverdraftLimit
+	q.data.accounts[account.ID] = account
+	return account, nil
+}
+
+func (q *memQueries) UpdateCustomerCredentialRole(ctx context.Context, arg UpdateCustomerCredentialRoleParams) (CustomerCredential, error) {
+	defer q.lock()()
+	credential, ok := q.data.credentials[arg.CustomerID]
+	if !ok {
+		return CustomerCredential{}, sql.ErrNoRows
+	}
+	credential.Role = arg.Role
+	q.data.credentials[credential.CustomerID] = credential
+	return credential, nil
+}
+
+func (q *memQueries) UsePasswordReset(ctx context.Context, arg UsePasswordResetParams) error {
+	defer q.lock()()
+	if reset, ok := q.data.passwordResets[arg.ID]; ok {
+		reset.UsedAt = arg.UsedAt
+		q.data.passwordResets[reset.ID] = reset
+	}
+	return nil
+}
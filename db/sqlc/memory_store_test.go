@@ -0,0 +1,123 @@
+package sqlc
+
+import (
+	"bank-api/util"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func createMemoryAccount(t *testing.T, store Store, balance int64) Account {
+	account, err := store.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:     util.RandomOwner(),
+		Balance:   balance,
+		Email:     util.RandomEmail(),
+		Currency:  util.JPY,
+		CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestMemoryStoreTransferTx(t *testing.T) {
+	store := NewMemoryStore()
+	account1 := createMemoryAccount(t, store, 100)
+	account2 := createMemoryAccount(t, store, 0)
+
+	n := 10
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        15,
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	for err := range errs {
+		if err != nil {
+			require.ErrorIs(t, err, ErrInsufficientFunds)
+			failed++
+		}
+	}
+	require.Equal(t, 4, failed)
+
+	updated1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), updated1.Balance)
+
+	updated2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(90), updated2.Balance)
+
+	entries, err := store.ListEntries(context.Background(), ListEntriesParams{AccountID: account1.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 6)
+}
+
+func TestMemoryStoreRollback(t *testing.T) {
+	store := NewMemoryStore()
+	account1 := createMemoryAccount(t, store, 100)
+
+	// the transfer fails on the unknown destination after the source was already debited
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account1.ID + 100,
+		Amount:        10,
+	})
+	require.Error(t, err)
+
+	account, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+
+	entries, err := store.ListEntries(context.Background(), ListEntriesParams{AccountID: account1.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestMemoryStoreConstraints(t *testing.T) {
+	store := NewMemoryStore()
+	account := createMemoryAccount(t, store, 0)
+
+	customer, err := store.CreateCustomer(context.Background(), CreateCustomerParams{Email: account.Email, Name: account.Owner})
+	require.NoError(t, err)
+	_, err = store.CreateCustomer(context.Background(), CreateCustomerParams{Email: account.Email, Name: util.RandomOwner()})
+	var pqErr *pq.Error
+	require.True(t, errors.As(err, &pqErr))
+	require.Equal(t, pq.ErrorCode("23505"), pqErr.Code)
+
+	// one account per customer and currency
+	accountParams := CreateAccountParams{
+		Owner:      customer.Name,
+		Email:      customer.Email,
+		Currency:   util.JPY,
+		CustomerID: sql.NullInt64{Int64: customer.ID, Valid: true},
+	}
+	_, err = store.CreateAccount(context.Background(), accountParams)
+	require.NoError(t, err)
+	_, err = store.CreateAccount(context.Background(), accountParams)
+	require.True(t, errors.As(err, &pqErr))
+	require.Equal(t, pq.ErrorCode("23505"), pqErr.Code)
+
+	_, err = store.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID + 100, Amount: 10})
+	require.True(t, errors.As(err, &pqErr))
+	require.Equal(t, pq.ErrorCode("23503"), pqErr.Code)
+
+	_, err = store.GetAccount(context.Background(), account.ID+100)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -6,20 +6,86 @@ package sqlc
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
-	ID                     int64           `json:"id"`
-	Owner                  string          `json:"owner"`
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	// the customer's email when the account was opened
 	Email                  string          `json:"email"`
 	ExtraInterest          sql.NullFloat64 `json:"extra_interest"`
 	ExtraInterestStartDate sql.NullTime    `json:"extra_interest_start_date"`
 	ExtraInterestDuration  int32           `json:"extra_interest_duration"`
 	Interest               float64         `json:"interest"`
 	Balance                int64           `json:"balance"`
-	Currency               string          `json:"currency"`
-	CreatedAt              time.Time       `json:"created_at"`
+	// ISO 4217 code, amounts of the account are in its minor unit
+	Currency  string    `json:"currency"`
+	CreatedAt time.Time `json:"created_at"`
+	// how far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// customer, or the kind of system account
+	AccountType string `json:"account_type"`
+	// the customer who holds the account, NULL for system accounts
+	CustomerID sql.NullInt64 `json:"customer_id"`
+}
+
+// the extra interest terms an account had until changed_at
+type AccountInterestChange struct {
+	ID                     int64           `json:"id"`
+	AccountID              int64           `json:"account_id"`
+	ExtraInterest          sql.NullFloat64 `json:"extra_interest"`
+	ExtraInterestStartDate sql.NullTime    `json:"extra_interest_start_date"`
+	ExtraInterestDuration  int32           `json:"extra_interest_duration"`
+	ChangedAt              time.Time       `json:"changed_at"`
+}
+
+type AuditEntry struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// what happened to the account, e.g. extra_interest_expired
+	Action string `json:"action"`
+	// the values before and after the change
+	Detail    json.RawMessage `json:"detail"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type Customer struct {
+	ID        int64     `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CustomerCredential struct {
+	CustomerID        int64     `json:"customer_id"`
+	HashedPassword    string    `json:"hashed_password"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	Role              string    `json:"role"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// a message per customer an outbox event is about, sent apart from the relay so a mail server that is down holds up nothing else
+type EmailDelivery struct {
+	ID            int64 `json:"id"`
+	OutboxEventID int64 `json:"outbox_event_id"`
+	// the kind of message, e.g. transfer_received
+	Kind       string `json:"kind"`
+	CustomerID int64  `json:"customer_id"`
+	// the account the message is about, null for messages about the customer
+	AccountID sql.NullInt64 `json:"account_id"`
+	// the payload of the event, the message is rendered from it when it is sent
+	Payload json.RawMessage `json:"payload"`
+	// pending, sent, skipped when there was nothing to send anymore, or dead once it ran out of attempts
+	Status        string         `json:"status"`
+	Attempts      int32          `json:"attempts"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LastError     sql.NullString `json:"last_error"`
+	CreatedAt     time.Time      `json:"created_at"`
+	SentAt        sql.NullTime   `json:"sent_at"`
 }
 
 type Entry struct {
@@ -28,6 +94,130 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// the transfer that caused the entry
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// the journal the entry is a leg of, its legs sum to zero
+	JournalID sql.NullInt64 `json:"journal_id"`
+	// what caused the entry: transfer, bonus, interest or adjustment
+	ReferenceType string `json:"reference_type"`
+}
+
+type FxQuote struct {
+	ID uuid.UUID `json:"id"`
+	// the account the quote was given to, only it can transfer with it
+	AccountID    int64  `json:"account_id"`
+	FromCurrency string `json:"from_currency"`
+	ToCurrency   string `json:"to_currency"`
+	Rate         string `json:"rate"`
+	// debited, in the minor unit of from_currency
+	Amount int64 `json:"amount"`
+	// credited, in the minor unit of to_currency
+	ConvertedAmount int64        `json:"converted_amount"`
+	ExpiresAt       time.Time    `json:"expires_at"`
+	UsedAt          sql.NullTime `json:"used_at"`
+	CreatedAt       time.Time    `json:"created_at"`
+}
+
+type FxRate struct {
+	ID            int64  `json:"id"`
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	// units of the quote currency one unit of the base currency buys
+	Rate string `json:"rate"`
+	// who set the rate: the admin email, or the file it was loaded from
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	// whose key it is: customer:<id>, or anonymous for signups
+	Scope string `json:"scope"`
+	Key   string `json:"key"`
+	// fingerprint of the method, URI and body of the first request with the key
+	RequestHash  string        `json:"request_hash"`
+	ResponseCode sql.NullInt32 `json:"response_code"`
+	ResponseBody []byte        `json:"response_body"`
+	// when the request holding the key started, a retry may take over a key locked for too long
+	LockedAt    time.Time    `json:"locked_at"`
+	CompletedAt sql.NullTime `json:"completed_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+type InterestAccrual struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	// end of day balance the interest accrued on
+	Balance int64 `json:"balance"`
+	// base plus extra interest in effect that day, in percent
+	AnnualRate float64 `json:"annual_rate"`
+	// accrued interest in millionths of the minor unit
+	AmountMicro int64     `json:"amount_micro"`
+	CreatedAt   time.Time `json:"created_at"`
+	// the period of the payout that paid the accrual, NULL until one does
+	PaidPeriod sql.NullString `json:"paid_period"`
+}
+
+type InterestPayout struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// the month paid for, e.g. 2024-07
+	Period string `json:"period"`
+	Amount int64  `json:"amount"`
+	// transfer from the interest account, none when nothing was due
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type JobRun struct {
+	ID      int64  `json:"id"`
+	JobName string `json:"job_name"`
+	// the period the run covers, e.g. 2024-07 for a monthly job
+	Period string `json:"period"`
+	// running, succeeded or failed
+	Status string `json:"status"`
+	// schedule, or the admin who started the run
+	TriggeredBy string         `json:"triggered_by"`
+	Attempts    int32          `json:"attempts"`
+	Processed   int64          `json:"processed"`
+	Error       sql.NullString `json:"error"`
+	StartedAt   time.Time      `json:"started_at"`
+	FinishedAt  sql.NullTime   `json:"finished_at"`
+}
+
+type Journal struct {
+	ID        int64     `json:"id"`
+	Memo      string    `json:"memo"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OutboxEvent struct {
+	ID int64 `json:"id"`
+	// what happened, e.g. transfer.completed
+	EventType string `json:"event_type"`
+	// the kind of row the event is about: account, transfer or referral
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	// pending, delivered, or dead once it ran out of attempts
+	Status   string `json:"status"`
+	Attempts int32  `json:"attempts"`
+	// when the relay picks the event up next, pushed back while it is being delivered and after a failed attempt
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LastError     sql.NullString `json:"last_error"`
+	CreatedAt     time.Time      `json:"created_at"`
+	DeliveredAt   sql.NullTime   `json:"delivered_at"`
+}
+
+// requests to choose a password, also how customers from before credentials get their first one
+type PasswordReset struct {
+	ID         int64 `json:"id"`
+	CustomerID int64 `json:"customer_id"`
+	// SHA-256 of the token last emailed, set when the email is sent; the token itself is never stored
+	TokenHash sql.NullString `json:"token_hash"`
+	ExpiresAt time.Time      `json:"expires_at"`
+	UsedAt    sql.NullTime   `json:"used_at"`
+	CreatedAt time.Time      `json:"created_at"`
 }
 
 type ReferralCode struct {
@@ -46,6 +236,21 @@ type ReferralHistory struct {
 	ReferralCodeID    int64     `json:"referral_code_id"`
 	ReferralDate      time.Time `json:"referral_date"`
 	CreatedAt         time.Time `json:"created_at"`
+	// the customer holding the referred account, who may be referred only once
+	ReferredCustomerID sql.NullInt64 `json:"referred_customer_id"`
+	// when the extra interest of the referral was added to the referrer, NULL until the monthly run does it
+	ExtraInterestAppliedAt sql.NullTime `json:"extra_interest_applied_at"`
+}
+
+type Session struct {
+	ID           uuid.UUID `json:"id"`
+	RefreshToken string    `json:"refresh_token"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+	IsBlocked    bool      `json:"is_blocked"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+	CustomerID   int64     `json:"customer_id"`
 }
 
 type Transfer struct {
@@ -53,6 +258,41 @@ type Transfer struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	// must be positive
-	Amount    int64     `json:"amount"`
+	Amount    int64         `json:"amount"`
+	CreatedAt time.Time     `json:"created_at"`
+	FxQuoteID uuid.NullUUID `json:"fx_quote_id"`
+	// the rate the amount was converted at, for transfers between currencies
+	FxRate sql.NullString `json:"fx_rate"`
+	// credited to the destination account in its currency, for transfers between currencies
+	ConvertedAmount sql.NullInt64 `json:"converted_amount"`
+}
+
+type WebhookDelivery struct {
+	ID             int64  `json:"id"`
+	SubscriptionID int64  `json:"subscription_id"`
+	OutboxEventID  int64  `json:"outbox_event_id"`
+	EventType      string `json:"event_type"`
+	// the request body, the same for every attempt
+	Payload json.RawMessage `json:"payload"`
+	// pending, delivered, or dead once it ran out of attempts
+	Status        string    `json:"status"`
+	Attempts      int32     `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// HTTP status of the last attempt, null when no response came back
+	ResponseStatus sql.NullInt32  `json:"response_status"`
+	LastError      sql.NullString `json:"last_error"`
+	CreatedAt      time.Time      `json:"created_at"`
+	DeliveredAt    sql.NullTime   `json:"delivered_at"`
+}
+
+type WebhookSubscription struct {
+	ID int64 `json:"id"`
+	// the account whose events are sent, null for every account
+	AccountID sql.NullInt64 `json:"account_id"`
+	Url       string        `json:"url"`
+	// the event types sent, empty for all of them
+	EventTypes []string `json:"event_types"`
+	// key the deliveries are signed with
+	Secret    string    `json:"secret"`
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -23,10 +23,6 @@ type CreateReferralCodeParams struct {
 	CreatedAt         time.Time `json:"created_at"`
 }
 
-type CreateReferralCodeErrorMsg struct {
-	ErrorMessage string `json:"error"`
-}
-
 func (q *Queries) CreateReferralCode(ctx context.Context, arg CreateReferralCodeParams) (ReferralCode, error) {
 	row := q.queryRow(ctx, q.createReferralCodeStmt, createReferralCode, arg.ReferralCode, arg.ReferrerAccountID, arg.CreatedAt)
 	var i ReferralCode
@@ -42,17 +38,19 @@ func (q *Queries) CreateReferralCode(ctx context.Context, arg CreateReferralCode
 }
 
 const createReferralHistory = `-- name: CreateReferralHistory :one
-INSERT INTO referral_history (referrer_account_id, referred_account_id, referral_code_id, referral_date, created_at)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, referrer_account_id, referred_account_id, referral_code_id, referral_date, created_at
+INSERT INTO referral_history (referrer_account_id, referred_account_id, referral_code_id, referral_date, created_at, referred_customer_id, extra_interest_applied_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, referrer_account_id, referred_account_id, referral_code_id, referral_date, created_at, referred_customer_id, extra_interest_applied_at
 `
 
 type CreateReferralHistoryParams struct {
-	ReferrerAccountID int64     `json:"referrer_account_id"`
-	ReferredAccountID int64     `json:"referred_account_id"`
-	ReferralCodeID    int64     `json:"referral_code_id"`
-	ReferralDate      time.Time `json:"referral_date"`
-	CreatedAt         time.Time `json:"created_at"`
+	ReferrerAccountID      int64         `json:"referrer_account_id"`
+	ReferredAccountID      int64         `json:"referred_account_id"`
+	ReferralCodeID         int64         `json:"referral_code_id"`
+	ReferralDate           time.Time     `json:"referral_date"`
+	CreatedAt              time.Time     `json:"created_at"`
+	ReferredCustomerID     sql.NullInt64 `json:"referred_customer_id"`
+	ExtraInterestAppliedAt sql.NullTime  `json:"extra_interest_applied_at"`
 }
 
 func (q *Queries) CreateReferralHistory(ctx context.Context, arg CreateReferralHistoryParams) (ReferralHistory, error) {
@@ -62,6 +60,8 @@ func (q *Queries) CreateReferralHistory(ctx context.Context, arg CreateReferralH
 		arg.ReferralCodeID,
 		arg.ReferralDate,
 		arg.CreatedAt,
+		arg.ReferredCustomerID,
+		arg.ExtraInterestAppliedAt,
 	)
 	var i ReferralHistory
 	err := row.Scan(
@@ -71,6 +71,8 @@ func (q *Queries) CreateReferralHistory(ctx context.Context, arg CreateReferralH
 		&i.ReferralCodeID,
 		&i.ReferralDate,
 		&i.CreatedAt,
+		&i.ReferredCustomerID,
+		&i.ExtraInterestAppliedAt,
 	)
 	return i, err
 }
@@ -95,6 +97,27 @@ func (q *Queries) GetReferralCode(ctx context.Context, referralCode string) (Ref
 	return i, err
 }
 
+const getReferralCodeForUpdate = `-- name: GetReferralCodeForUpdate :one
+SELECT id, referral_code, referrer_account_id, is_used, created_at, used_at FROM referral_codes
+WHERE referral_code = $1
+LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetReferralCodeForUpdate(ctx context.Context, referralCode string) (ReferralCode, error) {
+	row := q.queryRow(ctx, q.getReferralCodeForUpdateStmt, getReferralCodeForUpdate, referralCode)
+	var i ReferralCode
+	err := row.Scan(
+		&i.ID,
+		&i.ReferralCode,
+		&i.ReferrerAccountID,
+		&i.IsUsed,
+		&i.CreatedAt,
+		&i.UsedAt,
+	)
+	return i, err
+}
+
 const getReferralCodesForReferrerAccount = `-- name: GetReferralCodesForReferrerAccount :many
 SELECT id, referral_code, referrer_account_id, is_used, created_at, used_at FROM referral_codes
 WHERE referrer_account_id = $1
@@ -132,7 +155,7 @@ func (q *Queries) GetReferralCodesForReferrerAccount(ctx context.Context, referr
 }
 
 const getReferralHistory = `-- name: GetReferralHistory :many
-SELECT id, referrer_account_id, referred_account_id, referral_code_id, referral_date, created_at FROM referral_history
+SELECT id, referrer_account_id, referred_account_id, referral_code_id, referral_date, created_at, referred_customer_id, extra_interest_applied_at FROM referral_history
 WHERE referrer_account_id = $1
 ORDER BY referral_date
 `
@@ -153,6 +176,8 @@ func (q *Queries) GetReferralHistory(ctx context.Context, referrerAccountID int6
 			&i.ReferralCodeID,
 			&i.ReferralDate,
 			&i.CreatedAt,
+			&i.ReferredCustomerID,
+			&i.ExtraInterestAppliedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -168,7 +193,7 @@ func (q *Queries) GetReferralHistory(ctx context.Context, referrerAccountID int6
 }
 
 const getReferralHistoryByDate = `-- name: GetReferralHistoryByDate :many
-SELECT id, referrer_account_id, referred_account_id, referral_code_id, referral_date, created_at FROM referral_history
+SELECT id, referrer_account_id, referred_account_id, referral_code_id, referral_date, created_at, referred_customer_id, extra_interest_applied_at FROM referral_history
 WHERE referrer_account_id = $1
   AND referral_date >= $2 AND referral_date <= $3
 ORDER BY referral_date
@@ -196,6 +221,8 @@ func (q *Queries) GetReferralHistoryByDate(ctx context.Context, arg GetReferralH
 			&i.ReferralCodeID,
 			&i.ReferralDate,
 			&i.CreatedAt,
+			&i.ReferredCustomerID,
+			&i.ExtraInterestAppliedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -273,6 +300,27 @@ func (q *Queries) GetUnusedReferralCodes(ctx context.Context, arg GetUnusedRefer
 	return items, nil
 }
 
+const hasBeenReferred = `-- name: HasBeenReferred :one
+SELECT EXISTS (
+    SELECT 1
+    FROM referral_history
+    WHERE referred_account_id = $1
+       OR referred_customer_id = $2
+)
+`
+
+type HasBeenReferredParams struct {
+	ReferredAccountID  int64         `json:"referred_account_id"`
+	ReferredCustomerID sql.NullInt64 `json:"referred_customer_id"`
+}
+
+func (q *Queries) HasBeenReferred(ctx context.Context, arg HasBeenReferredParams) (bool, error) {
+	row := q.queryRow(ctx, q.hasBeenReferredStmt, hasBeenReferred, arg.ReferredAccountID, arg.ReferredCustomerID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const hasUnUsedCodeForReferrerAccount = `-- name: HasUnUsedCodeForReferrerAccount :one
 SELECT EXISTS (
     SELECT 1
@@ -289,6 +337,87 @@ func (q *Queries) HasUnUsedCodeForReferrerAccount(ctx context.Context, referrerA
 	return exists, err
 }
 
+const listReferrerAccountsByDateRange = `-- name: ListReferrerAccountsByDateRange :many
+SELECT DISTINCT referrer_account_id FROM referral_history
+WHERE extra_interest_applied_at IS NULL
+  AND referral_date >= $1 AND referral_date <= $2
+ORDER BY referrer_account_id
+`
+
+type ListReferrerAccountsByDateRangeParams struct {
+	ReferralDate   time.Time `json:"referral_date"`
+	ReferralDate_2 time.Time `json:"referral_date_2"`
+}
+
+func (q *Queries) ListReferrerAccountsByDateRange(ctx context.Context, arg ListReferrerAccountsByDateRangeParams) ([]int64, error) {
+	rows, err := q.query(ctx, q.listReferrerAccountsByDateRangeStmt, listReferrerAccountsByDateRange, arg.ReferralDate, arg.ReferralDate_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var referrer_account_id int64
+		if err := rows.Scan(&referrer_account_id); err != nil {
+			return nil, err
+		}
+		items = append(items, referrer_account_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnappliedReferrals = `-- name: ListUnappliedReferrals :many
+SELECT id, referrer_account_id, referred_account_id, referral_code_id, referral_date, created_at, referred_customer_id, extra_interest_applied_at FROM referral_history
+WHERE referrer_account_id = $1
+  AND extra_interest_applied_at IS NULL
+  AND referral_date >= $2 AND referral_date <= $3
+ORDER BY referral_date, id
+`
+
+type ListUnappliedReferralsParams struct {
+	ReferrerAccountID int64     `json:"referrer_account_id"`
+	ReferralDate      time.Time `json:"referral_date"`
+	ReferralDate_2    time.Time `json:"referral_date_2"`
+}
+
+func (q *Queries) ListUnappliedReferrals(ctx context.Context, arg ListUnappliedReferralsParams) ([]ReferralHistory, error) {
+	rows, err := q.query(ctx, q.listUnappliedReferralsStmt, listUnappliedReferrals, arg.ReferrerAccountID, arg.ReferralDate, arg.ReferralDate_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ReferralHistory{}
+	for rows.Next() {
+		var i ReferralHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.ReferrerAccountID,
+			&i.ReferredAccountID,
+			&i.ReferralCodeID,
+			&i.ReferralDate,
+			&i.CreatedAt,
+			&i.ReferredCustomerID,
+			&i.ExtraInterestAppliedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const markReferralCodeUsed = `-- name: MarkReferralCodeUsed :one
 UPDATE referral_codes
 SET is_used = true, used_at = $2
@@ -314,3 +443,19 @@ func (q *Queries) MarkReferralCodeUsed(ctx context.Context, arg MarkReferralCode
 	)
 	return i, err
 }
+
+const markReferralExtraInterestApplied = `-- name: MarkReferralExtraInterestApplied :exec
+UPDATE referral_history
+SET extra_interest_applied_at = $2
+WHERE id = $1
+`
+
+type MarkReferralExtraInterestAppliedParams struct {
+	ID                     int64        `json:"id"`
+	ExtraInterestAppliedAt sql.NullTime `json:"extra_interest_applied_at"`
+}
+
+func (q *Queries) MarkReferralExtraInterestApplied(ctx context.Context, arg MarkReferralExtraInterestAppliedParams) error {
+	_, err := q.exec(ctx, q.markReferralExtraInterestAppliedStmt, markReferralExtraInterestApplied, arg.ID, arg.ExtraInterestAppliedAt)
+	return err
+}
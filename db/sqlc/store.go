@@ -2,27 +2,100 @@ package sqlc
 
 import (
 	"4d63.com/tz"
+	"bank-api/apperr"
+	"bank-api/metrics"
+	"bank-api/sql/schema"
+	"bank-api/util"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/Meenachinmay/microservice-shared/utils"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"slices"
 	"time"
 )
 
-type Store struct {
+// Store provides all functions to execute queries and transactions
+type Store interface {
+	Querier
+	AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams) (AdjustBalanceTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (CreateAccountTxResult, error)
+	ExpireExtraInterestTx(ctx context.Context, arg ExpireExtraInterestTxParams) (ExpireExtraInterestTxResult, error)
+	OpenAccountTx(ctx context.Context, arg OpenAccountTxParams) (OpenAccountTxResult, error)
+	PayInterestTx(ctx context.Context, arg PayInterestTxParams) (PayInterestTxResult, error)
+	RedeemReferralCodeTx(ctx context.Context, arg RedeemReferralCodeTxParams) (RedeemReferralCodeTxResult, error)
+	RequestPasswordResetTx(ctx context.Context, arg RequestPasswordResetTxParams) (PasswordReset, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (CustomerCredential, error)
+	SignupWithReferralTx(ctx context.Context, arg SignupWithReferralTxParams) (SignupWithReferralTxResult, error)
+	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	UseReferralCodeTx(ctx context.Context, arg UseReferralCodeTxParams) (UseReferralCodeTxResult, error)
+	// Ping checks that the database answers
+	Ping(ctx context.Context) error
+	// SchemaVersion is the version of the last migration applied to the database
+	SchemaVersion(ctx context.Context) (int64, error)
+	// Stats are those of the connection pool, zero for a store without one
+	Stats() sql.DBStats
+}
+
+// txStore implements the transactions of Store on top of an execTx, so that every Store
+// implementation shares the same business logic and only differs in how a transaction is run.
+type txStore struct {
+	execTx func(ctx context.Context, fn func(Querier) error) error
+}
+
+// SQLStore provides all functions to execute SQL queries and transactions
+type SQLStore struct {
 	*Queries
+	txStore
 	db *sql.DB
 }
 
-func NewStore(db *sql.DB) *Store {
-	return &Store{
+func NewStore(db *sql.DB) Store {
+	store := &SQLStore{
 		db:      db,
 		Queries: New(db),
 	}
+	store.txStore = txStore{execTx: store.execTx}
+	return store
+}
+
+func (store *SQLStore) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+func (store *SQLStore) SchemaVersion(ctx context.Context) (int64, error) {
+	var version int64
+	err := store.db.QueryRowContext(ctx,
+		"SELECT COALESCE(max(version_id), 0) FROM "+schema.VersionTable+" WHERE is_applied").Scan(&version)
+	return version, err
+}
+
+func (store *SQLStore) Stats() sql.DBStats {
+	return store.db.Stats()
+}
+
+// maxTxAttempts bounds how often execTx runs a transaction that lost to a concurrent one
+const maxTxAttempts = 3
+
+// execTx runs fn in a transaction. A transaction Postgres aborted for a serialization failure or
+// a deadlock is run again from the start, up to maxTxAttempts times, so fn must not have effects
+// outside of q. Database errors a caller may be told about, like a unique violation, come back
+// as *apperr.Error, see apperr.FromDB.
+func (store *SQLStore) execTx(ctx context.Context, fn func(Querier) error) error {
+	for attempt := 1; ; attempt++ {
+		err := store.runTx(ctx, fn)
+		if attempt < maxTxAttempts && retryableTx(err) && ctx.Err() == nil {
+			metrics.DBTransactionRetries.Inc()
+			continue
+		}
+		return err
+	}
 }
 
-func (store *Store) execTx(ctx context.Context, fn func(*Queries) error) error {
+func (store *SQLStore) runTx(ctx context.Context, fn func(Querier) error) error {
 	tx, err := store.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -31,18 +104,483 @@ func (store *Store) execTx(ctx context.Context, fn func(*Queries) error) error {
 	q := New(tx)
 	err = fn(q)
 	if err != nil {
+		metrics.DBTransactions.WithLabelValues(metrics.TxRolledBack).Inc()
 		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err %v", err, rbErr)
+			return fmt.Errorf("tx err: %w, rb err %v", err, rbErr)
 		}
+		return apperr.FromDB(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		// Postgres rolls back a transaction it cannot commit
+		metrics.DBTransactions.WithLabelValues(metrics.TxRolledBack).Inc()
+		return apperr.FromDB(err)
+	}
+	metrics.DBTransactions.WithLabelValues(metrics.TxCommitted).Inc()
+	return nil
+}
+
+// Postgres error codes of a transaction that lost to a concurrent one
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// retryableTx tells whether the transaction failed only because of a concurrent one, so that
+// running it again may succeed
+func retryableTx(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	switch pqErr.Code {
+	case serializationFailure, deadlockDetected:
+		return true
+	}
+	return false
+}
+
+// CreateAccountTxParams describe the first account of a new customer; its Owner and Email
+// become the customer's name and email.
+type CreateAccountTxParams struct {
+	CreateAccountParams
+	HashedPassword string `json:"-"`
+}
+
+type CreateAccountTxResult struct {
+	Customer   Customer           `json:"customer"`
+	Account    Account            `json:"account"`
+	Credential CustomerCredential `json:"-"`
+}
+
+// ErrUnsupportedCurrency is returned when an account is opened in a currency that is not in
+// the currency registry.
+var ErrUnsupportedCurrency = apperr.New(apperr.KindValidation, "unsupported_currency", "unsupported currency")
+
+// CreateAccountTx signs up a customer with their first account and the credential they sign in
+// with, so a customer never exists without a way to sign in.
+func (store txStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (CreateAccountTxResult, error) {
+	var result CreateAccountTxResult
+
+	err := store.execTx(ctx, func(q Querier) error {
+		var err error
+		result, err = createCustomerWithAccount(ctx, q, arg)
 		return err
+	})
+
+	if err == nil {
+		metrics.AccountsCreated.WithLabelValues(result.Account.Currency).Inc()
+	}
+	return result, err
+}
+
+func createCustomerWithAccount(ctx context.Context, q Querier, arg CreateAccountTxParams) (result CreateAccountTxResult, err error) {
+	if !util.IsSupportedCurrency(arg.Currency) {
+		err = fmt.Errorf("%w: %q", ErrUnsupportedCurrency, arg.Currency)
+		return
+	}
+
+	result.Customer, err = q.CreateCustomer(ctx, CreateCustomerParams{
+		Email:     arg.Email,
+		Name:      arg.Owner,
+		CreatedAt: arg.CreatedAt,
+	})
+	if err != nil {
+		return
+	}
+
+	result.Credential, err = q.CreateCustomerCredential(ctx, CreateCustomerCredentialParams{
+		CustomerID:     result.Customer.ID,
+		HashedPassword: arg.HashedPassword,
+	})
+	if err != nil {
+		return
+	}
+
+	account := arg.CreateAccountParams
+	account.CustomerID = sql.NullInt64{Int64: result.Customer.ID, Valid: true}
+	result.Account, err = q.CreateAccount(ctx, account)
+	if err != nil {
+		return
 	}
-	return tx.Commit()
+
+	err = publishAccountCreated(ctx, q, result.Account)
+	return
+}
+
+type OpenAccountTxParams struct {
+	CustomerID int64     `json:"customer_id"`
+	Currency   string    `json:"currency"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type OpenAccountTxResult struct {
+	Customer Customer `json:"customer"`
+	Account  Account  `json:"account"`
+}
+
+// ErrCurrencyAccountExists is returned when a customer opens a second account in a currency
+var ErrCurrencyAccountExists = apperr.New(apperr.KindConflict, "currency_account_exists", "customer already has an account in the currency")
+
+// OpenAccountTx opens another account for an existing customer. A customer holds at most one
+// account per currency; the customer row is locked, so two requests for the same currency
+// cannot both get past the check.
+func (store txStore) OpenAccountTx(ctx context.Context, arg OpenAccountTxParams) (OpenAccountTxResult, error) {
+	var result OpenAccountTxResult
+
+	err := store.execTx(ctx, func(q Querier) error {
+		if !util.IsSupportedCurrency(arg.Currency) {
+			return fmt.Errorf("%w: %q", ErrUnsupportedCurrency, arg.Currency)
+		}
+
+		var err error
+		result.Customer, err = q.GetCustomerForUpdate(ctx, arg.CustomerID)
+		if err != nil {
+			return err
+		}
+
+		customerID := sql.NullInt64{Int64: result.Customer.ID, Valid: true}
+		accounts, err := q.ListCustomerAccounts(ctx, customerID)
+		if err != nil {
+			return err
+		}
+		for _, account := range accounts {
+			if account.Currency == arg.Currency {
+				return fmt.Errorf("%w: %s account [%d]", ErrCurrencyAccountExists, account.Currency, account.ID)
+			}
+		}
+
+		result.Account, err = q.CreateAccount(ctx, CreateAccountParams{
+			Owner:      result.Customer.Name,
+			Email:      result.Customer.Email,
+			Currency:   arg.Currency,
+			CreatedAt:  arg.CreatedAt,
+			CustomerID: customerID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return publishAccountCreated(ctx, q, result.Account)
+	})
+
+	if err == nil {
+		metrics.AccountsCreated.WithLabelValues(result.Account.Currency).Inc()
+	}
+	return result, err
+}
+
+type SignupWithReferralTxParams struct {
+	CreateAccountTxParams
+	ReferralCode string `json:"referral_code"`
+	// welcome bonus credited to the new account, in its currency
+	BonusAmount int64 `json:"bonus_amount"`
+}
+
+type SignupWithReferralTxResult struct {
+	CreateAccountTxResult
+	ReferralCode    ReferralCode    `json:"referral_code"`
+	ReferralHistory ReferralHistory `json:"referral_history"`
+	BonusTransfer   Transfer        `json:"bonus_transfer"`
+}
+
+var (
+	ErrReferralCodeNotFound    = apperr.New(apperr.KindNotFound, "referral_code_not_found", "referral code not found")
+	ErrReferralCodeUsed        = apperr.New(apperr.KindConflict, "referral_code_used", "referral code is already used")
+	ErrSelfReferral            = apperr.New(apperr.KindUnprocessable, "self_referral", "referral code belongs to the customer of the referred account")
+	ErrReferredAccountNotFound = apperr.New(apperr.KindNotFound, "referred_account_not_found", "referred account not found")
+	ErrAlreadyReferred         = apperr.New(apperr.KindConflict, "already_referred", "the customer of the referred account was referred already")
+)
+
+// ReferralTerms are the terms of the referral program, zero fields take those of
+// DefaultReferralTerms
+type ReferralTerms struct {
+	// extra interest in percent a referrer earns per redeemed code, up to MaxExtraInterest
+	ExtraInterest    float64
+	MaxExtraInterest float64
+	// months the extra interest stays in effect
+	ExtraInterestDuration int32
+	// day of the month a referral period starts on, it ends the day before a month later
+	CutoffDay int
+}
+
+var DefaultReferralTerms = ReferralTerms{
+	ExtraInterest:         1.0,
+	MaxExtraInterest:      10.0,
+	ExtraInterestDuration: 9,
+	CutoffDay:             21,
+}
+
+func (terms ReferralTerms) withDefaults() ReferralTerms {
+	if terms.ExtraInterest <= 0 {
+		terms.ExtraInterest = DefaultReferralTerms.ExtraInterest
+	}
+	if terms.MaxExtraInterest <= 0 {
+		terms.MaxExtraInterest = DefaultReferralTerms.MaxExtraInterest
+	}
+	if terms.ExtraInterestDuration <= 0 {
+		terms.ExtraInterestDuration = DefaultReferralTerms.ExtraInterestDuration
+	}
+	if terms.CutoffDay <= 0 {
+		terms.CutoffDay = DefaultReferralTerms.CutoffDay
+	}
+	return terms
+}
+
+// SignupWithReferralTx creates an account for someone who signs up with a referral code. The
+// code is locked and burned, the welcome bonus is paid from the bonus system account of the
+// currency and the referral is recorded, all or nothing.
+func (store txStore) SignupWithReferralTx(ctx context.Context, arg SignupWithReferralTxParams) (SignupWithReferralTxResult, error) {
+	var result SignupWithReferralTxResult
+
+	err := store.execTx(ctx, func(q Querier) error {
+		var err error
+		result.ReferralCode, err = q.GetReferralCodeForUpdate(ctx, arg.ReferralCode)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrReferralCodeNotFound
+			}
+			return err
+		}
+
+		if result.ReferralCode.IsUsed {
+			return ErrReferralCodeUsed
+		}
+
+		result.CreateAccountTxResult, err = createCustomerWithAccount(ctx, q, arg.CreateAccountTxParams)
+		if err != nil {
+			return err
+		}
+
+		result.ReferralCode, err = q.MarkReferralCodeUsed(ctx, MarkReferralCodeUsedParams{
+			ReferralCode: arg.ReferralCode,
+			UsedAt:       sql.NullTime{Time: arg.CreatedAt, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.ReferralHistory, err = q.CreateReferralHistory(ctx, CreateReferralHistoryParams{
+			ReferrerAccountID:  result.ReferralCode.ReferrerAccountID,
+			ReferredAccountID:  result.Account.ID,
+			ReferralCodeID:     result.ReferralCode.ID,
+			ReferralDate:       arg.CreatedAt,
+			CreatedAt:          arg.CreatedAt,
+			ReferredCustomerID: result.Account.CustomerID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.BonusAmount > 0 {
+			bonusAccount, err := q.GetSystemAccountForUpdate(ctx, GetSystemAccountForUpdateParams{
+				AccountType: util.BonusAccount,
+				Currency:    result.Account.Currency,
+			})
+			if err != nil {
+				return err
+			}
+
+			// the bonus account pays out without a funds check, its balance is what the bonuses cost
+			bonus, err := moveMoney(ctx, q, TransferTxParams{
+				FromAccountID: bonusAccount.ID,
+				ToAccountID:   result.Account.ID,
+				Amount:        arg.BonusAmount,
+			}, ReferenceBonus)
+			if err != nil {
+				return err
+			}
+
+			result.BonusTransfer = bonus.Transfer
+			result.Account = bonus.ToAccount
+		}
+
+		return publishReferralRedeemed(ctx, q, result.ReferralHistory, true, result.BonusTransfer.Amount)
+	})
+
+	if err == nil {
+		metrics.AccountsCreated.WithLabelValues(result.Account.Currency).Inc()
+		metrics.ReferralCodesRedeemed.WithLabelValues("true").Inc()
+	}
+	return result, err
+}
+
+type RedeemReferralCodeTxParams struct {
+	ReferralCode      string        `json:"referral_code"`
+	ReferredAccountID int64         `json:"referred_account_id"`
+	RedeemedAt        time.Time     `json:"redeemed_at"`
+	Terms             ReferralTerms `json:"-"`
+}
+
+type RedeemReferralCodeTxResult struct {
+	ReferralCode    ReferralCode    `json:"referral_code"`
+	ReferralHistory ReferralHistory `json:"referral_history"`
+	ReferrerAccount Account         `json:"referrer_account"`
+}
+
+// RedeemReferralCodeTx lets an existing account redeem someone else's referral code. The code
+// is locked so it can only be redeemed once, and a customer is referred once, whichever of
+// their accounts redeems a code. The referral is recorded and the referrer earns
+// extra interest from the first day of next month. A referrer whose extra interest is still in
+// effect, or about to start, keeps its start date, see referralExtraInterest.
+func (store txStore) RedeemReferralCodeTx(ctx context.Context, arg RedeemReferralCodeTxParams) (RedeemReferralCodeTxResult, error) {
+	var result RedeemReferralCodeTxResult
+
+	err := store.execTx(ctx, func(q Querier) error {
+		var err error
+		result.ReferralCode, err = q.GetReferralCodeForUpdate(ctx, arg.ReferralCode)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrReferralCodeNotFound
+			}
+			return err
+		}
+
+		if result.ReferralCode.IsUsed {
+			return ErrReferralCodeUsed
+		}
+
+		if result.ReferralCode.ReferrerAccountID == arg.ReferredAccountID {
+			return ErrSelfReferral
+		}
+
+		referred, err := q.GetAccount(ctx, arg.ReferredAccountID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrReferredAccountNotFound
+			}
+			return err
+		}
+		// a customer holds an account per currency, any of them may not redeem the codes of another
+		if referred.CustomerID.Valid {
+			referrer, err := q.GetAccount(ctx, result.ReferralCode.ReferrerAccountID)
+			if err != nil {
+				return err
+			}
+			if referrer.CustomerID == referred.CustomerID {
+				return ErrSelfReferral
+			}
+		}
+
+		// a concurrent redemption by another account of the customer runs into the unique
+		// referred_customer_id instead
+		referredAlready, err := q.HasBeenReferred(ctx, HasBeenReferredParams{
+			ReferredAccountID:  referred.ID,
+			ReferredCustomerID: referred.CustomerID,
+		})
+		if err != nil {
+			return err
+		}
+		if referredAlready {
+			return ErrAlreadyReferred
+		}
+
+		result.ReferralCode, err = q.MarkReferralCodeUsed(ctx, MarkReferralCodeUsedParams{
+			ReferralCode: arg.ReferralCode,
+			UsedAt:       sql.NullTime{Time: arg.RedeemedAt, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.ReferralHistory, err = q.CreateReferralHistory(ctx, CreateReferralHistoryParams{
+			ReferrerAccountID:  result.ReferralCode.ReferrerAccountID,
+			ReferredAccountID:  arg.ReferredAccountID,
+			ReferralCodeID:     result.ReferralCode.ID,
+			ReferralDate:       arg.RedeemedAt,
+			CreatedAt:          arg.RedeemedAt,
+			ReferredCustomerID: referred.CustomerID,
+			// the extra interest is added right below, the monthly run leaves the referral alone
+			ExtraInterestAppliedAt: sql.NullTime{Time: arg.RedeemedAt, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = publishReferralRedeemed(ctx, q, result.ReferralHistory, false, 0)
+		if err != nil {
+			return err
+		}
+
+		referrer, err := q.GetAccountForUpdate(ctx, result.ReferralCode.ReferrerAccountID)
+		if err != nil {
+			return err
+		}
+
+		err = recordInterestChange(ctx, q, referrer)
+		if err != nil {
+			return err
+		}
+
+		result.ReferrerAccount, err = q.UpdateAccountInterest(ctx, referralExtraInterest(referrer, arg.Terms.withDefaults(), arg.RedeemedAt))
+		if err != nil {
+			return err
+		}
+
+		return publishExtraInterestUpdated(ctx, q, result.ReferrerAccount, ExtraInterestReferralRedeemed)
+	})
+
+	if err == nil {
+		metrics.ReferralCodesRedeemed.WithLabelValues("false").Inc()
+		metrics.ExtraInterestUpdates.WithLabelValues(ExtraInterestReferralRedeemed).Inc()
+	}
+	return result, err
+}
+
+// recordInterestChange keeps the extra interest terms of a locked account that is about to get
+// new ones, so the days before the change that accrue late still earn the rate in effect on them.
+func recordInterestChange(ctx context.Context, q Querier, account Account) error {
+	_, err := q.CreateAccountInterestChange(ctx, CreateAccountInterestChangeParams{
+		AccountID:              account.ID,
+		ExtraInterest:          account.ExtraInterest,
+		ExtraInterestStartDate: account.ExtraInterestStartDate,
+		ExtraInterestDuration:  account.ExtraInterestDuration,
+	})
+	return err
+}
+
+// referralExtraInterest returns the extra interest of the referrer once a code of theirs was
+// redeemed at redeemedAt: the rate of the terms on top of what they earn, up to the maximum, for
+// the duration of the terms from the first day of next month. Extra interest in effect, or about
+// to start, keeps its start date, so the days already earning it go on doing so; its duration is
+// extended to cover as many months as new extra interest would.
+func referralExtraInterest(referrer Account, terms ReferralTerms, redeemedAt time.Time) UpdateAccountInterestParams {
+	expiry, ok := ExtraInterestExpiry(referrer)
+	year, month, day := redeemedAt.Date()
+	active := ok && expiry.After(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+
+	// extra interest that is over and only waits for the expiry job to reset it counts for nothing
+	extraInterest := terms.ExtraInterest
+	if referrer.ExtraInterest.Valid && (active || !referrer.ExtraInterestStartDate.Valid) {
+		extraInterest += referrer.ExtraInterest.Float64
+	}
+
+	arg := UpdateAccountInterestParams{
+		ID:                     referrer.ID,
+		ExtraInterest:          sql.NullFloat64{Float64: min(extraInterest, terms.MaxExtraInterest), Valid: true},
+		ExtraInterestStartDate: sql.NullTime{Time: getFirstDayOfNextMonth(redeemedAt), Valid: true},
+		ExtraInterestDuration:  terms.ExtraInterestDuration,
+	}
+
+	if !active {
+		return arg
+	}
+
+	// months from the start of the extra interest to the first day of next month
+	start, current := arg.ExtraInterestStartDate.Time, referrer.ExtraInterestStartDate.Time
+	elapsed := int32(start.Year()-current.Year())*12 + int32(start.Month()-current.Month())
+	arg.ExtraInterestStartDate = referrer.ExtraInterestStartDate
+	arg.ExtraInterestDuration = max(referrer.ExtraInterestDuration, elapsed+terms.ExtraInterestDuration)
+	return arg
 }
 
 type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// converts the amount at the rate of the quote, for transfers between currencies
+	FxQuoteID uuid.NullUUID `json:"fx_quote_id"`
 }
 
 type TransferTxResult struct {
@@ -53,162 +591,667 @@ type TransferTxResult struct {
 	ToEntry     Entry    `json:"to_entry"`
 }
 
-func (store *Store) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
-	var result TransferTxResult
+type AdjustBalanceTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// credited to the account, debited when negative
+	Amount int64 `json:"amount"`
+	// why the balance is corrected
+	Memo string `json:"memo"`
+}
+
+type AdjustBalanceTxResult struct {
+	Journal Journal `json:"journal"`
+	Entry   Entry   `json:"entry"`
+	Account Account `json:"account"`
+}
+
+// AdjustBalanceTx corrects the balance of an account by hand, against the adjustment system
+// account of its currency.
+func (store txStore) AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams) (AdjustBalanceTxResult, error) {
+	var result AdjustBalanceTxResult
+
+	err := store.execTx(ctx, func(q Querier) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		adjustmentAccount, err := q.GetSystemAccountForUpdate(ctx, GetSystemAccountForUpdateParams{
+			AccountType: util.AdjustmentAccount,
+			Currency:    account.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		journal, err := postJournal(ctx, q, journalParams{
+			ReferenceType: ReferenceAdjustment,
+			Memo:          arg.Memo,
+			Legs: []journalLeg{
+				{AccountID: account.ID, Amount: arg.Amount},
+				{AccountID: adjustmentAccount.ID, Amount: -arg.Amount},
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Journal = journal.Journal
+		result.Entry = journal.Entries[0]
+		result.Account = journal.Accounts[0]
+		return nil
+	})
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	return result, err
+}
+
+type RequestPasswordResetTxParams struct {
+	CustomerID int64     `json:"customer_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RequestPasswordResetTx records that the customer asked to choose a password and publishes the
+// event the customer is emailed the token for
+func (store txStore) RequestPasswordResetTx(ctx context.Context, arg RequestPasswordResetTxParams) (PasswordReset, error) {
+	var reset PasswordReset
+
+	err := store.execTx(ctx, func(q Querier) error {
 		var err error
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
+		reset, err = q.CreatePasswordReset(ctx, CreatePasswordResetParams(arg))
+		if err != nil {
+			return err
+		}
+
+		return publishEvent(ctx, q, EventPasswordResetRequested, AggregateCustomer, reset.CustomerID, PasswordResetRequestedEvent{
+			PasswordResetID: reset.ID,
+			CustomerID:      reset.CustomerID,
+			ExpiresAt:       reset.ExpiresAt,
 		})
+	})
+
+	return reset, err
+}
+
+type ResetPasswordTxParams struct {
+	// SHA-256 of the token the customer was emailed, see util.HashResetToken
+	TokenHash      string    `json:"token_hash"`
+	HashedPassword string    `json:"hashed_password"`
+	Now            time.Time `json:"now"`
+}
+
+// ErrInvalidResetToken is returned by ResetPasswordTx for a token that is unknown, was used
+// already, or expired
+var ErrInvalidResetToken = apperr.New(apperr.KindValidation, "invalid_reset_token", "the password reset token is invalid or expired")
+
+// ResetPasswordTx sets the password of the customer a reset token was emailed to, creating the
+// credential of a customer who had none. The token can only be used once, and the sessions of
+// the customer are blocked, so whoever knew the old password is signed out.
+func (store txStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (CustomerCredential, error) {
+	var credential CustomerCredential
+
+	err := store.execTx(ctx, func(q Querier) error {
+		reset, err := q.GetPasswordResetByTokenForUpdate(ctx, sql.NullString{String: arg.TokenHash, Valid: true})
 		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidResetToken
+			}
 			return err
 		}
+		if reset.UsedAt.Valid || !reset.ExpiresAt.After(arg.Now) {
+			return ErrInvalidResetToken
+		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+		err = q.UsePasswordReset(ctx, UsePasswordResetParams{
+			ID:     reset.ID,
+			UsedAt: sql.NullTime{Time: arg.Now, Valid: true},
 		})
 		if err != nil {
 			return err
 		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+		credential, err = q.SetCustomerPassword(ctx, SetCustomerPasswordParams{
+			CustomerID:        reset.CustomerID,
+			HashedPassword:    arg.HashedPassword,
+			PasswordChangedAt: arg.Now,
 		})
 		if err != nil {
 			return err
 		}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
+		return q.BlockCustomerSessions(ctx, reset.CustomerID)
+	})
+
+	return credential, err
+}
+
+var (
+	// ErrInsufficientFunds is returned by TransferTx when the source account cannot cover the
+	// amount, even after its overdraft limit is taken into account.
+	ErrInsufficientFunds = apperr.New(apperr.KindInsufficientFunds, "insufficient_funds", "insufficient funds")
+	// ErrCurrencyMismatch is returned by TransferTx when the accounts are held in different
+	// currencies, money only changes currency through an explicit conversion.
+	ErrCurrencyMismatch = apperr.New(apperr.KindValidation, "currency_mismatch", "currency mismatch")
+	ErrFxQuoteNotFound  = apperr.New(apperr.KindNotFound, "fx_quote_not_found", "fx quote not found")
+	ErrFxQuoteExpired   = apperr.New(apperr.KindConflict, "fx_quote_expired", "fx quote expired")
+	ErrFxQuoteUsed      = apperr.New(apperr.KindConflict, "fx_quote_used", "fx quote is already used")
+	// ErrFxQuoteMismatch is returned by TransferTx when the transfer is not the one quoted: another
+	// source account, other currencies or another amount.
+	ErrFxQuoteMismatch = apperr.New(apperr.KindValidation, "fx_quote_mismatch", "transfer does not match the fx quote")
+)
+
+func (store txStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q Querier) error {
+		// lock both accounts before touching any balance, so the funds check below
+		// sees the latest committed balance and concurrent transfers queue up behind it
+		fromAccount, toAccount, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		if fromAccount.Currency != toAccount.Currency && !arg.FxQuoteID.Valid {
+			return fmt.Errorf("account [%d] holds %s, account [%d] holds %s: %w",
+				fromAccount.ID, fromAccount.Currency, toAccount.ID, toAccount.Currency, ErrCurrencyMismatch)
+		}
+
+		var quote FxQuote
+		if arg.FxQuoteID.Valid {
+			quote, err = lockFxQuote(ctx, q, arg, fromAccount, toAccount)
+			if err != nil {
+				return err
+			}
+		}
+
+		if fromAccount.Balance+fromAccount.OverdraftLimit < arg.Amount {
+			return fmt.Errorf("account [%d] balance %d, overdraft limit %d, amount %d: %w",
+				fromAccount.ID, fromAccount.Balance, fromAccount.OverdraftLimit, arg.Amount, ErrInsufficientFunds)
+		}
+
+		if arg.FxQuoteID.Valid {
+			result, err = convertMoney(ctx, q, arg, quote)
 		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+			result, err = moveMoney(ctx, q, arg, ReferenceTransfer)
+		}
+		if err != nil {
+			return err
 		}
 
-		return nil
+		return publishTransferCompleted(ctx, q, result)
 	})
 
+	if err == nil {
+		metrics.Transfers.WithLabelValues(result.FromAccount.Currency).Inc()
+		metrics.TransferVolume.WithLabelValues(result.FromAccount.Currency).Add(float64(result.Transfer.Amount))
+	}
 	return result, err
 }
 
-func addMoney(ctx context.Context, q *Queries, accountID1 int64, amount1 int64, accountID2 int64, amount2 int64) (account1 Account, account2 Account, err error) {
-	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID1,
-		Amount: amount1,
+// lockFxQuote locks the quote of a transfer between currencies and checks that it is still good
+// and quoted exactly this transfer.
+func lockFxQuote(ctx context.Context, q Querier, arg TransferTxParams, fromAccount Account, toAccount Account) (FxQuote, error) {
+	quote, err := q.GetFxQuoteForUpdate(ctx, arg.FxQuoteID.UUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return quote, ErrFxQuoteNotFound
+		}
+		return quote, err
+	}
+
+	switch {
+	case quote.UsedAt.Valid:
+		return quote, ErrFxQuoteUsed
+	case !time.Now().Before(quote.ExpiresAt):
+		return quote, fmt.Errorf("%w at %s", ErrFxQuoteExpired, quote.ExpiresAt.Format(time.RFC3339))
+	case quote.AccountID != fromAccount.ID || quote.FromCurrency != fromAccount.Currency ||
+		quote.ToCurrency != toAccount.Currency || quote.Amount != arg.Amount:
+		return quote, fmt.Errorf("%w: quoted %d %s from account [%d] to %s, got %d %s from account [%d] to %s",
+			ErrFxQuoteMismatch, quote.Amount, quote.FromCurrency, quote.AccountID, quote.ToCurrency,
+			arg.Amount, fromAccount.Currency, fromAccount.ID, toAccount.Currency)
+	}
+	return quote, nil
+}
+
+// convertMoney moves money between accounts of different currencies at the rate of a locked
+// quote and burns the quote. The transfer is posted as one journal through the fx system
+// accounts, which buy the amount in one currency and sell the converted amount in the other, so
+// the legs of each currency balance on their own.
+func convertMoney(ctx context.Context, q Querier, arg TransferTxParams, quote FxQuote) (result TransferTxResult, err error) {
+	fxAccounts, err := lockFxAccounts(ctx, q, quote.FromCurrency, quote.ToCurrency)
+	if err != nil {
+		return
+	}
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID:   arg.FromAccountID,
+		ToAccountID:     arg.ToAccountID,
+		Amount:          arg.Amount,
+		FxQuoteID:       arg.FxQuoteID,
+		FxRate:          sql.NullString{String: quote.Rate, Valid: true},
+		ConvertedAmount: sql.NullInt64{Int64: quote.ConvertedAmount, Valid: true},
+	})
+	if err != nil {
+		return
+	}
+
+	journal, err := postJournal(ctx, q, journalParams{
+		ReferenceType: ReferenceTransfer,
+		TransferID:    sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		Memo:          fmt.Sprintf("%s/%s at %s", quote.FromCurrency, quote.ToCurrency, quote.Rate),
+		Legs: []journalLeg{
+			{AccountID: arg.FromAccountID, Amount: -arg.Amount},
+			{AccountID: fxAccounts[quote.FromCurrency].ID, Amount: arg.Amount},
+			{AccountID: fxAccounts[quote.ToCurrency].ID, Amount: -quote.ConvertedAmount},
+			{AccountID: arg.ToAccountID, Amount: quote.ConvertedAmount},
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	_, err = q.MarkFxQuoteUsed(ctx, MarkFxQuoteUsedParams{
+		ID:     quote.ID,
+		UsedAt: sql.NullTime{Time: result.Transfer.CreatedAt, Valid: true},
+	})
+	if err != nil {
+		return
+	}
+
+	result.FromEntry, result.ToEntry = journal.Entries[0], journal.Entries[3]
+	result.FromAccount, result.ToAccount = journal.Accounts[0], journal.Accounts[3]
+	return
+}
+
+// lockFxAccounts takes the row locks of the fx system accounts of both currencies, always in
+// the order of the currency codes so that conversions in opposite directions cannot deadlock.
+func lockFxAccounts(ctx context.Context, q Querier, currencies ...string) (map[string]Account, error) {
+	sorted := slices.Clone(currencies)
+	slices.Sort(sorted)
+
+	accounts := make(map[string]Account, len(sorted))
+	for _, currency := range sorted {
+		account, err := q.GetSystemAccountForUpdate(ctx, GetSystemAccountForUpdateParams{
+			AccountType: util.FXAccount,
+			Currency:    currency,
+		})
+		if err != nil {
+			return nil, err
+		}
+		accounts[currency] = account
+	}
+	return accounts, nil
+}
+
+// moveMoney records the transfer and posts it as a journal debiting the source and crediting
+// the destination account. Callers check the funds first when the source account has to cover
+// the amount.
+func moveMoney(ctx context.Context, q Querier, arg TransferTxParams, referenceType string) (result TransferTxResult, err error) {
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
 	})
 	if err != nil {
 		return
 	}
 
-	account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID2,
-		Amount: amount2,
+	journal, err := postJournal(ctx, q, journalParams{
+		ReferenceType: referenceType,
+		TransferID:    sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		Legs: []journalLeg{
+			{AccountID: arg.FromAccountID, Amount: -arg.Amount},
+			{AccountID: arg.ToAccountID, Amount: arg.Amount},
+		},
 	})
+	if err != nil {
+		return
+	}
+
+	result.FromEntry, result.ToEntry = journal.Entries[0], journal.Entries[1]
+	result.FromAccount, result.ToAccount = journal.Accounts[0], journal.Accounts[1]
 	return
 }
 
+// lockAccounts takes the row locks of both transfer accounts, always lowest ID first so that
+// transfers running in opposite directions cannot deadlock, and returns the source and the
+// destination account.
+func lockAccounts(ctx context.Context, q Querier, fromAccountID int64, toAccountID int64) (from Account, to Account, err error) {
+	firstID, secondID := fromAccountID, toAccountID
+	if firstID > secondID {
+		firstID, secondID = secondID, firstID
+	}
+
+	first, err := q.GetAccountForUpdate(ctx, firstID)
+	if err != nil {
+		return
+	}
+
+	second, err := q.GetAccountForUpdate(ctx, secondID)
+	if err != nil {
+		return
+	}
+
+	if first.ID == fromAccountID {
+		return first, second, nil
+	}
+	return second, first, nil
+}
+
+type PayInterestTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// the period paid for, e.g. 2024-07
+	Period string `json:"period"`
+	// the last accrual date the period covers
+	To time.Time `json:"to"`
+}
+
+type PayInterestTxResult struct {
+	Payout   InterestPayout `json:"payout"`
+	Transfer Transfer       `json:"transfer"`
+	Account  Account        `json:"account"`
+}
+
+// microsPerUnit is how many accrued micro amounts make up one minor unit of the currency
+const microsPerUnit = 1_000_000
+
+// ErrInterestAlreadyPaid is returned by PayInterestTx when the account was already paid for the period
+var ErrInterestAlreadyPaid = apperr.New(apperr.KindConflict, "interest_already_paid", "interest already paid for the period")
+
+// PayInterestTx pays an account the interest it accrued over a period, from the interest system
+// account of its currency. Accruals of earlier days that no payout paid yet, because they were
+// recorded late, are paid along, so each accrual is paid exactly once. Fractions of the minor unit
+// are not paid. The payout is recorded even when nothing is due, so every account is paid exactly
+// once per period.
+func (store txStore) PayInterestTx(ctx context.Context, arg PayInterestTxParams) (PayInterestTxResult, error) {
+	var result PayInterestTxResult
+
+	err := store.execTx(ctx, func(q Querier) error {
+		_, err := q.GetInterestPayout(ctx, GetInterestPayoutParams{
+			AccountID: arg.AccountID,
+			Period:    arg.Period,
+		})
+		if err == nil {
+			return ErrInterestAlreadyPaid
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		accruals, err := q.MarkInterestAccrualsPaid(ctx, MarkInterestAccrualsPaidParams{
+			AccountID:   arg.AccountID,
+			AccrualDate: arg.To,
+			PaidPeriod:  sql.NullString{String: arg.Period, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		var accrued int64
+		for _, accrual := range accruals {
+			accrued += accrual.AmountMicro
+		}
+
+		result.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		var transferID sql.NullInt64
+		if amount := accrued / microsPerUnit; amount > 0 {
+			interestAccount, err := q.GetSystemAccountForUpdate(ctx, GetSystemAccountForUpdateParams{
+				AccountType: util.InterestAccount,
+				Currency:    result.Account.Currency,
+			})
+			if err != nil {
+				return err
+			}
+
+			// like the bonus account, the interest account pays out without a funds check
+			payment, err := moveMoney(ctx, q, TransferTxParams{
+				FromAccountID: interestAccount.ID,
+				ToAccountID:   arg.AccountID,
+				Amount:        amount,
+			}, ReferenceInterest)
+			if err != nil {
+				return err
+			}
+
+			result.Transfer = payment.Transfer
+			result.Account = payment.ToAccount
+			transferID = sql.NullInt64{Int64: payment.Transfer.ID, Valid: true}
+		}
+
+		result.Payout, err = q.CreateInterestPayout(ctx, CreateInterestPayoutParams{
+			AccountID:  arg.AccountID,
+			Period:     arg.Period,
+			Amount:     accrued / microsPerUnit,
+			TransferID: transferID,
+		})
+		if err != nil {
+			// a concurrent payout for the same period got there first
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInterestAlreadyPaid
+			}
+			return err
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// AuditExtraInterestExpired is the audit action recorded when an expired extra interest is reset
+const AuditExtraInterestExpired = "extra_interest_expired"
+
+// ErrExtraInterestNotExpired is returned by ExpireExtraInterestTx when the account has no extra
+// interest, or it is still in effect on the given date.
+var ErrExtraInterestNotExpired = apperr.New(apperr.KindConflict, "extra_interest_not_expired", "extra interest has not expired")
+
+// ErrExtraInterestNotAccrued is returned by ExpireExtraInterestTx when the last day of the extra
+// interest has not accrued yet. Accruing a day reads the rate from the account, so resetting it
+// first would accrue that day without the extra interest.
+var ErrExtraInterestNotAccrued = apperr.New(apperr.KindConflict, "extra_interest_not_accrued", "the last day of the extra interest has not accrued yet")
+
+type ExpireExtraInterestTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// the day the extra interest must have ended by
+	AsOf time.Time `json:"as_of"`
+}
+
+type ExpireExtraInterestTxResult struct {
+	Account    Account    `json:"account"`
+	AuditEntry AuditEntry `json:"audit_entry"`
+}
+
+type extraInterestExpiredDetail struct {
+	ExtraInterest float64 `json:"extra_interest"`
+	StartDate     string  `json:"start_date"`
+	Duration      int32   `json:"duration"`
+	ExpiredOn     string  `json:"expired_on"`
+}
+
+// ExpireExtraInterestTx resets the extra interest of an account once its duration is over and
+// its last day accrued, and records what was reset in the audit log.
+func (store txStore) ExpireExtraInterestTx(ctx context.Context, arg ExpireExtraInterestTxParams) (ExpireExtraInterestTxResult, error) {
+	var result ExpireExtraInterestTxResult
+
+	err := store.execTx(ctx, func(q Querier) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		// the account is locked now, a referral that renewed the extra interest since it was
+		// listed as expired shows up here
+		expiry, ok := ExtraInterestExpiry(account)
+		asOf := time.Date(arg.AsOf.Year(), arg.AsOf.Month(), arg.AsOf.Day(), 0, 0, 0, 0, time.UTC)
+		if !ok || expiry.After(asOf) {
+			return ErrExtraInterestNotExpired
+		}
+
+		accrued, err := q.HasInterestAccrualSince(ctx, HasInterestAccrualSinceParams{
+			AccountID:   account.ID,
+			AccrualDate: expiry.AddDate(0, 0, -1),
+		})
+		if err != nil {
+			return err
+		}
+		if !accrued {
+			return ErrExtraInterestNotAccrued
+		}
+
+		err = recordInterestChange(ctx, q, account)
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.ResetExtraInterest(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		detail, err := json.Marshal(extraInterestExpiredDetail{
+			ExtraInterest: account.ExtraInterest.Float64,
+			StartDate:     account.ExtraInterestStartDate.Time.Format(time.DateOnly),
+			Duration:      account.ExtraInterestDuration,
+			ExpiredOn:     expiry.Format(time.DateOnly),
+		})
+		if err != nil {
+			return err
+		}
+
+		result.AuditEntry, err = q.CreateAuditEntry(ctx, CreateAuditEntryParams{
+			AccountID: account.ID,
+			Action:    AuditExtraInterestExpired,
+			Detail:    detail,
+		})
+		if err != nil {
+			return err
+		}
+
+		return publishExtraInterestUpdated(ctx, q, result.Account, ExtraInterestExpired)
+	})
+
+	if err == nil {
+		metrics.ExtraInterestUpdates.WithLabelValues(ExtraInterestExpired).Inc()
+	}
+	return result, err
+}
+
+// ExtraInterestExpiry returns the first day the extra interest of the account is no longer in
+// effect: its start date plus its duration in months. It reports false when the account has no
+// extra interest.
+func ExtraInterestExpiry(account Account) (time.Time, bool) {
+	if account.ExtraInterest.Float64 <= 0 || !account.ExtraInterestStartDate.Valid {
+		return time.Time{}, false
+	}
+
+	// a DATE reads back as midnight UTC, keep the calendar date as written
+	start := account.ExtraInterestStartDate.Time
+	expiry := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	return expiry.AddDate(0, int(account.ExtraInterestDuration), 0), true
+}
+
 type UseReferralCodeTxParams struct {
 	ReferrerAccountID int64 `json:"referrer_account_id"`
+	// day the calculation runs for, today when zero
+	Date  time.Time     `json:"date"`
+	Terms ReferralTerms `json:"-"`
 }
 
 type UseReferralCodeTxResult struct {
 	ReferrerAccountUpdate Account `json:"referrer_account"`
 }
 
-// UseReferralCodeTx Calculate Interest for the following month
-func (store *Store) UseReferralCodeTx(ctx context.Context, arg UseReferralCodeTxParams) (UseReferralCodeTxResult, error) {
+// UseReferralCodeTx adds the extra interest of the referrals in the referral period of
+// arg.Date that the referrer has not got yet, the ones of signups with a referral code, one by
+// one as if each code had been redeemed on arg.Date, see referralExtraInterest. A referral is
+// applied once, so running the period again leaves the account as it is.
+func (store txStore) UseReferralCodeTx(ctx context.Context, arg UseReferralCodeTxParams) (UseReferralCodeTxResult, error) {
 	var result UseReferralCodeTxResult
+	// the extra interest is only updated when the referrer got referrals in the period
+	var updated bool
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	err := store.execTx(ctx, func(q Querier) error {
+		// a retried transaction starts over
+		updated = false
 		var err error
 
-		// TODO: perform logic to give benefit to referrer_account_id
-		result.ReferrerAccountUpdate, err = q.GetAccountForUpdate(context.Background(), arg.ReferrerAccountID)
+		result.ReferrerAccountUpdate, err = q.GetAccountForUpdate(ctx, arg.ReferrerAccountID)
 		if err != nil {
 			return err
 		}
 
-		var currentExtraInterest, newExtraInterest float64
-		if result.ReferrerAccountUpdate.ExtraInterest.Valid {
-			currentExtraInterest = result.ReferrerAccountUpdate.ExtraInterest.Float64
+		currentDate := arg.Date
+		if currentDate.IsZero() {
+			currentDate = utils.ConvertToTokyoTime()
 		}
 
-		loc, err := tz.LoadLocation("Asia/Tokyo")
+		terms := arg.Terms.withDefaults()
+		startDate, endDate, err := ReferralDateRange(currentDate, terms.CutoffDay)
 		if err != nil {
 			return err
 		}
 
-		currentDate := utils.ConvertToTokyoTime()
-		year, month, _ := currentDate.Date()
-
-		// Determine the date range for the previous month
-		var startDate, endDate time.Time
-		if month == time.January {
-			year--
-			startDate = time.Date(year, time.December, 21, 0, 0, 0, 0, loc)
-			endDate = time.Date(year, time.January, 20, 23, 59, 59, 0, loc)
-		} else {
-			startDate = time.Date(year, month-1, 21, 0, 0, 0, 0, loc)
-			endDate = time.Date(year, month, 20, 23, 59, 59, 0, loc)
-		}
-
-		args := GetReferralsByDateRangeParams{
+		referrals, err := q.ListUnappliedReferrals(ctx, ListUnappliedReferralsParams{
 			ReferrerAccountID: result.ReferrerAccountUpdate.ID,
-			CreatedAt:         startDate,
-			CreatedAt_2:       endDate,
-		}
-
-		referralCount, err := q.GetReferralsByDateRange(ctx, args)
+			ReferralDate:      startDate,
+			ReferralDate_2:    endDate,
+		})
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				return fmt.Errorf("referral code not found")
-			}
 			return err
 		}
 
-		// updating the new extra interest
-		if referralCount > 0 {
-			if currentExtraInterest >= 10.0 {
-				newExtraInterest = currentExtraInterest
-			} else {
-				if referralCount == int64(currentExtraInterest) {
-					newExtraInterest = currentExtraInterest
-				} else {
-					newExtraInterest = float64(referralCount)
-					if newExtraInterest > 10.0 {
-						newExtraInterest = 10.0
-					}
-				}
+		for _, referral := range referrals {
+			err = recordInterestChange(ctx, q, result.ReferrerAccountUpdate)
+			if err != nil {
+				return err
 			}
 
-			extraInterestStartDate := getFirstDayOfNextMonth(currentDate)
-
-			if newExtraInterest > 0 {
-				// update the new interest here
-				updateInterestArgs := UpdateAccountInterestParams{
-					ID:                     result.ReferrerAccountUpdate.ID,
-					ExtraInterest:          sql.NullFloat64{Float64: newExtraInterest, Valid: true},
-					ExtraInterestStartDate: sql.NullTime{Time: extraInterestStartDate, Valid: true},
-					ExtraInterestDuration:  9,
-				}
+			result.ReferrerAccountUpdate, err = q.UpdateAccountInterest(ctx,
+				referralExtraInterest(result.ReferrerAccountUpdate, terms, currentDate))
+			if err != nil {
+				return err
+			}
 
-				result.ReferrerAccountUpdate, err = q.UpdateAccountInterest(ctx, updateInterestArgs)
-				if err != nil {
-					return err
-				}
+			err = q.MarkReferralExtraInterestApplied(ctx, MarkReferralExtraInterestAppliedParams{
+				ID:                     referral.ID,
+				ExtraInterestAppliedAt: sql.NullTime{Time: utils.ConvertToTokyoTime(), Valid: true},
+			})
+			if err != nil {
+				return err
 			}
 		}
 
-		return nil
+		if len(referrals) == 0 {
+			return nil
+		}
+		updated = true
+		return publishExtraInterestUpdated(ctx, q, result.ReferrerAccountUpdate, ExtraInterestRecalculated)
 	})
 
+	if err == nil && updated {
+		metrics.ExtraInterestUpdates.WithLabelValues(ExtraInterestRecalculated).Inc()
+	}
 	return result, err
 }
 
+// ReferralDateRange returns the referral period that ends in the month of date: from the cutoff
+// day of the month before through the day before the cutoff day, in Tokyo time. With the
+// default cutoff day that is the 21st through the 20th.
+func ReferralDateRange(date time.Time, cutoffDay int) (time.Time, time.Time, error) {
+	loc, err := tz.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	year, month, _ := date.In(loc).Date()
+	startDate := time.Date(year, month-1, cutoffDay, 0, 0, 0, 0, loc)
+	endDate := time.Date(year, month, cutoffDay-1, 23, 59, 59, 0, loc)
+	return startDate, endDate, nil
+}
+
 func getFirstDayOfNextMonth(currentDate time.Time) time.Time {
 	year, month, _ := currentDate.Date()
 	if month == time.December {
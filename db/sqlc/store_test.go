@@ -1,10 +1,16 @@
 package sqlc
 
 import (
+	"4d63.com/tz"
+	"bank-api/metrics"
 	"bank-api/util"
 	"context"
 	"database/sql"
+	"encoding/json"
 	"github.com/Meenachinmay/microservice-shared/utils"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"math/rand"
 	"sync"
@@ -14,11 +20,11 @@ import (
 
 func TestTransferTx(t *testing.T) {
 	store := testStore
-	account1 := CreateRandomAccount(t)
-	account2 := CreateRandomAccount(t)
 
 	n := 5
 	amount := int64(10)
+	account1 := createFundedAccount(t, util.RandomCurrency(), int64(n)*amount)
+	account2 := createRandomAccountIn(t, account1.Currency)
 	errs := make(chan error)
 	results := make(chan TransferTxResult)
 
@@ -109,11 +115,11 @@ func TestTransferTx(t *testing.T) {
 
 func TestTransferTxDeadlock(t *testing.T) {
 	store := testStore
-	account1 := CreateRandomAccount(t)
-	account2 := CreateRandomAccount(t)
 
 	n := 10
 	amount := int64(10)
+	account1 := createFundedAccount(t, util.RandomCurrency(), int64(n)*amount)
+	account2 := createFundedAccount(t, account1.Currency, int64(n)*amount)
 	errs := make(chan error)
 
 	for i := 0; i < n; i++ {
@@ -152,28 +158,433 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
 
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := testStore
+	account1 := CreateRandomAccount(t)
+	account2 := createRandomAccountIn(t, account1.Currency)
+
+	// only half of the concurrent transfers can be covered by the balance
+	n := 10
+	amount := int64(10)
+	account1, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account1.ID,
+		Balance: int64(n/2) * amount,
+	})
+	require.NoError(t, err)
+
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+			})
+
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err != nil {
+			require.ErrorIs(t, err, ErrInsufficientFunds)
+			continue
+		}
+		succeeded++
+	}
+	require.Equal(t, n/2, succeeded)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, updatedAccount1.Balance)
+
+	updatedAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+int64(n/2)*amount, updatedAccount2.Balance)
+}
+
+func TestTransferTxOverdraftLimit(t *testing.T) {
+	store := testStore
+	account1 := CreateRandomAccount(t)
+	account2 := createRandomAccountIn(t, account1.Currency)
+
+	account1, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 100,
+	})
+	require.NoError(t, err)
+
+	// the whole balance plus the overdraft limit can be spent
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + account1.OverdraftLimit,
+	})
+	require.NoError(t, err)
+	require.Equal(t, -account1.OverdraftLimit, result.FromAccount.Balance)
+
+	// but not a single unit more
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, -account1.OverdraftLimit, updatedAccount1.Balance)
+}
+
+func TestTransferTxUnknownAccount(t *testing.T) {
+	account := CreateRandomAccount(t)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   account.ID + 100000,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTransferTxCurrencyMismatch(t *testing.T) {
+	account1 := createFundedAccount(t, util.JPY, 100)
+	account2 := createRandomAccountIn(t, util.EUR)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	// no money moved
+	updatedAccount1, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := testStore.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
+
+func createTestFxQuote(t *testing.T, account Account, toCurrency string, amount int64, convertedAmount int64, expiresAt time.Time) FxQuote {
+	quote, err := testStore.CreateFxQuote(context.Background(), CreateFxQuoteParams{
+		ID:              uuid.New(),
+		AccountID:       account.ID,
+		FromCurrency:    account.Currency,
+		ToCurrency:      toCurrency,
+		Rate:            "0.006172839506",
+		Amount:          amount,
+		ConvertedAmount: convertedAmount,
+		ExpiresAt:       expiresAt,
+	})
+	require.NoError(t, err)
+	return quote
+}
+
+func TestTransferTxFx(t *testing.T) {
+	store := testStore
+	account1 := createFundedAccount(t, util.JPY, 10_000)
+	account2 := createRandomAccountIn(t, util.EUR)
+
+	quote := createTestFxQuote(t, account1, util.EUR, 10_000, 6172, time.Now().Add(time.Minute))
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10_000,
+		FxQuoteID:     uuid.NullUUID{UUID: quote.ID, Valid: true},
+	}
+
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// the transfer records the rate it was converted at
+	require.Equal(t, int64(10_000), result.Transfer.Amount)
+	require.Equal(t, arg.FxQuoteID, result.Transfer.FxQuoteID)
+	require.True(t, result.Transfer.FxRate.Valid)
+	require.Equal(t, sql.NullInt64{Int64: 6172, Valid: true}, result.Transfer.ConvertedAmount)
+
+	require.Equal(t, int64(-10_000), result.FromEntry.Amount)
+	require.Equal(t, int64(6172), result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-10_000, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+6172, result.ToAccount.Balance)
+
+	// the legs of each currency balance through the fx system accounts
+	legs, err := store.ListJournalEntries(context.Background(), result.FromEntry.JournalID)
+	require.NoError(t, err)
+	require.Len(t, legs, 4)
+	sums := make(map[string]int64)
+	for _, leg := range legs {
+		account, err := store.GetAccount(context.Background(), leg.AccountID)
+		require.NoError(t, err)
+		sums[account.Currency] += leg.Amount
+		if account.ID != account1.ID && account.ID != account2.ID {
+			require.Equal(t, util.FXAccount, account.AccountType)
+		}
+	}
+	require.Equal(t, map[string]int64{util.JPY: 0, util.EUR: 0}, sums)
+
+	// a quote is good for a single transfer
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrFxQuoteUsed)
+}
+
+func TestTransferTxFxErrors(t *testing.T) {
+	account1 := createFundedAccount(t, util.JPY, 10_000)
+	account2 := createRandomAccountIn(t, util.EUR)
+	other := createRandomAccountIn(t, util.USD)
+
+	valid := createTestFxQuote(t, account1, util.EUR, 10_000, 6172, time.Now().Add(time.Minute))
+	expired := createTestFxQuote(t, account1, util.EUR, 10_000, 6172, time.Now().Add(-time.Second))
+
+	testCases := []struct {
+		name    string
+		arg     TransferTxParams
+		wantErr error
+	}{
+		{
+			name:    "NoQuote",
+			arg:     TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10_000},
+			wantErr: ErrCurrencyMismatch,
+		},
+		{
+			name:    "UnknownQuote",
+			arg:     TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10_000, FxQuoteID: uuid.NullUUID{UUID: uuid.New(), Valid: true}},
+			wantErr: ErrFxQuoteNotFound,
+		},
+		{
+			name:    "Expired",
+			arg:     TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10_000, FxQuoteID: uuid.NullUUID{UUID: expired.ID, Valid: true}},
+			wantErr: ErrFxQuoteExpired,
+		},
+		{
+			name:    "OtherAmount",
+			arg:     TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 9_999, FxQuoteID: uuid.NullUUID{UUID: valid.ID, Valid: true}},
+			wantErr: ErrFxQuoteMismatch,
+		},
+		{
+			name:    "OtherCurrency",
+			arg:     TransferTxParams{FromAccountID: account1.ID, ToAccountID: other.ID, Amount: 10_000, FxQuoteID: uuid.NullUUID{UUID: valid.ID, Valid: true}},
+			wantErr: ErrFxQuoteMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := testStore.TransferTx(context.Background(), tc.arg)
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+
+	// no money moved and the quote is still good
+	updated, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updated.Balance)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10_000,
+		FxQuoteID:     uuid.NullUUID{UUID: valid.ID, Valid: true},
+	})
+	require.NoError(t, err)
+}
+
+func TestCreateAccountTxUnsupportedCurrency(t *testing.T) {
+	_, err := testStore.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:     util.RandomOwner(),
+			Email:     util.RandomEmail(),
+			Currency:  "YEN",
+			CreatedAt: time.Now(),
+		},
+		HashedPassword: "secret",
+	})
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+}
+
+func TestCreateAccountTx(t *testing.T) {
+	arg := CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:     util.RandomOwner(),
+			Email:     util.RandomEmail(),
+			Currency:  util.EUR,
+			CreatedAt: time.Now(),
+		},
+		HashedPassword: "secret",
+	}
+	result, err := testStore.CreateAccountTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.Email, result.Customer.Email)
+	require.Equal(t, arg.Owner, result.Customer.Name)
+	require.Equal(t, result.Customer.ID, result.Credential.CustomerID)
+	require.Equal(t, sql.NullInt64{Int64: result.Customer.ID, Valid: true}, result.Account.CustomerID)
+	require.Equal(t, arg.Email, result.Account.Email)
+
+	// the email belongs to the customer now, a second signup with it fails as a whole
+	arg.Currency = util.USD
+	_, err = testStore.CreateAccountTx(context.Background(), arg)
+	require.Error(t, err)
+
+	accounts, err := testStore.ListCustomerAccounts(context.Background(), result.Account.CustomerID)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	signup, err := testStore.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:     util.RandomOwner(),
+			Email:     util.RandomEmail(),
+			Currency:  util.JPY,
+			CreatedAt: time.Now(),
+		},
+		HashedPassword: "old secret",
+	})
+	require.NoError(t, err)
+	customerID := signup.Customer.ID
+
+	session, err := testStore.CreateSession(context.Background(), CreateSessionParams{
+		ID:           uuid.New(),
+		CustomerID:   customerID,
+		RefreshToken: util.RandomString(32),
+		ExpiresAt:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	reset, err := testStore.RequestPasswordResetTx(context.Background(), RequestPasswordResetTxParams{
+		CustomerID: customerID,
+		ExpiresAt:  now.Add(time.Hour),
+		CreatedAt:  now,
+	})
+	require.NoError(t, err)
+	require.Len(t, outboxEventsFor(t, AggregateCustomer, customerID), 1)
+
+	// no token was emailed yet
+	_, err = testStore.ResetPasswordTx(context.Background(), ResetPasswordTxParams{TokenHash: util.HashResetToken(""), HashedPassword: "new secret", Now: now})
+	require.ErrorIs(t, err, ErrInvalidResetToken)
+
+	token, hash, err := util.NewResetToken()
+	require.NoError(t, err)
+	_, err = testStore.SetPasswordResetToken(context.Background(), SetPasswordResetTokenParams{
+		TokenHash: sql.NullString{String: hash, Valid: true},
+		ID:        reset.ID,
+		Now:       now,
+	})
+	require.NoError(t, err)
+
+	arg := ResetPasswordTxParams{TokenHash: util.HashResetToken(token), HashedPassword: "new secret", Now: now}
+	credential, err := testStore.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, "new secret", credential.HashedPassword)
+	require.Equal(t, signup.Credential.Role, credential.Role)
+
+	// whoever knew the old password is signed out
+	session, err = testStore.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsBlocked)
+
+	_, err = testStore.ResetPasswordTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInvalidResetToken)
+
+	// a token set once the reset expired is refused
+	_, err = testStore.SetPasswordResetToken(context.Background(), SetPasswordResetTokenParams{
+		TokenHash: sql.NullString{String: hash, Valid: true},
+		ID:        reset.ID,
+		Now:       now.Add(2 * time.Hour),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestOpenAccountTx(t *testing.T) {
+	signup, err := testStore.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:     util.RandomOwner(),
+			Email:     util.RandomEmail(),
+			Currency:  util.JPY,
+			CreatedAt: time.Now(),
+		},
+		HashedPassword: "secret",
+	})
+	require.NoError(t, err)
+	customerID := signup.Customer.ID
+
+	result, err := testStore.OpenAccountTx(context.Background(), OpenAccountTxParams{CustomerID: customerID, Currency: util.USD, CreatedAt: time.Now()})
+	require.NoError(t, err)
+	require.Equal(t, signup.Customer.ID, result.Customer.ID)
+	require.Equal(t, util.USD, result.Account.Currency)
+	require.Equal(t, signup.Customer.Name, result.Account.Owner)
+	require.Zero(t, result.Account.Balance)
+
+	accounts, err := testStore.ListCustomerAccounts(context.Background(), sql.NullInt64{Int64: customerID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.Equal(t, signup.Account.ID, accounts[0].ID)
+	require.Equal(t, result.Account.ID, accounts[1].ID)
+
+	// one account per currency
+	for _, currency := range []string{util.JPY, util.USD} {
+		_, err = testStore.OpenAccountTx(context.Background(), OpenAccountTxParams{CustomerID: customerID, Currency: currency, CreatedAt: time.Now()})
+		require.ErrorIs(t, err, ErrCurrencyAccountExists)
+	}
+
+	_, err = testStore.OpenAccountTx(context.Background(), OpenAccountTxParams{CustomerID: customerID, Currency: "GBP", CreatedAt: time.Now()})
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+
+	_, err = testStore.OpenAccountTx(context.Background(), OpenAccountTxParams{CustomerID: customerID + 1000, Currency: util.EUR, CreatedAt: time.Now()})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// createFundedAccount creates a random account in the currency holding at least minBalance.
+func createFundedAccount(t *testing.T, currency string, minBalance int64) Account {
+	account := createRandomAccountIn(t, currency)
+
+	account, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account.ID,
+		Balance: minBalance + util.RandomMoney(),
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
+// createUnappliedReferral records a referral on the date whose extra interest the referrer has
+// not got yet, like a signup with a referral code leaves it
+func createUnappliedReferral(t *testing.T, referrerAccountID int64, referralDate time.Time) ReferralHistory {
+	code := createUniqueRandomReferralCode(t, referrerAccountID)
+	referral, err := testQueries.CreateReferralHistory(context.Background(), CreateReferralHistoryParams{
+		ReferrerAccountID: referrerAccountID,
+		ReferredAccountID: CreateUniqueRandomAccount(t).ID,
+		ReferralCodeID:    code.ID,
+		ReferralDate:      referralDate,
+		CreatedAt:         referralDate,
+	})
+	require.NoError(t, err)
+	return referral
+}
+
 func TestUseReferralCodeTx(t *testing.T) {
 	store := testStore
+	loc, err := tz.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	// the run of the period from 2024-06-21 to 2024-07-20
+	runDate := time.Date(2024, time.July, 21, 0, 0, 0, 0, loc)
 
 	n := 10
 
 	referrerAccounts := make([]Account, n)
+	referralCounts := make(map[int64]int, n)
 	for i := range referrerAccounts {
 		referrerAccounts[i] = CreateUniqueRandomAccount(t)
-	}
-
-	// create referral codes for each referrer account
-	for _, referrerAccount := range referrerAccounts {
-		for i := 0; i < 5; i++ {
-			referralCode := createUniqueRandomReferralCode(t, referrerAccount.ID)
-			//Randomly set some referral codes as used
-			if rand.Intn(2) == 0 { // 50% chance to set the code as used
-				_, err := store.MarkReferralCodeUsed(context.Background(), MarkReferralCodeUsedParams{
-					ReferralCode: referralCode.ReferralCode,
-					UsedAt:       sql.NullTime{Time: utils.ConvertToTokyoTime(), Valid: true},
-				})
-				require.NoError(t, err)
-			}
+		// some referrers got no referrals in the period
+		referralCounts[referrerAccounts[i].ID] = rand.Intn(6)
+		for j := 0; j < referralCounts[referrerAccounts[i].ID]; j++ {
+			createUnappliedReferral(t, referrerAccounts[i].ID, randomDateBetween(t, "2024-06-22", "2024-07-20"))
 		}
 	}
 
@@ -189,6 +600,7 @@ func TestUseReferralCodeTx(t *testing.T) {
 
 			result, err := store.UseReferralCodeTx(context.Background(), UseReferralCodeTxParams{
 				ReferrerAccountID: account.ID,
+				Date:              runDate,
 			})
 
 			errs <- err
@@ -208,36 +620,20 @@ func TestUseReferralCodeTx(t *testing.T) {
 	for result := range results {
 		require.NotEmpty(t, result)
 
-		// Verify updated extra interest
 		account, err := store.GetAccount(context.Background(), result.ReferrerAccountUpdate.ID)
 		require.NoError(t, err)
-		require.NotEmpty(t, account)
-
-		// Fetch used referral count for this account
-		startDate, endDate := getReferralDateRange()
-		referralCount, err := store.GetReferralsByDateRange(context.Background(), GetReferralsByDateRangeParams{
-			ReferrerAccountID: account.ID,
-			CreatedAt:         startDate,
-			CreatedAt_2:       endDate,
-		})
-		require.NoError(t, err)
-
-		expectedExtraInterest := float64(referralCount)
-		if expectedExtraInterest > 10.0 {
-			expectedExtraInterest = 10.0
-		}
 
-		if expectedExtraInterest == 0 {
-			require.Equal(t, expectedExtraInterest, account.ExtraInterest.Float64)
-			require.Equal(t, int32(9), account.ExtraInterestDuration)
-		}
-
-		if referralCount != 0 {
-			require.Equal(t, expectedExtraInterest, account.ExtraInterest.Float64)
-			require.NotZero(t, account.ExtraInterestStartDate)
-			require.Equal(t, int32(9), account.ExtraInterestDuration)
+		referralCount := referralCounts[account.ID]
+		if referralCount == 0 {
+			require.Zero(t, account.ExtraInterest.Float64)
+			require.False(t, account.ExtraInterestStartDate.Valid)
+			continue
 		}
 
+		// every referral of the period adds the extra interest of one redemption on the day of the run
+		require.Equal(t, min(float64(referralCount), DefaultReferralTerms.MaxExtraInterest), account.ExtraInterest.Float64)
+		require.Equal(t, "2024-08-01", account.ExtraInterestStartDate.Time.Format(time.DateOnly))
+		require.Equal(t, DefaultReferralTerms.ExtraInterestDuration, account.ExtraInterestDuration)
 	}
 }
 
@@ -269,76 +665,69 @@ func randomDateBetween(t *testing.T, startDateStr, endDateStr string) time.Time
 	return startDate.Add(time.Duration(sec) * time.Second)
 }
 
-// getReferralDateRange returns the start and end date for the referral code date range
-func getReferralDateRange() (time.Time, time.Time) {
-	currentDate := utils.ConvertToTokyoTime()
-	loc, _ := time.LoadLocation("Asia/Tokyo")
-	year, month, _ := currentDate.Date()
-
-	var startDate, endDate time.Time
-	if month == time.January {
-		year--
-		startDate = time.Date(year, time.December, 21, 0, 0, 0, 0, loc)
-		endDate = time.Date(year, time.January, 20, 23, 59, 59, 0, loc)
-	} else {
-		startDate = time.Date(year, month-1, 21, 0, 0, 0, 0, loc)
-		endDate = time.Date(year, month, 20, 23, 59, 59, 0, loc)
-	}
-
-	return startDate, endDate
-}
-
 func TestUseReferralCodeTxWithEdgeCases(t *testing.T) {
 	store := testStore
+	loc, err := tz.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	runDate := time.Date(2024, time.July, 21, 0, 0, 0, 0, loc)
 
 	// Create a single referrer account
 	referrerAccount := CreateUniqueRandomAccount(t)
 
-	// Edge case 1: No referral codes created
-	// Run the transaction
-	_, err := store.UseReferralCodeTx(context.Background(), UseReferralCodeTxParams{
+	// Edge case 1: No referrals
+	_, err = store.UseReferralCodeTx(context.Background(), UseReferralCodeTxParams{
 		ReferrerAccountID: referrerAccount.ID,
+		Date:              runDate,
 	})
 	require.NoError(t, err)
 
-	// Verify no extra interest
 	account, err := store.GetAccount(context.Background(), referrerAccount.ID)
 	require.NoError(t, err)
 	require.NotEmpty(t, account)
 	require.Zero(t, account.ExtraInterest.Float64)
 
-	// Edge case 2: Create referral codes on boundary dates
-	referralCodeOnStartBoundary := createReferralCodeWithDate(t, referrerAccount.ID, "2024-06-21T00:00:00Z")
-	referralCodeOnEndBoundary := createReferralCodeWithDate(t, referrerAccount.ID, "2024-07-20T23:59:59Z")
+	// Edge case 2: referrals on the first and the last day of the period count, those just
+	// outside of it do not
+	createUnappliedReferral(t, referrerAccount.ID, time.Date(2024, time.June, 20, 0, 0, 0, 0, loc))
+	createUnappliedReferral(t, referrerAccount.ID, time.Date(2024, time.June, 21, 0, 0, 0, 0, loc))
+	createUnappliedReferral(t, referrerAccount.ID, time.Date(2024, time.July, 20, 0, 0, 0, 0, loc))
+	createUnappliedReferral(t, referrerAccount.ID, time.Date(2024, time.July, 21, 0, 0, 0, 0, loc))
 
-	// Set referral codes as used
-	_, err = store.MarkReferralCodeUsed(context.Background(), MarkReferralCodeUsedParams{
-		ReferralCode: referralCodeOnStartBoundary.ReferralCode,
-		UsedAt:       sql.NullTime{Time: utils.ConvertToTokyoTime(), Valid: true},
+	_, err = store.UseReferralCodeTx(context.Background(), UseReferralCodeTxParams{
+		ReferrerAccountID: referrerAccount.ID,
+		Date:              runDate,
 	})
 	require.NoError(t, err)
 
-	_, err = store.MarkReferralCodeUsed(context.Background(), MarkReferralCodeUsedParams{
-		ReferralCode: referralCodeOnEndBoundary.ReferralCode,
-		UsedAt:       sql.NullTime{Time: utils.ConvertToTokyoTime(), Valid: true},
+	account, err = store.GetAccount(context.Background(), referrerAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2.0, account.ExtraInterest.Float64)
+	require.Equal(t, "2024-08-01", account.ExtraInterestStartDate.Time.Format(time.DateOnly))
+	require.Equal(t, int32(9), account.ExtraInterestDuration)
+
+	// Edge case 3: a code redeemed later adds to the extra interest at once, running the old
+	// period again neither takes that back nor restarts the extra interest
+	_, err = store.RedeemReferralCodeTx(context.Background(), RedeemReferralCodeTxParams{
+		ReferralCode:      createUniqueRandomReferralCode(t, referrerAccount.ID).ReferralCode,
+		ReferredAccountID: CreateUniqueRandomAccount(t).ID,
+		RedeemedAt:        time.Date(2024, time.September, 10, 10, 0, 0, 0, loc),
 	})
 	require.NoError(t, err)
 
-	// Run the transaction
+	redeemed, err := store.GetAccount(context.Background(), referrerAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, 3.0, redeemed.ExtraInterest.Float64)
+	require.Equal(t, "2024-08-01", redeemed.ExtraInterestStartDate.Time.Format(time.DateOnly))
+
 	_, err = store.UseReferralCodeTx(context.Background(), UseReferralCodeTxParams{
 		ReferrerAccountID: referrerAccount.ID,
+		Date:              runDate,
 	})
 	require.NoError(t, err)
 
-	// Verify updated extra interest
 	account, err = store.GetAccount(context.Background(), referrerAccount.ID)
 	require.NoError(t, err)
-	require.NotEmpty(t, account)
-
-	expectedExtraInterest := 2.0
-	require.Equal(t, expectedExtraInterest, account.ExtraInterest.Float64)
-	require.NotZero(t, account.ExtraInterestStartDate)
-	require.Equal(t, int32(9), account.ExtraInterestDuration)
+	require.Equal(t, redeemed, account)
 }
 
 // createReferralCodeWithDate creates a referral code with a specific creation date.
@@ -358,3 +747,720 @@ func createReferralCodeWithDate(t *testing.T, referrerAccountID int64, createdAt
 
 	return referralCode
 }
+
+func TestSignupWithReferralTx(t *testing.T) {
+	store := testStore
+	referrer := CreateUniqueRandomAccount(t)
+	referralCode := createUniqueRandomReferralCode(t, referrer.ID)
+
+	// two people sign up with the same code at once, only one of them gets it
+	n := 2
+	errs := make(chan error, n)
+	results := make(chan SignupWithReferralTxResult, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := store.SignupWithReferralTx(context.Background(), SignupWithReferralTxParams{
+				CreateAccountTxParams: CreateAccountTxParams{
+					CreateAccountParams: CreateAccountParams{
+						Owner:     util.RandomOwner(),
+						Email:     util.RandomEmail(),
+						Currency:  util.JPY,
+						CreatedAt: utils.ConvertToTokyoTime(),
+					},
+					HashedPassword: util.RandomString(20),
+				},
+				ReferralCode: referralCode.ReferralCode,
+				BonusAmount:  1000,
+			})
+			errs <- err
+			results <- result
+		}()
+	}
+
+	var result SignupWithReferralTxResult
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		r := <-results
+		if err != nil {
+			require.ErrorIs(t, err, ErrReferralCodeUsed)
+			continue
+		}
+		succeeded++
+		result = r
+	}
+	require.Equal(t, 1, succeeded)
+
+	require.Equal(t, int64(1000), result.Account.Balance)
+	require.True(t, result.ReferralCode.IsUsed)
+	require.Equal(t, referrer.ID, result.ReferralHistory.ReferrerAccountID)
+	require.Equal(t, result.Account.ID, result.ReferralHistory.ReferredAccountID)
+	require.Equal(t, result.Account.ID, result.BonusTransfer.ToAccountID)
+
+	bonusAccount, err := store.GetAccount(context.Background(), result.BonusTransfer.FromAccountID)
+	require.NoError(t, err)
+	require.Equal(t, util.BonusAccount, bonusAccount.AccountType)
+
+	_, err = store.SignupWithReferralTx(context.Background(), SignupWithReferralTxParams{
+		CreateAccountTxParams: CreateAccountTxParams{
+			CreateAccountParams: CreateAccountParams{
+				Owner:    util.RandomOwner(),
+				Email:    util.RandomEmail(),
+				Currency: util.JPY,
+			},
+		},
+		ReferralCode: util.RandomString(12),
+	})
+	require.ErrorIs(t, err, ErrReferralCodeNotFound)
+}
+
+func TestRedeemReferralCodeTx(t *testing.T) {
+	store := testStore
+	referrer := CreateUniqueRandomAccount(t)
+	referred := CreateUniqueRandomAccount(t)
+
+	// the referrer is just below the cap, so the redemption tops it off
+	_, err := store.UpdateAccountInterest(context.Background(), UpdateAccountInterestParams{
+		ID:                    referrer.ID,
+		ExtraInterest:         sql.NullFloat64{Float64: DefaultReferralTerms.MaxExtraInterest - 0.5, Valid: true},
+		ExtraInterestDuration: DefaultReferralTerms.ExtraInterestDuration,
+	})
+	require.NoError(t, err)
+
+	referralCode := createUniqueRandomReferralCode(t, referrer.ID)
+	redeemedAt := time.Date(2024, time.December, 15, 10, 0, 0, 0, time.UTC)
+
+	result, err := store.RedeemReferralCodeTx(context.Background(), RedeemReferralCodeTxParams{
+		ReferralCode:      referralCode.ReferralCode,
+		ReferredAccountID: referred.ID,
+		RedeemedAt:        redeemedAt,
+	})
+	require.NoError(t, err)
+
+	require.True(t, result.ReferralCode.IsUsed)
+	require.Equal(t, referrer.ID, result.ReferralHistory.ReferrerAccountID)
+	require.Equal(t, referred.ID, result.ReferralHistory.ReferredAccountID)
+	require.Equal(t, referralCode.ID, result.ReferralHistory.ReferralCodeID)
+	require.Equal(t, DefaultReferralTerms.MaxExtraInterest, result.ReferrerAccount.ExtraInterest.Float64)
+	require.Equal(t, DefaultReferralTerms.ExtraInterestDuration, result.ReferrerAccount.ExtraInterestDuration)
+	require.Equal(t, 2025, result.ReferrerAccount.ExtraInterestStartDate.Time.Year())
+	require.Equal(t, time.January, result.ReferrerAccount.ExtraInterestStartDate.Time.Month())
+	require.Equal(t, 1, result.ReferrerAccount.ExtraInterestStartDate.Time.Day())
+
+	// the code cannot be redeemed twice
+	_, err = store.RedeemReferralCodeTx(context.Background(), RedeemReferralCodeTxParams{
+		ReferralCode:      referralCode.ReferralCode,
+		ReferredAccountID: referred.ID,
+		RedeemedAt:        redeemedAt,
+	})
+	require.ErrorIs(t, err, ErrReferralCodeUsed)
+
+	ownCode := createUniqueRandomReferralCode(t, referrer.ID)
+	_, err = store.RedeemReferralCodeTx(context.Background(), RedeemReferralCodeTxParams{
+		ReferralCode:      ownCode.ReferralCode,
+		ReferredAccountID: referrer.ID,
+		RedeemedAt:        redeemedAt,
+	})
+	require.ErrorIs(t, err, ErrSelfReferral)
+
+	_, err = store.RedeemReferralCodeTx(context.Background(), RedeemReferralCodeTxParams{
+		ReferralCode:      ownCode.ReferralCode,
+		ReferredAccountID: referred.ID + 1000,
+		RedeemedAt:        redeemedAt,
+	})
+	require.ErrorIs(t, err, ErrReferredAccountNotFound)
+
+	// the failed attempts left the code unused
+	code, err := store.GetReferralCode(context.Background(), ownCode.ReferralCode)
+	require.NoError(t, err)
+	require.False(t, code.IsUsed)
+}
+
+func TestRedeemReferralCodeTxOncePerCustomer(t *testing.T) {
+	referrer := CreateUniqueRandomAccount(t)
+	signup, err := testStore.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:     util.RandomOwner(),
+			Email:     util.RandomEmail(),
+			Currency:  util.JPY,
+			CreatedAt: time.Now(),
+		},
+		HashedPassword: "secret",
+	})
+	require.NoError(t, err)
+	second, err := testStore.OpenAccountTx(context.Background(), OpenAccountTxParams{CustomerID: signup.Customer.ID, Currency: util.USD, CreatedAt: time.Now()})
+	require.NoError(t, err)
+
+	redeem := func(referredAccountID int64) (RedeemReferralCodeTxResult, error) {
+		return testStore.RedeemReferralCodeTx(context.Background(), RedeemReferralCodeTxParams{
+			ReferralCode:      createUniqueRandomReferralCode(t, referrer.ID).ReferralCode,
+			ReferredAccountID: referredAccountID,
+			RedeemedAt:        time.Now(),
+		})
+	}
+
+	result, err := redeem(signup.Account.ID)
+	require.NoError(t, err)
+	require.Equal(t, sql.NullInt64{Int64: signup.Customer.ID, Valid: true}, result.ReferralHistory.ReferredCustomerID)
+	extraInterest := result.ReferrerAccount.ExtraInterest
+
+	// neither the account nor another one of the customer earns the referrer anything again
+	for _, accountID := range []int64{signup.Account.ID, second.Account.ID} {
+		_, err = redeem(accountID)
+		require.ErrorIs(t, err, ErrAlreadyReferred)
+	}
+
+	account, err := testStore.GetAccount(context.Background(), referrer.ID)
+	require.NoError(t, err)
+	require.Equal(t, extraInterest, account.ExtraInterest)
+
+	// accounts without a customer are referred once as well
+	referred := CreateUniqueRandomAccount(t)
+	_, err = redeem(referred.ID)
+	require.NoError(t, err)
+	_, err = redeem(referred.ID)
+	require.ErrorIs(t, err, ErrAlreadyReferred)
+}
+
+func TestRedeemReferralCodeTxOwnSecondAccount(t *testing.T) {
+	signup, err := testStore.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:     util.RandomOwner(),
+			Email:     util.RandomEmail(),
+			Currency:  util.JPY,
+			CreatedAt: time.Now(),
+		},
+		HashedPassword: "secret",
+	})
+	require.NoError(t, err)
+	second, err := testStore.OpenAccountTx(context.Background(), OpenAccountTxParams{CustomerID: signup.Customer.ID, Currency: util.EUR, CreatedAt: time.Now()})
+	require.NoError(t, err)
+
+	// the code of the customer's first account is theirs just as much from the second one
+	code := createUniqueRandomReferralCode(t, signup.Account.ID)
+	_, err = testStore.RedeemReferralCodeTx(context.Background(), RedeemReferralCodeTxParams{
+		ReferralCode:      code.ReferralCode,
+		ReferredAccountID: second.Account.ID,
+		RedeemedAt:        time.Now(),
+	})
+	require.ErrorIs(t, err, ErrSelfReferral)
+
+	code, err = testStore.GetReferralCode(context.Background(), code.ReferralCode)
+	require.NoError(t, err)
+	require.False(t, code.IsUsed)
+
+	account, err := testStore.GetAccount(context.Background(), signup.Account.ID)
+	require.NoError(t, err)
+	require.False(t, account.ExtraInterest.Valid && account.ExtraInterest.Float64 > 0)
+}
+
+func TestRedeemReferralCodeTxWhileExtraInterestIsActive(t *testing.T) {
+	referrer := CreateUniqueRandomAccount(t)
+	redeem := func(redeemedAt time.Time) Account {
+		result, err := testStore.RedeemReferralCodeTx(context.Background(), RedeemReferralCodeTxParams{
+			ReferralCode:      createUniqueRandomReferralCode(t, referrer.ID).ReferralCode,
+			ReferredAccountID: CreateUniqueRandomAccount(t).ID,
+			RedeemedAt:        redeemedAt,
+		})
+		require.NoError(t, err)
+		return result.ReferrerAccount
+	}
+
+	// the first code starts the extra interest on 2025-01-01, through 2025-09-30
+	account := redeem(time.Date(2024, time.December, 15, 10, 0, 0, 0, time.UTC))
+	require.Equal(t, "2025-01-01", account.ExtraInterestStartDate.Time.Format(time.DateOnly))
+
+	// a second code in March adds to the rate it earns already, and runs it for nine months from
+	// April on, rather than pausing it until April
+	account = redeem(time.Date(2025, time.March, 10, 10, 0, 0, 0, time.UTC))
+	require.Equal(t, 2*DefaultReferralTerms.ExtraInterest, account.ExtraInterest.Float64)
+	require.Equal(t, "2025-01-01", account.ExtraInterestStartDate.Time.Format(time.DateOnly))
+	require.Equal(t, int32(3+9), account.ExtraInterestDuration)
+	expiry, ok := ExtraInterestExpiry(account)
+	require.True(t, ok)
+	require.Equal(t, time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), expiry)
+
+	// once it is over, the next code starts a new one, even before the expiry job reset it
+	account = redeem(time.Date(2026, time.February, 10, 10, 0, 0, 0, time.UTC))
+	require.Equal(t, DefaultReferralTerms.ExtraInterest, account.ExtraInterest.Float64)
+	require.Equal(t, "2026-03-01", account.ExtraInterestStartDate.Time.Format(time.DateOnly))
+	require.Equal(t, DefaultReferralTerms.ExtraInterestDuration, account.ExtraInterestDuration)
+}
+
+func TestRedeemReferralCodeTxTerms(t *testing.T) {
+	referrer := CreateUniqueRandomAccount(t)
+	terms := ReferralTerms{ExtraInterest: 0.5, MaxExtraInterest: 0.75, ExtraInterestDuration: 3}
+
+	for i, want := range []float64{0.5, 0.75} {
+		referralCode := createUniqueRandomReferralCode(t, referrer.ID)
+		result, err := testStore.RedeemReferralCodeTx(context.Background(), RedeemReferralCodeTxParams{
+			ReferralCode:      referralCode.ReferralCode,
+			ReferredAccountID: CreateUniqueRandomAccount(t).ID,
+			RedeemedAt:        time.Date(2024, time.December, 15+i, 10, 0, 0, 0, time.UTC),
+			Terms:             terms,
+		})
+		require.NoError(t, err)
+		require.Equal(t, want, result.ReferrerAccount.ExtraInterest.Float64)
+		require.Equal(t, int32(3), result.ReferrerAccount.ExtraInterestDuration)
+	}
+}
+
+func TestReferralDateRange(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	date := time.Date(2024, time.March, 10, 0, 0, 0, 0, loc)
+
+	start, end, err := ReferralDateRange(date, 21)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.February, 21, 0, 0, 0, 0, loc), start)
+	require.Equal(t, time.Date(2024, time.March, 20, 23, 59, 59, 0, loc), end)
+
+	// with the 1st the period is the whole month before
+	start, end, err = ReferralDateRange(date, 1)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.February, 1, 0, 0, 0, 0, loc), start)
+	require.Equal(t, time.Date(2024, time.February, 29, 23, 59, 59, 0, loc), end)
+}
+
+// outboxEventsFor returns the pending events about a row, oldest first
+func outboxEventsFor(t *testing.T, aggregateType string, aggregateID int64) []OutboxEvent {
+	var items []OutboxEvent
+	for offset := int32(0); ; offset += 1000 {
+		events, err := testStore.ListOutboxEvents(context.Background(), ListOutboxEventsParams{
+			Status: "pending",
+			Limit:  1000,
+			Offset: offset,
+		})
+		require.NoError(t, err)
+		for _, event := range events {
+			if event.AggregateType == aggregateType && event.AggregateID == aggregateID {
+				items = append(items, event)
+			}
+		}
+		if len(events) < 1000 {
+			return items
+		}
+	}
+}
+
+func TestTxPublishesEvents(t *testing.T) {
+	store := testStore
+
+	signup, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:     util.RandomOwner(),
+			Email:     util.RandomEmail(),
+			Currency:  util.JPY,
+			CreatedAt: time.Now(),
+		},
+		HashedPassword: "secret",
+	})
+	require.NoError(t, err)
+
+	events := outboxEventsFor(t, AggregateAccount, signup.Account.ID)
+	require.Len(t, events, 1)
+	require.Equal(t, EventAccountCreated, events[0].EventType)
+	require.Zero(t, events[0].Attempts)
+
+	var created AccountCreatedEvent
+	require.NoError(t, json.Unmarshal(events[0].Payload, &created))
+	require.Equal(t, signup.Customer.ID, created.CustomerID)
+	require.Equal(t, signup.Account.Email, created.Email)
+	require.Equal(t, util.JPY, created.Currency)
+
+	from := createFundedAccount(t, util.JPY, 100)
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   signup.Account.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	events = outboxEventsFor(t, AggregateTransfer, transfer.Transfer.ID)
+	require.Len(t, events, 1)
+	require.Equal(t, EventTransferCompleted, events[0].EventType)
+
+	var completed TransferCompletedEvent
+	require.NoError(t, json.Unmarshal(events[0].Payload, &completed))
+	require.Equal(t, TransferCompletedEvent{
+		TransferID:      transfer.Transfer.ID,
+		FromAccountID:   from.ID,
+		ToAccountID:     signup.Account.ID,
+		Amount:          100,
+		Currency:        util.JPY,
+		ConvertedAmount: 100,
+		ToCurrency:      util.JPY,
+		CreatedAt:       completed.CreatedAt,
+	}, completed)
+
+	events = outboxEventsFor(t, AggregateAccount, signup.Account.ID)
+	require.Len(t, events, 2)
+	require.Equal(t, EventBalanceChanged, events[1].EventType)
+
+	var changed BalanceChangedEvent
+	require.NoError(t, json.Unmarshal(events[1].Payload, &changed))
+	require.Equal(t, signup.Account.ID, changed.AccountID)
+	require.Equal(t, int64(100), changed.Amount)
+	require.Equal(t, transfer.ToAccount.Balance, changed.Balance)
+	require.Equal(t, ReferenceTransfer, changed.ReferenceType)
+	require.Equal(t, transfer.Transfer.ID, changed.TransferID)
+
+	referralCode := createUniqueRandomReferralCode(t, from.ID)
+	redeemed, err := store.RedeemReferralCodeTx(context.Background(), RedeemReferralCodeTxParams{
+		ReferralCode:      referralCode.ReferralCode,
+		ReferredAccountID: signup.Account.ID,
+		RedeemedAt:        time.Date(2024, time.December, 15, 10, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	events = outboxEventsFor(t, AggregateReferral, redeemed.ReferralHistory.ID)
+	require.Len(t, events, 1)
+	require.Equal(t, EventReferralRedeemed, events[0].EventType)
+
+	var referral ReferralRedeemedEvent
+	require.NoError(t, json.Unmarshal(events[0].Payload, &referral))
+	require.Equal(t, from.ID, referral.ReferrerAccountID)
+	require.Equal(t, signup.Account.ID, referral.ReferredAccountID)
+	require.False(t, referral.Signup)
+
+	events = outboxEventsFor(t, AggregateAccount, from.ID)
+	require.Equal(t, EventExtraInterestUpdated, events[len(events)-1].EventType)
+
+	var extraInterest ExtraInterestUpdatedEvent
+	require.NoError(t, json.Unmarshal(events[len(events)-1].Payload, &extraInterest))
+	require.Equal(t, ExtraInterestReferralRedeemed, extraInterest.Reason)
+	require.Equal(t, redeemed.ReferrerAccount.ExtraInterest.Float64, extraInterest.ExtraInterest)
+	require.Equal(t, "2025-01-01", extraInterest.StartDate)
+}
+
+func TestPayInterestTx(t *testing.T) {
+	store := testStore
+	account := CreateUniqueRandomAccount(t)
+	from := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.July, 31, 0, 0, 0, 0, time.UTC)
+
+	// 1.5 and 1.7 of a unit add up to 3.2, of which 3 is paid
+	for i, amountMicro := range []int64{1_500_000, 1_700_000} {
+		_, err := store.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+			AccountID:   account.ID,
+			AccrualDate: from.AddDate(0, 0, i),
+			Balance:     account.Balance,
+			AnnualRate:  4.5,
+			AmountMicro: amountMicro,
+		})
+		require.NoError(t, err)
+	}
+
+	arg := PayInterestTxParams{
+		AccountID: account.ID,
+		Period:    "2024-07",
+		To:        to,
+	}
+	result, err := store.PayInterestTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(3), result.Payout.Amount)
+	require.Equal(t, "2024-07", result.Payout.Period)
+	require.Equal(t, sql.NullInt64{Int64: result.Transfer.ID, Valid: true}, result.Payout.TransferID)
+	require.Equal(t, account.ID, result.Transfer.ToAccountID)
+	require.Equal(t, int64(3), result.Transfer.Amount)
+	require.Equal(t, account.Balance+3, result.Account.Balance)
+
+	interestAccount, err := store.GetAccount(context.Background(), result.Transfer.FromAccountID)
+	require.NoError(t, err)
+	require.Equal(t, util.InterestAccount, interestAccount.AccountType)
+	require.Equal(t, account.Currency, interestAccount.Currency)
+
+	// the period is paid once
+	_, err = store.PayInterestTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInterestAlreadyPaid)
+
+	updated, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+3, updated.Balance)
+
+	// a day of the period accrued after it was paid is paid with the next one
+	_, err = store.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+		AccountID:   account.ID,
+		AccrualDate: to,
+		Balance:     account.Balance,
+		AnnualRate:  4.5,
+		AmountMicro: 2_000_000,
+	})
+	require.NoError(t, err)
+
+	result, err = store.PayInterestTx(context.Background(), PayInterestTxParams{
+		AccountID: account.ID,
+		Period:    "2024-08",
+		To:        to.AddDate(0, 1, 0),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), result.Payout.Amount)
+	require.Equal(t, account.Balance+5, result.Account.Balance)
+
+	accruals, err := store.ListInterestAccruals(context.Background(), ListInterestAccrualsParams{
+		AccountID:     account.ID,
+		AccrualDate:   from,
+		AccrualDate_2: to,
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 3)
+	require.Equal(t, "2024-07", accruals[0].PaidPeriod.String)
+	require.Equal(t, "2024-07", accruals[1].PaidPeriod.String)
+	require.Equal(t, "2024-08", accruals[2].PaidPeriod.String)
+}
+
+func TestExpireExtraInterestTx(t *testing.T) {
+	store := testStore
+	account := CreateUniqueRandomAccount(t)
+
+	_, err := store.UpdateAccountInterest(context.Background(), UpdateAccountInterestParams{
+		ID:                     account.ID,
+		ExtraInterest:          sql.NullFloat64{Float64: 3, Valid: true},
+		ExtraInterestStartDate: sql.NullTime{Time: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		ExtraInterestDuration:  DefaultReferralTerms.ExtraInterestDuration,
+	})
+	require.NoError(t, err)
+
+	// the extra interest is in effect through 2024-09-30
+	arg := ExpireExtraInterestTxParams{
+		AccountID: account.ID,
+		AsOf:      time.Date(2024, time.September, 30, 0, 0, 0, 0, time.UTC),
+	}
+	_, err = store.ExpireExtraInterestTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrExtraInterestNotExpired)
+
+	// the last day has to accrue with the extra interest first
+	arg.AsOf = arg.AsOf.AddDate(0, 0, 1)
+	_, err = store.ExpireExtraInterestTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrExtraInterestNotAccrued)
+
+	_, err = store.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+		AccountID:   account.ID,
+		AccrualDate: time.Date(2024, time.September, 30, 0, 0, 0, 0, time.UTC),
+		Balance:     account.Balance,
+		AnnualRate:  3,
+	})
+	require.NoError(t, err)
+
+	expired, err := store.ListAccountsWithExpiredExtraInterest(context.Background(), ListAccountsWithExpiredExtraInterestParams{
+		AsOf:      arg.AsOf,
+		AfterID:   account.ID - 1,
+		BatchSize: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	require.Equal(t, account.ID, expired[0].ID)
+
+	result, err := store.ExpireExtraInterestTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, result.Account.ExtraInterest.Float64)
+	require.False(t, result.Account.ExtraInterestStartDate.Valid)
+	require.Equal(t, account.ID, result.AuditEntry.AccountID)
+	require.Equal(t, AuditExtraInterestExpired, result.AuditEntry.Action)
+	require.JSONEq(t, `{"extra_interest":3,"start_date":"2024-01-01","duration":9,"expired_on":"2024-10-01"}`, string(result.AuditEntry.Detail))
+
+	_, err = store.ExpireExtraInterestTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrExtraInterestNotExpired)
+}
+
+func TestListAccountEntries(t *testing.T) {
+	store := testStore
+	account1 := createFundedAccount(t, util.RandomCurrency(), 100)
+	account2 := createRandomAccountIn(t, account1.Currency)
+
+	var transfers []TransferTxResult
+	for _, amount := range []int64{10, 20, 30} {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		})
+		require.NoError(t, err)
+		require.Equal(t, sql.NullInt64{Int64: result.Transfer.ID, Valid: true}, result.FromEntry.TransferID)
+		require.Equal(t, sql.NullInt64{Int64: result.Transfer.ID, Valid: true}, result.ToEntry.TransferID)
+		transfers = append(transfers, result)
+	}
+
+	rows, err := store.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account1.ID,
+		PageSize:  2,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	// newest first, each with the balance right after it
+	require.Equal(t, transfers[2].FromEntry.ID, rows[0].ID)
+	require.Equal(t, account1.Balance-60, rows[0].RunningBalance)
+	require.Equal(t, transfers[1].FromEntry.ID, rows[1].ID)
+	require.Equal(t, account1.Balance-30, rows[1].RunningBalance)
+	require.Equal(t, sql.NullInt64{Int64: account2.ID, Valid: true}, rows[0].CounterpartyAccountID)
+	require.Equal(t, sql.NullString{String: util.CustomerAccount, Valid: true}, rows[0].CounterpartyAccountType)
+	require.False(t, rows[0].InterestPeriod.Valid)
+
+	rows, err = store.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account1.ID,
+		BeforeID:  sql.NullInt64{Int64: rows[1].ID, Valid: true},
+		PageSize:  2,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, transfers[0].FromEntry.ID, rows[0].ID)
+	require.Equal(t, account1.Balance-10, rows[0].RunningBalance)
+
+	rows, err = store.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+		AccountID: account2.ID,
+		Direction: sql.NullString{String: "debit", Valid: true},
+		PageSize:  10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, rows)
+}
+
+func TestAdjustBalanceTx(t *testing.T) {
+	store := testStore
+	account := CreateUniqueRandomAccount(t)
+
+	result, err := store.AdjustBalanceTx(context.Background(), AdjustBalanceTxParams{
+		AccountID: account.ID,
+		Amount:    -25,
+		Memo:      "duplicate bonus",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "duplicate bonus", result.Journal.Memo)
+	require.Equal(t, account.Balance-25, result.Account.Balance)
+	require.Equal(t, int64(-25), result.Entry.Amount)
+	require.Equal(t, ReferenceAdjustment, result.Entry.ReferenceType)
+	require.False(t, result.Entry.TransferID.Valid)
+
+	legs, err := store.ListJournalEntries(context.Background(), result.Entry.JournalID)
+	require.NoError(t, err)
+	require.Len(t, legs, 2)
+	require.Equal(t, int64(25), legs[1].Amount)
+
+	adjustmentAccount, err := store.GetAccount(context.Background(), legs[1].AccountID)
+	require.NoError(t, err)
+	require.Equal(t, util.AdjustmentAccount, adjustmentAccount.AccountType)
+
+	_, err = store.AdjustBalanceTx(context.Background(), AdjustBalanceTxParams{AccountID: account.ID})
+	require.ErrorIs(t, err, ErrInvalidJournal)
+}
+
+func TestPostJournalRejectsInvalidLegs(t *testing.T) {
+	account1 := CreateUniqueRandomAccount(t)
+	account2 := CreateUniqueRandomAccount(t)
+
+	for _, legs := range [][]journalLeg{
+		nil,
+		{{AccountID: account1.ID, Amount: 0}, {AccountID: account2.ID, Amount: 0}},
+		{{AccountID: account1.ID, Amount: -10}, {AccountID: account2.ID, Amount: 9}},
+	} {
+		_, err := postJournal(context.Background(), testQueries, journalParams{
+			ReferenceType: ReferenceAdjustment,
+			Legs:          legs,
+		})
+		require.ErrorIs(t, err, ErrInvalidJournal)
+	}
+}
+
+func TestLedgerBalancesMatchEntries(t *testing.T) {
+	store := testStore
+	account1 := CreateUniqueRandomAccount(t)
+	account2 := createRandomAccountIn(t, account1.Currency)
+
+	// every account starts out with its opening balance booked as an adjustment
+	for _, account := range []Account{account1, account2} {
+		_, err := store.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID})
+		require.NoError(t, err)
+		_, err = store.AdjustBalanceTx(context.Background(), AdjustBalanceTxParams{
+			AccountID: account.ID,
+			Amount:    100,
+			Memo:      "opening balance",
+		})
+		require.NoError(t, err)
+	}
+
+	var journals []sql.NullInt64
+	for _, arg := range []TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 70},
+	} {
+		result, err := store.TransferTx(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, ReferenceTransfer, result.FromEntry.ReferenceType)
+		require.Equal(t, result.FromEntry.JournalID, result.ToEntry.JournalID)
+		journals = append(journals, result.FromEntry.JournalID)
+	}
+
+	for _, journalID := range journals {
+		legs, err := store.ListJournalEntries(context.Background(), journalID)
+		require.NoError(t, err)
+		require.Len(t, legs, 2)
+		require.Zero(t, legs[0].Amount+legs[1].Amount)
+	}
+
+	for _, account := range []Account{account1, account2} {
+		updated, err := store.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+
+		entries, err := store.ListEntries(context.Background(), ListEntriesParams{AccountID: account.ID, Limit: 10})
+		require.NoError(t, err)
+
+		var sum int64
+		for _, entry := range entries {
+			sum += entry.Amount
+		}
+		require.Equal(t, updated.Balance, sum)
+	}
+}
+
+func TestExecTxRetriesConflicts(t *testing.T) {
+	store, ok := testStore.(*SQLStore)
+	if !ok {
+		t.Skip("only transactions of the database are retried")
+	}
+
+	testCases := []struct {
+		name     string
+		err      error
+		attempts int
+		retries  float64
+	}{
+		{"SerializationFailure", &pq.Error{Code: serializationFailure}, maxTxAttempts, maxTxAttempts - 1},
+		{"Deadlock", &pq.Error{Code: deadlockDetected}, maxTxAttempts, maxTxAttempts - 1},
+		{"OtherError", ErrInsufficientFunds, 1, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			retries := testutil.ToFloat64(metrics.DBTransactionRetries)
+			rolledBack := testutil.ToFloat64(metrics.DBTransactions.WithLabelValues(metrics.TxRolledBack))
+
+			attempts := 0
+			err := store.execTx(context.Background(), func(q Querier) error {
+				attempts++
+				return tc.err
+			})
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.attempts, attempts)
+			require.Equal(t, tc.retries, testutil.ToFloat64(metrics.DBTransactionRetries)-retries)
+			require.Equal(t, float64(tc.attempts), testutil.ToFloat64(metrics.DBTransactions.WithLabelValues(metrics.TxRolledBack))-rolledBack)
+		})
+	}
+
+	// a conflict that goes away is not seen by the caller
+	account := createRandomAccountIn(t, util.RandomCurrency())
+	attempts := 0
+	err := store.execTx(context.Background(), func(q Querier) error {
+		attempts++
+		if _, err := q.GetAccountForUpdate(context.Background(), account.ID); err != nil {
+			return err
+		}
+		if attempts == 1 {
+			return &pq.Error{Code: deadlockDetected}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+}
@@ -7,26 +7,42 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
                        from_account_id,
                        to_account_id,
-                       amount
+                       amount,
+                       fx_quote_id,
+                       fx_rate,
+                       converted_amount
 ) VALUES (
-          $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+          $1, $2, $3, $4, $5, $6
+) RETURNING id, from_account_id, to_account_id, amount, created_at, fx_quote_id, fx_rate, converted_amount
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID   int64          `json:"from_account_id"`
+	ToAccountID     int64          `json:"to_account_id"`
+	Amount          int64          `json:"amount"`
+	FxQuoteID       uuid.NullUUID  `json:"fx_quote_id"`
+	FxRate          sql.NullString `json:"fx_rate"`
+	ConvertedAmount sql.NullInt64  `json:"converted_amount"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.queryRow(ctx, q.createTransferStmt, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.queryRow(ctx, q.createTransferStmt, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.FxQuoteID,
+		arg.FxRate,
+		arg.ConvertedAmount,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +50,15 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.FxQuoteID,
+		&i.FxRate,
+		&i.ConvertedAmount,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, fx_quote_id, fx_rate, converted_amount FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,12 +71,15 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.FxQuoteID,
+		&i.FxRate,
+		&i.ConvertedAmount,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, fx_quote_id, fx_rate, converted_amount FROM transfers
 WHERE
     from_account_id = $1 OR
     to_account_id = $2
@@ -93,6 +115,9 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.FxQuoteID,
+			&i.FxRate,
+			&i.ConvertedAmount,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,47 @@
+package fx
+
+import (
+	"bank-api/db/sqlc"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LoadRates records the rates of a CSV file of base,quote,rate rows, e.g. EUR,JPY,161.25, with
+// an optional header row. The whole file is checked before any rate is recorded, so a file with
+// a bad row changes nothing. It returns how many rates it recorded.
+func LoadRates(ctx context.Context, store sqlc.Querier, r io.Reader, source string) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) > 0 && strings.EqualFold(rows[0][0], "base") {
+		rows = rows[1:]
+	}
+
+	rates := make([]SetRateParams, len(rows))
+	for i, row := range rows {
+		rates[i] = SetRateParams{
+			BaseCurrency:  strings.TrimSpace(row[0]),
+			QuoteCurrency: strings.TrimSpace(row[1]),
+			Rate:          strings.TrimSpace(row[2]),
+			Source:        source,
+		}
+		if _, err := validRate(rates[i].BaseCurrency, rates[i].QuoteCurrency, rates[i].Rate); err != nil {
+			return 0, fmt.Errorf("row %d: %w", i+1, err)
+		}
+	}
+
+	for i, rate := range rates {
+		if _, err := SetRate(ctx, store, rate); err != nil {
+			return i, fmt.Errorf("row %d: %w", i+1, err)
+		}
+	}
+	return len(rates), nil
+}
@@ -0,0 +1,219 @@
+// Package fx converts amounts between currencies at locally managed rates. Rates are set by an
+// admin or loaded from a file and never change once recorded; the latest rate of a pair is its
+// current rate. A quote locks the current rate for a single transfer for a short while.
+//
+// Rates are exact decimals, never floats. A converted amount is rounded toward zero to the minor
+// unit of the currency it is converted to: the customer is never credited more than the rate
+// gives, the fraction of a minor unit that cannot be credited stays with the bank.
+package fx
+
+import (
+	"bank-api/apperr"
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// decimal places of a rate, see fx_rates.rate
+	rateScale = 12
+	// QuoteDuration is how long a quote locks its rate for
+	QuoteDuration = time.Minute
+)
+
+var (
+	ErrInvalidRate         = apperr.New(apperr.KindValidation, "invalid_rate", "invalid rate")
+	ErrRateNotFound        = apperr.New(apperr.KindNotFound, "rate_not_found", "no rate for the currency pair")
+	ErrSameCurrency        = apperr.New(apperr.KindValidation, "same_currency", "nothing to convert within the same currency")
+	ErrUnsupportedCurrency = apperr.New(apperr.KindValidation, "unsupported_currency", "unsupported currency")
+	ErrConvertedTooSmall   = apperr.New(apperr.KindValidation, "converted_too_small", "amount converts to less than one minor unit")
+	ErrConvertedTooLarge   = apperr.New(apperr.KindValidation, "converted_too_large", "converted amount is out of range")
+)
+
+// rateDenominator is 10^12, the denominator of every rate that can be stored
+var rateDenominator = pow10(rateScale)
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// ParseRate parses a positive decimal rate of at most 12 decimal places, e.g. 161.25
+func ParseRate(s string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+	}
+	if rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q is not positive", ErrInvalidRate, s)
+	}
+	if new(big.Int).Mod(rateDenominator, rate.Denom()).Sign() != 0 {
+		return nil, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidRate, s, rateScale)
+	}
+	return rate, nil
+}
+
+// FormatRate renders a rate with the 12 decimal places it is stored with
+func FormatRate(rate *big.Rat) string {
+	return rate.FloatString(rateScale)
+}
+
+// invert returns the rate of the opposite direction, rounded toward zero to 12 decimal places
+// so that it can be stored and converts the same once read back
+func invert(rate *big.Rat) *big.Rat {
+	inverse := new(big.Rat).Inv(rate)
+	scaled := new(big.Int).Mul(inverse.Num(), rateDenominator)
+	scaled.Quo(scaled, inverse.Denom())
+	return new(big.Rat).SetFrac(scaled, rateDenominator)
+}
+
+// Convert converts an amount in the minor unit of from into the minor unit of to, at a rate in
+// units of to per unit of from, rounded toward zero.
+func Convert(amount int64, from util.Currency, to util.Currency, rate *big.Rat) (int64, error) {
+	value := new(big.Rat).SetInt64(amount)
+	value.Mul(value, rate)
+
+	// shift from the minor unit of from to the minor unit of to
+	shift := new(big.Rat).SetInt(pow10(abs(to.Exponent - from.Exponent)))
+	if to.Exponent >= from.Exponent {
+		value.Mul(value, shift)
+	} else {
+		value.Quo(value, shift)
+	}
+
+	converted := new(big.Int).Quo(value.Num(), value.Denom())
+	if !converted.IsInt64() {
+		return 0, fmt.Errorf("%w: %s %s", ErrConvertedTooLarge, converted, to.Code)
+	}
+	return converted.Int64(), nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// LatestRate returns the current rate from one currency to another. When only the opposite
+// direction was ever set, its inverse is used.
+func LatestRate(ctx context.Context, store sqlc.Querier, from string, to string) (*big.Rat, error) {
+	if from == to {
+		return nil, ErrSameCurrency
+	}
+
+	rate, err := store.GetLatestFxRate(ctx, sqlc.GetLatestFxRateParams{BaseCurrency: from, QuoteCurrency: to})
+	if err == nil {
+		return ParseRate(rate.Rate)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	rate, err = store.GetLatestFxRate(ctx, sqlc.GetLatestFxRateParams{BaseCurrency: to, QuoteCurrency: from})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+		}
+		return nil, err
+	}
+
+	inverse, err := ParseRate(rate.Rate)
+	if err != nil {
+		return nil, err
+	}
+	return invert(inverse), nil
+}
+
+type SetRateParams struct {
+	BaseCurrency  string
+	QuoteCurrency string
+	Rate          string
+	// the admin email, or the file the rate was loaded from
+	Source string
+}
+
+// SetRate records a new current rate for a currency pair
+func SetRate(ctx context.Context, store sqlc.Querier, arg SetRateParams) (sqlc.FxRate, error) {
+	rate, err := validRate(arg.BaseCurrency, arg.QuoteCurrency, arg.Rate)
+	if err != nil {
+		return sqlc.FxRate{}, err
+	}
+
+	return store.CreateFxRate(ctx, sqlc.CreateFxRateParams{
+		BaseCurrency:  arg.BaseCurrency,
+		QuoteCurrency: arg.QuoteCurrency,
+		Rate:          FormatRate(rate),
+		Source:        arg.Source,
+	})
+}
+
+func validRate(base string, quote string, rate string) (*big.Rat, error) {
+	for _, code := range []string{base, quote} {
+		if !util.IsSupportedCurrency(code) {
+			return nil, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, code)
+		}
+	}
+	if base == quote {
+		return nil, ErrSameCurrency
+	}
+	return ParseRate(rate)
+}
+
+type QuoteParams struct {
+	// the account the money is converted from, only it can use the quote
+	AccountID  int64
+	ToCurrency string
+	// in the minor unit of the account's currency
+	Amount int64
+	Now    time.Time
+}
+
+// CreateQuote locks the current rate for converting an amount out of an account, until
+// QuoteDuration after arg.Now. The quote holds both amounts, a transfer using it debits and
+// credits exactly those.
+func CreateQuote(ctx context.Context, store sqlc.Querier, arg QuoteParams) (sqlc.FxQuote, error) {
+	account, err := store.GetAccount(ctx, arg.AccountID)
+	if err != nil {
+		return sqlc.FxQuote{}, err
+	}
+
+	from, ok := util.LookupCurrency(account.Currency)
+	if !ok {
+		return sqlc.FxQuote{}, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, account.Currency)
+	}
+	to, ok := util.LookupCurrency(arg.ToCurrency)
+	if !ok {
+		return sqlc.FxQuote{}, fmt.Errorf("%w: %q", ErrUnsupportedCurrency, arg.ToCurrency)
+	}
+
+	rate, err := LatestRate(ctx, store, from.Code, to.Code)
+	if err != nil {
+		return sqlc.FxQuote{}, err
+	}
+
+	converted, err := Convert(arg.Amount, from, to, rate)
+	if err != nil {
+		return sqlc.FxQuote{}, err
+	}
+	if converted <= 0 {
+		return sqlc.FxQuote{}, fmt.Errorf("%w: %s at %s", ErrConvertedTooSmall, from.Format(arg.Amount), FormatRate(rate))
+	}
+
+	return store.CreateFxQuote(ctx, sqlc.CreateFxQuoteParams{
+		ID:              uuid.New(),
+		AccountID:       account.ID,
+		FromCurrency:    from.Code,
+		ToCurrency:      to.Code,
+		Rate:            FormatRate(rate),
+		Amount:          arg.Amount,
+		ConvertedAmount: converted,
+		ExpiresAt:       arg.Now.Add(QuoteDuration),
+	})
+}
@@ -0,0 +1,175 @@
+package fx
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func currency(t *testing.T, code string) util.Currency {
+	currency, ok := util.LookupCurrency(code)
+	require.True(t, ok)
+	return currency
+}
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("161.25")
+	require.NoError(t, err)
+	require.Equal(t, "161.250000000000", FormatRate(rate))
+
+	rate, err = ParseRate("0.000000000001")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(1, 1_000_000_000_000), rate)
+
+	for _, s := range []string{"", "abc", "0", "-1.5", "1/3", "0.0000000000001"} {
+		_, err := ParseRate(s)
+		require.ErrorIs(t, err, ErrInvalidRate, s)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		name   string
+		from   string
+		to     string
+		rate   string
+		amount int64
+		want   int64
+	}{
+		// the yen has no minor unit, 100 yen are 100 minor units
+		{"JPYToEUR", util.JPY, util.EUR, "0.006172839506", 10_000, 6172},
+		// 61.728...cents is rounded down to 61 cents
+		{"JPYToEURRoundsDown", util.JPY, util.EUR, "0.006172839506", 100, 61},
+		{"JPYToEURTooSmall", util.JPY, util.EUR, "0.006172839506", 1, 0},
+		// 1.00 EUR is 100 minor units and buys 162 yen
+		{"EURToJPY", util.EUR, util.JPY, "162", 100, 162},
+		// 0.99 EUR buys 160.38 yen, rounded down to 160
+		{"EURToJPYRoundsDown", util.EUR, util.JPY, "162", 99, 160},
+		{"EURToUSD", util.EUR, util.USD, "1.0825", 10_000, 10_825},
+		// 0.01 EUR buys 1.0825 cents, rounded down to 1 cent
+		{"EURToUSDRoundsDown", util.EUR, util.USD, "1.0825", 1, 1},
+		{"USDToINR", util.USD, util.INR, "83.123456789012", 1, 83},
+		{"Exact", util.USD, util.EUR, "0.5", 1_000_001, 500_000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rate, err := ParseRate(tc.rate)
+			require.NoError(t, err)
+
+			converted, err := Convert(tc.amount, currency(t, tc.from), currency(t, tc.to), rate)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, converted)
+		})
+	}
+
+	rate, err := ParseRate("1000")
+	require.NoError(t, err)
+	_, err = Convert(math.MaxInt64, currency(t, util.EUR), currency(t, util.JPY), rate)
+	require.ErrorIs(t, err, ErrConvertedTooLarge)
+}
+
+func TestLatestRate(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	ctx := context.Background()
+
+	_, err := LatestRate(ctx, store, util.EUR, util.JPY)
+	require.ErrorIs(t, err, ErrRateNotFound)
+	_, err = LatestRate(ctx, store, util.EUR, util.EUR)
+	require.ErrorIs(t, err, ErrSameCurrency)
+
+	for _, rate := range []string{"160", "162"} {
+		_, err := SetRate(ctx, store, SetRateParams{BaseCurrency: util.EUR, QuoteCurrency: util.JPY, Rate: rate, Source: "test"})
+		require.NoError(t, err)
+	}
+
+	// the latest rate of the pair is the current one
+	rate, err := LatestRate(ctx, store, util.EUR, util.JPY)
+	require.NoError(t, err)
+	require.Equal(t, "162.000000000000", FormatRate(rate))
+
+	// the opposite direction uses the inverse, cut to the 12 decimal places a rate is stored with
+	rate, err = LatestRate(ctx, store, util.JPY, util.EUR)
+	require.NoError(t, err)
+	require.Equal(t, "0.006172839506", FormatRate(rate))
+
+	_, err = SetRate(ctx, store, SetRateParams{BaseCurrency: util.EUR, QuoteCurrency: "YEN", Rate: "162"})
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+	_, err = SetRate(ctx, store, SetRateParams{BaseCurrency: util.EUR, QuoteCurrency: util.JPY, Rate: "-1"})
+	require.ErrorIs(t, err, ErrInvalidRate)
+}
+
+func TestCreateQuote(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	ctx := context.Background()
+
+	account, err := store.CreateAccount(ctx, sqlc.CreateAccountParams{
+		Owner:     util.RandomOwner(),
+		Email:     util.RandomEmail(),
+		Currency:  util.JPY,
+		CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	_, err = CreateQuote(ctx, store, QuoteParams{AccountID: account.ID, ToCurrency: util.EUR, Amount: 10_000, Now: now})
+	require.ErrorIs(t, err, ErrRateNotFound)
+
+	_, err = SetRate(ctx, store, SetRateParams{BaseCurrency: util.EUR, QuoteCurrency: util.JPY, Rate: "162", Source: "test"})
+	require.NoError(t, err)
+
+	quote, err := CreateQuote(ctx, store, QuoteParams{AccountID: account.ID, ToCurrency: util.EUR, Amount: 10_000, Now: now})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, quote.AccountID)
+	require.Equal(t, util.JPY, quote.FromCurrency)
+	require.Equal(t, util.EUR, quote.ToCurrency)
+	require.Equal(t, "0.006172839506", quote.Rate)
+	require.Equal(t, int64(10_000), quote.Amount)
+	require.Equal(t, int64(6172), quote.ConvertedAmount)
+	require.True(t, now.Add(QuoteDuration).Equal(quote.ExpiresAt))
+	require.False(t, quote.UsedAt.Valid)
+
+	// a single yen is worth less than a cent
+	_, err = CreateQuote(ctx, store, QuoteParams{AccountID: account.ID, ToCurrency: util.EUR, Amount: 1, Now: now})
+	require.ErrorIs(t, err, ErrConvertedTooSmall)
+
+	_, err = CreateQuote(ctx, store, QuoteParams{AccountID: account.ID, ToCurrency: util.JPY, Amount: 100, Now: now})
+	require.ErrorIs(t, err, ErrSameCurrency)
+
+	_, err = CreateQuote(ctx, store, QuoteParams{AccountID: account.ID + 100, ToCurrency: util.EUR, Amount: 100, Now: now})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestLoadRates(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	ctx := context.Background()
+
+	loaded, err := LoadRates(ctx, store, strings.NewReader("base,quote,rate\nEUR,JPY,162\nUSD, JPY, 150.5\n"), "file:rates.csv")
+	require.NoError(t, err)
+	require.Equal(t, 2, loaded)
+
+	rates, err := store.ListLatestFxRates(ctx)
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	require.Equal(t, util.EUR, rates[0].BaseCurrency)
+	require.Equal(t, "file:rates.csv", rates[0].Source)
+	require.Equal(t, util.USD, rates[1].BaseCurrency)
+	require.Equal(t, "150.500000000000", rates[1].Rate)
+
+	// a bad row anywhere records nothing
+	_, err = LoadRates(ctx, store, strings.NewReader("EUR,USD,1.08\nEUR,XXX,1\n"), "file:bad.csv")
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+	_, err = LoadRates(ctx, store, strings.NewReader("EUR,USD\n"), "file:bad.csv")
+	require.Error(t, err)
+
+	rates, err = store.ListLatestFxRates(ctx)
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+}
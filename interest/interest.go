@@ -0,0 +1,230 @@
+// Package interest accrues interest on customer accounts every day, pays it out every month
+// and resets extra interest once it ran out.
+//
+// Everything is derived from the date being processed, never from the clock: accruing a day
+// uses the balance at the end of that day and the rate in effect on it, so past days can be
+// backfilled and running a day or month twice changes nothing.
+package interest
+
+import (
+	"4d63.com/tz"
+	"bank-api/db/sqlc"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// interest accrues on a 365 day year, leap years included
+	daysPerYear = 365
+	// accruals are kept in millionths of the minor unit, see interest_accruals.amount_micro
+	microsPerUnit = 1_000_000
+	// accounts are accrued in batches of this size
+	batchSize = 500
+)
+
+// AnnualRate returns the rate in percent an account earns on day: the base interest plus the
+// extra interest while it is in effect, that is from its start date for its duration in months.
+func AnnualRate(account sqlc.Account, day time.Time) float64 {
+	rate := account.Interest
+	expiry, ok := sqlc.ExtraInterestExpiry(account)
+	if !ok {
+		return rate
+	}
+
+	start := dateOf(account.ExtraInterestStartDate.Time)
+	if date := dateOf(day); !date.Before(start) && date.Before(expiry) {
+		rate += account.ExtraInterest.Float64
+	}
+	return rate
+}
+
+// DailyAmountMicro returns the interest a balance earns in one day at the annual rate, in
+// millionths of the minor unit, rounded half away from zero. Balances below zero earn nothing.
+func DailyAmountMicro(balance int64, annualRate float64) int64 {
+	if balance <= 0 || annualRate <= 0 {
+		return 0
+	}
+	return int64(math.Round(float64(balance) * annualRate / 100 * microsPerUnit / daysPerYear))
+}
+
+// dateOf strips the time of day, keeping the calendar date as written. Dates read from a DATE
+// column come back as UTC midnight, so converting them to another zone could shift the day.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Engine accrues and pays interest for every customer account
+type Engine struct {
+	store sqlc.Store
+	loc   *time.Location
+}
+
+func NewEngine(store sqlc.Store) (*Engine, error) {
+	loc, err := tz.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Engine{store: store, loc: loc}, nil
+}
+
+// AccrueDay records the interest every customer account earned on the Tokyo calendar day of
+// day, on its balance at the end of that day. Accounts that already accrued for the day are
+// skipped; it returns how many accruals it recorded.
+func (engine *Engine) AccrueDay(ctx context.Context, day time.Time) (int64, error) {
+	year, month, date := day.In(engine.loc).Date()
+	start := time.Date(year, month, date, 0, 0, 0, 0, engine.loc)
+	end := start.AddDate(0, 0, 1)
+
+	var accrued int64
+	var afterID int64
+	for {
+		accounts, err := engine.store.ListAccountsForInterest(ctx, sqlc.ListAccountsForInterestParams{
+			CreatedBefore: end,
+			AfterID:       afterID,
+			BatchSize:     batchSize,
+		})
+		if err != nil {
+			return accrued, err
+		}
+
+		for _, account := range accounts {
+			ok, err := engine.accrue(ctx, account, start, end)
+			if err != nil {
+				return accrued, fmt.Errorf("account [%d]: %w", account.ID, err)
+			}
+			if ok {
+				accrued++
+			}
+			afterID = account.ID
+		}
+
+		if len(accounts) < batchSize {
+			return accrued, nil
+		}
+	}
+}
+
+func (engine *Engine) accrue(ctx context.Context, account sqlc.Account, start time.Time, end time.Time) (bool, error) {
+	balance, err := engine.store.GetAccountBalanceAt(ctx, sqlc.GetAccountBalanceAtParams{
+		At: end,
+		ID: account.ID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// the extra interest may have changed since the day, the first change after it kept the terms
+	// the account had then
+	change, err := engine.store.GetAccountInterestChangeSince(ctx, sqlc.GetAccountInterestChangeSinceParams{
+		AccountID: account.ID,
+		ChangedAt: end,
+	})
+	if err == nil {
+		account.ExtraInterest = change.ExtraInterest
+		account.ExtraInterestStartDate = change.ExtraInterestStartDate
+		account.ExtraInterestDuration = change.ExtraInterestDuration
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	rate := AnnualRate(account, start)
+	_, err = engine.store.CreateInterestAccrual(ctx, sqlc.CreateInterestAccrualParams{
+		AccountID:   account.ID,
+		AccrualDate: dateOf(start),
+		Balance:     balance,
+		AnnualRate:  rate,
+		AmountMicro: DailyAmountMicro(balance, rate),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// PayMonth pays every account the interest it accrued during the Tokyo calendar month of
+// month, along with what it accrued earlier that was not paid yet: a day accrued after its
+// month was paid, by a late or backfilled run, is paid with the next month. Accounts already
+// paid for the month are skipped; it returns how many it paid. One account failing doesn't hold
+// up the others, the errors are returned together.
+func (engine *Engine) PayMonth(ctx context.Context, month time.Time) (int64, error) {
+	year, mon, _ := month.In(engine.loc).Date()
+	first := time.Date(year, mon, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+	period := first.Format("2006-01")
+
+	accounts, err := engine.store.ListAccountsWithUnpaidInterestAccruals(ctx, last)
+	if err != nil {
+		return 0, err
+	}
+
+	var paid int64
+	var errs []error
+	for _, accountID := range accounts {
+		_, err := engine.store.PayInterestTx(ctx, sqlc.PayInterestTxParams{
+			AccountID: accountID,
+			Period:    period,
+			To:        last,
+		})
+		if err != nil {
+			if errors.Is(err, sqlc.ErrInterestAlreadyPaid) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("account [%d]: %w", accountID, err))
+			continue
+		}
+		paid++
+	}
+
+	return paid, errors.Join(errs...)
+}
+
+// ExpireExtraInterest resets the extra interest of every account whose extra interest is no
+// longer in effect on the Tokyo calendar day of day, and returns how many it reset. An account
+// whose last day of extra interest has not accrued yet is left for a later run, see
+// sqlc.ErrExtraInterestNotAccrued.
+func (engine *Engine) ExpireExtraInterest(ctx context.Context, day time.Time) (int64, error) {
+	asOf := dateOf(day.In(engine.loc))
+
+	var expired int64
+	var afterID int64
+	var errs []error
+	for {
+		accounts, err := engine.store.ListAccountsWithExpiredExtraInterest(ctx, sqlc.ListAccountsWithExpiredExtraInterestParams{
+			AsOf:      asOf,
+			AfterID:   afterID,
+			BatchSize: batchSize,
+		})
+		if err != nil {
+			errs = append(errs, err)
+			return expired, errors.Join(errs...)
+		}
+
+		for _, account := range accounts {
+			afterID = account.ID
+			_, err := engine.store.ExpireExtraInterestTx(ctx, sqlc.ExpireExtraInterestTxParams{
+				AccountID: account.ID,
+				AsOf:      asOf,
+			})
+			if err != nil {
+				if errors.Is(err, sqlc.ErrExtraInterestNotExpired) || errors.Is(err, sqlc.ErrExtraInterestNotAccrued) {
+					continue
+				}
+				errs = append(errs, fmt.Errorf("account [%d]: %w", account.ID, err))
+				continue
+			}
+			expired++
+		}
+
+		if len(accounts) < batchSize {
+			return expired, errors.Join(errs...)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package interest
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func createAccount(t *testing.T, store sqlc.Store, balance int64, createdAt time.Time) sqlc.Account {
+	account, err := store.CreateAccount(context.Background(), sqlc.CreateAccountParams{
+		Owner:     util.RandomOwner(),
+		Balance:   balance,
+		Email:     util.RandomEmail(),
+		Currency:  util.JPY,
+		CreatedAt: createdAt,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestAnnualRate(t *testing.T) {
+	account := sqlc.Account{
+		Interest:               4.5,
+		ExtraInterest:          sql.NullFloat64{Float64: 2, Valid: true},
+		ExtraInterestStartDate: sql.NullTime{Time: time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		ExtraInterestDuration:  9,
+	}
+
+	testCases := []struct {
+		name string
+		day  time.Time
+		rate float64
+	}{
+		{"BeforeStart", time.Date(2024, time.July, 31, 0, 0, 0, 0, time.UTC), 4.5},
+		{"StartDate", time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC), 6.5},
+		{"LastDay", time.Date(2025, time.April, 30, 0, 0, 0, 0, time.UTC), 6.5},
+		{"Expired", time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC), 4.5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.rate, AnnualRate(account, tc.day))
+		})
+	}
+
+	account.ExtraInterestStartDate = sql.NullTime{}
+	require.Equal(t, 4.5, AnnualRate(account, time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestDailyAmountMicro(t *testing.T) {
+	// 1,000,000 at 3.65% earns 100 a day
+	require.Equal(t, int64(100*microsPerUnit), DailyAmountMicro(1_000_000, 3.65))
+	// 1 at 4.5% earns 123.287... micro a day, rounded half away from zero
+	require.Equal(t, int64(123), DailyAmountMicro(1, 4.5))
+	require.Equal(t, int64(0), DailyAmountMicro(0, 4.5))
+	require.Equal(t, int64(0), DailyAmountMicro(-1000, 4.5))
+}
+
+func TestAccrueAndPay(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	engine, err := NewEngine(store)
+	require.NoError(t, err)
+
+	july := time.Date(2024, time.July, 1, 0, 0, 0, 0, engine.loc)
+	account := createAccount(t, store, 1_000_000, july)
+	empty := createAccount(t, store, 0, july)
+	// accounts opened later don't accrue for days before they existed
+	late := createAccount(t, store, 1_000_000, july.AddDate(0, 0, 20))
+
+	ctx := context.Background()
+	for day := july; day.Month() == time.July; day = day.AddDate(0, 0, 1) {
+		_, err := engine.AccrueDay(ctx, day)
+		require.NoError(t, err)
+	}
+
+	// running a day again accrues nothing
+	accrued, err := engine.AccrueDay(ctx, july)
+	require.NoError(t, err)
+	require.Zero(t, accrued)
+
+	accruals, err := store.ListInterestAccruals(ctx, sqlc.ListInterestAccrualsParams{
+		AccountID:     account.ID,
+		AccrualDate:   july,
+		AccrualDate_2: july.AddDate(0, 1, -1),
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 31)
+	for _, accrual := range accruals {
+		require.Equal(t, account.Balance, accrual.Balance)
+		require.Equal(t, 4.5, accrual.AnnualRate)
+		require.Equal(t, DailyAmountMicro(account.Balance, 4.5), accrual.AmountMicro)
+	}
+
+	paid, err := engine.PayMonth(ctx, july)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), paid)
+
+	// paying the month again pays nothing
+	paid, err = engine.PayMonth(ctx, july)
+	require.NoError(t, err)
+	require.Zero(t, paid)
+
+	want := 31 * DailyAmountMicro(account.Balance, 4.5) / microsPerUnit
+	payout, err := store.GetInterestPayout(ctx, sqlc.GetInterestPayoutParams{AccountID: account.ID, Period: "2024-07"})
+	require.NoError(t, err)
+	require.Equal(t, want, payout.Amount)
+	require.True(t, payout.TransferID.Valid)
+
+	updated, err := store.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+want, updated.Balance)
+
+	payout, err = store.GetInterestPayout(ctx, sqlc.GetInterestPayoutParams{AccountID: empty.ID, Period: "2024-07"})
+	require.NoError(t, err)
+	require.Zero(t, payout.Amount)
+	require.False(t, payout.TransferID.Valid)
+
+	payout, err = store.GetInterestPayout(ctx, sqlc.GetInterestPayoutParams{AccountID: late.ID, Period: "2024-07"})
+	require.NoError(t, err)
+	require.Equal(t, 11*DailyAmountMicro(late.Balance, 4.5)/microsPerUnit, payout.Amount)
+}
+
+func TestPayMonthPaysLateAccrualsWithNextMonth(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	engine, err := NewEngine(store)
+	require.NoError(t, err)
+
+	july := time.Date(2024, time.July, 1, 0, 0, 0, 0, engine.loc)
+	account := createAccount(t, store, 1_000_000, july)
+	daily := DailyAmountMicro(account.Balance, 4.5)
+
+	ctx := context.Background()
+	for day := july; day.Day() < 31; day = day.AddDate(0, 0, 1) {
+		_, err := engine.AccrueDay(ctx, day)
+		require.NoError(t, err)
+	}
+
+	// July is paid before its last day accrued
+	paid, err := engine.PayMonth(ctx, july)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), paid)
+
+	payout, err := store.GetInterestPayout(ctx, sqlc.GetInterestPayoutParams{AccountID: account.ID, Period: "2024-07"})
+	require.NoError(t, err)
+	require.Equal(t, 30*daily/microsPerUnit, payout.Amount)
+
+	// the backfilled day is not lost, August pays it
+	_, err = engine.AccrueDay(ctx, july.AddDate(0, 0, 30))
+	require.NoError(t, err)
+
+	august := july.AddDate(0, 1, 0)
+	paid, err = engine.PayMonth(ctx, august)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), paid)
+
+	payout, err = store.GetInterestPayout(ctx, sqlc.GetInterestPayoutParams{AccountID: account.ID, Period: "2024-08"})
+	require.NoError(t, err)
+	require.Equal(t, daily/microsPerUnit, payout.Amount)
+}
+
+func TestAccrueDayUsesBalanceAtEndOfDay(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	engine, err := NewEngine(store)
+	require.NoError(t, err)
+
+	// the money arrives now, after the day being backfilled, so it doesn't count
+	day := time.Date(2024, time.July, 1, 0, 0, 0, 0, engine.loc)
+	from := createAccount(t, store, 5000, day)
+	to := createAccount(t, store, 1000, day)
+	_, err = store.TransferTx(context.Background(), sqlc.TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        500,
+	})
+	require.NoError(t, err)
+
+	_, err = engine.AccrueDay(context.Background(), day)
+	require.NoError(t, err)
+
+	accruals, err := store.ListInterestAccruals(context.Background(), sqlc.ListInterestAccrualsParams{
+		AccountID:     to.ID,
+		AccrualDate:   day,
+		AccrualDate_2: day,
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 1)
+	require.Equal(t, int64(1000), accruals[0].Balance)
+}
+
+func TestAccrueDayUsesRateInEffectOnDay(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	engine, err := NewEngine(store)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	account := createAccount(t, store, 1_000_000, time.Date(2024, time.July, 1, 0, 0, 0, 0, engine.loc))
+	_, err = store.UpdateAccountInterest(ctx, sqlc.UpdateAccountInterestParams{
+		ID:                     account.ID,
+		ExtraInterest:          sql.NullFloat64{Float64: 2, Valid: true},
+		ExtraInterestStartDate: sql.NullTime{Time: time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		ExtraInterestDuration:  9,
+	})
+	require.NoError(t, err)
+
+	lastDay := time.Date(2025, time.April, 30, 0, 0, 0, 0, engine.loc)
+	_, err = engine.AccrueDay(ctx, lastDay)
+	require.NoError(t, err)
+	expired, err := engine.ExpireExtraInterest(ctx, lastDay.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), expired)
+
+	// the day before is backfilled once the extra interest was reset, it still earned it
+	dayBefore := lastDay.AddDate(0, 0, -1)
+	_, err = engine.AccrueDay(ctx, dayBefore)
+	require.NoError(t, err)
+
+	accruals, err := store.ListInterestAccruals(ctx, sqlc.ListInterestAccrualsParams{
+		AccountID:     account.ID,
+		AccrualDate:   dayBefore,
+		AccrualDate_2: dayBefore,
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 1)
+	require.Equal(t, 6.5, accruals[0].AnnualRate)
+
+	// days accrued after the change earn the rate the account has now
+	_, err = engine.AccrueDay(ctx, lastDay.AddDate(0, 0, 1))
+	require.NoError(t, err)
+
+	accruals, err = store.ListInterestAccruals(ctx, sqlc.ListInterestAccrualsParams{
+		AccountID:     account.ID,
+		AccrualDate:   lastDay.AddDate(0, 0, 1),
+		AccrualDate_2: lastDay.AddDate(0, 0, 1),
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 1)
+	require.Equal(t, 4.5, accruals[0].AnnualRate)
+}
+
+func TestExpireExtraInterest(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	engine, err := NewEngine(store)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	account := createAccount(t, store, 1_000_000, time.Date(2024, time.July, 1, 0, 0, 0, 0, engine.loc))
+	account, err = store.UpdateAccountInterest(ctx, sqlc.UpdateAccountInterestParams{
+		ID:                     account.ID,
+		ExtraInterest:          sql.NullFloat64{Float64: 2, Valid: true},
+		ExtraInterestStartDate: sql.NullTime{Time: time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		ExtraInterestDuration:  9,
+	})
+	require.NoError(t, err)
+
+	// the last day of the extra interest
+	lastDay := time.Date(2025, time.April, 30, 0, 0, 0, 0, engine.loc)
+	expired, err := engine.ExpireExtraInterest(ctx, lastDay)
+	require.NoError(t, err)
+	require.Zero(t, expired)
+
+	// the accrual of the last day is late, the expiry waits for it
+	expired, err = engine.ExpireExtraInterest(ctx, lastDay.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Zero(t, expired)
+
+	_, err = engine.AccrueDay(ctx, lastDay)
+	require.NoError(t, err)
+
+	expired, err = engine.ExpireExtraInterest(ctx, lastDay.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), expired)
+
+	expired, err = engine.ExpireExtraInterest(ctx, lastDay.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Zero(t, expired)
+
+	updated, err := store.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Zero(t, updated.ExtraInterest.Float64)
+	require.False(t, updated.ExtraInterestStartDate.Valid)
+
+	// the last day still accrued with the extra interest
+	accruals, err := store.ListInterestAccruals(ctx, sqlc.ListInterestAccrualsParams{
+		AccountID:     account.ID,
+		AccrualDate:   lastDay,
+		AccrualDate_2: lastDay,
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 1)
+	require.Equal(t, 6.5, accruals[0].AnnualRate)
+
+	entries, err := store.ListAuditEntries(ctx, sqlc.ListAuditEntriesParams{AccountID: account.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, sqlc.AuditExtraInterestExpired, entries[0].Action)
+	require.JSONEq(t, `{"extra_interest":2,"start_date":"2024-08-01","duration":9,"expired_on":"2025-05-01"}`, string(entries[0].Detail))
+}
@@ -0,0 +1,188 @@
+// Package metrics holds the Prometheus metrics of the API: request latency per route, the
+// connection pool, database transactions, and the business events behind them. The counters
+// are package variables so the store and the handlers can count without being handed anything;
+// NewRegistry gathers them with the collectors of a single process for /metrics to serve.
+//
+// Labels stay bounded on purpose: routes are the templates Gin matched, like /accounts/:id,
+// never the path that was requested, and nothing is labelled by account or customer.
+package metrics
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"net/http"
+)
+
+const namespace = "bankapi"
+
+// UnmatchedRoute is the route label of requests no route matched
+const UnmatchedRoute = "unmatched"
+
+// Outcomes of a database transaction
+const (
+	TxCommitted  = "committed"
+	TxRolledBack = "rolled_back"
+)
+
+var (
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Time spent serving HTTP requests, by method, route template and status.",
+		// the reconciliation report streams for up to a minute
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"method", "route", "status"})
+	HTTPRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "HTTP requests being served.",
+	})
+
+	DBTransactions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "transactions_total",
+		Help:      "Database transactions run, by outcome: committed or rolled_back.",
+	}, []string{"outcome"})
+	DBTransactionRetries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "transaction_retries_total",
+		Help:      "Database transactions run again after a serialization failure or a deadlock.",
+	})
+
+	AccountsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "accounts_created_total",
+		Help:      "Accounts opened, at signup or by existing customers, by currency.",
+	}, []string{"currency"})
+	Transfers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Transfers between customer accounts, by the currency of the source account.",
+	}, []string{"currency"})
+	TransferVolume = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfer_volume_total",
+		Help:      "Money moved by transfers in the minor unit of the currency of the source account.",
+	}, []string{"currency"})
+	ReferralCodesCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "referral_codes_created_total",
+		Help:      "Referral codes handed out.",
+	})
+	ReferralCodesRedeemed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "referral_codes_redeemed_total",
+		Help:      "Referral codes redeemed, by whether the code was used at signup or by an existing account.",
+	}, []string{"signup"})
+	ExtraInterestUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "extra_interest_updates_total",
+		Help:      "Changes to the extra interest of accounts, by reason.",
+	}, []string{"reason"})
+)
+
+// all are the metrics of the package, every registry gets them
+var all = []prometheus.Collector{
+	HTTPRequestDuration,
+	HTTPRequestsInFlight,
+	DBTransactions,
+	DBTransactionRetries,
+	AccountsCreated,
+	Transfers,
+	TransferVolume,
+	ReferralCodesCreated,
+	ReferralCodesRedeemed,
+	ExtraInterestUpdates,
+}
+
+// NewRegistry gathers the metrics of the package, those of the Go runtime and the process, and
+// the given collectors
+func NewRegistry(extra ...prometheus.Collector) (*prometheus.Registry, error) {
+	registry := prometheus.NewRegistry()
+	cs := append([]prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	}, all...)
+	for _, c := range append(cs, extra...) {
+		if err := registry.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// MethodLabel is the method of a request as a label. Clients may send any token as the method,
+// those that are not standard are lumped together so they cannot blow up the label values.
+func MethodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "other"
+}
+
+// dbStatsCollector reads the stats of a connection pool at every scrape
+type dbStatsCollector struct {
+	stats func() sql.DBStats
+
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxIdleTimeClosed *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+// NewDBStatsCollector exports the stats of the connection pool stats returns, like those of
+// sql.DB.Stats
+func NewDBStatsCollector(stats func() sql.DBStats) prometheus.Collector {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db", name), help, nil, nil)
+	}
+	return &dbStatsCollector{
+		stats:             stats,
+		maxOpen:           desc("max_open_connections", "Maximum number of open connections to the database."),
+		open:              desc("open_connections", "Established connections, in use and idle."),
+		inUse:             desc("in_use_connections", "Connections in use."),
+		idle:              desc("idle_connections", "Idle connections."),
+		waitCount:         desc("wait_count_total", "Times a connection had to be waited for."),
+		waitDuration:      desc("wait_duration_seconds_total", "Time spent waiting for a connection."),
+		maxIdleClosed:     desc("max_idle_closed_total", "Connections closed because of the maximum of idle connections."),
+		maxIdleTimeClosed: desc("max_idle_time_closed_total", "Connections closed because they were idle for too long."),
+		maxLifetimeClosed: desc("max_lifetime_closed_total", "Connections closed because they reached their maximum lifetime."),
+	}
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxIdleClosed
+	ch <- c.maxIdleTimeClosed
+	ch <- c.maxLifetimeClosed
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxIdleTimeClosed, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
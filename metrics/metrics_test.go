@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDBStatsCollector(t *testing.T) {
+	stats := sql.DBStats{
+		MaxOpenConnections: 25,
+		OpenConnections:    7,
+		InUse:              5,
+		Idle:               2,
+		WaitCount:          3,
+		WaitDuration:       1500 * time.Millisecond,
+		MaxLifetimeClosed:  4,
+	}
+	collector := NewDBStatsCollector(func() sql.DBStats { return stats })
+
+	expected := `
+# HELP bankapi_db_in_use_connections Connections in use.
+# TYPE bankapi_db_in_use_connections gauge
+bankapi_db_in_use_connections 5
+# HELP bankapi_db_max_open_connections Maximum number of open connections to the database.
+# TYPE bankapi_db_max_open_connections gauge
+bankapi_db_max_open_connections 25
+# HELP bankapi_db_wait_duration_seconds_total Time spent waiting for a connection.
+# TYPE bankapi_db_wait_duration_seconds_total counter
+bankapi_db_wait_duration_seconds_total 1.5
+# HELP bankapi_db_max_lifetime_closed_total Connections closed because they reached their maximum lifetime.
+# TYPE bankapi_db_max_lifetime_closed_total counter
+bankapi_db_max_lifetime_closed_total 4
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"bankapi_db_in_use_connections", "bankapi_db_max_open_connections",
+		"bankapi_db_wait_duration_seconds_total", "bankapi_db_max_lifetime_closed_total"))
+
+	// read again at every scrape
+	stats.InUse = 1
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP bankapi_db_in_use_connections Connections in use.
+# TYPE bankapi_db_in_use_connections gauge
+bankapi_db_in_use_connections 1
+`), "bankapi_db_in_use_connections"))
+}
+
+func TestNewRegistry(t *testing.T) {
+	// every server gets a registry of its own, the package metrics go into each of them
+	for i := 0; i < 2; i++ {
+		registry, err := NewRegistry(NewDBStatsCollector(func() sql.DBStats { return sql.DBStats{} }))
+		require.NoError(t, err)
+
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		names := map[string]bool{}
+		for _, family := range families {
+			names[family.GetName()] = true
+		}
+		require.True(t, names["bankapi_db_open_connections"])
+		require.True(t, names["go_goroutines"])
+
+		// the names and help texts follow the conventions of Prometheus
+		problems, err := testutil.GatherAndLint(registry)
+		require.NoError(t, err)
+		require.Empty(t, problems)
+	}
+}
+
+func TestMethodLabel(t *testing.T) {
+	require.Equal(t, "GET", MethodLabel("GET"))
+	require.Equal(t, "DELETE", MethodLabel("DELETE"))
+	require.Equal(t, "other", MethodLabel("PROPFIND"))
+	require.Equal(t, "other", MethodLabel("get"))
+}
@@ -0,0 +1,65 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileSender writes every message to a .eml file in a directory instead of sending it, for
+// development without a mail server. The files open in any mail client.
+type FileSender struct {
+	dir  string
+	from string
+	mu   sync.Mutex
+	seq  int
+}
+
+func NewFileSender(dir string, from string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileSender{dir: dir, from: from}, nil
+}
+
+func (sender *FileSender) Send(ctx context.Context, message Message) error {
+	now := time.Now()
+
+	sender.mu.Lock()
+	sender.seq++
+	seq := sender.seq
+	sender.mu.Unlock()
+
+	// named by time so the files list in the order they were sent
+	recipient := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(message.To)
+	name := fmt.Sprintf("%s-%04d-%s.eml", now.Format("20060102T150405.000000"), seq, recipient)
+	return os.WriteFile(filepath.Join(sender.dir, name), message.format(sender.from, now), 0o644)
+}
+
+// MemorySender keeps the messages it is given, for tests
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+func (sender *MemorySender) Send(ctx context.Context, message Message) error {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	sender.messages = append(sender.messages, message)
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first
+func (sender *MemorySender) Messages() []Message {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	return append([]Message(nil), sender.messages...)
+}
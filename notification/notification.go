@@ -0,0 +1,50 @@
+// Package notification emails customers about what happened to their accounts. It never runs
+// in a request: the store writes a domain event to the outbox with every change, and the
+// Notifier, plugged into the outbox relay as a sink, records an email delivery for every
+// customer the event is about. The Worker renders the deliveries and hands them to a Sender,
+// apart from the relay and each with retries of its own, so a mail server that is down delays
+// notifications but holds up nothing else and loses none of them.
+//
+// Delivery is at least once: a customer may get a message twice when the worker dies between
+// sending it and recording that it did, but never misses one.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"time"
+)
+
+// Message is a plain text email to a single recipient
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// format renders the message as an RFC 5322 email from the given address
+func (message Message) format(from string, date time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	// Close only flushes into the buffer, it cannot fail
+	_, _ = body.Write([]byte(message.Body))
+	_ = body.Close()
+	return buf.Bytes()
+}
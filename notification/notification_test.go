@@ -0,0 +1,157 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/require"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	message, err := Render(KindTransferReceived, "taro@example.com", TransferReceivedData{
+		Name:       "Taro",
+		AccountID:  7,
+		TransferID: 42,
+		Amount:     "¥1,000",
+		Date:       "2024-08-01",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "taro@example.com", message.To)
+	require.Equal(t, "You received ¥1,000", message.Subject)
+	require.Equal(t, "Hello Taro,\n\n¥1,000 arrived in your account 7 on 2024-08-01 (transfer 42).\n", message.Body)
+
+	message, err = Render(KindInterestChanged, "taro@example.com", InterestChangedData{Name: "Taro", AccountID: 7, ExtraInterest: 1.5, StartDate: "2024-09-01", Months: 9})
+	require.NoError(t, err)
+	require.Equal(t, "Your interest goes up", message.Subject)
+	require.Contains(t, message.Body, "an extra 1.5% interest from 2024-09-01, for 9 months")
+
+	message, err = Render(KindInterestChanged, "taro@example.com", InterestChangedData{Name: "Taro", AccountID: 7})
+	require.NoError(t, err)
+	require.Equal(t, "Your extra interest has ended", message.Subject)
+
+	_, err = Render("newsletter", "taro@example.com", nil)
+	require.ErrorIs(t, err, ErrUnknownKind)
+
+	// every kind renders with its data
+	for kind, data := range map[string]any{
+		KindReferralUsed:     ReferralUsedData{Name: "Taro", Signup: true, Date: "2024-08-01"},
+		KindBonusGranted:     BonusGrantedData{Name: "Taro", AccountID: 7, Amount: "¥1,000"},
+		KindTransferReceived: TransferReceivedData{Name: "Taro"},
+		KindInterestChanged:  InterestChangedData{Name: "Taro"},
+		KindPasswordReset:    PasswordResetData{Name: "Taro", Token: "abc"},
+	} {
+		message, err := Render(kind, "taro@example.com", data)
+		require.NoError(t, err, kind)
+		require.True(t, strings.HasPrefix(message.Body, "Hello Taro,"), kind)
+	}
+}
+
+func TestFileSender(t *testing.T) {
+	dir := t.TempDir()
+	sender, err := NewFileSender(filepath.Join(dir, "mail"), "bank@example.com")
+	require.NoError(t, err)
+
+	message := Message{To: "taro@example.com", Subject: "Grüße", Body: "Hello Taro,\n"}
+	require.NoError(t, sender.Send(context.Background(), message))
+	require.NoError(t, sender.Send(context.Background(), message))
+
+	files, err := os.ReadDir(filepath.Join(dir, "mail"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.True(t, strings.HasSuffix(files[0].Name(), "-taro_at_example.com.eml"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "mail", files[0].Name()))
+	require.NoError(t, err)
+	require.Contains(t, string(data), "From: bank@example.com\r\n")
+	require.Contains(t, string(data), "To: taro@example.com\r\n")
+	require.Contains(t, string(data), "Subject: =?utf-8?q?Gr=C3=BC=C3=9Fe?=\r\n")
+	require.True(t, strings.HasSuffix(string(data), "\r\n\r\nHello Taro,\r\n"))
+}
+
+// fakeSMTPServer accepts a single session and passes on the data of the message it received
+func fakeSMTPServer(t *testing.T) (string, int, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ready")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.Fields(line)[0])
+			switch command {
+			case "EHLO":
+				reply("250-localhost")
+				reply("250 8BITMIME")
+			case "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return host, portNumber, received
+}
+
+func TestSMTPSender(t *testing.T) {
+	host, port, received := fakeSMTPServer(t)
+	sender := NewSMTPSender(host, port, "", "", "bank@example.com")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := sender.Send(ctx, Message{To: "taro@example.com", Subject: "You received ¥1,000", Body: "Hello Taro,\n"})
+	require.NoError(t, err)
+
+	data := <-received
+	require.Contains(t, data, "To: taro@example.com\r\n")
+	require.Contains(t, data, "Content-Type: text/plain; charset=utf-8\r\n")
+	require.Contains(t, data, "Hello Taro,")
+
+	// a server that is not there fails the send, for the relay to retry it
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener.Close()
+
+	_, closedPort, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	port, err = strconv.Atoi(closedPort)
+	require.NoError(t, err)
+	sender = NewSMTPSender(host, port, "", "", "bank@example.com")
+	require.Error(t, sender.Send(ctx, Message{To: "taro@example.com"}))
+}
@@ -0,0 +1,98 @@
+package notification
+
+import (
+	"bank-api/db/sqlc"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// Notifier is an outbox sink that records an email delivery for every customer an event is
+// about, which the Worker then sends. It only writes to the database, so a mail server that is
+// down holds up neither the relay nor the other sinks. Events no customer needs to hear about
+// are taken without recording anything.
+type Notifier struct {
+	store sqlc.Querier
+}
+
+func NewNotifier(store sqlc.Querier) *Notifier {
+	return &Notifier{store: store}
+}
+
+// Deliver records the deliveries of the event. A delivery already recorded for a customer is
+// left alone, so the relay may hand over the same event again without anyone getting a message
+// twice.
+func (notifier *Notifier) Deliver(ctx context.Context, event sqlc.OutboxEvent) error {
+	var err error
+	switch event.EventType {
+	case sqlc.EventReferralRedeemed:
+		err = notifier.referralRedeemed(ctx, event)
+	case sqlc.EventTransferCompleted:
+		var payload sqlc.TransferCompletedEvent
+		if err = json.Unmarshal(event.Payload, &payload); err == nil {
+			err = notifier.notifyAccount(ctx, event, KindTransferReceived, payload.ToAccountID)
+		}
+	case sqlc.EventExtraInterestUpdated:
+		var payload sqlc.ExtraInterestUpdatedEvent
+		if err = json.Unmarshal(event.Payload, &payload); err == nil {
+			err = notifier.notifyAccount(ctx, event, KindInterestChanged, payload.AccountID)
+		}
+	case sqlc.EventPasswordResetRequested:
+		var payload sqlc.PasswordResetRequestedEvent
+		if err = json.Unmarshal(event.Payload, &payload); err == nil {
+			err = notifier.notifyCustomer(ctx, event, KindPasswordReset, payload.CustomerID, sql.NullInt64{})
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("notify %s %d: %w", event.EventType, event.ID, err)
+	}
+	return nil
+}
+
+// referralRedeemed tells the referrer their code was used, and the referred customer about their
+// welcome bonus if there was one. Each gets a delivery of their own, so one failing to send never
+// sends the other again.
+func (notifier *Notifier) referralRedeemed(ctx context.Context, event sqlc.OutboxEvent) error {
+	var payload sqlc.ReferralRedeemedEvent
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+
+	err := notifier.notifyAccount(ctx, event, KindReferralUsed, payload.ReferrerAccountID)
+	if err != nil || payload.BonusAmount <= 0 {
+		return err
+	}
+	return notifier.notifyAccount(ctx, event, KindBonusGranted, payload.ReferredAccountID)
+}
+
+// notifyAccount records a message of the kind to the customer holding the account. System
+// accounts have no one to notify, and an account that no longer exists is skipped rather than
+// retried forever.
+func (notifier *Notifier) notifyAccount(ctx context.Context, event sqlc.OutboxEvent, kind string, accountID int64) error {
+	account, err := notifier.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("notification: %s for account %d skipped, the account no longer exists", kind, accountID)
+			return nil
+		}
+		return err
+	}
+	if !account.CustomerID.Valid {
+		return nil
+	}
+
+	return notifier.notifyCustomer(ctx, event, kind, account.CustomerID.Int64, sql.NullInt64{Int64: account.ID, Valid: true})
+}
+
+func (notifier *Notifier) notifyCustomer(ctx context.Context, event sqlc.OutboxEvent, kind string, customerID int64, accountID sql.NullInt64) error {
+	return notifier.store.CreateEmailDelivery(ctx, sqlc.CreateEmailDeliveryParams{
+		OutboxEventID: event.ID,
+		Kind:          kind,
+		CustomerID:    customerID,
+		AccountID:     accountID,
+		Payload:       event.Payload,
+	})
+}
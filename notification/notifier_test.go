@@ -0,0 +1,297 @@
+package notification
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/outbox"
+	"bank-api/util"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signup(t *testing.T, store sqlc.Store, name string, balance int64) sqlc.CreateAccountTxResult {
+	result, err := store.CreateAccountTx(context.Background(), sqlc.CreateAccountTxParams{
+		CreateAccountParams: sqlc.CreateAccountParams{
+			Owner:     name,
+			Email:     util.RandomEmail(),
+			Balance:   balance,
+			Currency:  util.JPY,
+			CreatedAt: time.Now(),
+		},
+		HashedPassword: "secret",
+	})
+	require.NoError(t, err)
+	return result
+}
+
+// notifyAll relays every pending event to a notifier, then sends the deliveries it recorded
+func notifyAll(t *testing.T, store sqlc.Store, worker *Worker) {
+	_, err := outbox.NewRelay(store, NewNotifier(store), outbox.Config{}).RelayBatch(context.Background())
+	require.NoError(t, err)
+	_, err = worker.SendBatch(context.Background())
+	require.NoError(t, err)
+}
+
+func TestNotifier(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	sender := NewMemorySender()
+	worker, err := NewWorker(store, sender, Config{})
+	require.NoError(t, err)
+
+	referrer := signup(t, store, "Hanako", 5000)
+	code, err := store.CreateReferralCode(context.Background(), sqlc.CreateReferralCodeParams{
+		ReferralCode:      util.RandomString(12),
+		ReferrerAccountID: referrer.Account.ID,
+		CreatedAt:         time.Now(),
+	})
+	require.NoError(t, err)
+
+	referred, err := store.SignupWithReferralTx(context.Background(), sqlc.SignupWithReferralTxParams{
+		CreateAccountTxParams: sqlc.CreateAccountTxParams{
+			CreateAccountParams: sqlc.CreateAccountParams{
+				Owner:     "Taro",
+				Email:     util.RandomEmail(),
+				Currency:  util.JPY,
+				CreatedAt: time.Date(2024, time.August, 1, 12, 0, 0, 0, time.UTC),
+			},
+			HashedPassword: "secret",
+		},
+		ReferralCode: code.ReferralCode,
+		BonusAmount:  1000,
+	})
+	require.NoError(t, err)
+
+	// the signups themselves and the bonus paid from a system account send nothing
+	notifyAll(t, store, worker)
+	messages := sender.Messages()
+	require.Len(t, messages, 2)
+
+	require.Equal(t, referrer.Customer.Email, messages[0].To)
+	require.Equal(t, "Your referral code was used", messages[0].Subject)
+	require.Contains(t, messages[0].Body, "Someone signed up with your referral code on 2024-08-01.")
+	require.NotContains(t, messages[0].Body, "Taro")
+
+	require.Equal(t, referred.Customer.Email, messages[1].To)
+	require.Equal(t, "Your welcome bonus of ¥1,000 has arrived", messages[1].Subject)
+
+	transfer, err := store.TransferTx(context.Background(), sqlc.TransferTxParams{
+		FromAccountID: referrer.Account.ID,
+		ToAccountID:   referred.Account.ID,
+		Amount:        1234,
+	})
+	require.NoError(t, err)
+
+	notifyAll(t, store, worker)
+	messages = sender.Messages()
+	require.Len(t, messages, 3)
+	require.Equal(t, referred.Customer.Email, messages[2].To)
+	require.Equal(t, "You received ¥1,234", messages[2].Subject)
+	require.Contains(t, messages[2].Body, "(transfer "+strconv.FormatInt(transfer.Transfer.ID, 10)+")")
+
+	_, err = store.RedeemReferralCodeTx(context.Background(), sqlc.RedeemReferralCodeTxParams{
+		ReferralCode:      code.ReferralCode,
+		ReferredAccountID: referred.Account.ID,
+		RedeemedAt:        time.Now(),
+	})
+	require.ErrorIs(t, err, sqlc.ErrReferralCodeUsed)
+}
+
+func TestNotifierInterestChanged(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	sender := NewMemorySender()
+	worker, err := NewWorker(store, sender, Config{})
+	require.NoError(t, err)
+
+	referrer := signup(t, store, "Hanako", 0)
+	referred := signup(t, store, "Taro", 0)
+	code, err := store.CreateReferralCode(context.Background(), sqlc.CreateReferralCodeParams{
+		ReferralCode:      util.RandomString(12),
+		ReferrerAccountID: referrer.Account.ID,
+		CreatedAt:         time.Now(),
+	})
+	require.NoError(t, err)
+
+	_, err = store.RedeemReferralCodeTx(context.Background(), sqlc.RedeemReferralCodeTxParams{
+		ReferralCode:      code.ReferralCode,
+		ReferredAccountID: referred.Account.ID,
+		RedeemedAt:        time.Date(2024, time.December, 15, 10, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	notifyAll(t, store, worker)
+	messages := sender.Messages()
+	require.Len(t, messages, 2)
+	require.Contains(t, messages[0].Body, "Your referral code was redeemed on 2024-12-15.")
+	require.Equal(t, referrer.Customer.Email, messages[1].To)
+	require.Equal(t, "Your interest goes up", messages[1].Subject)
+	require.Contains(t, messages[1].Body, "an extra 1% interest from 2025-01-01, for 9 months")
+
+	_, err = store.CreateInterestAccrual(context.Background(), sqlc.CreateInterestAccrualParams{
+		AccountID:   referrer.Account.ID,
+		AccrualDate: time.Date(2025, time.September, 30, 0, 0, 0, 0, time.UTC),
+		AnnualRate:  1,
+	})
+	require.NoError(t, err)
+	_, err = store.ExpireExtraInterestTx(context.Background(), sqlc.ExpireExtraInterestTxParams{
+		AccountID: referrer.Account.ID,
+		AsOf:      time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	notifyAll(t, store, worker)
+	messages = sender.Messages()
+	require.Len(t, messages, 3)
+	require.Equal(t, "Your extra interest has ended", messages[2].Subject)
+}
+
+// failingSender fails every send to the address, like a mailbox that is down, and every send at
+// all without one, like a mail server that is down
+type failingSender struct {
+	*MemorySender
+	to string
+}
+
+func (sender failingSender) Send(ctx context.Context, message Message) error {
+	if sender.to == "" || sender.to == message.To {
+		return errors.New("connection refused")
+	}
+	return sender.MemorySender.Send(ctx, message)
+}
+
+func TestNotifierRetriesFailedSends(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	referrer := signup(t, store, "Hanako", 5000)
+	referred := signup(t, store, "Taro", 0)
+
+	_, err := store.TransferTx(context.Background(), sqlc.TransferTxParams{
+		FromAccountID: referrer.Account.ID,
+		ToAccountID:   referred.Account.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	sender := NewMemorySender()
+	worker, err := NewWorker(store, failingSender{MemorySender: sender}, Config{})
+	require.NoError(t, err)
+	notifyAll(t, store, worker)
+
+	// the relay is done with the event, the message waits for the next attempt instead of being
+	// lost
+	events, err := store.ListOutboxEvents(context.Background(), sqlc.ListOutboxEventsParams{Status: outbox.StatusPending, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	deliveries, err := store.ListEmailDeliveries(context.Background(), sqlc.ListEmailDeliveriesParams{Status: outbox.StatusPending, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, KindTransferReceived, deliveries[0].Kind)
+	require.Equal(t, referred.Customer.ID, deliveries[0].CustomerID)
+	require.Equal(t, int32(1), deliveries[0].Attempts)
+	require.Contains(t, deliveries[0].LastError.String, "connection refused")
+
+	// the mail server is back once the backoff is over
+	worker.sender = sender
+	worker.now = func() time.Time { return time.Now().Add(worker.Backoff(1)) }
+	_, err = worker.SendBatch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, sender.Messages(), 1)
+}
+
+func TestNotifierSendsEachMessageOnce(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	referrer := signup(t, store, "Hanako", 5000)
+	code, err := store.CreateReferralCode(context.Background(), sqlc.CreateReferralCodeParams{
+		ReferralCode:      util.RandomString(12),
+		ReferrerAccountID: referrer.Account.ID,
+		CreatedAt:         time.Now(),
+	})
+	require.NoError(t, err)
+
+	referred, err := store.SignupWithReferralTx(context.Background(), sqlc.SignupWithReferralTxParams{
+		CreateAccountTxParams: sqlc.CreateAccountTxParams{
+			CreateAccountParams: sqlc.CreateAccountParams{
+				Owner:     "Taro",
+				Email:     util.RandomEmail(),
+				Currency:  util.JPY,
+				CreatedAt: time.Now(),
+			},
+			HashedPassword: "secret",
+		},
+		ReferralCode: code.ReferralCode,
+		BonusAmount:  1000,
+	})
+	require.NoError(t, err)
+
+	// the mailbox of the referred customer is down, the referrer is told all the same
+	sender := NewMemorySender()
+	worker, err := NewWorker(store, failingSender{MemorySender: sender, to: referred.Customer.Email}, Config{})
+	require.NoError(t, err)
+	notifyAll(t, store, worker)
+	require.Len(t, sender.Messages(), 1)
+	require.Equal(t, referrer.Customer.Email, sender.Messages()[0].To)
+
+	// the relay handing the event over again records nothing new
+	events, err := store.ListOutboxEvents(context.Background(), sqlc.ListOutboxEventsParams{Status: outbox.StatusDelivered, Limit: 10})
+	require.NoError(t, err)
+	for _, event := range events {
+		require.NoError(t, NewNotifier(store).Deliver(context.Background(), event))
+	}
+
+	// the retry sends the bonus message, and only that
+	worker.sender = sender
+	worker.now = func() time.Time { return time.Now().Add(worker.Backoff(1)) }
+	_, err = worker.SendBatch(context.Background())
+	require.NoError(t, err)
+	messages := sender.Messages()
+	require.Len(t, messages, 2)
+	require.Equal(t, referred.Customer.Email, messages[1].To)
+	require.Equal(t, "Your welcome bonus of ¥1,000 has arrived", messages[1].Subject)
+}
+
+func TestNotifierPasswordReset(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	sender := NewMemorySender()
+	worker, err := NewWorker(store, sender, Config{})
+	require.NoError(t, err)
+
+	customer := signup(t, store, "Hanako", 0)
+	_, err = store.RequestPasswordResetTx(context.Background(), sqlc.RequestPasswordResetTxParams{
+		CustomerID: customer.Customer.ID,
+		ExpiresAt:  time.Now().Add(time.Hour),
+		CreatedAt:  time.Now(),
+	})
+	require.NoError(t, err)
+
+	notifyAll(t, store, worker)
+	messages := sender.Messages()
+	require.Len(t, messages, 1)
+	require.Equal(t, customer.Customer.Email, messages[0].To)
+	require.Equal(t, "Choose your password", messages[0].Subject)
+
+	// the token in the email is the one that resets the password
+	token := strings.Fields(strings.SplitN(messages[0].Body, "\n\n", 4)[2])[0]
+	_, err = store.ResetPasswordTx(context.Background(), sqlc.ResetPasswordTxParams{
+		TokenHash:      util.HashResetToken(token),
+		HashedPassword: "new secret",
+		Now:            time.Now(),
+	})
+	require.NoError(t, err)
+
+	// an expired reset sends nothing
+	_, err = store.RequestPasswordResetTx(context.Background(), sqlc.RequestPasswordResetTxParams{
+		CustomerID: customer.Customer.ID,
+		ExpiresAt:  time.Now().Add(-time.Minute),
+		CreatedAt:  time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	notifyAll(t, store, worker)
+	require.Len(t, sender.Messages(), 1)
+
+	skipped, err := store.ListEmailDeliveries(context.Background(), sqlc.ListEmailDeliveriesParams{Status: StatusSkipped, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, skipped, 1)
+}
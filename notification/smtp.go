@@ -0,0 +1,86 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPSender sends messages through an SMTP server. It upgrades to TLS when the server offers
+// it and signs in when a username is set, so it works with a local stand-in like Mailpit as well
+// as with a real relay.
+type SMTPSender struct {
+	host     string
+	addr     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPSender(host string, port int, username string, password string, from string) *SMTPSender {
+	return &SMTPSender{
+		host:     host,
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (sender *SMTPSender) Send(ctx context.Context, message Message) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", sender.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// a server that stops answering must not hold up the relay forever
+	if deadline, ok := ctx.Deadline(); ok {
+		err = conn.SetDeadline(deadline)
+	} else {
+		err = conn.SetDeadline(time.Now().Add(time.Minute))
+	}
+	if err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, sender.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: sender.host}); err != nil {
+			return err
+		}
+	}
+	if sender.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", sender.username, sender.password, sender.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(sender.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return err
+	}
+
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := data.Write(message.format(sender.from, time.Now())); err != nil {
+		return err
+	}
+	if err := data.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
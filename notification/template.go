@@ -0,0 +1,132 @@
+package notification
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Kinds of message
+const (
+	KindReferralUsed     = "referral_used"
+	KindBonusGranted     = "bonus_granted"
+	KindTransferReceived = "transfer_received"
+	KindInterestChanged  = "interest_changed"
+	KindPasswordReset    = "password_reset"
+)
+
+var ErrUnknownKind = errors.New("unknown kind of message")
+
+// ReferralUsedData fills the message to a referrer whose code was used. It does not say who
+// used the code, that is none of the referrer's business.
+type ReferralUsedData struct {
+	Name string
+	// the referred customer signed up with the code, rather than redeeming it later
+	Signup bool
+	Date   string
+}
+
+// BonusGrantedData fills the message to a new customer who got a welcome bonus
+type BonusGrantedData struct {
+	Name      string
+	AccountID int64
+	// formatted in the currency of the account, e.g. ¥1,000
+	Amount string
+}
+
+// TransferReceivedData fills the message to the holder of an account money was sent to
+type TransferReceivedData struct {
+	Name       string
+	AccountID  int64
+	TransferID int64
+	// formatted in the currency of the account
+	Amount string
+	Date   string
+}
+
+// InterestChangedData fills the message to the holder of an account whose extra interest changed
+type InterestChangedData struct {
+	Name      string
+	AccountID int64
+	// extra interest in percent, zero once it ended
+	ExtraInterest float64
+	StartDate     string
+	Months        int32
+}
+
+// PasswordResetData fills the message with the token a customer chooses a new password with
+type PasswordResetData struct {
+	Name  string
+	Token string
+	// Tokyo time, with the zone
+	ExpiresAt string
+}
+
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+func newMessageTemplate(kind string, subject string, body string) messageTemplate {
+	return messageTemplate{
+		subject: template.Must(template.New(kind + ".subject").Parse(subject)),
+		body:    template.Must(template.New(kind + ".body").Parse(strings.TrimSpace(body) + "\n")),
+	}
+}
+
+var templates = map[string]messageTemplate{
+	KindReferralUsed: newMessageTemplate(KindReferralUsed, "Your referral code was used", `
+Hello {{.Name}},
+
+{{if .Signup}}Someone signed up with your referral code{{else}}Your referral code was redeemed{{end}} on {{.Date}}.
+Your extra interest is recalculated for the coming month, you will hear from us when it changes.
+
+Thank you for recommending us.
+`),
+	KindBonusGranted: newMessageTemplate(KindBonusGranted, "Your welcome bonus of {{.Amount}} has arrived", `
+Hello {{.Name}},
+
+Welcome aboard! As you signed up with a referral code, we credited a bonus of {{.Amount}} to your account {{.AccountID}}.
+`),
+	KindTransferReceived: newMessageTemplate(KindTransferReceived, "You received {{.Amount}}", `
+Hello {{.Name}},
+
+{{.Amount}} arrived in your account {{.AccountID}} on {{.Date}} (transfer {{.TransferID}}).
+`),
+	KindInterestChanged: newMessageTemplate(KindInterestChanged, "{{if .ExtraInterest}}Your interest goes up{{else}}Your extra interest has ended{{end}}", `
+Hello {{.Name}},
+
+{{if .ExtraInterest}}Your account {{.AccountID}} earns an extra {{printf "%g" .ExtraInterest}}% interest from {{.StartDate}}, for {{.Months}} months.
+Refer more friends to keep it going.{{else}}The extra interest on your account {{.AccountID}} has ended, it earns its base interest again.
+Refer a friend to earn extra interest once more.{{end}}
+`),
+	KindPasswordReset: newMessageTemplate(KindPasswordReset, "Choose your password", `
+Hello {{.Name}},
+
+Use this token to choose a new password, until {{.ExpiresAt}}:
+
+{{.Token}}
+
+If you did not ask for it, you can ignore this message; your password stays as it is.
+`),
+}
+
+// Render fills the template of the kind of message with data, which is the matching *Data
+// struct. The message goes to the given address.
+func Render(kind string, to string, data any) (Message, error) {
+	tmpl, ok := templates[kind]
+	if !ok {
+		return Message{}, fmt.Errorf("%w %q", ErrUnknownKind, kind)
+	}
+
+	var subject, body strings.Builder
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return Message{}, err
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{To: to, Subject: subject.String(), Body: body.String()}, nil
+}
@@ -0,0 +1,302 @@
+package notification
+
+import (
+	"4d63.com/tz"
+	"bank-api/db/sqlc"
+	"bank-api/outbox"
+	"bank-api/util"
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+)
+
+// StatusSkipped is the status of a delivery there was nothing to send for anymore, like a
+// password reset that was used or expired before its email went out
+const StatusSkipped = "skipped"
+
+const (
+	DefaultBatchSize    = 20
+	DefaultPollInterval = time.Second
+	DefaultMaxAttempts  = 10
+	DefaultBaseBackoff  = 30 * time.Second
+	DefaultMaxBackoff   = 6 * time.Hour
+	// DefaultTimeout is how long a message gets to be sent
+	DefaultTimeout = 30 * time.Second
+	// DefaultLease is how long claimed deliveries are kept from other workers, long enough for a
+	// whole batch of sends that time out
+	DefaultLease = 15 * time.Minute
+)
+
+// Config tunes a worker, zero fields take their default
+type Config struct {
+	BatchSize    int32
+	PollInterval time.Duration
+	MaxAttempts  int32
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+	Lease        time.Duration
+}
+
+func (config Config) withDefaults() Config {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = DefaultBaseBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Lease <= 0 {
+		config.Lease = DefaultLease
+	}
+	return config
+}
+
+// Worker renders the recorded deliveries and hands them to a Sender, each on its own with its
+// own retries. Like outbox relays, several workers may run against the same database.
+type Worker struct {
+	store  sqlc.Store
+	sender Sender
+	config Config
+	// dates in messages are Tokyo calendar dates
+	loc *time.Location
+	// now is swapped for a fake clock in tests
+	now func() time.Time
+}
+
+func NewWorker(store sqlc.Store, sender Sender, config Config) (*Worker, error) {
+	loc, err := tz.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Worker{
+		store:  store,
+		sender: sender,
+		config: config.withDefaults(),
+		loc:    loc,
+		now:    time.Now,
+	}, nil
+}
+
+// Run sends deliveries until ctx is done, polling like outbox.Relay.Run
+func (worker *Worker) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		claimed, err := worker.SendBatch(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("email worker: %v", err)
+		}
+
+		wait := worker.config.PollInterval
+		if err == nil && claimed == int(worker.config.BatchSize) {
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+// SendBatch claims the due deliveries and sends them one by one, oldest first. It returns how
+// many deliveries it claimed; a send that fails is retried later, not an error.
+func (worker *Worker) SendBatch(ctx context.Context) (int, error) {
+	now := worker.now()
+	deliveries, err := worker.store.ClaimEmailDeliveries(ctx, sqlc.ClaimEmailDeliveriesParams{
+		LeasedUntil: now.Add(worker.config.Lease),
+		DueAt:       now,
+		BatchSize:   worker.config.BatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	slices.SortFunc(deliveries, func(a, b sqlc.EmailDelivery) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	for _, delivery := range deliveries {
+		if err := worker.attempt(ctx, delivery); err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// errNothingToSend is returned by message for a delivery that no longer needs a message
+var errNothingToSend = errors.New("nothing to send")
+
+// attempt sends a delivery once and records the outcome
+func (worker *Worker) attempt(ctx context.Context, delivery sqlc.EmailDelivery) error {
+	sendCtx, cancel := context.WithTimeout(ctx, worker.config.Timeout)
+	defer cancel()
+
+	message, sendErr := worker.message(sendCtx, delivery)
+	if sendErr == nil {
+		sendErr = worker.sender.Send(sendCtx, message)
+	}
+	if sendErr == nil {
+		return worker.store.MarkEmailDeliverySent(ctx, sqlc.MarkEmailDeliverySentParams{
+			ID:     delivery.ID,
+			SentAt: sql.NullTime{Time: worker.now(), Valid: true},
+		})
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	arg := sqlc.MarkEmailDeliveryFailedParams{
+		ID:            delivery.ID,
+		Status:        outbox.StatusPending,
+		NextAttemptAt: worker.now().Add(worker.Backoff(delivery.Attempts + 1)),
+		LastError:     sql.NullString{String: sendErr.Error(), Valid: true},
+	}
+	switch {
+	case errors.Is(sendErr, errNothingToSend):
+		arg.Status = StatusSkipped
+	case delivery.Attempts+1 >= worker.config.MaxAttempts:
+		arg.Status = outbox.StatusDead
+		log.Printf("email worker: %s delivery %d is dead after %d attempts: %v",
+			delivery.Kind, delivery.ID, delivery.Attempts+1, sendErr)
+	default:
+		log.Printf("email worker: %s delivery %d: %v", delivery.Kind, delivery.ID, sendErr)
+	}
+	return worker.store.MarkEmailDeliveryFailed(ctx, arg)
+}
+
+// message renders the message of a delivery from the payload of its event. It is rendered again
+// on every attempt, so a password reset gets a fresh token each time and only the last email
+// sent works.
+func (worker *Worker) message(ctx context.Context, delivery sqlc.EmailDelivery) (Message, error) {
+	customer, err := worker.store.GetCustomer(ctx, delivery.CustomerID)
+	if err != nil {
+		return Message{}, err
+	}
+
+	var account sqlc.Account
+	if delivery.AccountID.Valid {
+		account, err = worker.store.GetAccount(ctx, delivery.AccountID.Int64)
+		if err != nil {
+			return Message{}, err
+		}
+	}
+
+	var data any
+	switch delivery.Kind {
+	case KindReferralUsed:
+		var payload sqlc.ReferralRedeemedEvent
+		err = json.Unmarshal(delivery.Payload, &payload)
+		data = ReferralUsedData{
+			Name:   customer.Name,
+			Signup: payload.Signup,
+			Date:   worker.date(payload.RedeemedAt),
+		}
+	case KindBonusGranted:
+		var payload sqlc.ReferralRedeemedEvent
+		err = json.Unmarshal(delivery.Payload, &payload)
+		data = BonusGrantedData{
+			Name:      customer.Name,
+			AccountID: account.ID,
+			Amount:    formatAmount(account.Currency, payload.BonusAmount),
+		}
+	case KindTransferReceived:
+		var payload sqlc.TransferCompletedEvent
+		err = json.Unmarshal(delivery.Payload, &payload)
+		data = TransferReceivedData{
+			Name:       customer.Name,
+			AccountID:  account.ID,
+			TransferID: payload.TransferID,
+			Amount:     formatAmount(payload.ToCurrency, payload.ConvertedAmount),
+			Date:       worker.date(payload.CreatedAt),
+		}
+	case KindInterestChanged:
+		var payload sqlc.ExtraInterestUpdatedEvent
+		err = json.Unmarshal(delivery.Payload, &payload)
+		data = InterestChangedData{
+			Name:          customer.Name,
+			AccountID:     account.ID,
+			ExtraInterest: payload.ExtraInterest,
+			StartDate:     payload.StartDate,
+			Months:        payload.Duration,
+		}
+	case KindPasswordReset:
+		data, err = worker.passwordReset(ctx, customer, delivery)
+	}
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Render(delivery.Kind, customer.Email, data)
+}
+
+// passwordReset records a fresh token for the reset and returns the data of the message that
+// carries it
+func (worker *Worker) passwordReset(ctx context.Context, customer sqlc.Customer, delivery sqlc.EmailDelivery) (PasswordResetData, error) {
+	var payload sqlc.PasswordResetRequestedEvent
+	if err := json.Unmarshal(delivery.Payload, &payload); err != nil {
+		return PasswordResetData{}, err
+	}
+
+	token, hash, err := util.NewResetToken()
+	if err != nil {
+		return PasswordResetData{}, err
+	}
+	_, err = worker.store.SetPasswordResetToken(ctx, sqlc.SetPasswordResetTokenParams{
+		TokenHash: sql.NullString{String: hash, Valid: true},
+		ID:        payload.PasswordResetID,
+		Now:       worker.now(),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return PasswordResetData{}, fmt.Errorf("%w, password reset %d was used or expired", errNothingToSend, payload.PasswordResetID)
+		}
+		return PasswordResetData{}, err
+	}
+
+	return PasswordResetData{
+		Name:      customer.Name,
+		Token:     token,
+		ExpiresAt: payload.ExpiresAt.In(worker.loc).Format("2006-01-02 15:04 MST"),
+	}, nil
+}
+
+// Backoff is how long the worker waits after the given number of failed attempts
+func (worker *Worker) Backoff(attempts int32) time.Duration {
+	return outbox.ExponentialBackoff(worker.config.BaseBackoff, worker.config.MaxBackoff, attempts)
+}
+
+func (worker *Worker) date(t time.Time) string {
+	return t.In(worker.loc).Format(time.DateOnly)
+}
+
+// formatAmount renders an amount in the minor unit of the currency for display
+func formatAmount(code string, amount int64) string {
+	currency, ok := util.LookupCurrency(code)
+	if !ok {
+		return fmt.Sprintf("%d %s", amount, code)
+	}
+	return currency.Format(amount)
+}
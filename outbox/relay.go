@@ -0,0 +1,196 @@
+// Package outbox delivers the domain events the store writes to outbox_events. An event is
+// written in the same transaction as the change it is about, so it exists exactly when the
+// change committed; the relay then hands it to a sink until the sink accepts it.
+//
+// Delivery is at least once: an event is marked delivered only after the sink returned, so a
+// relay that dies in between delivers it again. Sinks tell duplicates apart by the event ID.
+// A failed delivery is retried with exponential backoff; once an event ran out of attempts it
+// is dead and stays put until an admin requeues it.
+package outbox
+
+import (
+	"bank-api/db/sqlc"
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"slices"
+	"time"
+)
+
+// Statuses of an event
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+)
+
+const (
+	DefaultBatchSize    = 100
+	DefaultPollInterval = time.Second
+	DefaultMaxAttempts  = 10
+	DefaultBaseBackoff  = 5 * time.Second
+	DefaultMaxBackoff   = time.Hour
+	// DefaultLease is how long a claimed event is kept from other relays while it is delivered
+	DefaultLease = time.Minute
+)
+
+// Sink receives the events. It must be safe to call again with an event it already took.
+type Sink interface {
+	Deliver(ctx context.Context, event sqlc.OutboxEvent) error
+}
+
+// SinkFunc makes a function a Sink
+type SinkFunc func(ctx context.Context, event sqlc.OutboxEvent) error
+
+func (f SinkFunc) Deliver(ctx context.Context, event sqlc.OutboxEvent) error {
+	return f(ctx, event)
+}
+
+// Config tunes a relay, zero fields take their default
+type Config struct {
+	BatchSize    int32
+	PollInterval time.Duration
+	MaxAttempts  int32
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Lease        time.Duration
+}
+
+func (config Config) withDefaults() Config {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = DefaultBaseBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.Lease <= 0 {
+		config.Lease = DefaultLease
+	}
+	return config
+}
+
+// Relay moves events from the outbox to a sink. Several relays may run against the same
+// database, each event is claimed by one of them at a time.
+type Relay struct {
+	store  sqlc.Store
+	sink   Sink
+	config Config
+	// now is swapped for a fake clock in tests
+	now func() time.Time
+}
+
+func NewRelay(store sqlc.Store, sink Sink, config Config) *Relay {
+	return &Relay{
+		store:  store,
+		sink:   sink,
+		config: config.withDefaults(),
+		now:    time.Now,
+	}
+}
+
+// Run delivers events until ctx is done. Whenever a batch came back full it goes on right
+// away, otherwise it waits for the poll interval.
+func (relay *Relay) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		claimed, err := relay.RelayBatch(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("outbox relay: %v", err)
+		}
+
+		wait := relay.config.PollInterval
+		if err == nil && claimed == int(relay.config.BatchSize) {
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+// RelayBatch claims the due events and delivers them one by one, oldest first. It returns how
+// many events it claimed; an event the sink fails on is rescheduled, not an error.
+func (relay *Relay) RelayBatch(ctx context.Context) (int, error) {
+	now := relay.now()
+	events, err := relay.store.ClaimOutboxEvents(ctx, sqlc.ClaimOutboxEventsParams{
+		LeasedUntil: now.Add(relay.config.Lease),
+		DueAt:       now,
+		BatchSize:   relay.config.BatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	slices.SortFunc(events, func(a, b sqlc.OutboxEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	for _, event := range events {
+		if err := relay.deliver(ctx, event); err != nil {
+			// the events left keep their lease and are picked up again once it ran out
+			return len(events), err
+		}
+	}
+	return len(events), nil
+}
+
+func (relay *Relay) deliver(ctx context.Context, event sqlc.OutboxEvent) error {
+	deliverErr := relay.sink.Deliver(ctx, event)
+	if deliverErr == nil {
+		return relay.store.MarkOutboxEventDelivered(ctx, sqlc.MarkOutboxEventDeliveredParams{
+			ID:          event.ID,
+			DeliveredAt: sql.NullTime{Time: relay.now(), Valid: true},
+		})
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	arg := sqlc.MarkOutboxEventFailedParams{
+		ID:            event.ID,
+		Status:        StatusPending,
+		NextAttemptAt: relay.now().Add(relay.Backoff(event.Attempts + 1)),
+		LastError:     sql.NullString{String: deliverErr.Error(), Valid: true},
+	}
+	if event.Attempts+1 >= relay.config.MaxAttempts {
+		arg.Status = StatusDead
+		log.Printf("outbox relay: event %d (%s) is dead after %d attempts: %v",
+			event.ID, event.EventType, event.Attempts+1, deliverErr)
+	}
+	return relay.store.MarkOutboxEventFailed(ctx, arg)
+}
+
+// Backoff is how long the relay waits after the given number of failed attempts
+func (relay *Relay) Backoff(attempts int32) time.Duration {
+	return ExponentialBackoff(relay.config.BaseBackoff, relay.config.MaxBackoff, attempts)
+}
+
+// ExponentialBackoff is how long to wait after the given number of failed attempts: the base
+// backoff, doubled with every further attempt, up to the max backoff.
+func ExponentialBackoff(base time.Duration, max time.Duration, attempts int32) time.Duration {
+	backoff := base
+	for i := int32(1); i < attempts; i++ {
+		backoff *= 2
+		if backoff >= max {
+			return max
+		}
+	}
+	return min(backoff, max)
+}
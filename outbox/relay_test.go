@@ -0,0 +1,226 @@
+package outbox
+
+import (
+	"bank-api/db/sqlc"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSink remembers the IDs of the events it took and fails while failing is set
+type recordingSink struct {
+	mu        sync.Mutex
+	delivered []int64
+	failing   bool
+}
+
+func (sink *recordingSink) Deliver(ctx context.Context, event sqlc.OutboxEvent) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if sink.failing {
+		return errors.New("sink is down")
+	}
+	sink.delivered = append(sink.delivered, event.ID)
+	return nil
+}
+
+// newTestRelay returns a relay whose clock stands still at the time it was created, events
+// created before are due
+func newTestRelay(store sqlc.Store, sink Sink, config Config) (*Relay, *time.Time) {
+	relay := NewRelay(store, sink, config)
+	now := time.Now()
+	relay.now = func() time.Time { return now }
+	return relay, &now
+}
+
+func createEvent(t *testing.T, store sqlc.Store, aggregateID int64) sqlc.OutboxEvent {
+	event, err := store.CreateOutboxEvent(context.Background(), sqlc.CreateOutboxEventParams{
+		EventType:     sqlc.EventAccountCreated,
+		AggregateType: sqlc.AggregateAccount,
+		AggregateID:   aggregateID,
+		Payload:       []byte(`{}`),
+	})
+	require.NoError(t, err)
+	return event
+}
+
+func listEvents(t *testing.T, store sqlc.Store, status string) []sqlc.OutboxEvent {
+	events, err := store.ListOutboxEvents(context.Background(), sqlc.ListOutboxEventsParams{
+		Status: status,
+		Limit:  100,
+	})
+	require.NoError(t, err)
+	return events
+}
+
+func TestRelayBatch(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	event1 := createEvent(t, store, 1)
+	event2 := createEvent(t, store, 2)
+	event3 := createEvent(t, store, 3)
+
+	sink := &recordingSink{}
+	relay, _ := newTestRelay(store, sink, Config{BatchSize: 2})
+
+	// oldest first, a batch at a time
+	claimed, err := relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, claimed)
+	require.Equal(t, []int64{event1.ID, event2.ID}, sink.delivered)
+
+	claimed, err = relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, claimed)
+	require.Equal(t, []int64{event1.ID, event2.ID, event3.ID}, sink.delivered)
+
+	delivered := listEvents(t, store, StatusDelivered)
+	require.Len(t, delivered, 3)
+	for _, event := range delivered {
+		require.Equal(t, int32(1), event.Attempts)
+		require.True(t, event.DeliveredAt.Valid)
+	}
+
+	// delivered events are not delivered again
+	claimed, err = relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, claimed)
+}
+
+func TestRelayRetryAndDeadLetter(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	event := createEvent(t, store, 1)
+
+	sink := &recordingSink{failing: true}
+	relay, now := newTestRelay(store, sink, Config{MaxAttempts: 3, BaseBackoff: time.Second, MaxBackoff: time.Minute})
+
+	_, err := relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+
+	pending := listEvents(t, store, StatusPending)
+	require.Len(t, pending, 1)
+	require.Equal(t, int32(1), pending[0].Attempts)
+	require.Equal(t, "sink is down", pending[0].LastError.String)
+	require.Equal(t, now.Add(time.Second), pending[0].NextAttemptAt)
+
+	// nothing is due before the backoff is over
+	claimed, err := relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, claimed)
+
+	*now = now.Add(time.Second)
+	_, err = relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+
+	pending = listEvents(t, store, StatusPending)
+	require.Len(t, pending, 1)
+	require.Equal(t, int32(2), pending[0].Attempts)
+	require.Equal(t, now.Add(2*time.Second), pending[0].NextAttemptAt)
+
+	// the last attempt fails too, the event is dead and left alone
+	*now = now.Add(2 * time.Second)
+	_, err = relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, listEvents(t, store, StatusPending))
+
+	dead := listEvents(t, store, StatusDead)
+	require.Len(t, dead, 1)
+	require.Equal(t, int32(3), dead[0].Attempts)
+
+	*now = now.Add(time.Hour)
+	claimed, err = relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, claimed)
+
+	// once the sink is back, a requeued event goes through
+	sink.failing = false
+	_, err = store.RequeueOutboxEvent(context.Background(), sqlc.RequeueOutboxEventParams{ID: event.ID, NextAttemptAt: *now})
+	require.NoError(t, err)
+
+	claimed, err = relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, claimed)
+	require.Equal(t, []int64{event.ID}, sink.delivered)
+	require.Len(t, listEvents(t, store, StatusDelivered), 1)
+}
+
+func TestRelayLease(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	createEvent(t, store, 1)
+
+	// a relay that died after claiming the event keeps it from the others until its lease ran out
+	now := time.Now()
+	_, err := store.ClaimOutboxEvents(context.Background(), sqlc.ClaimOutboxEventsParams{
+		LeasedUntil: now.Add(DefaultLease),
+		DueAt:       now,
+		BatchSize:   1,
+	})
+	require.NoError(t, err)
+
+	sink := &recordingSink{}
+	relay, clock := newTestRelay(store, sink, Config{})
+	*clock = now
+
+	claimed, err := relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, claimed)
+
+	*clock = now.Add(DefaultLease)
+	claimed, err = relay.RelayBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, claimed)
+	require.Len(t, sink.delivered, 1)
+}
+
+func TestRelayRun(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	sink := &recordingSink{}
+	relay := NewRelay(store, sink, Config{PollInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	event := createEvent(t, store, 1)
+	require.Eventually(t, func() bool {
+		return len(listEvents(t, store, StatusDelivered)) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+	require.Equal(t, []int64{event.ID}, sink.delivered)
+}
+
+func TestBackoff(t *testing.T) {
+	relay := NewRelay(nil, nil, Config{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	require.Equal(t, time.Second, relay.Backoff(1))
+	require.Equal(t, 2*time.Second, relay.Backoff(2))
+	require.Equal(t, 8*time.Second, relay.Backoff(4))
+	require.Equal(t, 10*time.Second, relay.Backoff(5))
+	require.Equal(t, 10*time.Second, relay.Backoff(50))
+}
+
+func TestFanOut(t *testing.T) {
+	sink1 := &recordingSink{}
+	sink2 := &recordingSink{failing: true}
+	event := sqlc.OutboxEvent{ID: 1, EventType: sqlc.EventTransferCompleted}
+
+	// the event is delivered again to every sink until all of them took it
+	require.Error(t, FanOut(sink1, sink2).Deliver(context.Background(), event))
+	sink2.failing = false
+	require.NoError(t, FanOut(sink1, sink2).Deliver(context.Background(), event))
+	require.Equal(t, []int64{1, 1}, sink1.delivered)
+	require.Equal(t, []int64{1}, sink2.delivered)
+
+	transfers := &recordingSink{}
+	sink := ForTypes(transfers, sqlc.EventTransferCompleted)
+	require.NoError(t, sink.Deliver(context.Background(), sqlc.OutboxEvent{ID: 2, EventType: sqlc.EventAccountCreated}))
+	require.NoError(t, sink.Deliver(context.Background(), event))
+	require.Equal(t, []int64{1}, transfers.delivered)
+}
@@ -0,0 +1,51 @@
+package outbox
+
+import (
+	"bank-api/db/sqlc"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// LogSink writes a line per event to the standard logger, for a setup without any consumer
+type LogSink struct{}
+
+func (LogSink) Deliver(ctx context.Context, event sqlc.OutboxEvent) error {
+	log.Printf("outbox: event %d %s %s [%d] %s", event.ID, event.EventType, event.AggregateType, event.AggregateID, event.Payload)
+	return nil
+}
+
+// FanOut delivers every event to all of the sinks. An event counts as delivered once every
+// sink took it; when one fails, all of them get the event again on the retry, so a sink must
+// take an event it has seen before without acting on it twice. The webhook dispatcher and the
+// notifier only record deliveries keyed by the event and send them from workers of their own.
+func FanOut(sinks ...Sink) Sink {
+	return SinkFunc(func(ctx context.Context, event sqlc.OutboxEvent) error {
+		var errs []error
+		for _, sink := range sinks {
+			if err := sink.Deliver(ctx, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// ForTypes delivers only the events of the given types to the sink and drops the others
+func ForTypes(sink Sink, eventTypes ...string) Sink {
+	wanted := make(map[string]bool, len(eventTypes))
+	for _, eventType := range eventTypes {
+		wanted[eventType] = true
+	}
+
+	return SinkFunc(func(ctx context.Context, event sqlc.OutboxEvent) error {
+		if !wanted[event.EventType] {
+			return nil
+		}
+		if err := sink.Deliver(ctx, event); err != nil {
+			return fmt.Errorf("%s: %w", event.EventType, err)
+		}
+		return nil
+	})
+}
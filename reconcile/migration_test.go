@@ -0,0 +1,122 @@
+package reconcile
+
+import (
+	"bank-api/config"
+	"bank-api/db/sqlc"
+	"bank-api/sql/schema"
+	"bank-api/util"
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"io/fs"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openSchemaDB connects to the test database with a schema of its own as the search path, so
+// migrations can be run from the start without touching the tables the other tests use. The
+// test is skipped when the database cannot be reached.
+func openSchemaDB(t *testing.T) *sql.DB {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	admin, err := sql.Open("postgres", cfg.TestDBSource)
+	require.NoError(t, err)
+	t.Cleanup(func() { admin.Close() })
+	if err := admin.Ping(); err != nil {
+		t.Skipf("test database not reachable: %v", err)
+	}
+
+	name := fmt.Sprintf("reconcile_test_%d", time.Now().UnixNano())
+	_, err = admin.Exec(`CREATE SCHEMA ` + name)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := admin.Exec(`DROP SCHEMA ` + name + ` CASCADE`)
+		require.NoError(t, err)
+	})
+
+	source, err := url.Parse(cfg.TestDBSource)
+	require.NoError(t, err)
+	query := source.Query()
+	query.Set("search_path", name)
+	source.RawQuery = query.Encode()
+
+	db, err := sql.Open("postgres", source.String())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// migrate runs the up migrations from one version to another the way goose does, each file in a
+// transaction of its own
+func migrate(t *testing.T, db *sql.DB, from int64, to int64) {
+	files, err := fs.Glob(schema.Migrations, "*.sql")
+	require.NoError(t, err)
+	sort.Strings(files)
+
+	for _, file := range files {
+		var version int64
+		_, err := fmt.Sscanf(file, "%d_", &version)
+		require.NoError(t, err)
+		if version < from || version > to {
+			continue
+		}
+
+		content, err := fs.ReadFile(schema.Migrations, file)
+		require.NoError(t, err)
+		_, up, found := strings.Cut(string(content), "-- +goose Up")
+		require.True(t, found, file)
+		up, _, _ = strings.Cut(up, "-- +goose Down")
+
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		_, err = tx.Exec(up)
+		require.NoError(t, err, file)
+		require.NoError(t, tx.Commit())
+	}
+}
+
+func TestRunAfterJournalMigration(t *testing.T) {
+	db := openSchemaDB(t)
+	latest, err := schema.LatestVersion()
+	require.NoError(t, err)
+
+	// the ledger as it was before journals: initial deposits went straight into the balance and
+	// only transfers wrote entries
+	migrate(t, db, 1, 9)
+	_, err = db.Exec(`
+INSERT INTO accounts (owner, email, balance, currency) VALUES
+    ('alice', 'alice@example.com', 900, 'YEN'),
+    ('bob', 'bob@example.com', 600, 'YEN'),
+    ('carol', 'carol@example.com', 0, 'USD');
+INSERT INTO transfers (from_account_id, to_account_id, amount, created_at) VALUES (1, 2, 100, '2024-01-02');
+INSERT INTO entries (account_id, amount, transfer_id, created_at) VALUES
+    (1, -100, 1, '2024-01-02'),
+    (2, 100, 1, '2024-01-02');`)
+	require.NoError(t, err)
+
+	migrate(t, db, 10, latest)
+
+	var buf bytes.Buffer
+	summary, err := Run(context.Background(), sqlc.NewStore(db), 2, NewJSONWriter(&buf))
+	require.NoError(t, err)
+	require.True(t, summary.OK(), buf.String())
+	// the three accounts and the adjustment account the opening balances were booked against
+	require.Equal(t, int64(4), summary.Accounts)
+	require.Equal(t, int64(1), summary.Transfers)
+	// the transfer and the opening balances of alice and bob; carol had nothing to open with
+	require.Equal(t, int64(3), summary.Journals)
+
+	adjustment, err := sqlc.New(db).GetSystemAccountForUpdate(context.Background(), sqlc.GetSystemAccountForUpdateParams{
+		AccountType: util.AdjustmentAccount,
+		Currency:    util.JPY,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-1500), adjustment.Balance)
+}
@@ -0,0 +1,213 @@
+// Package reconcile checks the ledger against itself: every balance must equal the sum of its
+// entries, every transfer must be booked by its debit and credit entries and every journal must
+// sum to zero. Tables are read in batches, and mismatches are written out as they are found, so
+// it runs in constant memory however large the ledger grows.
+package reconcile
+
+import (
+	"bank-api/db/sqlc"
+	"context"
+	"fmt"
+)
+
+// Kinds of mismatch
+const (
+	// the account balance differs from the sum of its entries
+	BalanceDrift = "balance_drift"
+	// the transfer is not booked by exactly one debit and one credit entry, plus the two fx legs
+	// when it converts between currencies
+	OrphanedTransfer = "orphaned_transfer"
+	// the journal legs don't sum to zero, or there are fewer than two
+	UnbalancedJournal = "unbalanced_journal"
+)
+
+// DefaultBatchSize is how many rows are read at a time unless told otherwise
+const DefaultBatchSize = 1000
+
+// Mismatch is one inconsistency in the ledger. ID is the account, transfer or journal it was
+// found on, depending on the kind.
+type Mismatch struct {
+	Kind     string `json:"kind"`
+	ID       int64  `json:"id"`
+	Expected int64  `json:"expected"`
+	Actual   int64  `json:"actual"`
+	Detail   string `json:"detail"`
+}
+
+// Summary counts what a run checked and found
+type Summary struct {
+	Accounts   int64  `json:"accounts"`
+	Transfers  int64  `json:"transfers"`
+	Journals   int64  `json:"journals"`
+	Mismatches int64  `json:"mismatches"`
+	Error      string `json:"error,omitempty"`
+}
+
+// OK tells whether the run went through and found the ledger consistent
+func (summary Summary) OK() bool {
+	return summary.Mismatches == 0 && summary.Error == ""
+}
+
+// Writer receives mismatches as they are found, and the summary once the run is over
+type Writer interface {
+	Write(mismatch Mismatch) error
+	Close(summary Summary) error
+}
+
+// Run checks the whole ledger, batchSize rows at a time, and writes every mismatch to out. The
+// summary is written even when the run fails, with the error in it.
+func Run(ctx context.Context, store sqlc.Querier, batchSize int32, out Writer) (Summary, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	reconciler := &reconciler{store: store, batchSize: batchSize, out: out}
+	err := reconciler.run(ctx)
+	if err != nil {
+		reconciler.summary.Error = err.Error()
+	}
+
+	if closeErr := out.Close(reconciler.summary); err == nil {
+		err = closeErr
+	}
+	return reconciler.summary, err
+}
+
+type reconciler struct {
+	store     sqlc.Querier
+	batchSize int32
+	out       Writer
+	summary   Summary
+}
+
+func (reconciler *reconciler) run(ctx context.Context) error {
+	if err := reconciler.checkAccounts(ctx); err != nil {
+		return fmt.Errorf("accounts: %w", err)
+	}
+	if err := reconciler.checkTransfers(ctx); err != nil {
+		return fmt.Errorf("transfers: %w", err)
+	}
+	if err := reconciler.checkJournals(ctx); err != nil {
+		return fmt.Errorf("journals: %w", err)
+	}
+	return nil
+}
+
+func (reconciler *reconciler) report(mismatch Mismatch) error {
+	reconciler.summary.Mismatches++
+	return reconciler.out.Write(mismatch)
+}
+
+func (reconciler *reconciler) checkAccounts(ctx context.Context) error {
+	var afterID int64
+	for {
+		rows, err := reconciler.store.ListAccountEntrySums(ctx, sqlc.ListAccountEntrySumsParams{
+			AfterID:   afterID,
+			BatchSize: reconciler.batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			afterID = row.ID
+			reconciler.summary.Accounts++
+			if row.Balance == row.EntriesSum {
+				continue
+			}
+
+			err := reconciler.report(Mismatch{
+				Kind:     BalanceDrift,
+				ID:       row.ID,
+				Expected: row.EntriesSum,
+				Actual:   row.Balance,
+				Detail:   fmt.Sprintf("balance is off by %d", row.Balance-row.EntriesSum),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < int(reconciler.batchSize) {
+			return nil
+		}
+	}
+}
+
+func (reconciler *reconciler) checkTransfers(ctx context.Context) error {
+	var afterID int64
+	for {
+		rows, err := reconciler.store.ListTransferEntryCounts(ctx, sqlc.ListTransferEntryCountsParams{
+			AfterID:   afterID,
+			BatchSize: reconciler.batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			afterID = row.ID
+			reconciler.summary.Transfers++
+			entryCount := int64(2)
+			credited := row.Amount
+			if row.ConvertedAmount.Valid {
+				entryCount, credited = 4, row.ConvertedAmount.Int64
+			}
+			if row.EntryCount == entryCount && row.MatchingEntryCount == 2 {
+				continue
+			}
+
+			err := reconciler.report(Mismatch{
+				Kind:     OrphanedTransfer,
+				ID:       row.ID,
+				Expected: 2,
+				Actual:   row.MatchingEntryCount,
+				Detail: fmt.Sprintf("%d of %d entries book %d from account [%d] and %d to account [%d]",
+					row.MatchingEntryCount, row.EntryCount, row.Amount, row.FromAccountID, credited, row.ToAccountID),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < int(reconciler.batchSize) {
+			return nil
+		}
+	}
+}
+
+func (reconciler *reconciler) checkJournals(ctx context.Context) error {
+	var afterID int64
+	for {
+		rows, err := reconciler.store.ListJournalEntrySums(ctx, sqlc.ListJournalEntrySumsParams{
+			AfterID:   afterID,
+			BatchSize: reconciler.batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			afterID = row.ID
+			reconciler.summary.Journals++
+			if row.EntryCount >= 2 && row.EntriesSum == 0 {
+				continue
+			}
+
+			err := reconciler.report(Mismatch{
+				Kind:     UnbalancedJournal,
+				ID:       row.ID,
+				Expected: 0,
+				Actual:   row.EntriesSum,
+				Detail:   fmt.Sprintf("%d legs sum to %d", row.EntryCount, row.EntriesSum),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < int(reconciler.batchSize) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package reconcile
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// createLedger opens accounts through adjustments and moves money between them, all of which
+// keeps the ledger consistent.
+func createLedger(t *testing.T, store sqlc.Store, n int) []sqlc.Account {
+	accounts := make([]sqlc.Account, n)
+	for i := range accounts {
+		account, err := store.CreateAccount(context.Background(), sqlc.CreateAccountParams{
+			Owner:     util.RandomOwner(),
+			Email:     util.RandomEmail(),
+			Currency:  util.JPY,
+			CreatedAt: time.Now(),
+		})
+		require.NoError(t, err)
+
+		_, err = store.AdjustBalanceTx(context.Background(), sqlc.AdjustBalanceTxParams{
+			AccountID: account.ID,
+			Amount:    1000,
+			Memo:      "opening balance",
+		})
+		require.NoError(t, err)
+		accounts[i] = account
+	}
+
+	for i := 1; i < n; i++ {
+		_, err := store.TransferTx(context.Background(), sqlc.TransferTxParams{
+			FromAccountID: accounts[i-1].ID,
+			ToAccountID:   accounts[i].ID,
+			Amount:        int64(10 * i),
+		})
+		require.NoError(t, err)
+	}
+	return accounts
+}
+
+func runJSON(t *testing.T, store sqlc.Store, batchSize int32) (Summary, []Mismatch) {
+	var buf bytes.Buffer
+	summary, err := Run(context.Background(), store, batchSize, NewJSONWriter(&buf))
+	require.NoError(t, err)
+
+	var report struct {
+		Mismatches []Mismatch `json:"mismatches"`
+		Summary    Summary    `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	require.Equal(t, summary, report.Summary)
+	return summary, report.Mismatches
+}
+
+func TestRunConsistentLedger(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	createLedger(t, store, 5)
+
+	// a batch size that doesn't divide the row counts, so the last batch is partial
+	summary, mismatches := runJSON(t, store, 2)
+	require.True(t, summary.OK())
+	require.Empty(t, mismatches)
+	// the five accounts and the adjustment account
+	require.Equal(t, int64(6), summary.Accounts)
+	require.Equal(t, int64(4), summary.Transfers)
+	require.Equal(t, int64(9), summary.Journals)
+}
+
+func TestRunFxTransfer(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	accounts := createLedger(t, store, 1)
+	ctx := context.Background()
+
+	eurAccount, err := store.CreateAccount(ctx, sqlc.CreateAccountParams{
+		Owner:     util.RandomOwner(),
+		Email:     util.RandomEmail(),
+		Currency:  util.EUR,
+		CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	quote, err := store.CreateFxQuote(ctx, sqlc.CreateFxQuoteParams{
+		ID:              uuid.New(),
+		AccountID:       accounts[0].ID,
+		FromCurrency:    util.JPY,
+		ToCurrency:      util.EUR,
+		Rate:            "0.006172839506",
+		Amount:          1000,
+		ConvertedAmount: 617,
+		ExpiresAt:       time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(ctx, sqlc.TransferTxParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID:   eurAccount.ID,
+		Amount:        1000,
+		FxQuoteID:     uuid.NullUUID{UUID: quote.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// the converted transfer is booked by four entries, through both fx accounts
+	summary, mismatches := runJSON(t, store, 100)
+	require.Empty(t, mismatches)
+	require.True(t, summary.OK())
+	require.Equal(t, int64(1), summary.Transfers)
+}
+
+func TestRunReportsMismatches(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	accounts := createLedger(t, store, 3)
+	ctx := context.Background()
+
+	// a balance changed behind the ledger's back
+	_, err := store.AddAccountBalance(ctx, sqlc.AddAccountBalanceParams{ID: accounts[0].ID, Amount: 7})
+	require.NoError(t, err)
+
+	// a transfer without entries
+	transfer, err := store.CreateTransfer(ctx, sqlc.CreateTransferParams{
+		FromAccountID: accounts[1].ID,
+		ToAccountID:   accounts[2].ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+
+	// a journal with a single leg, which also throws the balance of its account off
+	journal, err := store.CreateJournal(ctx, "")
+	require.NoError(t, err)
+	_, err = store.CreateEntry(ctx, sqlc.CreateEntryParams{
+		AccountID:     accounts[2].ID,
+		Amount:        3,
+		JournalID:     sql.NullInt64{Int64: journal.ID, Valid: true},
+		ReferenceType: sqlc.ReferenceAdjustment,
+	})
+	require.NoError(t, err)
+
+	summary, mismatches := runJSON(t, store, 1)
+	require.False(t, summary.OK())
+	require.Equal(t, int64(4), summary.Mismatches)
+	require.Equal(t, []Mismatch{
+		{Kind: BalanceDrift, ID: accounts[0].ID, Expected: 990, Actual: 997, Detail: "balance is off by 7"},
+		{Kind: BalanceDrift, ID: accounts[2].ID, Expected: 1023, Actual: 1020, Detail: "balance is off by -3"},
+		{Kind: OrphanedTransfer, ID: transfer.ID, Expected: 2, Actual: 0, Detail: fmt.Sprintf("0 of 0 entries book 5 from account [%d] and 5 to account [%d]", accounts[1].ID, accounts[2].ID)},
+		{Kind: UnbalancedJournal, ID: journal.ID, Expected: 0, Actual: 3, Detail: "1 legs sum to 3"},
+	}, mismatches)
+
+	var buf bytes.Buffer
+	_, err = Run(ctx, store, 100, NewCSVWriter(&buf))
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+	require.Equal(t, []string{"kind", "id", "expected", "actual", "detail"}, rows[0])
+	require.Equal(t, BalanceDrift, rows[1][0])
+}
+
+func TestNewWriter(t *testing.T) {
+	_, err := NewWriter("xml", &bytes.Buffer{})
+	require.Error(t, err)
+
+	var buf bytes.Buffer
+	writer, err := NewWriter(FormatJSON, &buf)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close(Summary{Accounts: 1}))
+	require.JSONEq(t, `{"mismatches":[],"summary":{"accounts":1,"transfers":0,"journals":0,"mismatches":0}}`, buf.String())
+}
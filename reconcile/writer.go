@@ -0,0 +1,107 @@
+package reconcile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Output formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// NewWriter returns a writer for the named format
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatJSON:
+		return NewJSONWriter(w), nil
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	}
+	return nil, fmt.Errorf("unknown format %q, want %s or %s", format, FormatJSON, FormatCSV)
+}
+
+// JSONWriter writes a single JSON object, {"mismatches": [...], "summary": {...}}, streaming
+// the mismatches as they come in.
+type JSONWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{w: w}
+}
+
+func (writer *JSONWriter) Write(mismatch Mismatch) error {
+	data, err := json.Marshal(mismatch)
+	if err != nil {
+		return err
+	}
+
+	prefix := ","
+	if !writer.started {
+		prefix = `{"mismatches":[`
+		writer.started = true
+	}
+	_, err = fmt.Fprintf(writer.w, "%s\n%s", prefix, data)
+	return err
+}
+
+func (writer *JSONWriter) Close(summary Summary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	if !writer.started {
+		_, err = fmt.Fprintf(writer.w, "{\"mismatches\":[],\"summary\":%s}\n", data)
+		return err
+	}
+	_, err = fmt.Fprintf(writer.w, "\n],\"summary\":%s}\n", data)
+	return err
+}
+
+// CSVWriter writes one row per mismatch under a header row. The summary is not part of the
+// output; a run that failed leaves the rows written so far.
+type CSVWriter struct {
+	w       *csv.Writer
+	started bool
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (writer *CSVWriter) header() error {
+	if writer.started {
+		return nil
+	}
+	writer.started = true
+	return writer.w.Write([]string{"kind", "id", "expected", "actual", "detail"})
+}
+
+func (writer *CSVWriter) Write(mismatch Mismatch) error {
+	if err := writer.header(); err != nil {
+		return err
+	}
+
+	return writer.w.Write([]string{
+		mismatch.Kind,
+		strconv.FormatInt(mismatch.ID, 10),
+		strconv.FormatInt(mismatch.Expected, 10),
+		strconv.FormatInt(mismatch.Actual, 10),
+		mismatch.Detail,
+	})
+}
+
+func (writer *CSVWriter) Close(summary Summary) error {
+	if err := writer.header(); err != nil {
+		return err
+	}
+	writer.w.Flush()
+	return writer.w.Error()
+}
@@ -0,0 +1,26 @@
+package scheduler
+
+import (
+	"bank-api/db/sqlc"
+	"context"
+	"time"
+)
+
+const IdempotencyKeyCleanupJobName = "idempotency_key_cleanup"
+
+// IdempotencyKeyRetention is how long at least a client can retry a request with the same
+// idempotency key
+const IdempotencyKeyRetention = 24 * time.Hour
+
+// IdempotencyKeyCleanupJob deletes the idempotency keys created more than the retention before
+// the start of the day, once a day at 03:00.
+func IdempotencyKeyCleanupJob(store sqlc.Store) Job {
+	return Job{
+		Name:   IdempotencyKeyCleanupJobName,
+		Spec:   "0 3 * * *",
+		Period: Daily,
+		Run: func(ctx context.Context, day time.Time) (int64, error) {
+			return store.DeleteIdempotencyKeysBefore(ctx, day.Add(-IdempotencyKeyRetention))
+		},
+	}
+}
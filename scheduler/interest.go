@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"bank-api/interest"
+)
+
+const (
+	InterestAccrualJobName     = "interest_accrual"
+	InterestPayoutJobName      = "interest_payout"
+	ExtraInterestExpiryJobName = "extra_interest_expiry"
+)
+
+// InterestAccrualJob accrues the interest of the day that just ended, shortly after midnight
+func InterestAccrualJob(engine *interest.Engine) Job {
+	return Job{
+		Name:     InterestAccrualJobName,
+		Spec:     "10 0 * * *",
+		Period:   Daily,
+		Previous: true,
+		Run:      engine.AccrueDay,
+	}
+}
+
+// InterestPayoutJob pays out the interest of the month that just ended on the 1st of every
+// month, after the accrual of its last day ran.
+func InterestPayoutJob(engine *interest.Engine) Job {
+	return Job{
+		Name:     InterestPayoutJobName,
+		Spec:     "30 0 1 * *",
+		Period:   Monthly,
+		Previous: true,
+		Run:      engine.PayMonth,
+	}
+}
+
+// ExtraInterestExpiryJob resets the extra interest that is no longer in effect today. An account
+// whose last day of extra interest has not accrued yet, because the accrual job failed or is
+// late, keeps it until a later run finds that day accrued.
+func ExtraInterestExpiryJob(engine *interest.Engine) Job {
+	return Job{
+		Name:   ExtraInterestExpiryJobName,
+		Spec:   "20 0 * * *",
+		Period: Daily,
+		Run:    engine.ExpireExtraInterest,
+	}
+}
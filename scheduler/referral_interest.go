@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"bank-api/db/sqlc"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const ReferralInterestJobName = "referral_interest"
+
+// ReferralInterestJob adds the extra interest of the referrals during the referral period that
+// their referrers have not got on redemption, those of signups with a referral code, at midnight
+// on the cutoff day of every month. Extra interest already in effect keeps its start date, and
+// running a past period again changes nothing, see sqlc.UseReferralCodeTx.
+func ReferralInterestJob(store sqlc.Store, terms sqlc.ReferralTerms) Job {
+	if terms.CutoffDay <= 0 {
+		terms.CutoffDay = sqlc.DefaultReferralTerms.CutoffDay
+	}
+
+	return Job{
+		Name:   ReferralInterestJobName,
+		Spec:   fmt.Sprintf("0 0 %d * *", terms.CutoffDay),
+		Period: Monthly,
+		Run: func(ctx context.Context, month time.Time) (int64, error) {
+			startDate, endDate, err := sqlc.ReferralDateRange(month, terms.CutoffDay)
+			if err != nil {
+				return 0, err
+			}
+
+			referrers, err := store.ListReferrerAccountsByDateRange(ctx, sqlc.ListReferrerAccountsByDateRangeParams{
+				ReferralDate:   startDate,
+				ReferralDate_2: endDate,
+			})
+			if err != nil {
+				return 0, err
+			}
+
+			// one account failing doesn't hold up the others; the run fails and can be retried,
+			// which is safe since every referral is applied once, those of a failed account are
+			// still there for the retry
+			var processed int64
+			var errs []error
+			for _, accountID := range referrers {
+				_, err := store.UseReferralCodeTx(ctx, sqlc.UseReferralCodeTxParams{
+					ReferrerAccountID: accountID,
+					Date:              month,
+					Terms:             terms,
+				})
+				if err != nil {
+					errs = append(errs, fmt.Errorf("account [%d]: %w", accountID, err))
+					continue
+				}
+				processed++
+			}
+
+			return processed, errors.Join(errs...)
+		},
+	}
+}
@@ -0,0 +1,252 @@
+package scheduler
+
+import (
+	"4d63.com/tz"
+	"bank-api/apperr"
+	"bank-api/db/sqlc"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"log"
+	"sync"
+	"time"
+)
+
+// Statuses of a job run
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// TriggerSchedule marks the runs the scheduler started on its own
+const TriggerSchedule = "schedule"
+
+var (
+	ErrUnknownJob    = apperr.New(apperr.KindNotFound, "unknown_job", "unknown job")
+	ErrInvalidPeriod = apperr.New(apperr.KindValidation, "invalid_period", "invalid period")
+	ErrAlreadyRan    = apperr.New(apperr.KindConflict, "job_already_ran", "job already ran or is running for the period")
+	ErrShuttingDown  = apperr.New(apperr.KindConflict, "shutting_down", "the scheduler is shutting down")
+	ErrTakenOver     = errors.New("the run outlived its lease and was taken over")
+)
+
+// DefaultLease is the lease of jobs that don't set one
+const DefaultLease = time.Hour
+
+// Period is how often a job may run, written as the layout its period names use. A job runs at
+// most once per period; a failed run may be retried.
+type Period string
+
+const (
+	Daily   Period = "2006-01-02"
+	Monthly Period = "2006-01"
+)
+
+// before returns a time in the period before the one t is in
+func (period Period) before(t time.Time) time.Time {
+	if period == Monthly {
+		return time.Date(t.Year(), t.Month()-1, 1, 0, 0, 0, 0, t.Location())
+	}
+	return t.AddDate(0, 0, -1)
+}
+
+// Job is a task the scheduler runs on a cron schedule
+type Job struct {
+	Name string
+	// standard cron spec (minute hour day-of-month month day-of-week), in Tokyo time
+	Spec   string
+	Period Period
+	// Previous makes scheduled runs cover the period before the current one, for jobs that
+	// work on a period once it is over
+	Previous bool
+	// Lease is how long a run may take. The run is cancelled once it is over, and a run still
+	// marked running after that is taken to have died with its instance and may be claimed
+	// again. Zero means DefaultLease.
+	Lease time.Duration
+	// Run does the work for the period starting at start and reports how many items it processed
+	Run func(ctx context.Context, start time.Time) (int64, error)
+}
+
+func (job Job) lease() time.Duration {
+	if job.Lease > 0 {
+		return job.Lease
+	}
+	return DefaultLease
+}
+
+// Scheduler runs jobs on their schedule and records every run in job_runs, which is what keeps
+// a job from running twice for the same period, be it on several instances or after a restart.
+type Scheduler struct {
+	store sqlc.Store
+	cron  *cron.Cron
+	loc   *time.Location
+	jobs  map[string]Job
+	// ctx is the context of scheduled runs, every run is cancelled along with it on shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+	// mu guards stopped, which keeps runs from starting once Shutdown waits for runs
+	mu      sync.Mutex
+	stopped bool
+	runs    sync.WaitGroup
+	// now is swapped for a fake clock in tests
+	now func() time.Time
+}
+
+func New(store sqlc.Store) (*Scheduler, error) {
+	loc, err := tz.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		store:  store,
+		cron:   cron.New(cron.WithLocation(loc)),
+		loc:    loc,
+		jobs:   make(map[string]Job),
+		ctx:    ctx,
+		cancel: cancel,
+		now:    time.Now,
+	}, nil
+}
+
+func (scheduler *Scheduler) Register(job Job) error {
+	if _, ok := scheduler.jobs[job.Name]; ok {
+		return fmt.Errorf("job %s is already registered", job.Name)
+	}
+
+	_, err := scheduler.cron.AddFunc(job.Spec, func() {
+		scheduler.runScheduled(job)
+	})
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+
+	scheduler.jobs[job.Name] = job
+	return nil
+}
+
+// Start runs the registered jobs on their schedule in the background
+func (scheduler *Scheduler) Start() {
+	scheduler.cron.Start()
+}
+
+// Shutdown stops scheduling runs and waits for the runs in progress, scheduled or started by
+// hand, to finish. Once ctx is done it cancels them instead and waits for them to roll back and
+// record their run as failed, so that no run is left marked running.
+func (scheduler *Scheduler) Shutdown(ctx context.Context) error {
+	scheduler.mu.Lock()
+	scheduler.stopped = true
+	scheduler.mu.Unlock()
+	scheduler.cron.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		scheduler.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		scheduler.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (scheduler *Scheduler) runScheduled(job Job) {
+	now := scheduler.now().In(scheduler.loc)
+	if job.Previous {
+		now = job.Period.before(now)
+	}
+	period := now.Format(string(job.Period))
+
+	run, err := scheduler.Run(scheduler.ctx, job.Name, period, TriggerSchedule)
+	if err != nil {
+		if errors.Is(err, ErrAlreadyRan) {
+			log.Printf("job %s: period %s already ran", job.Name, period)
+			return
+		}
+		log.Printf("job %s: period %s: %v", job.Name, period, err)
+		return
+	}
+
+	log.Printf("job %s: period %s %s, %d processed", job.Name, period, run.Status, run.Processed)
+}
+
+// Run runs the job for the named period, unless the period already has a run that didn't fail
+// or one that is still within its lease. The job failing is not an error of Run; it is recorded
+// in the returned run.
+func (scheduler *Scheduler) Run(ctx context.Context, name string, period string, triggeredBy string) (sqlc.JobRun, error) {
+	job, ok := scheduler.jobs[name]
+	if !ok {
+		return sqlc.JobRun{}, ErrUnknownJob
+	}
+
+	start, err := time.ParseInLocation(string(job.Period), period, scheduler.loc)
+	if err != nil {
+		return sqlc.JobRun{}, fmt.Errorf("%w %q, want the layout %s", ErrInvalidPeriod, period, job.Period)
+	}
+
+	scheduler.mu.Lock()
+	if scheduler.stopped {
+		scheduler.mu.Unlock()
+		return sqlc.JobRun{}, ErrShuttingDown
+	}
+	scheduler.runs.Add(1)
+	scheduler.mu.Unlock()
+	defer scheduler.runs.Done()
+
+	run, err := scheduler.store.ClaimJobRun(ctx, sqlc.ClaimJobRunParams{
+		JobName:     job.Name,
+		Period:      period,
+		TriggeredBy: triggeredBy,
+		StartedAt:   scheduler.now(),
+		StaleBefore: scheduler.now().Add(-job.lease()),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return sqlc.JobRun{}, ErrAlreadyRan
+		}
+		return sqlc.JobRun{}, err
+	}
+
+	// the run must be over before its lease is, or another instance could take it over while it
+	// still works
+	jobCtx, cancel := context.WithTimeout(ctx, job.lease())
+	defer cancel()
+	defer context.AfterFunc(scheduler.ctx, cancel)()
+	processed, jobErr := job.Run(jobCtx, start)
+
+	arg := sqlc.FinishJobRunParams{
+		ID:         run.ID,
+		Attempts:   run.Attempts,
+		Status:     StatusSucceeded,
+		Processed:  processed,
+		FinishedAt: sql.NullTime{Time: scheduler.now(), Valid: true},
+	}
+	if jobErr != nil {
+		if scheduler.ctx.Err() != nil {
+			jobErr = fmt.Errorf("interrupted by shutdown: %w", jobErr)
+		}
+		arg.Status = StatusFailed
+		arg.Error = sql.NullString{String: jobErr.Error(), Valid: true}
+	}
+
+	// the job is done even when the caller went away, so its outcome is always recorded
+	finished, err := scheduler.store.FinishJobRun(context.WithoutCancel(ctx), arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sqlc.JobRun{}, ErrTakenOver
+	}
+	return finished, err
+}
+
+// HasJob tells whether a job of that name is registered
+func (scheduler *Scheduler) HasJob(name string) bool {
+	_, ok := scheduler.jobs[name]
+	return ok
+}
@@ -0,0 +1,345 @@
+package scheduler
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/interest"
+	"bank-api/util"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestScheduler(t *testing.T, store sqlc.Store, now time.Time) *Scheduler {
+	scheduler, err := New(store)
+	require.NoError(t, err)
+	scheduler.now = func() time.Time { return now }
+	return scheduler
+}
+
+func TestReferralInterestJobSpec(t *testing.T) {
+	scheduler := newTestScheduler(t, sqlc.NewMemoryStore(), time.Now())
+
+	schedule, err := cron.ParseStandard(ReferralInterestJob(nil, sqlc.ReferralTerms{}).Spec)
+	require.NoError(t, err)
+
+	// 2024-07-20 15:30 UTC is already the 21st in Tokyo, so the next run is a month later
+	next := schedule.Next(time.Date(2024, time.July, 20, 15, 30, 0, 0, time.UTC).In(scheduler.loc))
+	require.Equal(t, time.Date(2024, time.August, 21, 0, 0, 0, 0, scheduler.loc), next)
+
+	next = schedule.Next(time.Date(2024, time.July, 20, 14, 30, 0, 0, time.UTC).In(scheduler.loc))
+	require.Equal(t, time.Date(2024, time.July, 21, 0, 0, 0, 0, scheduler.loc), next)
+
+	// the job follows the cutoff day of the terms
+	schedule, err = cron.ParseStandard(ReferralInterestJob(nil, sqlc.ReferralTerms{CutoffDay: 15}).Spec)
+	require.NoError(t, err)
+	next = schedule.Next(time.Date(2024, time.July, 20, 14, 30, 0, 0, time.UTC).In(scheduler.loc))
+	require.Equal(t, time.Date(2024, time.August, 15, 0, 0, 0, 0, scheduler.loc), next)
+}
+
+func TestRunOncePerPeriod(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	scheduler := newTestScheduler(t, store, time.Date(2024, time.July, 21, 0, 0, 0, 0, time.UTC))
+
+	calls := 0
+	var start time.Time
+	require.NoError(t, scheduler.Register(Job{
+		Name:   "test",
+		Spec:   "0 0 21 * *",
+		Period: Monthly,
+		Run: func(ctx context.Context, month time.Time) (int64, error) {
+			calls++
+			start = month
+			return 3, nil
+		},
+	}))
+
+	run, err := scheduler.Run(context.Background(), "test", "2024-07", TriggerSchedule)
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, run.Status)
+	require.Equal(t, int64(3), run.Processed)
+	require.Equal(t, int32(1), run.Attempts)
+	require.True(t, run.FinishedAt.Valid)
+	require.Equal(t, time.Date(2024, time.July, 1, 0, 0, 0, 0, scheduler.loc), start)
+
+	// neither the schedule nor an admin can run the period again
+	scheduler.runScheduled(scheduler.jobs["test"])
+	_, err = scheduler.Run(context.Background(), "test", "2024-07", "admin@bank.com")
+	require.ErrorIs(t, err, ErrAlreadyRan)
+	require.Equal(t, 1, calls)
+
+	// the next period runs as usual
+	_, err = scheduler.Run(context.Background(), "test", "2024-08", TriggerSchedule)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	_, err = scheduler.Run(context.Background(), "test", "2024-8", TriggerSchedule)
+	require.ErrorIs(t, err, ErrInvalidPeriod)
+
+	_, err = scheduler.Run(context.Background(), "unknown", "2024-08", TriggerSchedule)
+	require.ErrorIs(t, err, ErrUnknownJob)
+}
+
+func TestRetryFailedRun(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	scheduler := newTestScheduler(t, store, time.Date(2024, time.July, 21, 0, 0, 0, 0, time.UTC))
+
+	fail := true
+	require.NoError(t, scheduler.Register(Job{
+		Name:   "test",
+		Spec:   "0 0 * * *",
+		Period: Daily,
+		Run: func(ctx context.Context, day time.Time) (int64, error) {
+			if fail {
+				return 1, errors.New("boom")
+			}
+			return 2, nil
+		},
+	}))
+
+	scheduler.runScheduled(scheduler.jobs["test"])
+
+	run, err := store.GetJobRun(context.Background(), sqlc.GetJobRunParams{JobName: "test", Period: "2024-07-21"})
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, run.Status)
+	require.Equal(t, sql.NullString{String: "boom", Valid: true}, run.Error)
+	require.Equal(t, TriggerSchedule, run.TriggeredBy)
+
+	fail = false
+	run, err = scheduler.Run(context.Background(), "test", "2024-07-21", "admin@bank.com")
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, run.Status)
+	require.Equal(t, int32(2), run.Attempts)
+	require.Equal(t, int64(2), run.Processed)
+	require.False(t, run.Error.Valid)
+	require.Equal(t, "admin@bank.com", run.TriggeredBy)
+}
+
+func TestTakeOverStaleRun(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	now := time.Date(2024, time.July, 21, 0, 0, 0, 0, time.UTC)
+	scheduler := newTestScheduler(t, store, now)
+	scheduler.now = func() time.Time { return now }
+
+	require.NoError(t, scheduler.Register(Job{
+		Name:   "test",
+		Spec:   "0 0 * * *",
+		Period: Daily,
+		Lease:  time.Hour,
+		Run: func(ctx context.Context, day time.Time) (int64, error) {
+			return 1, nil
+		},
+	}))
+
+	// an instance claimed the period and died before finishing it
+	dead, err := store.ClaimJobRun(context.Background(), sqlc.ClaimJobRunParams{
+		JobName:     "test",
+		Period:      "2024-07-21",
+		TriggeredBy: TriggerSchedule,
+		StartedAt:   now,
+		StaleBefore: now.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+
+	// within its lease the run may still be working
+	now = now.Add(59 * time.Minute)
+	_, err = scheduler.Run(context.Background(), "test", "2024-07-21", "admin@bank.com")
+	require.ErrorIs(t, err, ErrAlreadyRan)
+
+	now = now.Add(2 * time.Minute)
+	run, err := scheduler.Run(context.Background(), "test", "2024-07-21", "admin@bank.com")
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, run.Status)
+	require.Equal(t, int32(2), run.Attempts)
+
+	// the run that was taken over can no longer record its outcome
+	_, err = store.FinishJobRun(context.Background(), sqlc.FinishJobRunParams{
+		ID:       dead.ID,
+		Attempts: dead.Attempts,
+		Status:   StatusFailed,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestRunIsCancelledWithItsLease(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	scheduler := newTestScheduler(t, store, time.Date(2024, time.July, 21, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, scheduler.Register(Job{
+		Name:   "test",
+		Spec:   "0 0 * * *",
+		Period: Daily,
+		Lease:  10 * time.Millisecond,
+		Run: func(ctx context.Context, day time.Time) (int64, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		},
+	}))
+
+	run, err := scheduler.Run(context.Background(), "test", "2024-07-21", TriggerSchedule)
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, run.Status)
+	require.Equal(t, context.DeadlineExceeded.Error(), run.Error.String)
+}
+
+func TestShutdownCancelsRuns(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	scheduler := newTestScheduler(t, store, time.Date(2024, time.July, 21, 0, 0, 0, 0, time.UTC))
+
+	started := make(chan struct{})
+	require.NoError(t, scheduler.Register(Job{
+		Name:   "test",
+		Spec:   "0 0 * * *",
+		Period: Daily,
+		Run: func(ctx context.Context, day time.Time) (int64, error) {
+			close(started)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		},
+	}))
+
+	go scheduler.runScheduled(scheduler.jobs["test"])
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, scheduler.Shutdown(ctx), context.DeadlineExceeded)
+
+	// the run was waited for and recorded, so it can be retried right away
+	run, err := store.GetJobRun(context.Background(), sqlc.GetJobRunParams{JobName: "test", Period: "2024-07-21"})
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, run.Status)
+	require.Equal(t, "interrupted by shutdown: context canceled", run.Error.String)
+
+	_, err = scheduler.Run(context.Background(), "test", "2024-07-21", "admin@bank.com")
+	require.ErrorIs(t, err, ErrShuttingDown)
+}
+
+func TestReferralInterestJob(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	scheduler := newTestScheduler(t, store, time.Date(2024, time.July, 21, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, scheduler.Register(ReferralInterestJob(store, sqlc.DefaultReferralTerms)))
+
+	referrer, err := store.CreateAccount(context.Background(), sqlc.CreateAccountParams{
+		Owner:    util.RandomOwner(),
+		Email:    util.RandomEmail(),
+		Currency: util.JPY,
+	})
+	require.NoError(t, err)
+
+	// the referrer earns 5% on top since May from referrals before
+	_, err = store.UpdateAccountInterest(context.Background(), sqlc.UpdateAccountInterestParams{
+		ID:                     referrer.ID,
+		ExtraInterest:          sql.NullFloat64{Float64: 5, Valid: true},
+		ExtraInterestStartDate: sql.NullTime{Time: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+		ExtraInterestDuration:  9,
+	})
+	require.NoError(t, err)
+
+	// two signups with a code within the period from 2024-06-21 to 2024-07-20, one after it
+	for _, createdAt := range []time.Time{
+		time.Date(2024, time.June, 21, 0, 0, 0, 0, scheduler.loc),
+		time.Date(2024, time.July, 20, 23, 0, 0, 0, scheduler.loc),
+		time.Date(2024, time.July, 21, 0, 0, 0, 0, scheduler.loc),
+	} {
+		code, err := store.CreateReferralCode(context.Background(), sqlc.CreateReferralCodeParams{
+			ReferralCode:      util.RandomString(10),
+			ReferrerAccountID: referrer.ID,
+			CreatedAt:         createdAt,
+		})
+		require.NoError(t, err)
+
+		_, err = store.SignupWithReferralTx(context.Background(), sqlc.SignupWithReferralTxParams{
+			CreateAccountTxParams: sqlc.CreateAccountTxParams{
+				CreateAccountParams: sqlc.CreateAccountParams{
+					Owner:     util.RandomOwner(),
+					Email:     util.RandomEmail(),
+					Currency:  util.JPY,
+					CreatedAt: createdAt,
+				},
+				HashedPassword: "secret",
+			},
+			ReferralCode: code.ReferralCode,
+		})
+		require.NoError(t, err)
+	}
+
+	run, err := scheduler.Run(context.Background(), ReferralInterestJobName, "2024-07", TriggerSchedule)
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, run.Status)
+	require.Equal(t, int64(1), run.Processed)
+
+	// the referrals of the period add to the extra interest in effect, which keeps its start
+	// and runs for nine months from August
+	account, err := store.GetAccount(context.Background(), referrer.ID)
+	require.NoError(t, err)
+	require.Equal(t, 7.0, account.ExtraInterest.Float64)
+	require.Equal(t, "2024-05-01", account.ExtraInterestStartDate.Time.Format(time.DateOnly))
+	require.Equal(t, int32(12), account.ExtraInterestDuration)
+
+	// running the period again adds nothing
+	_, err = store.UseReferralCodeTx(context.Background(), sqlc.UseReferralCodeTxParams{
+		ReferrerAccountID: referrer.ID,
+		Date:              time.Date(2024, time.July, 21, 0, 0, 0, 0, scheduler.loc),
+	})
+	require.NoError(t, err)
+	rerun, err := store.GetAccount(context.Background(), referrer.ID)
+	require.NoError(t, err)
+	require.Equal(t, account, rerun)
+}
+
+func TestInterestJobsRunForThePreviousPeriod(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	// 00:30 on 2024-08-01 in Tokyo
+	scheduler := newTestScheduler(t, store, time.Date(2024, time.July, 31, 15, 30, 0, 0, time.UTC))
+
+	engine, err := interest.NewEngine(store)
+	require.NoError(t, err)
+	require.NoError(t, scheduler.Register(InterestAccrualJob(engine)))
+	require.NoError(t, scheduler.Register(InterestPayoutJob(engine)))
+
+	scheduler.runScheduled(scheduler.jobs[InterestAccrualJobName])
+	scheduler.runScheduled(scheduler.jobs[InterestPayoutJobName])
+
+	run, err := store.GetJobRun(context.Background(), sqlc.GetJobRunParams{JobName: InterestAccrualJobName, Period: "2024-07-31"})
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, run.Status)
+
+	run, err = store.GetJobRun(context.Background(), sqlc.GetJobRunParams{JobName: InterestPayoutJobName, Period: "2024-07"})
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, run.Status)
+}
+
+func TestIdempotencyKeyCleanupJob(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	// 03:00 on 2024-08-02 in Tokyo
+	scheduler := newTestScheduler(t, store, time.Date(2024, time.August, 1, 18, 0, 0, 0, time.UTC))
+	require.NoError(t, scheduler.Register(IdempotencyKeyCleanupJob(store)))
+
+	for key, createdAt := range map[string]time.Time{
+		"old":    time.Date(2024, time.July, 31, 14, 59, 0, 0, time.UTC),
+		"recent": time.Date(2024, time.July, 31, 15, 0, 0, 0, time.UTC),
+	} {
+		_, err := store.ClaimIdempotencyKey(context.Background(), sqlc.ClaimIdempotencyKeyParams{
+			Scope:       "anonymous",
+			Key:         key,
+			RequestHash: "hash",
+			LockedAt:    createdAt,
+		})
+		require.NoError(t, err)
+	}
+
+	run, err := scheduler.Run(context.Background(), IdempotencyKeyCleanupJobName, "2024-08-02", "test")
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, run.Status)
+	require.Equal(t, int64(1), run.Processed)
+
+	// keys from the whole day before the run are kept
+	_, err = store.GetIdempotencyKey(context.Background(), sqlc.GetIdempotencyKeyParams{Scope: "anonymous", Key: "recent"})
+	require.NoError(t, err)
+	_, err = store.GetIdempotencyKey(context.Background(), sqlc.GetIdempotencyKeyParams{Scope: "anonymous", Key: "old"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
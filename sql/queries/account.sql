@@ -1,16 +1,12 @@
 -- name: CreateAccount :one
-INSERT INTO accounts (owner, balance, email, currency, created_at)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO accounts (owner, balance, email, currency, created_at, customer_id)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: GetAccount :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
 
--- name: GetAccountWithEmail :one
-SELECT * FROM accounts
-WHERE email = $1 LIMIT 1;
-
 -- name: GetAccountForUpdate :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
@@ -22,6 +18,11 @@ ORDER BY id
 LIMIT $1
 OFFSET $2;
 
+-- name: ListCustomerAccounts :many
+SELECT * FROM accounts
+WHERE customer_id = $1
+ORDER BY id;
+
 -- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
@@ -42,4 +43,65 @@ WHERE id = $1;
 UPDATE accounts
 SET extra_interest = $2, extra_interest_start_date = $3, extra_interest_duration = $4
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: ResetExtraInterest :one
+UPDATE accounts
+SET extra_interest = 0, extra_interest_start_date = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateAccountInterestChange :one
+-- records the extra interest terms an account had until now, before they are changed
+INSERT INTO account_interest_changes (account_id, extra_interest, extra_interest_start_date, extra_interest_duration)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetAccountInterestChangeSince :one
+-- the first change of the extra interest terms of the account at or after the given time, which
+-- holds the terms the account had then
+SELECT * FROM account_interest_changes
+WHERE account_id = $1 AND changed_at >= $2
+ORDER BY changed_at, id
+LIMIT 1;
+
+-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts
+SET overdraft_limit = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: GetSystemAccountForUpdate :one
+-- creates the system account of the type and currency on first use
+INSERT INTO accounts (owner, balance, email, currency, account_type)
+VALUES (sqlc.arg(account_type), 0, sqlc.arg(account_type) || '.' || lower(sqlc.arg(currency)) || '@system.bank-api', sqlc.arg(currency), sqlc.arg(account_type))
+ON CONFLICT ("account_type", "currency") WHERE "account_type" <> 'customer'
+DO UPDATE SET account_type = EXCLUDED.account_type
+RETURNING *;
+
+-- name: ListAccountsForInterest :many
+-- customer accounts that existed before the given time, in batches after the given ID
+SELECT * FROM accounts
+WHERE account_type = 'customer'
+  AND created_at < sqlc.arg(created_before)
+  AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(batch_size);
+
+-- name: GetAccountBalanceAt :one
+-- the balance as it was at the given time, the current balance minus every entry since
+SELECT (balance - COALESCE((
+    SELECT SUM(amount) FROM entries
+    WHERE entries.account_id = accounts.id AND entries.created_at >= sqlc.arg(at)
+), 0))::bigint FROM accounts
+WHERE id = sqlc.arg(id);
+
+-- name: ListAccountsWithExpiredExtraInterest :many
+-- accounts whose extra interest ended on or before the given date, in batches after the given ID
+SELECT * FROM accounts
+WHERE extra_interest > 0
+  AND extra_interest_start_date IS NOT NULL
+  AND (extra_interest_start_date + make_interval(months => extra_interest_duration))::date <= sqlc.arg(as_of)::date
+  AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(batch_size);
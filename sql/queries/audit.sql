@@ -0,0 +1,11 @@
+-- name: CreateAuditEntry :one
+INSERT INTO audit_entries (account_id, action, detail)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListAuditEntries :many
+SELECT * FROM audit_entries
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
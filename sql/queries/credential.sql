@@ -0,0 +1,22 @@
+-- name: CreateCustomerCredential :one
+INSERT INTO customer_credentials (customer_id, hashed_password)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetCustomerCredential :one
+SELECT * FROM customer_credentials
+WHERE customer_id = $1 LIMIT 1;
+
+-- name: UpdateCustomerCredentialRole :one
+UPDATE customer_credentials
+SET role = $2
+WHERE customer_id = $1
+RETURNING *;
+
+-- name: SetCustomerPassword :one
+-- creates the credential of a customer who had none, keeping the role of one who had
+INSERT INTO customer_credentials (customer_id, hashed_password, password_changed_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (customer_id) DO UPDATE
+SET hashed_password = EXCLUDED.hashed_password, password_changed_at = EXCLUDED.password_changed_at
+RETURNING *;
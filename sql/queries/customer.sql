@@ -0,0 +1,17 @@
+-- name: CreateCustomer :one
+INSERT INTO customers (email, name, created_at)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetCustomer :one
+SELECT * FROM customers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetCustomerByEmail :one
+SELECT * FROM customers
+WHERE email = $1 LIMIT 1;
+
+-- name: GetCustomerForUpdate :one
+SELECT * FROM customers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
@@ -0,0 +1,37 @@
+-- name: CreateEmailDelivery :exec
+-- a customer gets every kind of message about an event once, however often the relay hands it over
+INSERT INTO email_deliveries (outbox_event_id, kind, customer_id, account_id, payload)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (outbox_event_id, kind, customer_id) DO NOTHING;
+
+-- name: ClaimEmailDeliveries :many
+-- leases the oldest due deliveries to a worker until leased_until, like ClaimOutboxEvents
+UPDATE email_deliveries
+SET next_attempt_at = sqlc.arg(leased_until)
+WHERE id IN (
+    SELECT id FROM email_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= sqlc.arg(due_at)
+    ORDER BY id
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkEmailDeliverySent :exec
+UPDATE email_deliveries
+SET status = 'sent', attempts = attempts + 1, last_error = NULL, sent_at = $2
+WHERE id = $1;
+
+-- name: MarkEmailDeliveryFailed :exec
+-- records an attempt that sent nothing; status is pending to retry at next_attempt_at, dead, or
+-- skipped when there is nothing to send anymore
+UPDATE email_deliveries
+SET status = $2, attempts = attempts + 1, next_attempt_at = $3, last_error = $4
+WHERE id = $1;
+
+-- name: ListEmailDeliveries :many
+SELECT * FROM email_deliveries
+WHERE status = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
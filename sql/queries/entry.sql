@@ -1,9 +1,12 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
 account_id,
-amount
+amount,
+transfer_id,
+journal_id,
+reference_type
 ) VALUES (
-$1, $2
+$1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -15,4 +18,35 @@ SELECT * FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+
+-- name: ListAccountEntries :many
+-- newest first, the page after the before_id cursor; every row carries the balance right after
+-- it, and the counterparty and interest period of the transfer that caused it
+SELECT e.id, e.account_id, e.amount, e.created_at, e.transfer_id, e.journal_id, e.reference_type,
+       c.id AS counterparty_account_id,
+       c.account_type AS counterparty_account_type,
+       p.period AS interest_period,
+       (a.balance - COALESCE((
+           SELECT SUM(later.amount) FROM entries later
+           WHERE later.account_id = e.account_id AND later.id > e.id
+       ), 0))::bigint AS running_balance
+FROM entries e
+JOIN accounts a ON a.id = e.account_id
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts c ON c.id = CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END
+LEFT JOIN interest_payouts p ON p.transfer_id = e.transfer_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND (sqlc.narg(before_id)::bigint IS NULL OR e.id < sqlc.narg(before_id))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR e.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR e.created_at < sqlc.narg(to_time))
+  AND (sqlc.narg(direction)::text IS NULL
+    OR (sqlc.narg(direction) = 'credit' AND e.amount > 0)
+    OR (sqlc.narg(direction) = 'debit' AND e.amount < 0))
+ORDER BY e.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListJournalEntries :many
+SELECT * FROM entries
+WHERE journal_id = $1
+ORDER BY id;
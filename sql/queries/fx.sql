@@ -0,0 +1,32 @@
+-- name: CreateFxRate :one
+INSERT INTO fx_rates (base_currency, quote_currency, rate, source)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetLatestFxRate :one
+-- the current rate of a currency pair
+SELECT * FROM fx_rates
+WHERE base_currency = $1 AND quote_currency = $2
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ListLatestFxRates :many
+-- the current rate of every currency pair
+SELECT DISTINCT ON (base_currency, quote_currency) * FROM fx_rates
+ORDER BY base_currency, quote_currency, id DESC;
+
+-- name: CreateFxQuote :one
+INSERT INTO fx_quotes (id, account_id, from_currency, to_currency, rate, amount, converted_amount, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetFxQuoteForUpdate :one
+SELECT * FROM fx_quotes
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: MarkFxQuoteUsed :one
+UPDATE fx_quotes
+SET used_at = $2
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,28 @@
+-- name: ClaimIdempotencyKey :one
+-- records the key for a request about to run; a key whose request died while holding it is
+-- taken over by a retry of the same request. Returns no row when the key is taken.
+INSERT INTO idempotency_keys (scope, key, request_hash, locked_at, created_at)
+VALUES (sqlc.arg(scope), sqlc.arg(key), sqlc.arg(request_hash), sqlc.arg(locked_at), sqlc.arg(locked_at))
+ON CONFLICT (scope, key) DO UPDATE
+SET locked_at = EXCLUDED.locked_at
+WHERE idempotency_keys.completed_at IS NULL
+  AND idempotency_keys.request_hash = EXCLUDED.request_hash
+  AND idempotency_keys.locked_at < sqlc.arg(stale_before)
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE scope = $1 AND key = $2 LIMIT 1;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET response_code = $3, response_body = $4, completed_at = $5
+WHERE scope = $1 AND key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE scope = $1 AND key = $2;
+
+-- name: DeleteIdempotencyKeysBefore :execrows
+DELETE FROM idempotency_keys
+WHERE created_at < $1;
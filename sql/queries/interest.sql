@@ -0,0 +1,45 @@
+-- name: CreateInterestAccrual :one
+-- returns no row when the account already accrued that day
+INSERT INTO interest_accruals (account_id, accrual_date, balance, annual_rate, amount_micro)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (account_id, accrual_date) DO NOTHING
+RETURNING *;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+  AND accrual_date >= $2 AND accrual_date <= $3
+ORDER BY accrual_date;
+
+-- name: MarkInterestAccrualsPaid :many
+-- marks the accruals of the account up to the given date that no payout paid yet as paid for
+-- the period, and returns them
+UPDATE interest_accruals
+SET paid_period = $3
+WHERE account_id = $1 AND accrual_date <= $2 AND paid_period IS NULL
+RETURNING *;
+
+-- name: HasInterestAccrualSince :one
+-- whether the account accrued on the given date or a later one
+SELECT EXISTS (
+    SELECT 1 FROM interest_accruals
+    WHERE account_id = $1 AND accrual_date >= $2
+)::bool;
+
+-- name: ListAccountsWithUnpaidInterestAccruals :many
+-- accounts with accruals up to the given date that no payout paid yet
+SELECT DISTINCT account_id FROM interest_accruals
+WHERE accrual_date <= $1 AND paid_period IS NULL
+ORDER BY account_id;
+
+-- name: CreateInterestPayout :one
+-- returns no row when the account was already paid for the period
+INSERT INTO interest_payouts (account_id, period, amount, transfer_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (account_id, period) DO NOTHING
+RETURNING *;
+
+-- name: GetInterestPayout :one
+SELECT * FROM interest_payouts
+WHERE account_id = $1 AND period = $2
+LIMIT 1;
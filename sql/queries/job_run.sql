@@ -0,0 +1,30 @@
+-- name: ClaimJobRun :one
+-- returns no row when the period already has a run that did not fail. A run still marked
+-- running that started before stale_before died with its instance and is taken over.
+INSERT INTO job_runs (job_name, period, status, triggered_by, started_at)
+VALUES (sqlc.arg(job_name), sqlc.arg(period), 'running', sqlc.arg(triggered_by), sqlc.arg(started_at))
+ON CONFLICT (job_name, period) DO UPDATE
+SET status = 'running', triggered_by = EXCLUDED.triggered_by, attempts = job_runs.attempts + 1,
+    processed = 0, error = NULL, started_at = EXCLUDED.started_at, finished_at = NULL
+WHERE job_runs.status = 'failed'
+   OR (job_runs.status = 'running' AND job_runs.started_at < sqlc.arg(stale_before))
+RETURNING *;
+
+-- name: FinishJobRun :one
+-- returns no row when the run was taken over since it was claimed
+UPDATE job_runs
+SET status = $3, processed = $4, error = $5, finished_at = $6
+WHERE id = $1 AND attempts = $2 AND status = 'running'
+RETURNING *;
+
+-- name: GetJobRun :one
+SELECT * FROM job_runs
+WHERE job_name = $1 AND period = $2
+LIMIT 1;
+
+-- name: ListJobRuns :many
+SELECT * FROM job_runs
+WHERE job_name = $1
+ORDER BY period DESC
+LIMIT $2
+OFFSET $3;
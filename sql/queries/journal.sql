@@ -0,0 +1,8 @@
+-- name: CreateJournal :one
+INSERT INTO journals (memo)
+VALUES ($1)
+RETURNING *;
+
+-- name: GetJournal :one
+SELECT * FROM journals
+WHERE id = $1 LIMIT 1;
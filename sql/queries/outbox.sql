@@ -0,0 +1,43 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox_events (event_type, aggregate_type, aggregate_id, payload)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ClaimOutboxEvents :many
+-- leases the oldest due events to a relay until leased_until; events another relay is
+-- claiming at the same time are skipped
+UPDATE outbox_events
+SET next_attempt_at = sqlc.arg(leased_until)
+WHERE id IN (
+    SELECT id FROM outbox_events
+    WHERE status = 'pending' AND next_attempt_at <= sqlc.arg(due_at)
+    ORDER BY id
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkOutboxEventDelivered :exec
+UPDATE outbox_events
+SET status = 'delivered', attempts = attempts + 1, last_error = NULL, delivered_at = $2
+WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+-- records a failed attempt; status is pending to retry at next_attempt_at, or dead
+UPDATE outbox_events
+SET status = $2, attempts = attempts + 1, next_attempt_at = $3, last_error = $4
+WHERE id = $1;
+
+-- name: ListOutboxEvents :many
+SELECT * FROM outbox_events
+WHERE status = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: RequeueOutboxEvent :one
+-- gives a dead event a fresh set of attempts
+UPDATE outbox_events
+SET status = 'pending', attempts = 0, next_attempt_at = $2
+WHERE id = $1 AND status = 'dead'
+RETURNING *;
@@ -0,0 +1,22 @@
+-- name: CreatePasswordReset :one
+INSERT INTO password_resets (customer_id, expires_at, created_at)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: SetPasswordResetToken :one
+-- records the token about to be emailed, replacing the one of an earlier attempt. Returns no row
+-- once the reset was used or expired.
+UPDATE password_resets
+SET token_hash = sqlc.arg(token_hash)
+WHERE id = sqlc.arg(id) AND used_at IS NULL AND expires_at > sqlc.arg(now)
+RETURNING *;
+
+-- name: GetPasswordResetByTokenForUpdate :one
+SELECT * FROM password_resets
+WHERE token_hash = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: UsePasswordReset :exec
+UPDATE password_resets
+SET used_at = $2
+WHERE id = $1;
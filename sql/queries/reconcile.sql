@@ -0,0 +1,34 @@
+-- name: ListAccountEntrySums :many
+-- balances next to the sum of their entries, in batches after the given account ID
+SELECT a.id, a.balance, COALESCE(SUM(e.amount), 0)::bigint AS entries_sum
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+WHERE a.id > sqlc.arg(after_id)
+GROUP BY a.id
+ORDER BY a.id
+LIMIT sqlc.arg(batch_size);
+
+-- name: ListTransferEntryCounts :many
+-- transfers with how many entries point at them and how many of those book the transfer right,
+-- debiting the amount and crediting the converted amount if there is one, in batches after the
+-- given transfer ID
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.converted_amount,
+       COUNT(e.id) AS entry_count,
+       COUNT(e.id) FILTER (WHERE (e.account_id = t.from_account_id AND e.amount = -t.amount)
+                              OR (e.account_id = t.to_account_id AND e.amount = COALESCE(t.converted_amount, t.amount))) AS matching_entry_count
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+WHERE t.id > sqlc.arg(after_id)
+GROUP BY t.id
+ORDER BY t.id
+LIMIT sqlc.arg(batch_size);
+
+-- name: ListJournalEntrySums :many
+-- journals with the number and sum of their legs, in batches after the given journal ID
+SELECT j.id, COUNT(e.id) AS entry_count, COALESCE(SUM(e.amount), 0)::bigint AS entries_sum
+FROM journals j
+LEFT JOIN entries e ON e.journal_id = j.id
+WHERE j.id > sqlc.arg(after_id)
+GROUP BY j.id
+ORDER BY j.id
+LIMIT sqlc.arg(batch_size);
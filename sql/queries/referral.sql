@@ -40,10 +40,18 @@ WHERE referral_code = $1
 RETURNING *;
 
 -- name: CreateReferralHistory :one
-INSERT INTO referral_history (referrer_account_id, referred_account_id, referral_code_id, referral_date, created_at)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO referral_history (referrer_account_id, referred_account_id, referral_code_id, referral_date, created_at, referred_customer_id, extra_interest_applied_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
+-- name: HasBeenReferred :one
+SELECT EXISTS (
+    SELECT 1
+    FROM referral_history
+    WHERE referred_account_id = $1
+       OR referred_customer_id = $2
+);
+
 -- name: GetReferralHistory :many
 SELECT * FROM referral_history
 WHERE referrer_account_id = $1
@@ -53,4 +61,27 @@ ORDER BY referral_date;
 SELECT * FROM referral_history
 WHERE referrer_account_id = $1
   AND referral_date >= $2 AND referral_date <= $3
-ORDER BY referral_date;
\ No newline at end of file
+ORDER BY referral_date;
+-- name: GetReferralCodeForUpdate :one
+SELECT * FROM referral_codes
+WHERE referral_code = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListReferrerAccountsByDateRange :many
+SELECT DISTINCT referrer_account_id FROM referral_history
+WHERE extra_interest_applied_at IS NULL
+  AND referral_date >= $1 AND referral_date <= $2
+ORDER BY referrer_account_id;
+
+-- name: ListUnappliedReferrals :many
+SELECT * FROM referral_history
+WHERE referrer_account_id = $1
+  AND extra_interest_applied_at IS NULL
+  AND referral_date >= $2 AND referral_date <= $3
+ORDER BY referral_date, id;
+
+-- name: MarkReferralExtraInterestApplied :exec
+UPDATE referral_history
+SET extra_interest_applied_at = $2
+WHERE id = $1;
@@ -2,9 +2,12 @@
 INSERT INTO transfers (
                        from_account_id,
                        to_account_id,
-                       amount
+                       amount,
+                       fx_quote_id,
+                       fx_rate,
+                       converted_amount
 ) VALUES (
-          $1, $2, $3
+          $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetTransfer :one
@@ -0,0 +1,64 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (account_id, url, event_types, secret)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptions :many
+-- the subscriptions of an account, or the ones for every account when account_id is null
+SELECT * FROM webhook_subscriptions
+WHERE account_id IS NOT DISTINCT FROM sqlc.narg(account_id)
+ORDER BY id;
+
+-- name: ListWebhookSubscriptionsForEvent :many
+-- the subscriptions an event goes to: those for every account and those of the accounts the
+-- event is about, that take every event type or this one
+SELECT * FROM webhook_subscriptions
+WHERE (account_id IS NULL OR account_id = ANY(sqlc.arg(account_ids)::bigint[]))
+  AND (cardinality(event_types) = 0 OR sqlc.arg(event_type)::varchar = ANY(event_types))
+ORDER BY id;
+
+-- name: DeleteWebhookSubscription :exec
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: CreateWebhookDelivery :exec
+-- a subscription gets every event once, however often the relay hands it over
+INSERT INTO webhook_deliveries (subscription_id, outbox_event_id, event_type, payload)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (subscription_id, outbox_event_id) DO NOTHING;
+
+-- name: ClaimWebhookDeliveries :many
+-- leases the oldest due deliveries to a worker until leased_until, like ClaimOutboxEvents
+UPDATE webhook_deliveries
+SET next_attempt_at = sqlc.arg(leased_until)
+WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= sqlc.arg(due_at)
+    ORDER BY id
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered', attempts = attempts + 1, response_status = $2, last_error = NULL, delivered_at = $3
+WHERE id = $1;
+
+-- name: MarkWebhookDeliveryFailed :exec
+-- records a failed attempt; status is pending to retry at next_attempt_at, or dead
+UPDATE webhook_deliveries
+SET status = $2, attempts = attempts + 1, next_attempt_at = $3, response_status = $4, last_error = $5
+WHERE id = $1;
+
+-- name: ListWebhookDeliveries :many
+-- the delivery log of a subscription, newest first
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE "accounts" ADD COLUMN "overdraft_limit" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_overdraft_limit_check" CHECK ("overdraft_limit" >= 0);
+
+COMMENT ON COLUMN "accounts"."overdraft_limit" IS 'how far below zero the balance may go';
+
+-- +goose Down
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_overdraft_limit_check";
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "overdraft_limit";
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE "account_credentials" (
+                                       "account_id"          bigint PRIMARY KEY,
+                                       "hashed_password"     varchar     NOT NULL,
+                                       "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+                                       "created_at"          timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "sessions" (
+                            "id"            uuid PRIMARY KEY,
+                            "account_id"    bigint      NOT NULL,
+                            "refresh_token" varchar     NOT NULL,
+                            "user_agent"    varchar     NOT NULL,
+                            "client_ip"     varchar     NOT NULL,
+                            "is_blocked"    boolean     NOT NULL DEFAULT false,
+                            "expires_at"    timestamptz NOT NULL,
+                            "created_at"    timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_credentials" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+ALTER TABLE "sessions" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "sessions" ("account_id");
+
+-- +goose Down
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS account_credentials;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE "account_credentials" ADD COLUMN "role" varchar NOT NULL DEFAULT 'depositor';
+
+-- +goose Down
+ALTER TABLE "account_credentials" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE "accounts" ADD COLUMN "account_type" varchar NOT NULL DEFAULT 'customer';
+
+-- there is one system account of each type per currency, e.g. the account welcome bonuses are paid from
+CREATE UNIQUE INDEX "accounts_system_account_key" ON "accounts" ("account_type", "currency") WHERE "account_type" <> 'customer';
+
+COMMENT ON COLUMN "accounts"."account_type" IS 'customer, or the kind of system account';
+
+-- +goose Down
+DROP INDEX IF EXISTS "accounts_system_account_key";
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "account_type";
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE "job_runs" (
+                            "id"           bigserial PRIMARY KEY,
+                            "job_name"     varchar     NOT NULL,
+                            "period"       varchar     NOT NULL,
+                            "status"       varchar     NOT NULL,
+                            "triggered_by" varchar     NOT NULL,
+                            "attempts"     int         NOT NULL DEFAULT 1,
+                            "processed"    bigint      NOT NULL DEFAULT 0,
+                            "error"        varchar,
+                            "started_at"   timestamptz NOT NULL,
+                            "finished_at"  timestamptz
+);
+
+-- a job runs at most once per period, a failed run is retried in place
+CREATE UNIQUE INDEX ON "job_runs" ("job_name", "period");
+
+COMMENT ON COLUMN "job_runs"."period" IS 'the period the run covers, e.g. 2024-07 for a monthly job';
+COMMENT ON COLUMN "job_runs"."status" IS 'running, succeeded or failed';
+COMMENT ON COLUMN "job_runs"."triggered_by" IS 'schedule, or the admin who started the run';
+
+-- +goose Down
+DROP TABLE IF EXISTS job_runs;
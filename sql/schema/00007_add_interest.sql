@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE "interest_accruals" (
+                                     "id"           bigserial PRIMARY KEY,
+                                     "account_id"   bigint      NOT NULL,
+                                     "accrual_date" date        NOT NULL,
+                                     "balance"      bigint      NOT NULL,
+                                     "annual_rate"  float       NOT NULL,
+                                     "amount_micro" bigint      NOT NULL,
+                                     "created_at"   timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_payouts" (
+                                    "id"          bigserial PRIMARY KEY,
+                                    "account_id"  bigint      NOT NULL,
+                                    "period"      varchar     NOT NULL,
+                                    "amount"      bigint      NOT NULL,
+                                    "transfer_id" bigint,
+                                    "created_at"  timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "interest_payouts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "interest_payouts" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- an account accrues once a day and is paid once a month
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "accrual_date");
+CREATE UNIQUE INDEX ON "interest_payouts" ("account_id", "period");
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'end of day balance the interest accrued on';
+COMMENT ON COLUMN "interest_accruals"."annual_rate" IS 'base plus extra interest in effect that day, in percent';
+COMMENT ON COLUMN "interest_accruals"."amount_micro" IS 'accrued interest in millionths of the minor unit';
+COMMENT ON COLUMN "interest_payouts"."period" IS 'the month paid for, e.g. 2024-07';
+COMMENT ON COLUMN "interest_payouts"."transfer_id" IS 'transfer from the interest account, none when nothing was due';
+
+-- +goose Down
+DROP TABLE IF EXISTS interest_payouts;
+DROP TABLE IF EXISTS interest_accruals;
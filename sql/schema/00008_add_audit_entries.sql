@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE "audit_entries" (
+                                 "id"         bigserial PRIMARY KEY,
+                                 "account_id" bigint      NOT NULL,
+                                 "action"     varchar     NOT NULL,
+                                 "detail"     jsonb       NOT NULL DEFAULT '{}',
+                                 "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "audit_entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "audit_entries" ("account_id", "created_at");
+
+COMMENT ON COLUMN "audit_entries"."action" IS 'what happened to the account, e.g. extra_interest_expired';
+COMMENT ON COLUMN "audit_entries"."detail" IS 'the values before and after the change';
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_entries;
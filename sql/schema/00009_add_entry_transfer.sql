@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "entries" ("account_id", "id");
+
+-- entries were written in the same transaction as their transfer, so they share its now()
+UPDATE "entries" e
+SET "transfer_id" = t."id"
+FROM "transfers" t
+WHERE e."created_at" = t."created_at"
+  AND ((e."account_id" = t."from_account_id" AND e."amount" = -t."amount")
+    OR (e."account_id" = t."to_account_id" AND e."amount" = t."amount"));
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'the transfer that caused the entry';
+
+-- +goose Down
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "transfer_id";
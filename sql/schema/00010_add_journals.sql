@@ -0,0 +1,85 @@
+-- +goose Up
+CREATE TABLE "journals" (
+                            "id"         bigserial PRIMARY KEY,
+                            "memo"       varchar     NOT NULL DEFAULT '',
+                            "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "entries" ADD COLUMN "journal_id" bigint;
+ALTER TABLE "entries" ADD COLUMN "reference_type" varchar NOT NULL DEFAULT 'transfer';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+CREATE INDEX ON "entries" ("journal_id");
+
+-- every transfer so far was posted as its own journal of two entries; the journals take over
+-- the transfer IDs so the entries can be matched up
+INSERT INTO "journals" ("id", "created_at")
+SELECT "id", "created_at" FROM "transfers";
+
+SELECT setval(pg_get_serial_sequence('journals', 'id'), COALESCE(MAX("id"), 0) + 1, false) FROM "journals";
+
+UPDATE "entries" e
+SET "journal_id" = e."transfer_id",
+    "reference_type" = CASE c."account_type" WHEN 'bonus' THEN 'bonus' WHEN 'interest' THEN 'interest' ELSE 'transfer' END
+FROM "transfers" t, "accounts" c
+WHERE t."id" = e."transfer_id"
+  AND c."id" = CASE WHEN t."from_account_id" = e."account_id" THEN t."to_account_id" ELSE t."from_account_id" END;
+
+-- money that reached an account other than by a transfer, like the initial deposit, has no entry,
+-- so the balance is not the sum of the entries. Each such account gets an opening journal for the
+-- difference, against the adjustment account of its currency, which is what AdjustBalanceTx books
+-- corrections against from now on.
+CREATE TEMPORARY TABLE "opening_balances" ON COMMIT DROP AS
+SELECT a."id" AS "account_id", a."currency", a."created_at",
+       a."balance" - COALESCE(SUM(e."amount"), 0) AS "amount"
+FROM "accounts" a
+LEFT JOIN "entries" e ON e."account_id" = a."id"
+WHERE a."account_type" <> 'adjustment'
+GROUP BY a."id"
+HAVING a."balance" <> COALESCE(SUM(e."amount"), 0);
+
+ALTER TABLE "opening_balances" ADD COLUMN "journal_id" bigint;
+UPDATE "opening_balances" SET "journal_id" = nextval(pg_get_serial_sequence('journals', 'id'));
+
+INSERT INTO "accounts" ("owner", "balance", "email", "currency", "account_type")
+SELECT DISTINCT 'adjustment', 0, 'adjustment.' || lower("currency") || '@system.bank-api', "currency", 'adjustment'
+FROM "opening_balances"
+ON CONFLICT ("account_type", "currency") WHERE "account_type" <> 'customer' DO NOTHING;
+
+INSERT INTO "journals" ("id", "memo", "created_at")
+SELECT "journal_id", 'opening balance before journals', "created_at" FROM "opening_balances";
+
+INSERT INTO "entries" ("account_id", "amount", "created_at", "journal_id", "reference_type")
+SELECT "account_id", "amount", "created_at", "journal_id", 'adjustment' FROM "opening_balances"
+UNION ALL
+SELECT s."id", -o."amount", o."created_at", o."journal_id", 'adjustment'
+FROM "opening_balances" o
+JOIN "accounts" s ON s."account_type" = 'adjustment' AND s."currency" = o."currency";
+
+UPDATE "accounts" s
+SET "balance" = s."balance" - o."total"
+FROM (SELECT "currency", SUM("amount") AS "total" FROM "opening_balances" GROUP BY "currency") o
+WHERE s."account_type" = 'adjustment' AND s."currency" = o."currency";
+
+COMMENT ON COLUMN "entries"."journal_id" IS 'the journal the entry is a leg of, its legs sum to zero';
+COMMENT ON COLUMN "entries"."reference_type" IS 'what caused the entry: transfer, bonus, interest or adjustment';
+
+-- +goose Down
+UPDATE "accounts" s
+SET "balance" = s."balance" - o."total"
+FROM (SELECT e."account_id", SUM(e."amount") AS "total"
+      FROM "entries" e
+      JOIN "journals" j ON j."id" = e."journal_id"
+      JOIN "accounts" a ON a."id" = e."account_id"
+      WHERE j."memo" = 'opening balance before journals' AND a."account_type" = 'adjustment'
+      GROUP BY e."account_id") o
+WHERE s."id" = o."account_id";
+
+DELETE FROM "entries" e
+USING "journals" j
+WHERE j."id" = e."journal_id" AND j."memo" = 'opening balance before journals';
+
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "reference_type";
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "journal_id";
+DROP TABLE IF EXISTS journals;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- currencies are ISO 4217 codes, the yen was stored as YEN
+UPDATE "accounts" SET "currency" = 'JPY' WHERE "currency" = 'YEN';
+UPDATE "accounts" SET "email" = "account_type" || '.jpy@system.bank-api'
+WHERE "currency" = 'JPY' AND "account_type" <> 'customer';
+
+COMMENT ON COLUMN "accounts"."currency" IS 'ISO 4217 code, amounts of the account are in its minor unit';
+
+-- +goose Down
+COMMENT ON COLUMN "accounts"."currency" IS NULL;
+
+UPDATE "accounts" SET "email" = "account_type" || '.yen@system.bank-api'
+WHERE "currency" = 'JPY' AND "account_type" <> 'customer';
+UPDATE "accounts" SET "currency" = 'YEN' WHERE "currency" = 'JPY';
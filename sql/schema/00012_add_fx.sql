@@ -0,0 +1,56 @@
+-- +goose Up
+CREATE TABLE "fx_rates" (
+                            "id"             bigserial PRIMARY KEY,
+                            "base_currency"  varchar         NOT NULL,
+                            "quote_currency" varchar         NOT NULL,
+                            "rate"           numeric(24, 12) NOT NULL,
+                            "source"         varchar         NOT NULL,
+                            "created_at"     timestamptz     NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "fx_rates" ADD CONSTRAINT "fx_rates_rate_check" CHECK ("rate" > 0);
+
+-- rates are never updated, the latest row of a pair is its current rate
+CREATE INDEX ON "fx_rates" ("base_currency", "quote_currency", "id");
+
+COMMENT ON COLUMN "fx_rates"."rate" IS 'units of the quote currency one unit of the base currency buys';
+COMMENT ON COLUMN "fx_rates"."source" IS 'who set the rate: the admin email, or the file it was loaded from';
+
+CREATE TABLE "fx_quotes" (
+                             "id"               uuid PRIMARY KEY,
+                             "account_id"       bigint          NOT NULL,
+                             "from_currency"    varchar         NOT NULL,
+                             "to_currency"      varchar         NOT NULL,
+                             "rate"             numeric(24, 12) NOT NULL,
+                             "amount"           bigint          NOT NULL,
+                             "converted_amount" bigint          NOT NULL,
+                             "expires_at"       timestamptz     NOT NULL,
+                             "used_at"          timestamptz,
+                             "created_at"       timestamptz     NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "fx_quotes" ("account_id");
+
+COMMENT ON COLUMN "fx_quotes"."account_id" IS 'the account the quote was given to, only it can transfer with it';
+COMMENT ON COLUMN "fx_quotes"."amount" IS 'debited, in the minor unit of from_currency';
+COMMENT ON COLUMN "fx_quotes"."converted_amount" IS 'credited, in the minor unit of to_currency';
+
+ALTER TABLE "transfers" ADD COLUMN "fx_quote_id" uuid;
+ALTER TABLE "transfers" ADD COLUMN "fx_rate" numeric(24, 12);
+ALTER TABLE "transfers" ADD COLUMN "converted_amount" bigint;
+
+-- a quote is good for one transfer
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_fx_quote_id_key" UNIQUE ("fx_quote_id");
+ALTER TABLE "transfers" ADD FOREIGN KEY ("fx_quote_id") REFERENCES "fx_quotes" ("id");
+
+COMMENT ON COLUMN "transfers"."fx_rate" IS 'the rate the amount was converted at, for transfers between currencies';
+COMMENT ON COLUMN "transfers"."converted_amount" IS 'credited to the destination account in its currency, for transfers between currencies';
+
+-- +goose Down
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "converted_amount";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "fx_rate";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "fx_quote_id";
+DROP TABLE IF EXISTS fx_quotes;
+DROP TABLE IF EXISTS fx_rates;
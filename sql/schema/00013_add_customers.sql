@@ -0,0 +1,102 @@
+-- +goose Up
+CREATE TABLE "customers" (
+                             "id"         bigserial PRIMARY KEY,
+                             "email"      varchar(255) NOT NULL UNIQUE,
+                             "name"       varchar      NOT NULL,
+                             "created_at" timestamptz  NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "accounts" ADD COLUMN "customer_id" bigint;
+ALTER TABLE "accounts" ADD FOREIGN KEY ("customer_id") REFERENCES "customers" ("id");
+
+-- every customer account so far was opened by a customer of its own, who signed in with the
+-- account's email
+INSERT INTO "customers" ("email", "name", "created_at")
+SELECT "email", "owner", "created_at" FROM "accounts"
+WHERE "account_type" = 'customer'
+ORDER BY "id";
+
+UPDATE "accounts" a
+SET "customer_id" = c."id"
+FROM "customers" c
+WHERE c."email" = a."email" AND a."account_type" = 'customer';
+
+-- a customer may hold several accounts, one per currency
+ALTER TABLE "accounts" DROP CONSTRAINT "accounts_email_key";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_customer_id_currency_key" UNIQUE ("customer_id", "currency");
+
+COMMENT ON COLUMN "accounts"."customer_id" IS 'the customer who holds the account, NULL for system accounts';
+COMMENT ON COLUMN "accounts"."email" IS 'the customer''s email when the account was opened';
+
+-- a customer signs in once for all of their accounts
+CREATE TABLE "customer_credentials" (
+                                        "customer_id"         bigint PRIMARY KEY,
+                                        "hashed_password"     varchar     NOT NULL,
+                                        "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+                                        "role"                varchar     NOT NULL DEFAULT 'depositor',
+                                        "created_at"          timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "customer_credentials" ADD FOREIGN KEY ("customer_id") REFERENCES "customers" ("id") ON DELETE CASCADE;
+
+INSERT INTO "customer_credentials" ("customer_id", "hashed_password", "password_changed_at", "role", "created_at")
+SELECT a."customer_id", c."hashed_password", c."password_changed_at", c."role", c."created_at"
+FROM "account_credentials" c
+JOIN "accounts" a ON a."id" = c."account_id"
+WHERE a."customer_id" IS NOT NULL;
+
+DROP TABLE "account_credentials";
+
+ALTER TABLE "sessions" ADD COLUMN "customer_id" bigint;
+
+UPDATE "sessions" s
+SET "customer_id" = a."customer_id"
+FROM "accounts" a
+WHERE a."id" = s."account_id";
+
+DELETE FROM "sessions" WHERE "customer_id" IS NULL;
+
+ALTER TABLE "sessions" ALTER COLUMN "customer_id" SET NOT NULL;
+ALTER TABLE "sessions" ADD FOREIGN KEY ("customer_id") REFERENCES "customers" ("id") ON DELETE CASCADE;
+ALTER TABLE "sessions" DROP COLUMN "account_id";
+
+CREATE INDEX ON "sessions" ("customer_id");
+
+-- +goose Down
+ALTER TABLE "sessions" ADD COLUMN "account_id" bigint;
+
+-- a session goes back to the customer's first account
+UPDATE "sessions" s
+SET "account_id" = (SELECT MIN(a."id") FROM "accounts" a WHERE a."customer_id" = s."customer_id");
+
+DELETE FROM "sessions" WHERE "account_id" IS NULL;
+
+ALTER TABLE "sessions" ALTER COLUMN "account_id" SET NOT NULL;
+ALTER TABLE "sessions" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+ALTER TABLE "sessions" DROP COLUMN "customer_id";
+
+CREATE INDEX ON "sessions" ("account_id");
+
+CREATE TABLE "account_credentials" (
+                                       "account_id"          bigint PRIMARY KEY,
+                                       "hashed_password"     varchar     NOT NULL,
+                                       "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+                                       "created_at"          timestamptz NOT NULL DEFAULT (now()),
+                                       "role"                varchar     NOT NULL DEFAULT 'depositor'
+);
+
+ALTER TABLE "account_credentials" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+INSERT INTO "account_credentials" ("account_id", "hashed_password", "password_changed_at", "created_at", "role")
+SELECT a."id", c."hashed_password", c."password_changed_at", c."created_at", c."role"
+FROM "customer_credentials" c
+JOIN "accounts" a ON a."customer_id" = c."customer_id";
+
+DROP TABLE IF EXISTS customer_credentials;
+
+-- fails while a customer holds more than one account, they have to be split up by hand first
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_customer_id_currency_key";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_email_key" UNIQUE ("email");
+COMMENT ON COLUMN "accounts"."email" IS NULL;
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "customer_id";
+DROP TABLE IF EXISTS customers;
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE "idempotency_keys" (
+                                    "scope"         varchar      NOT NULL,
+                                    "key"           varchar(255) NOT NULL,
+                                    "request_hash"  varchar      NOT NULL,
+                                    "response_code" int,
+                                    "response_body" bytea,
+                                    "locked_at"     timestamptz  NOT NULL,
+                                    "completed_at"  timestamptz,
+                                    "created_at"    timestamptz  NOT NULL DEFAULT (now()),
+                                    PRIMARY KEY ("scope", "key")
+);
+
+CREATE INDEX ON "idempotency_keys" ("created_at");
+
+COMMENT ON COLUMN "idempotency_keys"."scope" IS 'whose key it is: customer:<id>, or anonymous for signups';
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'fingerprint of the method, URI and body of the first request with the key';
+COMMENT ON COLUMN "idempotency_keys"."locked_at" IS 'when the request holding the key started, a retry may take over a key locked for too long';
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotency_keys;
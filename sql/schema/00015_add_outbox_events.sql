@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE "outbox_events" (
+                                 "id"              bigserial PRIMARY KEY,
+                                 "event_type"      varchar     NOT NULL,
+                                 "aggregate_type"  varchar     NOT NULL,
+                                 "aggregate_id"    bigint      NOT NULL,
+                                 "payload"         jsonb       NOT NULL DEFAULT '{}',
+                                 "status"          varchar     NOT NULL DEFAULT 'pending',
+                                 "attempts"        int         NOT NULL DEFAULT 0,
+                                 "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+                                 "last_error"      varchar,
+                                 "created_at"      timestamptz NOT NULL DEFAULT (now()),
+                                 "delivered_at"    timestamptz
+);
+
+-- the relay only ever looks for pending events that are due
+CREATE INDEX ON "outbox_events" ("next_attempt_at") WHERE "status" = 'pending';
+CREATE INDEX ON "outbox_events" ("status", "id");
+
+COMMENT ON COLUMN "outbox_events"."event_type" IS 'what happened, e.g. transfer.completed';
+COMMENT ON COLUMN "outbox_events"."aggregate_type" IS 'the kind of row the event is about: account, transfer or referral';
+COMMENT ON COLUMN "outbox_events"."status" IS 'pending, delivered, or dead once it ran out of attempts';
+COMMENT ON COLUMN "outbox_events"."next_attempt_at" IS 'when the relay picks the event up next, pushed back while it is being delivered and after a failed attempt';
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox_events;
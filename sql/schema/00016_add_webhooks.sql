@@ -0,0 +1,47 @@
+-- +goose Up
+CREATE TABLE "webhook_subscriptions" (
+                                         "id"          bigserial PRIMARY KEY,
+                                         "account_id"  bigint,
+                                         "url"         varchar     NOT NULL,
+                                         "event_types" varchar[]   NOT NULL DEFAULT '{}',
+                                         "secret"      varchar     NOT NULL,
+                                         "created_at"  timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "webhook_subscriptions" ("account_id");
+
+COMMENT ON COLUMN "webhook_subscriptions"."account_id" IS 'the account whose events are sent, null for every account';
+COMMENT ON COLUMN "webhook_subscriptions"."event_types" IS 'the event types sent, empty for all of them';
+COMMENT ON COLUMN "webhook_subscriptions"."secret" IS 'key the deliveries are signed with';
+
+CREATE TABLE "webhook_deliveries" (
+                                      "id"              bigserial PRIMARY KEY,
+                                      "subscription_id" bigint      NOT NULL,
+                                      "outbox_event_id" bigint      NOT NULL,
+                                      "event_type"      varchar     NOT NULL,
+                                      "payload"         jsonb       NOT NULL,
+                                      "status"          varchar     NOT NULL DEFAULT 'pending',
+                                      "attempts"        int         NOT NULL DEFAULT 0,
+                                      "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+                                      "response_status" int,
+                                      "last_error"      varchar,
+                                      "created_at"      timestamptz NOT NULL DEFAULT (now()),
+                                      "delivered_at"    timestamptz,
+                                      UNIQUE ("subscription_id", "outbox_event_id")
+);
+
+ALTER TABLE "webhook_subscriptions" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE;
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("outbox_event_id") REFERENCES "outbox_events" ("id");
+
+-- like the outbox relay, the webhook worker only ever looks for pending deliveries that are due
+CREATE INDEX ON "webhook_deliveries" ("next_attempt_at") WHERE "status" = 'pending';
+CREATE INDEX ON "webhook_deliveries" ("subscription_id", "id");
+
+COMMENT ON COLUMN "webhook_deliveries"."payload" IS 'the request body, the same for every attempt';
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending, delivered, or dead once it ran out of attempts';
+COMMENT ON COLUMN "webhook_deliveries"."response_status" IS 'HTTP status of the last attempt, null when no response came back';
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
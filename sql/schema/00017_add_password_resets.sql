@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE "password_resets" (
+                                   "id"          bigserial PRIMARY KEY,
+                                   "customer_id" bigint      NOT NULL,
+                                   "token_hash"  varchar UNIQUE,
+                                   "expires_at"  timestamptz NOT NULL,
+                                   "used_at"     timestamptz,
+                                   "created_at"  timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "password_resets" ADD FOREIGN KEY ("customer_id") REFERENCES "customers" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "password_resets" ("customer_id");
+
+COMMENT ON TABLE "password_resets" IS 'requests to choose a password, also how customers from before credentials get their first one';
+COMMENT ON COLUMN "password_resets"."token_hash" IS 'SHA-256 of the token last emailed, set when the email is sent; the token itself is never stored';
+
+-- +goose Down
+DROP TABLE IF EXISTS password_resets;
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE "email_deliveries" (
+                                    "id"              bigserial PRIMARY KEY,
+                                    "outbox_event_id" bigint      NOT NULL,
+                                    "kind"            varchar     NOT NULL,
+                                    "customer_id"     bigint      NOT NULL,
+                                    "account_id"      bigint,
+                                    "payload"         jsonb       NOT NULL,
+                                    "status"          varchar     NOT NULL DEFAULT 'pending',
+                                    "attempts"        int         NOT NULL DEFAULT 0,
+                                    "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+                                    "last_error"      varchar,
+                                    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+                                    "sent_at"         timestamptz,
+                                    UNIQUE ("outbox_event_id", "kind", "customer_id")
+);
+
+ALTER TABLE "email_deliveries" ADD FOREIGN KEY ("outbox_event_id") REFERENCES "outbox_events" ("id");
+ALTER TABLE "email_deliveries" ADD FOREIGN KEY ("customer_id") REFERENCES "customers" ("id") ON DELETE CASCADE;
+ALTER TABLE "email_deliveries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+-- like the outbox relay, the email worker only ever looks for pending deliveries that are due
+CREATE INDEX ON "email_deliveries" ("next_attempt_at") WHERE "status" = 'pending';
+CREATE INDEX ON "email_deliveries" ("status", "id");
+
+COMMENT ON TABLE "email_deliveries" IS 'a message per customer an outbox event is about, sent apart from the relay so a mail server that is down holds up nothing else';
+COMMENT ON COLUMN "email_deliveries"."kind" IS 'the kind of message, e.g. transfer_received';
+COMMENT ON COLUMN "email_deliveries"."account_id" IS 'the account the message is about, null for messages about the customer';
+COMMENT ON COLUMN "email_deliveries"."payload" IS 'the payload of the event, the message is rendered from it when it is sent';
+COMMENT ON COLUMN "email_deliveries"."status" IS 'pending, sent, skipped when there was nothing to send anymore, or dead once it ran out of attempts';
+
+-- +goose Down
+DROP TABLE IF EXISTS email_deliveries;
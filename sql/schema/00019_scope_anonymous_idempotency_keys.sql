@@ -0,0 +1,7 @@
+-- +goose Up
+-- keys of signups used to share one anonymous scope, where strangers picking the same key got
+-- each other's answers; the keys left in it are never matched again and age out as usual
+COMMENT ON COLUMN "idempotency_keys"."scope" IS 'whose key it is: customer:<id>, or anonymous:<hash of client address and email> for signups';
+
+-- +goose Down
+COMMENT ON COLUMN "idempotency_keys"."scope" IS 'whose key it is: customer:<id>, or anonymous for signups';
@@ -0,0 +1,23 @@
+-- +goose Up
+-- a customer is referred once, whichever of their accounts redeems the code
+ALTER TABLE "referral_history" ADD COLUMN "referred_customer_id" bigint;
+ALTER TABLE "referral_history" ADD FOREIGN KEY ("referred_customer_id") REFERENCES "customers" ("id");
+
+-- referrals from before keep their history, the first one of a customer counts as theirs
+UPDATE "referral_history" h
+SET "referred_customer_id" = a."customer_id"
+FROM "accounts" a
+WHERE a."id" = h."referred_account_id"
+  AND h."id" = (
+    SELECT min(h2."id")
+    FROM "referral_history" h2
+    JOIN "accounts" a2 ON a2."id" = h2."referred_account_id"
+    WHERE a2."customer_id" = a."customer_id"
+  );
+
+ALTER TABLE "referral_history" ADD CONSTRAINT "referral_history_referred_customer_id_key" UNIQUE ("referred_customer_id");
+
+COMMENT ON COLUMN "referral_history"."referred_customer_id" IS 'the customer holding the referred account, who may be referred only once';
+
+-- +goose Down
+ALTER TABLE "referral_history" DROP COLUMN IF EXISTS "referred_customer_id";
@@ -0,0 +1,14 @@
+-- +goose Up
+-- a referral earns the referrer extra interest once, when the code is redeemed or, for those
+-- not applied then, in the monthly run of its referral period
+ALTER TABLE "referral_history" ADD COLUMN "extra_interest_applied_at" timestamptz;
+
+-- the referrals so far were counted already, by their redemption or the monthly run
+UPDATE "referral_history" SET "extra_interest_applied_at" = "created_at";
+
+CREATE INDEX ON "referral_history" ("referral_date") WHERE "extra_interest_applied_at" IS NULL;
+
+COMMENT ON COLUMN "referral_history"."extra_interest_applied_at" IS 'when the extra interest of the referral was added to the referrer, NULL until the monthly run does it';
+
+-- +goose Down
+ALTER TABLE "referral_history" DROP COLUMN IF EXISTS "extra_interest_applied_at";
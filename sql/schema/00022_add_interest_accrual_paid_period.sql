@@ -0,0 +1,17 @@
+-- +goose Up
+-- an accrual is paid once, by the payout of its month or, when it was recorded after that
+-- payout, by the next one
+ALTER TABLE "interest_accruals" ADD COLUMN "paid_period" varchar;
+
+-- the accruals so far were paid by the payout of their month, where there was one
+UPDATE "interest_accruals" SET "paid_period" = "interest_payouts"."period"
+FROM "interest_payouts"
+WHERE "interest_payouts"."account_id" = "interest_accruals"."account_id"
+  AND "interest_payouts"."period" = to_char("interest_accruals"."accrual_date", 'YYYY-MM');
+
+CREATE INDEX ON "interest_accruals" ("account_id", "accrual_date") WHERE "paid_period" IS NULL;
+
+COMMENT ON COLUMN "interest_accruals"."paid_period" IS 'the period of the payout that paid the accrual, NULL until one does';
+
+-- +goose Down
+ALTER TABLE "interest_accruals" DROP COLUMN IF EXISTS "paid_period";
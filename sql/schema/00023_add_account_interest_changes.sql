@@ -0,0 +1,20 @@
+-- +goose Up
+-- the extra interest terms an account had before each change, so a day accrued late still earns
+-- the rate in effect on it
+CREATE TABLE "account_interest_changes" (
+                                            "id"                        bigserial PRIMARY KEY,
+                                            "account_id"                bigint      NOT NULL,
+                                            "extra_interest"            float,
+                                            "extra_interest_start_date" date,
+                                            "extra_interest_duration"   int         NOT NULL,
+                                            "changed_at"                timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_interest_changes" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "account_interest_changes" ("account_id", "changed_at");
+
+COMMENT ON TABLE "account_interest_changes" IS 'the extra interest terms an account had until changed_at';
+
+-- +goose Down
+DROP TABLE IF EXISTS account_interest_changes;
@@ -0,0 +1,41 @@
+// Package schema holds the goose migrations of the database, embedded so the server knows
+// which version the database it runs against should be at.
+package schema
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// Migrations are the migration files, named <version>_<name>.sql
+//
+//go:embed *.sql
+var Migrations embed.FS
+
+// VersionTable is where goose records the migrations it applied
+const VersionTable = "goose_db_version"
+
+// LatestVersion is the version of the last migration, the one an up to date database is at
+func LatestVersion() (int64, error) {
+	entries, err := fs.ReadDir(Migrations, ".")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		prefix, _, found := strings.Cut(entry.Name(), "_")
+		if !found {
+			continue
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
@@ -0,0 +1,18 @@
+package schema
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	version, err := LatestVersion()
+	require.NoError(t, err)
+
+	// the migrations are numbered one after the other
+	files, err := fs.Glob(Migrations, "*.sql")
+	require.NoError(t, err)
+	require.Equal(t, int64(len(files)), version)
+}
@@ -0,0 +1,85 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const minSecretKeySize = 32
+
+// jwtHeader is the only header JWTMaker issues and accepts. Pinning the algorithm means a token
+// claiming "none" or an asymmetric algorithm is rejected before its signature is even looked at.
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// JWTMaker is a JSON Web Token maker signing tokens with HMAC-SHA256
+type JWTMaker struct {
+	secretKey []byte
+}
+
+// NewJWTMaker creates a new JWTMaker
+func NewJWTMaker(secretKey string) (Maker, error) {
+	if len(secretKey) < minSecretKeySize {
+		return nil, fmt.Errorf("invalid key size: must be at least %d characters", minSecretKeySize)
+	}
+	return &JWTMaker{secretKey: []byte(secretKey)}, nil
+}
+
+// CreateToken creates a new token for a specific customer, role and duration
+func (maker *JWTMaker) CreateToken(customerID int64, email string, role string, tokenType TokenType, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(customerID, email, role, tokenType, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	claims, err := json.Marshal(payload)
+	if err != nil {
+		return "", payload, err
+	}
+
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return unsigned + "." + maker.sign(unsigned), payload, nil
+}
+
+// VerifyToken checks if the token is valid and of the expected type
+func (maker *JWTMaker) VerifyToken(token string, tokenType TokenType) (*Payload, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, ErrInvalidToken
+	}
+
+	unsigned := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(maker.sign(unsigned))) {
+		return nil, ErrInvalidToken
+	}
+
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	payload := &Payload{}
+	if err := json.Unmarshal(claims, payload); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := payload.Valid(tokenType); err != nil {
+		if errors.Is(err, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
+
+func (maker *JWTMaker) sign(unsigned string) string {
+	mac := hmac.New(sha256.New, maker.secretKey)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,100 @@
+package token
+
+import (
+	"bank-api/util"
+	"encoding/base64"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJWTMaker(t *testing.T) {
+	maker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	customerID := util.RandomInt(1, 1000)
+	email := util.RandomEmail()
+	role := util.DepositorRole
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(customerID, email, role, TokenTypeAccessToken, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token, TokenTypeAccessToken)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, customerID, payload.CustomerID)
+	require.Equal(t, email, payload.Email)
+	require.Equal(t, role, payload.Role)
+	require.Equal(t, TokenTypeAccessToken, payload.Type)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestExpiredJWTToken(t *testing.T) {
+	maker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(util.RandomInt(1, 1000), util.RandomEmail(), util.DepositorRole, TokenTypeAccessToken, -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token, TokenTypeAccessToken)
+	require.ErrorIs(t, err, ErrExpiredToken)
+	require.Nil(t, payload)
+}
+
+func TestJWTTokenWrongType(t *testing.T) {
+	maker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomInt(1, 1000), util.RandomEmail(), util.DepositorRole, TokenTypeRefreshToken, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token, TokenTypeAccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	require.Nil(t, payload)
+}
+
+func TestInvalidJWTTokenAlgNone(t *testing.T) {
+	maker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomInt(1, 1000), util.RandomEmail(), util.DepositorRole, TokenTypeAccessToken, time.Minute)
+	require.NoError(t, err)
+
+	// swap the header for an unsigned one and drop the signature
+	parts := strings.Split(token, ".")
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	payload, err := maker.VerifyToken(none+"."+parts[1]+".", TokenTypeAccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	require.Nil(t, payload)
+}
+
+func TestInvalidJWTTokenSignature(t *testing.T) {
+	maker1, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+	maker2, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, _, err := maker1.CreateToken(util.RandomInt(1, 1000), util.RandomEmail(), util.DepositorRole, TokenTypeAccessToken, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker2.VerifyToken(token, TokenTypeAccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	require.Nil(t, payload)
+}
+
+func TestNewJWTMakerShortKey(t *testing.T) {
+	_, err := NewJWTMaker(util.RandomString(minSecretKeySize - 1))
+	require.Error(t, err)
+}
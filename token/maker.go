@@ -0,0 +1,14 @@
+package token
+
+import (
+	"time"
+)
+
+// Maker is an interface for managing tokens
+type Maker interface {
+	// CreateToken creates a new token for a specific customer, role and duration
+	CreateToken(customerID int64, email string, role string, tokenType TokenType, duration time.Duration) (string, *Payload, error)
+
+	// VerifyToken checks if the token is valid and of the expected type
+	VerifyToken(token string, tokenType TokenType) (*Payload, error)
+}
@@ -0,0 +1,62 @@
+package token
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Different types of error returned by the VerifyToken function
+var (
+	ErrInvalidToken = errors.New("token is invalid")
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+// TokenType tells access tokens and refresh tokens apart, so one cannot be used in place of the other
+type TokenType string
+
+const (
+	TokenTypeAccessToken  TokenType = "access"
+	TokenTypeRefreshToken TokenType = "refresh"
+)
+
+// Payload contains the payload data of the token
+type Payload struct {
+	ID         uuid.UUID `json:"id"`
+	CustomerID int64     `json:"customer_id"`
+	Email      string    `json:"email"`
+	Role       string    `json:"role"`
+	Type       TokenType `json:"token_type"`
+	IssuedAt   time.Time `json:"issued_at"`
+	ExpiredAt  time.Time `json:"expired_at"`
+}
+
+// NewPayload creates a new token payload for a specific customer and duration
+func NewPayload(customerID int64, email string, role string, tokenType TokenType, duration time.Duration) (*Payload, error) {
+	tokenID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &Payload{
+		ID:         tokenID,
+		CustomerID: customerID,
+		Email:      email,
+		Role:       role,
+		Type:       tokenType,
+		IssuedAt:   time.Now(),
+		ExpiredAt:  time.Now().Add(duration),
+	}
+	return payload, nil
+}
+
+// Valid checks if the token payload is of the expected type and not expired yet
+func (payload *Payload) Valid(tokenType TokenType) error {
+	if payload.Type != tokenType {
+		return ErrInvalidToken
+	}
+	if time.Now().After(payload.ExpiredAt) {
+		return ErrExpiredToken
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package util
+
+// Account types. Customers own regular accounts; the bank itself holds one system account of
+// each other type per currency, which its own money movements are booked against.
+const (
+	CustomerAccount   = "customer"
+	BonusAccount      = "bonus"
+	InterestAccount   = "interest"
+	AdjustmentAccount = "adjustment"
+	// the bank's position in a currency, money converted between currencies passes through it
+	FXAccount = "fx"
+)
@@ -0,0 +1,88 @@
+package util
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency accounts can be held in. Amounts are always kept in the minor
+// unit of the currency, Exponent is how many digits of an amount are behind the decimal point:
+// 2 for cents, 0 for the yen which has no minor unit.
+type Currency struct {
+	Code     string `json:"code"`
+	Exponent int    `json:"exponent"`
+	Symbol   string `json:"symbol"`
+}
+
+// Supported currency codes
+const (
+	EUR = "EUR"
+	INR = "INR"
+	JPY = "JPY"
+	USD = "USD"
+)
+
+var currencies = map[string]Currency{
+	EUR: {Code: EUR, Exponent: 2, Symbol: "€"},
+	INR: {Code: INR, Exponent: 2, Symbol: "₹"},
+	JPY: {Code: JPY, Exponent: 0, Symbol: "¥"},
+	USD: {Code: USD, Exponent: 2, Symbol: "$"},
+}
+
+// LookupCurrency returns the registered currency of an ISO 4217 code
+func LookupCurrency(code string) (Currency, bool) {
+	currency, ok := currencies[code]
+	return currency, ok
+}
+
+// IsSupportedCurrency tells whether accounts can be held in the currency
+func IsSupportedCurrency(code string) bool {
+	_, ok := currencies[code]
+	return ok
+}
+
+// SupportedCurrencies returns the codes of all supported currencies, sorted
+func SupportedCurrencies() []string {
+	codes := make([]string, 0, len(currencies))
+	for code := range currencies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Format renders an amount in the minor unit for display, e.g. 123456 EUR as €1,234.56
+func (currency Currency) Format(amount int64) string {
+	var sb strings.Builder
+	if amount < 0 {
+		sb.WriteByte('-')
+	}
+	sb.WriteString(currency.Symbol)
+
+	digits := strconv.FormatUint(absUint(amount), 10)
+	if len(digits) <= currency.Exponent {
+		digits = strings.Repeat("0", currency.Exponent-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-currency.Exponent], digits[len(digits)-currency.Exponent:]
+
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(digit)
+	}
+	if fraction != "" {
+		sb.WriteByte('.')
+		sb.WriteString(fraction)
+	}
+	return sb.String()
+}
+
+// absUint returns the magnitude of n, which doesn't overflow for the lowest int64
+func absUint(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1
+	}
+	return uint64(n)
+}
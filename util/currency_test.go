@@ -0,0 +1,49 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestLookupCurrency(t *testing.T) {
+	currency, ok := LookupCurrency(JPY)
+	require.True(t, ok)
+	require.Equal(t, 0, currency.Exponent)
+
+	currency, ok = LookupCurrency(EUR)
+	require.True(t, ok)
+	require.Equal(t, 2, currency.Exponent)
+
+	_, ok = LookupCurrency("YEN")
+	require.False(t, ok)
+	require.False(t, IsSupportedCurrency("eur"))
+
+	require.Equal(t, []string{EUR, INR, JPY, USD}, SupportedCurrencies())
+	require.True(t, IsSupportedCurrency(RandomCurrency()))
+}
+
+func TestCurrencyFormat(t *testing.T) {
+	jpy, _ := LookupCurrency(JPY)
+	eur, _ := LookupCurrency(EUR)
+	usd, _ := LookupCurrency(USD)
+
+	testCases := []struct {
+		currency Currency
+		amount   int64
+		want     string
+	}{
+		{jpy, 0, "¥0"},
+		{jpy, 1000, "¥1,000"},
+		{jpy, -1234567, "-¥1,234,567"},
+		{eur, 5, "€0.05"},
+		{eur, 123456, "€1,234.56"},
+		{usd, -100, "-$1.00"},
+		{usd, 99999999, "$999,999.99"},
+		{usd, math.MinInt64, "-$92,233,720,368,547,758.08"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.want, tc.currency.Format(tc.amount))
+	}
+}
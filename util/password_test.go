@@ -0,0 +1,40 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+)
+
+func TestPassword(t *testing.T) {
+	password := RandomString(8)
+
+	hashedPassword1, err := HashPassword(password)
+	require.NoError(t, err)
+	require.NotEmpty(t, hashedPassword1)
+
+	err = CheckPassword(password, hashedPassword1)
+	require.NoError(t, err)
+
+	wrongPassword := RandomString(8)
+	err = CheckPassword(wrongPassword, hashedPassword1)
+	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+
+	// the same password never hashes to the same value twice
+	hashedPassword2, err := HashPassword(password)
+	require.NoError(t, err)
+	require.NotEqual(t, hashedPassword1, hashedPassword2)
+}
+
+func TestResetToken(t *testing.T) {
+	token1, hash1, err := NewResetToken()
+	require.NoError(t, err)
+	require.Len(t, token1, 64)
+	require.Equal(t, hash1, HashResetToken(token1))
+	require.NotEqual(t, token1, hash1)
+
+	token2, hash2, err := NewResetToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token1, token2)
+	require.NotEqual(t, hash1, hash2)
+}
@@ -38,7 +38,7 @@ func RandomMoney() int64 {
 }
 
 func RandomCurrency() string {
-	currencies := []string{"EUR", "INR", "YEN"}
+	currencies := SupportedCurrencies()
 	n := len(currencies)
 	return currencies[rand.Intn(n)]
 }
@@ -0,0 +1,8 @@
+package util
+
+// Roles an authenticated caller can have. Depositors may only act on their own accounts,
+// admins may act on any account.
+const (
+	DepositorRole = "depositor"
+	AdminRole     = "admin"
+)
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"bank-api/db/sqlc"
+	"context"
+	"encoding/json"
+)
+
+// Dispatcher is an outbox sink that records a delivery of every event for each subscription it
+// goes to. It sends nothing itself, that is up to the Worker.
+type Dispatcher struct {
+	store sqlc.Querier
+}
+
+func NewDispatcher(store sqlc.Querier) *Dispatcher {
+	return &Dispatcher{store: store}
+}
+
+// Deliver records the deliveries of the event. A delivery already recorded for a subscription
+// is left alone, so the relay may hand over the same event again.
+func (dispatcher *Dispatcher) Deliver(ctx context.Context, event sqlc.OutboxEvent) error {
+	// events only the bank handles itself, like password resets, are not for subscribers, not
+	// even those to every event
+	if !IsEventType(event.EventType) {
+		return nil
+	}
+
+	accounts, err := accountsOf(event)
+	if err != nil {
+		return err
+	}
+
+	subscriptions, err := dispatcher.store.ListWebhookSubscriptionsForEvent(ctx, sqlc.ListWebhookSubscriptionsForEventParams{
+		AccountIds: accounts,
+		EventType:  event.EventType,
+	})
+	if err != nil || len(subscriptions) == 0 {
+		return err
+	}
+
+	body, err := json.Marshal(Payload{
+		ID:        event.ID,
+		Type:      event.EventType,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		err := dispatcher.store.CreateWebhookDelivery(ctx, sqlc.CreateWebhookDeliveryParams{
+			SubscriptionID: subscription.ID,
+			OutboxEventID:  event.ID,
+			EventType:      event.EventType,
+			Payload:        body,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package webhook
+
+import (
+	"bank-api/db/sqlc"
+	"time"
+)
+
+// NewTestWorker returns a worker whose clock stands still at the time it was created, deliveries
+// recorded before are due. The worker tests live outside the package, as webhooktest imports it.
+func NewTestWorker(store sqlc.Store, config Config) (*Worker, *time.Time) {
+	worker := NewWorker(store, config)
+	now := time.Now()
+	worker.now = func() time.Time { return now }
+	return worker, &now
+}
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned for a webhook URL that points into a private network. The
+// worker posts from inside the deployment, so without this check a subscription could make it
+// call the database, the metadata service of the cloud or anything else only it can reach.
+var ErrForbiddenAddress = errors.New("webhook URL points to a forbidden address")
+
+// forbiddenPrefixes are ranges netip.Addr has no predicate for
+var forbiddenPrefixes = []netip.Prefix{
+	// "this network", 0.0.0.0 reaches the host itself on Linux
+	netip.MustParsePrefix("0.0.0.0/8"),
+	// carrier-grade NAT, used for internal addresses by some clouds
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// forbidden tells whether an address is one webhooks must not be sent to: loopback, private,
+// link-local, unspecified or multicast
+func forbidden(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return true
+	}
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckURL checks that a webhook URL is http or https and that every address its host resolves
+// to may be called. It is meant for when a subscription is made; the worker checks the address
+// it actually connects to on every send, as the name may resolve differently by then.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("webhook URL must be http or https, not %q", u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return errors.New("webhook URL has no host")
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if forbidden(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, host, addr)
+		}
+	}
+	return nil
+}
+
+// checkDial refuses connections to forbidden addresses. It runs after the name was resolved,
+// on the address the connection goes to, so a name that resolves to a public address when the
+// subscription is made and to a private one later does not get through.
+func checkDial(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if forbidden(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// newClient is the client deliveries are posted with. It does not follow redirects, a receiver
+// could otherwise send the worker on to any URL, and it connects directly rather than through the
+// proxy of the environment, so the address checked is the one connected to.
+func newClient(config Config) *http.Client {
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	if !config.AllowPrivateNetworks {
+		dialer.Control = checkDial
+	}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: config.Timeout,
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,152 @@
+// Package webhook pushes domain events to the URLs partners and frontends subscribed, so they
+// need not poll the API. A subscription is either for one account, receiving the events about
+// that account, or for every account; either way it may be limited to some event types.
+//
+// Delivery takes two steps. The Dispatcher, plugged into the outbox relay as a sink, records a
+// delivery for every subscription an event goes to. The Worker then posts the deliveries to their
+// URLs and retries the failed ones with exponential backoff, each on its own, so one receiver
+// that is down holds up no one else. Every attempt ends up in the delivery log of the subscription.
+//
+// Every request is signed, see Sign; receivers check it with Verify. Delivery is at least once:
+// a receiver may get a delivery twice and tells duplicates apart by the Webhook-Id header.
+package webhook
+
+import (
+	"bank-api/db/sqlc"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Headers of a delivery
+const (
+	// HeaderID is the ID of the delivery, the same for every attempt
+	HeaderID = "Webhook-Id"
+	// HeaderEvent is the type of the event delivered
+	HeaderEvent = "Webhook-Event"
+	// HeaderTimestamp is the Unix time the attempt was signed at
+	HeaderTimestamp = "Webhook-Timestamp"
+	// HeaderSignature is the signature of the attempt, see Sign
+	HeaderSignature = "Webhook-Signature"
+)
+
+// signatureVersion prefixes the signature, so the scheme can change without breaking receivers
+const signatureVersion = "v1"
+
+// DefaultTolerance is how old a signed request Verify accepts by default
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrStaleTimestamp is returned by Verify for a request signed too long ago, or too far
+	// ahead, which might be a replay of an old request
+	ErrStaleTimestamp = errors.New("webhook timestamp out of tolerance")
+)
+
+// Payload is the JSON body of a delivery
+type Payload struct {
+	// ID of the event, the same in the deliveries of all subscriptions
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Sign signs a request body with the secret of a subscription: the hex encoded HMAC-SHA256 of
+// the timestamp, a dot and the body, prefixed by the version of the scheme, e.g. v1=5257a8...
+// Signing the timestamp along with the body keeps a captured request from being replayed later.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a request received at now against the secret of the
+// subscription, and that it was signed no more than tolerance ago.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	signature := header.Get(HeaderSignature)
+	timestamp := header.Get(HeaderTimestamp)
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: timestamp %q", ErrInvalidSignature, timestamp)
+	}
+	if age := now.Sub(time.Unix(signedAt, 0)); age > tolerance || age < -tolerance {
+		return ErrStaleTimestamp
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, signedAt, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// NewSecret generates a secret for a subscription that did not bring its own
+func NewSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(key), nil
+}
+
+// IsEventType tells whether a subscription may ask for the event type
+func IsEventType(eventType string) bool {
+	return slices.Contains(sqlc.EventTypes, eventType)
+}
+
+// accountsOf returns the accounts an event is about, the account subscriptions it goes to
+func accountsOf(event sqlc.OutboxEvent) ([]int64, error) {
+	switch event.EventType {
+	case sqlc.EventTransferCompleted:
+		var payload sqlc.TransferCompletedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, err
+		}
+		return []int64{payload.FromAccountID, payload.ToAccountID}, nil
+	case sqlc.EventReferralRedeemed:
+		var payload sqlc.ReferralRedeemedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, err
+		}
+		return []int64{payload.ReferrerAccountID, payload.ReferredAccountID}, nil
+	}
+
+	// every other event is about the account it was published for
+	if event.AggregateType == sqlc.AggregateAccount {
+		return []int64{event.AggregateID}, nil
+	}
+	return []int64{}, nil
+}
+
+// describe renders a failed attempt for the delivery log. The log is shown to the subscriber, so
+// it tells no more than the status of the answer or the kind of failure: neither the body of the
+// answer nor the network error, which could tell about the network the worker runs in.
+func describe(status int, err error) string {
+	if status != 0 {
+		return fmt.Sprintf("receiver answered %d %s", status, http.StatusText(status))
+	}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrForbiddenAddress):
+		return "receiver address is not allowed"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "receiver did not answer in time"
+	}
+	return "receiver could not be reached"
+}
@@ -0,0 +1,214 @@
+package webhook
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/util"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{"id":1,"type":"account.created"}`)
+	now := time.Unix(1_700_000_000, 0)
+
+	signature := Sign(secret, now.Unix(), body)
+	require.Regexp(t, "^v1=[0-9a-f]{64}$", signature)
+	// the same input signs the same
+	require.Equal(t, signature, Sign(secret, now.Unix(), body))
+
+	header := http.Header{}
+	header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	header.Set(HeaderSignature, signature)
+
+	testCases := []struct {
+		name   string
+		secret string
+		body   []byte
+		header func() http.Header
+		now    time.Time
+		err    error
+	}{
+		{name: "OK", secret: secret, body: body, header: header.Clone, now: now.Add(time.Minute)},
+		{name: "WrongSecret", secret: "whsec_other", body: body, header: header.Clone, now: now, err: ErrInvalidSignature},
+		{name: "TamperedBody", secret: secret, body: []byte(`{"id":2,"type":"account.created"}`), header: header.Clone, now: now, err: ErrInvalidSignature},
+		{name: "Replayed", secret: secret, body: body, header: header.Clone, now: now.Add(DefaultTolerance + time.Second), err: ErrStaleTimestamp},
+		{
+			name: "TimestampChanged", secret: secret, body: body, now: now,
+			header: func() http.Header {
+				changed := header.Clone()
+				changed.Set(HeaderTimestamp, strconv.FormatInt(now.Unix()+1, 10))
+				return changed
+			},
+			err: ErrInvalidSignature,
+		},
+		{
+			name: "Unsigned", secret: secret, body: body, now: now,
+			header: func() http.Header { return http.Header{} },
+			err:    ErrMissingSignature,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify(tc.secret, tc.header(), tc.body, DefaultTolerance, tc.now)
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func createCustomerAccount(t *testing.T, store sqlc.Store, balance int64) sqlc.Account {
+	result, err := store.CreateAccountTx(context.Background(), sqlc.CreateAccountTxParams{
+		CreateAccountParams: sqlc.CreateAccountParams{
+			Owner:     util.RandomOwner(),
+			Email:     util.RandomEmail(),
+			Balance:   balance,
+			Currency:  util.JPY,
+			CreatedAt: time.Now(),
+		},
+		HashedPassword: "secret",
+	})
+	require.NoError(t, err)
+	return result.Account
+}
+
+func subscribe(t *testing.T, store sqlc.Store, account *sqlc.Account, url string, eventTypes ...string) sqlc.WebhookSubscription {
+	var accountID sql.NullInt64
+	if account != nil {
+		accountID = sql.NullInt64{Int64: account.ID, Valid: true}
+	}
+	subscription, err := store.CreateWebhookSubscription(context.Background(), sqlc.CreateWebhookSubscriptionParams{
+		AccountID:  accountID,
+		Url:        url,
+		EventTypes: eventTypes,
+		Secret:     "whsec_" + util.RandomString(16),
+	})
+	require.NoError(t, err)
+	return subscription
+}
+
+func listDeliveries(t *testing.T, store sqlc.Store, subscription sqlc.WebhookSubscription) []sqlc.WebhookDelivery {
+	deliveries, err := store.ListWebhookDeliveries(context.Background(), sqlc.ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          100,
+	})
+	require.NoError(t, err)
+	return deliveries
+}
+
+// dispatchAll hands every pending event to the dispatcher, like the outbox relay does
+func dispatchAll(t *testing.T, store sqlc.Store) {
+	events, err := store.ListOutboxEvents(context.Background(), sqlc.ListOutboxEventsParams{Status: "pending", Limit: 1000})
+	require.NoError(t, err)
+	dispatcher := NewDispatcher(store)
+	for _, event := range events {
+		require.NoError(t, dispatcher.Deliver(context.Background(), event))
+		require.NoError(t, store.MarkOutboxEventDelivered(context.Background(), sqlc.MarkOutboxEventDeliveredParams{ID: event.ID}))
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	from := createCustomerAccount(t, store, 1000)
+	to := createCustomerAccount(t, store, 0)
+	bystander := createCustomerAccount(t, store, 0)
+	dispatchAll(t, store)
+
+	everything := subscribe(t, store, nil, "https://partner.example/all")
+	transfersOnly := subscribe(t, store, nil, "https://partner.example/transfers", sqlc.EventTransferCompleted)
+	recipient := subscribe(t, store, &to, "https://app.example/hooks", sqlc.EventBalanceChanged)
+	other := subscribe(t, store, &bystander, "https://app.example/hooks")
+
+	transfer, err := store.TransferTx(context.Background(), sqlc.TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        300,
+	})
+	require.NoError(t, err)
+	dispatchAll(t, store)
+
+	// a balance change of either account and the transfer itself
+	require.Len(t, listDeliveries(t, store, everything), 3)
+	require.Len(t, listDeliveries(t, store, other), 0)
+
+	deliveries := listDeliveries(t, store, transfersOnly)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, sqlc.EventTransferCompleted, deliveries[0].EventType)
+	require.Equal(t, "pending", deliveries[0].Status)
+
+	deliveries = listDeliveries(t, store, recipient)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, sqlc.EventBalanceChanged, deliveries[0].EventType)
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(deliveries[0].Payload, &payload))
+	require.Equal(t, deliveries[0].OutboxEventID, payload.ID)
+	require.Equal(t, sqlc.EventBalanceChanged, payload.Type)
+
+	var changed sqlc.BalanceChangedEvent
+	require.NoError(t, json.Unmarshal(payload.Data, &changed))
+	require.Equal(t, to.ID, changed.AccountID)
+	require.Equal(t, int64(300), changed.Amount)
+	require.Equal(t, transfer.ToAccount.Balance, changed.Balance)
+
+	// the relay handing an event over again records no second delivery
+	require.NoError(t, NewDispatcher(store).Deliver(context.Background(), sqlc.OutboxEvent{
+		ID:            deliveries[0].OutboxEventID,
+		EventType:     sqlc.EventBalanceChanged,
+		AggregateType: sqlc.AggregateAccount,
+		AggregateID:   to.ID,
+		Payload:       payload.Data,
+		CreatedAt:     payload.CreatedAt,
+	}))
+	require.Len(t, listDeliveries(t, store, recipient), 1)
+
+	// password resets are between the bank and the customer, not even a subscription to
+	// everything gets them
+	before := len(listDeliveries(t, store, everything))
+	_, err = store.RequestPasswordResetTx(context.Background(), sqlc.RequestPasswordResetTxParams{
+		CustomerID: to.CustomerID.Int64,
+		ExpiresAt:  time.Now().Add(time.Hour),
+		CreatedAt:  time.Now(),
+	})
+	require.NoError(t, err)
+	dispatchAll(t, store)
+	require.Len(t, listDeliveries(t, store, everything), before)
+}
+
+func TestCheckURL(t *testing.T) {
+	for _, rawURL := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://10.0.0.5/hook",
+		"http://172.16.3.4/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://0.0.0.0:5432",
+		"http://[::1]/hook",
+		"http://[fd00::1]/hook",
+		"http://[::ffff:10.0.0.1]/hook",
+		"http://100.64.0.1/hook",
+	} {
+		require.ErrorIs(t, CheckURL(context.Background(), rawURL), ErrForbiddenAddress, rawURL)
+	}
+
+	require.NoError(t, CheckURL(context.Background(), "https://93.184.215.14/hooks"))
+	require.NoError(t, CheckURL(context.Background(), "https://[2606:2800:21f:cb07:6820:80da:af6b:8b2c]/hooks"))
+	require.ErrorContains(t, CheckURL(context.Background(), "ftp://93.184.215.14/hooks"), "http or https")
+}
+
+func TestCheckDial(t *testing.T) {
+	require.ErrorIs(t, checkDial("tcp4", "10.1.2.3:443", nil), ErrForbiddenAddress)
+	require.ErrorIs(t, checkDial("tcp6", "[fe80::1]:443", nil), ErrForbiddenAddress)
+	require.NoError(t, checkDial("tcp4", "93.184.215.14:443", nil))
+}
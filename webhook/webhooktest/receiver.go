@@ -0,0 +1,103 @@
+// Package webhooktest provides a webhook receiver for tests, so deliveries can be checked end to
+// end without a partner on the other side.
+package webhooktest
+
+import (
+	"bank-api/webhook"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// Delivery is a request the receiver accepted
+type Delivery struct {
+	ID      string
+	Event   string
+	Header  http.Header
+	Body    []byte
+	Payload webhook.Payload
+}
+
+// Receiver is a webhook endpoint on an httptest.Server. It checks the signature of every request
+// against its secret, answering 401 to the ones that fail, and keeps the deliveries it accepted.
+// It can be told to fail requests, to see the worker retry.
+type Receiver struct {
+	*httptest.Server
+	secret string
+
+	mu         sync.Mutex
+	deliveries []Delivery
+	rejected   int
+	failures   int
+	failStatus int
+}
+
+// NewReceiver starts a receiver checking signatures against secret. Close it when done.
+func NewReceiver(secret string) *Receiver {
+	receiver := &Receiver{secret: secret}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(receiver.serveHTTP))
+	return receiver
+}
+
+// FailNext makes the receiver answer the next n requests with status instead of accepting them
+func (receiver *Receiver) FailNext(n int, status int) {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	receiver.failures = n
+	receiver.failStatus = status
+}
+
+// Deliveries returns the deliveries accepted so far, in the order they came in
+func (receiver *Receiver) Deliveries() []Delivery {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	return append([]Delivery(nil), receiver.deliveries...)
+}
+
+// Rejected returns how many requests failed the signature check
+func (receiver *Receiver) Rejected() int {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	return receiver.rejected
+}
+
+func (receiver *Receiver) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+
+	if err := webhook.Verify(receiver.secret, r.Header, body, webhook.DefaultTolerance, time.Now()); err != nil {
+		receiver.rejected++
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if receiver.failures > 0 {
+		receiver.failures--
+		http.Error(w, "failing on purpose", receiver.failStatus)
+		return
+	}
+
+	var payload webhook.Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	receiver.deliveries = append(receiver.deliveries, Delivery{
+		ID:      r.Header.Get(webhook.HeaderID),
+		Event:   r.Header.Get(webhook.HeaderEvent),
+		Header:  r.Header.Clone(),
+		Body:    body,
+		Payload: payload,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
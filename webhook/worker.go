@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/outbox"
+	"bytes"
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultBatchSize    = 20
+	DefaultPollInterval = time.Second
+	DefaultMaxAttempts  = 10
+	DefaultBaseBackoff  = 10 * time.Second
+	DefaultMaxBackoff   = 6 * time.Hour
+	// DefaultTimeout is how long a receiver gets to answer
+	DefaultTimeout = 10 * time.Second
+	// DefaultLease is how long claimed deliveries are kept from other workers, long enough for a
+	// whole batch of receivers that time out
+	DefaultLease = 5 * time.Minute
+)
+
+// Config tunes a worker, zero fields take their default
+type Config struct {
+	BatchSize    int32
+	PollInterval time.Duration
+	MaxAttempts  int32
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+	Lease        time.Duration
+	// AllowPrivateNetworks lets deliveries go to loopback and private addresses, for receivers
+	// running next to the API in development and tests
+	AllowPrivateNetworks bool
+}
+
+func (config Config) withDefaults() Config {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = DefaultBaseBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Lease <= 0 {
+		config.Lease = DefaultLease
+	}
+	return config
+}
+
+// Worker posts the recorded deliveries to the URLs of their subscriptions. Like outbox relays,
+// several workers may run against the same database.
+type Worker struct {
+	store  sqlc.Store
+	client *http.Client
+	config Config
+	// now is swapped for a fake clock in tests
+	now func() time.Time
+}
+
+func NewWorker(store sqlc.Store, config Config) *Worker {
+	config = config.withDefaults()
+	return &Worker{
+		store:  store,
+		client: newClient(config),
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Run sends deliveries until ctx is done, polling like outbox.Relay.Run
+func (worker *Worker) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		claimed, err := worker.SendBatch(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("webhook worker: %v", err)
+		}
+
+		wait := worker.config.PollInterval
+		if err == nil && claimed == int(worker.config.BatchSize) {
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+// SendBatch claims the due deliveries and sends them one by one, oldest first. It returns how
+// many deliveries it claimed; a receiver that fails is retried later, not an error.
+func (worker *Worker) SendBatch(ctx context.Context) (int, error) {
+	now := worker.now()
+	deliveries, err := worker.store.ClaimWebhookDeliveries(ctx, sqlc.ClaimWebhookDeliveriesParams{
+		LeasedUntil: now.Add(worker.config.Lease),
+		DueAt:       now,
+		BatchSize:   worker.config.BatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	slices.SortFunc(deliveries, func(a, b sqlc.WebhookDelivery) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	for _, delivery := range deliveries {
+		if err := worker.attempt(ctx, delivery); err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// attempt sends a delivery once and records the outcome in the delivery log
+func (worker *Worker) attempt(ctx context.Context, delivery sqlc.WebhookDelivery) error {
+	subscription, err := worker.store.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		// the subscription was deleted since, and its deliveries with it
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	status, sendErr := worker.send(ctx, subscription, delivery)
+	responseStatus := sql.NullInt32{Int32: int32(status), Valid: status != 0}
+	if sendErr != nil && ctx.Err() == nil {
+		log.Printf("webhook worker: delivery %d to %s: %v", delivery.ID, subscription.Url, sendErr)
+	}
+	if sendErr == nil {
+		return worker.store.MarkWebhookDeliveryDelivered(ctx, sqlc.MarkWebhookDeliveryDeliveredParams{
+			ID:             delivery.ID,
+			ResponseStatus: responseStatus,
+			DeliveredAt:    sql.NullTime{Time: worker.now(), Valid: true},
+		})
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	arg := sqlc.MarkWebhookDeliveryFailedParams{
+		ID:             delivery.ID,
+		Status:         outbox.StatusPending,
+		NextAttemptAt:  worker.now().Add(worker.Backoff(delivery.Attempts + 1)),
+		ResponseStatus: responseStatus,
+		LastError:      sql.NullString{String: describe(status, sendErr), Valid: true},
+	}
+	if delivery.Attempts+1 >= worker.config.MaxAttempts {
+		arg.Status = outbox.StatusDead
+		log.Printf("webhook worker: delivery %d to %s is dead after %d attempts: %v",
+			delivery.ID, subscription.Url, delivery.Attempts+1, sendErr)
+	}
+	return worker.store.MarkWebhookDeliveryFailed(ctx, arg)
+}
+
+// send posts the delivery, signed with the secret of the subscription. It returns the status the
+// receiver answered with, zero when no answer came back; anything but a 2xx is an error, a
+// redirect included. The body of the answer is never read, see describe.
+func (worker *Worker) send(ctx context.Context, subscription sqlc.WebhookSubscription, delivery sqlc.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := worker.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bank-api-webhooks")
+	req.Header.Set(HeaderID, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := worker.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver answered %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Backoff is how long the worker waits after the given number of failed attempts
+func (worker *Worker) Backoff(attempts int32) time.Duration {
+	return outbox.ExponentialBackoff(worker.config.BaseBackoff, worker.config.MaxBackoff, attempts)
+}
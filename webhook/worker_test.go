@@ -0,0 +1,218 @@
+package webhook_test
+
+import (
+	"bank-api/db/sqlc"
+	"bank-api/outbox"
+	"bank-api/util"
+	"bank-api/webhook"
+	"bank-api/webhook/webhooktest"
+	"context"
+	"database/sql"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func subscribe(t *testing.T, store sqlc.Store, url string, secret string, eventTypes ...string) sqlc.WebhookSubscription {
+	subscription, err := store.CreateWebhookSubscription(context.Background(), sqlc.CreateWebhookSubscriptionParams{
+		Url:        url,
+		EventTypes: eventTypes,
+		Secret:     secret,
+	})
+	require.NoError(t, err)
+	return subscription
+}
+
+func deliveriesOf(t *testing.T, store sqlc.Store, subscription sqlc.WebhookSubscription) []sqlc.WebhookDelivery {
+	deliveries, err := store.ListWebhookDeliveries(context.Background(), sqlc.ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          100,
+	})
+	require.NoError(t, err)
+	return deliveries
+}
+
+// signupEvent publishes an account.created event by signing up a customer, and hands it to the
+// dispatcher through the outbox relay
+func signupEvent(t *testing.T, store sqlc.Store) sqlc.Account {
+	result, err := store.CreateAccountTx(context.Background(), sqlc.CreateAccountTxParams{
+		CreateAccountParams: sqlc.CreateAccountParams{
+			Owner:     util.RandomOwner(),
+			Email:     util.RandomEmail(),
+			Currency:  util.JPY,
+			CreatedAt: time.Now(),
+		},
+		HashedPassword: "secret",
+	})
+	require.NoError(t, err)
+
+	_, err = outbox.NewRelay(store, webhook.NewDispatcher(store), outbox.Config{}).RelayBatch(context.Background())
+	require.NoError(t, err)
+	return result.Account
+}
+
+func TestWorkerDelivers(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	receiver := webhooktest.NewReceiver("whsec_partner")
+	defer receiver.Close()
+
+	subscription := subscribe(t, store, receiver.URL, "whsec_partner", sqlc.EventAccountCreated)
+	account := signupEvent(t, store)
+
+	worker := webhook.NewWorker(store, webhook.Config{AllowPrivateNetworks: true})
+	claimed, err := worker.SendBatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, claimed)
+
+	received := receiver.Deliveries()
+	require.Len(t, received, 1)
+	require.Equal(t, sqlc.EventAccountCreated, received[0].Event)
+	require.Equal(t, sqlc.EventAccountCreated, received[0].Payload.Type)
+	require.Equal(t, "application/json", received[0].Header.Get("Content-Type"))
+	require.Contains(t, string(received[0].Payload.Data), `"account_id":`+strconv.FormatInt(account.ID, 10))
+
+	log := deliveriesOf(t, store, subscription)
+	require.Len(t, log, 1)
+	require.Equal(t, strconv.FormatInt(log[0].ID, 10), received[0].ID)
+	require.Equal(t, outbox.StatusDelivered, log[0].Status)
+	require.Equal(t, int32(1), log[0].Attempts)
+	require.Equal(t, sql.NullInt32{Int32: http.StatusNoContent, Valid: true}, log[0].ResponseStatus)
+	require.True(t, log[0].DeliveredAt.Valid)
+
+	// nothing is left to send
+	claimed, err = worker.SendBatch(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, claimed)
+}
+
+func TestWorkerRetries(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	receiver := webhooktest.NewReceiver("whsec_partner")
+	defer receiver.Close()
+
+	subscription := subscribe(t, store, receiver.URL, "whsec_partner")
+	signupEvent(t, store)
+
+	worker, now := webhook.NewTestWorker(store, webhook.Config{BaseBackoff: 10 * time.Second, AllowPrivateNetworks: true})
+	receiver.FailNext(2, http.StatusServiceUnavailable)
+
+	_, err := worker.SendBatch(context.Background())
+	require.NoError(t, err)
+
+	log := deliveriesOf(t, store, subscription)
+	require.Equal(t, outbox.StatusPending, log[0].Status)
+	require.Equal(t, int32(1), log[0].Attempts)
+	require.Equal(t, sql.NullInt32{Int32: http.StatusServiceUnavailable, Valid: true}, log[0].ResponseStatus)
+	// the subscriber sees the status, never what the receiver answered
+	require.Equal(t, "receiver answered 503 Service Unavailable", log[0].LastError.String)
+	require.Equal(t, now.Add(10*time.Second), log[0].NextAttemptAt)
+
+	// not due before the backoff ran out
+	claimed, err := worker.SendBatch(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, claimed)
+
+	*now = now.Add(10 * time.Second)
+	_, err = worker.SendBatch(context.Background())
+	require.NoError(t, err)
+	log = deliveriesOf(t, store, subscription)
+	require.Equal(t, int32(2), log[0].Attempts)
+	require.Equal(t, now.Add(20*time.Second), log[0].NextAttemptAt)
+
+	*now = now.Add(20 * time.Second)
+	_, err = worker.SendBatch(context.Background())
+	require.NoError(t, err)
+	log = deliveriesOf(t, store, subscription)
+	require.Equal(t, outbox.StatusDelivered, log[0].Status)
+	require.Equal(t, int32(3), log[0].Attempts)
+	require.False(t, log[0].LastError.Valid)
+	require.Len(t, receiver.Deliveries(), 1)
+}
+
+func TestWorkerDeadLetter(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	receiver := webhooktest.NewReceiver("whsec_partner")
+	defer receiver.Close()
+
+	// the receiver expects another secret and rejects every request
+	subscription := subscribe(t, store, receiver.URL, "whsec_wrong")
+	healthy := subscribe(t, store, receiver.URL+"/other", "whsec_partner")
+	signupEvent(t, store)
+
+	worker, now := webhook.NewTestWorker(store, webhook.Config{MaxAttempts: 2, BaseBackoff: time.Second, AllowPrivateNetworks: true})
+	for i := 0; i < 3; i++ {
+		_, err := worker.SendBatch(context.Background())
+		require.NoError(t, err)
+		*now = now.Add(time.Minute)
+	}
+
+	log := deliveriesOf(t, store, subscription)
+	require.Equal(t, outbox.StatusDead, log[0].Status)
+	require.Equal(t, int32(2), log[0].Attempts)
+	require.Equal(t, sql.NullInt32{Int32: http.StatusUnauthorized, Valid: true}, log[0].ResponseStatus)
+	require.Equal(t, 2, receiver.Rejected())
+
+	// a receiver that fails holds up no other
+	require.Equal(t, outbox.StatusDelivered, deliveriesOf(t, store, healthy)[0].Status)
+}
+
+func TestWorkerUnreachable(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	receiver := webhooktest.NewReceiver("whsec_partner")
+	url := receiver.URL
+	receiver.Close()
+
+	subscription := subscribe(t, store, url, "whsec_partner")
+	signupEvent(t, store)
+
+	worker := webhook.NewWorker(store, webhook.Config{AllowPrivateNetworks: true})
+	_, err := worker.SendBatch(context.Background())
+	require.NoError(t, err)
+
+	log := deliveriesOf(t, store, subscription)
+	require.Equal(t, outbox.StatusPending, log[0].Status)
+	require.False(t, log[0].ResponseStatus.Valid)
+	require.Equal(t, "receiver could not be reached", log[0].LastError.String)
+}
+
+func TestWorkerRefusesPrivateNetworks(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	receiver := webhooktest.NewReceiver("whsec_partner")
+	defer receiver.Close()
+
+	subscription := subscribe(t, store, receiver.URL, "whsec_partner")
+	signupEvent(t, store)
+
+	// the receiver listens on loopback, like a service inside the deployment would
+	worker := webhook.NewWorker(store, webhook.Config{})
+	_, err := worker.SendBatch(context.Background())
+	require.NoError(t, err)
+
+	log := deliveriesOf(t, store, subscription)
+	require.Equal(t, outbox.StatusPending, log[0].Status)
+	require.Equal(t, "receiver address is not allowed", log[0].LastError.String)
+	require.Empty(t, receiver.Deliveries())
+}
+
+func TestWorkerDoesNotFollowRedirects(t *testing.T) {
+	store := sqlc.NewMemoryStore()
+	receiver := webhooktest.NewReceiver("whsec_partner")
+	defer receiver.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(receiver.URL, http.StatusTemporaryRedirect))
+	defer redirect.Close()
+
+	subscription := subscribe(t, store, redirect.URL, "whsec_partner")
+	signupEvent(t, store)
+
+	worker := webhook.NewWorker(store, webhook.Config{AllowPrivateNetworks: true})
+	_, err := worker.SendBatch(context.Background())
+	require.NoError(t, err)
+
+	log := deliveriesOf(t, store, subscription)
+	require.Equal(t, outbox.StatusPending, log[0].Status)
+	require.Equal(t, sql.NullInt32{Int32: http.StatusTemporaryRedirect, Valid: true}, log[0].ResponseStatus)
+	require.Empty(t, receiver.Deliveries())
+}